@@ -0,0 +1,12 @@
+// Package accountnames embeds the deprecated lists of known addresses in this
+// directory. Prefer named-addresses; Nexus only uses these lists for addresses
+// that are not named there.
+package accountnames
+
+import "embed"
+
+// Files holds the lists of known addresses, one `<chain>_<layer>.json` file
+// per chain and layer. The `paratime` layer refers to Emerald.
+//
+//go:embed *.json
+var Files embed.FS
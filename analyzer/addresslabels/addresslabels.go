@@ -0,0 +1,269 @@
+// Package addresslabels implements the address labels analyzer, which keeps
+// chain.address_labels in sync with the lists of known addresses bundled with
+// Nexus and, optionally, with a signed remote registry of labels.
+package addresslabels
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"time"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+
+	accountnames "github.com/oasisprotocol/nexus/account-names"
+	"github.com/oasisprotocol/nexus/analyzer"
+	"github.com/oasisprotocol/nexus/analyzer/httpmisc"
+	"github.com/oasisprotocol/nexus/analyzer/item"
+	"github.com/oasisprotocol/nexus/analyzer/pubclient"
+	"github.com/oasisprotocol/nexus/analyzer/queries"
+	"github.com/oasisprotocol/nexus/analyzer/util/addresses"
+	"github.com/oasisprotocol/nexus/common"
+	"github.com/oasisprotocol/nexus/config"
+	staking "github.com/oasisprotocol/nexus/coreapi/v22.2.11/staking/api"
+	"github.com/oasisprotocol/nexus/log"
+	namedaddresses "github.com/oasisprotocol/nexus/named-addresses"
+	"github.com/oasisprotocol/nexus/storage"
+)
+
+const (
+	AddressLabelsAnalyzerName = "address_labels"
+
+	registryMaxResponseSize = 10 * 1024 * 1024 // 10 MiB.
+)
+
+// Sources of address labels. When several sources label the same address,
+// the label from the later source in this list wins.
+const (
+	SourceAccountNames   = "account_names"
+	SourceNamedAddresses = "named_addresses"
+	SourceRegistry       = "registry"
+)
+
+var knownLayers = map[common.Layer]struct{}{
+	common.LayerConsensus:   {},
+	common.LayerEmerald:     {},
+	common.LayerCipher:      {},
+	common.LayerSapphire:    {},
+	common.LayerPontusxTest: {},
+	common.LayerPontusxDev:  {},
+}
+
+// entry is an entry of a list of known addresses, as found in named-addresses/*.json.
+type entry struct {
+	Name        string `json:"Name"`
+	Address     string `json:"Address"`
+	Description string `json:"Description"`
+}
+
+// signedRegistry is the document served by a remote registry of labels.
+type signedRegistry struct {
+	// Labels is the JSON encoding of the labels, keyed by layer, in the
+	// same format as named-addresses/*.json.
+	Labels []byte `json:"labels"`
+	// Signature is the Ed25519 signature of Labels.
+	Signature []byte `json:"signature"`
+}
+
+type labelKey struct {
+	layer   common.Layer
+	address string
+}
+
+type label struct {
+	name        string
+	description string
+	source      string
+}
+
+// labelSet holds the labels of a chain, keyed by layer and Oasis address.
+type labelSet map[labelKey]label
+
+// add adds the entries to the set, replacing existing labels of the same addresses.
+func (s labelSet) add(layer common.Layer, source string, entries []entry) error {
+	if _, ok := knownLayers[layer]; !ok {
+		return fmt.Errorf("unknown layer %s", layer)
+	}
+	for _, e := range entries {
+		addr, err := parseAddress(e.Address)
+		if err != nil {
+			return fmt.Errorf("label %q: %w", e.Name, err)
+		}
+		if e.Name == "" {
+			return fmt.Errorf("label of address %s has no name", e.Address)
+		}
+		s[labelKey{layer, addr}] = label{
+			name:        e.Name,
+			description: e.Description,
+			source:      source,
+		}
+	}
+	return nil
+}
+
+// parseAddress returns the Oasis address of an Oasis (bech32) or Ethereum (hex) address.
+func parseAddress(addr string) (string, error) {
+	if ethCommon.IsHexAddress(addr) {
+		oasisAddr, err := addresses.FromEthAddress(ethCommon.HexToAddress(addr).Bytes())
+		if err != nil {
+			return "", err
+		}
+		return string(oasisAddr), nil
+	}
+	var oasisAddr staking.Address
+	if err := oasisAddr.UnmarshalText([]byte(addr)); err != nil {
+		return "", fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+	return oasisAddr.String(), nil
+}
+
+// addStatic adds the labels of the chain from the `<chain>_<layer>.json` files in fsys.
+// The `paratime` layer, used by the deprecated account-names lists, refers to Emerald.
+func (s labelSet) addStatic(fsys fs.FS, chain common.ChainName, source string) error {
+	files, err := fs.Glob(fsys, string(chain)+"_*.json")
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		layer := common.Layer(strings.TrimSuffix(strings.TrimPrefix(file, string(chain)+"_"), ".json"))
+		if layer == "paratime" {
+			layer = common.LayerEmerald
+		}
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		var entries []entry
+		if err := json.Unmarshal(content, &entries); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if err := s.add(layer, source, entries); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// addRegistry verifies the signed registry document and adds its labels.
+func (s labelSet) addRegistry(r io.Reader, publicKey ed25519.PublicKey) error {
+	var registry signedRegistry
+	if err := json.NewDecoder(r).Decode(&registry); err != nil {
+		return fmt.Errorf("decoding registry: %w", err)
+	}
+	if !ed25519.Verify(publicKey, registry.Labels, registry.Signature) {
+		return fmt.Errorf("invalid registry signature")
+	}
+	var layers map[common.Layer][]entry
+	if err := json.Unmarshal(registry.Labels, &layers); err != nil {
+		return fmt.Errorf("decoding registry labels: %w", err)
+	}
+	for layer, entries := range layers {
+		if err := s.add(layer, SourceRegistry, entries); err != nil {
+			return fmt.Errorf("registry: %w", err)
+		}
+	}
+	return nil
+}
+
+type processor struct {
+	chain             common.ChainName
+	registryURL       string
+	registryPublicKey ed25519.PublicKey
+	target            storage.TargetStorage
+	logger            *log.Logger
+}
+
+var _ item.ItemProcessor[struct{}] = (*processor)(nil)
+
+func NewAnalyzer(
+	chain common.ChainName,
+	cfg config.AddressLabelsConfig,
+	target storage.TargetStorage,
+	logger *log.Logger,
+) (analyzer.Analyzer, error) {
+	logger.Info("Starting address_labels analyzer")
+	if cfg.Interval == 0 {
+		cfg.Interval = 10 * time.Minute
+	}
+	logger = logger.With("analyzer", AddressLabelsAnalyzerName)
+	p := &processor{
+		chain:       chain,
+		registryURL: cfg.RegistryURL,
+		target:      target,
+		logger:      logger,
+	}
+	if cfg.RegistryURL != "" {
+		publicKey, err := base64.StdEncoding.DecodeString(cfg.RegistryPublicKey)
+		if err != nil || len(publicKey) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid registry public key %q", cfg.RegistryPublicKey)
+		}
+		p.registryPublicKey = publicKey
+	}
+
+	return item.NewAnalyzer[struct{}](
+		AddressLabelsAnalyzerName,
+		cfg.ItemBasedAnalyzerConfig,
+		p,
+		target,
+		logger,
+	)
+}
+
+func (p *processor) GetItems(ctx context.Context, limit uint64) ([]struct{}, error) {
+	return []struct{}{{}}, nil
+}
+
+func (p *processor) ProcessItem(ctx context.Context, batch *storage.QueryBatch, item struct{}) error {
+	labels := labelSet{}
+	if err := labels.addStatic(accountnames.Files, p.chain, SourceAccountNames); err != nil {
+		return fmt.Errorf("loading account names: %w", err)
+	}
+	if err := labels.addStatic(namedaddresses.Files, p.chain, SourceNamedAddresses); err != nil {
+		return fmt.Errorf("loading named addresses: %w", err)
+	}
+	if p.registryURL != "" {
+		if err := p.fetchRegistry(ctx, labels); err != nil {
+			// Keep the labels from the last successful fetch rather than dropping them.
+			return fmt.Errorf("fetching registry %s: %w", p.registryURL, err)
+		}
+	}
+
+	// Replace all labels, so that labels removed from their source are removed here too.
+	batch.Queue(queries.AddressLabelsDelete)
+	for key, l := range labels {
+		batch.Queue(
+			queries.AddressLabelInsert,
+			key.layer,
+			key.address,
+			l.name,
+			l.description,
+			l.source,
+		)
+	}
+	p.logger.Info("updated address labels", "num_labels", len(labels))
+
+	return nil
+}
+
+func (p *processor) fetchRegistry(ctx context.Context, labels labelSet) error {
+	resp, err := pubclient.GetWithContext(ctx, p.registryURL)
+	if err != nil {
+		return err
+	}
+	if err = httpmisc.ResponseOK(resp); err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return labels.addRegistry(io.LimitReader(resp.Body, registryMaxResponseSize), p.registryPublicKey)
+}
+
+func (p *processor) QueueLength(ctx context.Context) (int, error) {
+	// The concept of a work queue does not apply to this analyzer.
+	return 0, nil
+}
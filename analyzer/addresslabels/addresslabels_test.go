@@ -0,0 +1,71 @@
+package addresslabels
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	accountnames "github.com/oasisprotocol/nexus/account-names"
+	"github.com/oasisprotocol/nexus/common"
+	namedaddresses "github.com/oasisprotocol/nexus/named-addresses"
+)
+
+func TestStaticLabels(t *testing.T) {
+	for _, chain := range []common.ChainName{common.ChainNameMainnet, common.ChainNameTestnet} {
+		labels := labelSet{}
+		require.NoError(t, labels.addStatic(accountnames.Files, chain, SourceAccountNames), chain)
+		require.NoError(t, labels.addStatic(namedaddresses.Files, chain, SourceNamedAddresses), chain)
+		require.NotEmpty(t, labels, chain)
+
+		for key, l := range labels {
+			require.Contains(t, knownLayers, key.layer)
+			require.NotEmpty(t, l.name)
+		}
+	}
+
+	// Named addresses take precedence over the deprecated account names.
+	labels := labelSet{}
+	require.NoError(t, labels.addStatic(accountnames.Files, common.ChainNameMainnet, SourceAccountNames))
+	require.NoError(t, labels.addStatic(namedaddresses.Files, common.ChainNameMainnet, SourceNamedAddresses))
+	l, ok := labels[labelKey{common.LayerConsensus, "oasis1qrmufhkkyyf79s5za2r8yga9gnk4t446dcy3a5zm"}]
+	require.True(t, ok)
+	require.Equal(t, SourceNamedAddresses, l.source)
+}
+
+func TestRegistryLabels(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	payload := []byte(`{
+		"consensus": [{"Name": "Some Validator", "Address": "oasis1qrmufhkkyyf79s5za2r8yga9gnk4t446dcy3a5zm"}],
+		"sapphire": [{"Name": "Some Contract", "Address": "0x0000000000000000000000000000000000001234", "Description": "A contract."}]
+	}`)
+	signed := func(labels []byte, signature []byte) *bytes.Reader {
+		doc, err2 := json.Marshal(signedRegistry{Labels: labels, Signature: signature})
+		require.NoError(t, err2)
+		return bytes.NewReader(doc)
+	}
+
+	labels := labelSet{}
+	require.NoError(t, labels.addRegistry(signed(payload, ed25519.Sign(privateKey, payload)), publicKey))
+	require.Len(t, labels, 2)
+	l := labels[labelKey{common.LayerConsensus, "oasis1qrmufhkkyyf79s5za2r8yga9gnk4t446dcy3a5zm"}]
+	require.Equal(t, "Some Validator", l.name)
+	require.Equal(t, SourceRegistry, l.source)
+
+	// Tampered labels.
+	tampered := bytes.Replace(payload, []byte("Some Validator"), []byte("Evil Validator"), 1)
+	require.Error(t, labels.addRegistry(signed(tampered, ed25519.Sign(privateKey, payload)), publicKey))
+
+	// Wrong key.
+	otherPublicKey, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	require.Error(t, labels.addRegistry(signed(payload, ed25519.Sign(privateKey, payload)), otherPublicKey))
+
+	// Unknown layer.
+	unknownLayer := []byte(`{"foo": [{"Name": "Some Validator", "Address": "oasis1qrmufhkkyyf79s5za2r8yga9gnk4t446dcy3a5zm"}]}`)
+	require.Error(t, labels.addRegistry(signed(unknownLayer, ed25519.Sign(privateKey, unknownLayer)), publicKey))
+}
@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/jackc/pgx/v5"
-
 	"github.com/oasisprotocol/nexus/analyzer"
 	"github.com/oasisprotocol/nexus/common"
 	"github.com/oasisprotocol/nexus/log"
@@ -181,6 +179,16 @@ func (a *aggregateStatsAnalyzer) aggregateStatsWorker(ctx context.Context) {
 		statsComputations = append(statsComputations, sc)
 	}
 
+	// Compute price candles of swap pairs for all runtimes: 5-minute candles
+	// from the recorded reserves, and hourly and daily candles from the candles
+	// of the next smaller interval.
+	for _, layer := range statsLayers {
+		if layer == layerConsensus {
+			continue
+		}
+		statsComputations = append(statsComputations, a.swapCandlesComputations(layer)...)
+	}
+
 	for {
 		// If batch limit was reached, or the batch timeout was reached, start the next iteration sooner.
 		var useCatchupTimeout bool
@@ -206,14 +214,14 @@ func (a *aggregateStatsAnalyzer) aggregateStatsWorker(ctx context.Context) {
 			switch {
 			case err == nil:
 				// Continues below.
-			case errors.Is(err, pgx.ErrNoRows):
+			case errors.Is(err, storage.ErrNoRows):
 				// No stats yet. Start at the earliest indexed block.
 				var earliestBlockTs *time.Time
 				earliestBlockTs, err = a.earliestBlockTs(statCtx, statsComputation.layer)
 				switch {
 				case err == nil:
 					latestComputed = floorWindow(earliestBlockTs)
-				case errors.Is(err, pgx.ErrNoRows):
+				case errors.Is(err, storage.ErrNoRows):
 					// No data log a debug only log.
 					logger.Debug("no stats available yet, skipping iteration")
 					cancel()
@@ -234,7 +242,7 @@ func (a *aggregateStatsAnalyzer) aggregateStatsWorker(ctx context.Context) {
 			switch {
 			case err == nil:
 				// Continues below.
-			case errors.Is(err, pgx.ErrNoRows):
+			case errors.Is(err, storage.ErrNoRows):
 				logger.Debug("no stats available yet, skipping iteration")
 				cancel()
 				continue
@@ -297,9 +305,67 @@ func (a *aggregateStatsAnalyzer) aggregateStatsWorker(ctx context.Context) {
 	}
 }
 
+// swapCandlesComputations returns the computations of the 5-minute, hourly
+// and daily price candles of the swap pairs of a runtime. Each window's
+// candles are replaced when it is computed. After a reorg, the runtime
+// analyzer deletes the candles since the fork and rolls back the progress
+// of these computations, so that the windows are computed again.
+func (a *aggregateStatsAnalyzer) swapCandlesComputations(runtime string) []*statsComputation {
+	intervals := []struct {
+		name   string
+		length time.Duration
+	}{
+		{"5m", 5 * time.Minute},
+		{"1h", time.Hour},
+		{"1d", 24 * time.Hour},
+	}
+	var computations []*statsComputation
+	for i, interval := range intervals {
+		interval := interval
+		sc := &statsComputation{
+			target:      a.target,
+			name:        "swap_candles_" + interval.name + "_" + runtime,
+			layer:       runtime,
+			outputTable: "stats.evm_swap_pair_candles_computed",
+			progressKey: runtime + "_" + interval.name,
+			windowSize:  interval.length,
+			windowStep:  interval.length,
+		}
+		if i == 0 {
+			sc.latestAvailableDataTs = func(ctx context.Context, target storage.TargetStorage) (*time.Time, error) {
+				var latestBlockTs *time.Time
+				return latestBlockTs, target.QueryRow(ctx, QueryLatestRuntimeBlockTime, runtime).Scan(&latestBlockTs)
+			}
+			sc.computeBatch = func(ctx context.Context, windowStart time.Time, windowEnd time.Time) (*storage.QueryBatch, error) {
+				batch := &storage.QueryBatch{}
+				batch.Queue(QueryDeleteSwapCandles, runtime, interval.name, windowStart.UTC())
+				batch.Queue(QueryInsertMin5SwapCandles, runtime, windowStart.UTC(), windowEnd.UTC())
+				batch.Queue(QueryUpsertSwapCandlesComputed, sc.progressKey, windowEnd.UTC())
+				return batch, nil
+			}
+		} else {
+			source := intervals[i-1].name
+			// Latest available data is the latest computed window of the next smaller interval.
+			sc.latestAvailableDataTs = func(ctx context.Context, target storage.TargetStorage) (*time.Time, error) {
+				var latestTs *time.Time
+				return latestTs, target.QueryRow(ctx, fmt.Sprintf(QueryLatestStatsComputation, sc.outputTable), runtime+"_"+source).Scan(&latestTs)
+			}
+			sc.computeBatch = func(ctx context.Context, windowStart time.Time, windowEnd time.Time) (*storage.QueryBatch, error) {
+				batch := &storage.QueryBatch{}
+				batch.Queue(QueryDeleteSwapCandles, runtime, interval.name, windowStart.UTC())
+				batch.Queue(QueryInsertAggregatedSwapCandles, runtime, windowStart.UTC(), windowEnd.UTC(), interval.name, source)
+				batch.Queue(QueryUpsertSwapCandlesComputed, sc.progressKey, windowEnd.UTC())
+				return batch, nil
+			}
+		}
+		computations = append(computations, sc)
+	}
+	return computations
+}
+
 // Queries the earliest indexed block for the specified layer.
 func (a *aggregateStatsAnalyzer) earliestBlockTs(ctx context.Context, layer string) (*time.Time, error) {
-	var earliestBlockTsRow pgx.Row
+	var earliestBlockTsRow storage.QueryResult
 	switch layer {
 	case layerConsensus:
 		earliestBlockTsRow = a.target.QueryRow(ctx, QueryEarliestConsensusBlockTime)
@@ -117,4 +117,90 @@ const (
 		ORDER BY window_end DESC
 		LIMIT 1
 	`
+
+	// QueryDeleteSwapCandles is the query to delete the candles of interval $2
+	// of all swap pairs of runtime $1 in the window that starts at $3, so that
+	// recomputing a window does not keep candles of pairs whose syncs were
+	// unwound in a reorg.
+	QueryDeleteSwapCandles = `
+		DELETE FROM stats.evm_swap_pair_candles
+		WHERE runtime = $1 AND interval = $2::text AND window_start = $3::timestamptz
+	`
+
+	// QueryInsertMin5SwapCandles is the query to compute the 5-minute price
+	// candles of all swap pairs of runtime $1 whose reserves changed in the
+	// given time range.
+	QueryInsertMin5SwapCandles = `
+		INSERT INTO stats.evm_swap_pair_candles (runtime, pair_address, interval, window_start, open, high, low, close, volume0, volume1)
+		SELECT
+			$1, pair_address, '5m', $2::timestamptz,
+			(array_agg(price ORDER BY round))[1],
+			MAX(price),
+			MIN(price),
+			(array_agg(price ORDER BY round DESC))[1],
+			SUM(volume0),
+			SUM(volume1)
+		FROM (
+			SELECT
+				pair_address,
+				round,
+				-- Uniswap V3 pools have an exact price, even when they have no active liquidity.
+				CASE
+					WHEN sqrt_price_x96 IS NOT NULL THEN (sqrt_price_x96::double precision / 2::double precision ^ 96) ^ 2
+					ELSE reserve1::double precision / reserve0::double precision
+				END AS price,
+				volume0,
+				volume1
+			FROM chain.evm_swap_pair_syncs
+			WHERE
+				runtime = $1 AND timestamp >= $2::timestamptz AND timestamp < $3::timestamptz AND
+				(sqrt_price_x96 > 0 OR (sqrt_price_x96 IS NULL AND reserve0 > 0 AND reserve1 > 0))
+		) AS syncs
+		GROUP BY pair_address
+		ON CONFLICT (runtime, pair_address, interval, window_start) DO UPDATE
+		SET
+			open = excluded.open,
+			high = excluded.high,
+			low = excluded.low,
+			close = excluded.close,
+			volume0 = excluded.volume0,
+			volume1 = excluded.volume1
+	`
+
+	// QueryInsertAggregatedSwapCandles is the query to compute the price
+	// candles of interval $4 of all swap pairs of runtime $1 in the given
+	// time range, by merging the already computed candles of interval $5.
+	QueryInsertAggregatedSwapCandles = `
+		INSERT INTO stats.evm_swap_pair_candles (runtime, pair_address, interval, window_start, open, high, low, close, volume0, volume1)
+		SELECT
+			$1, pair_address, $4::text, $2::timestamptz,
+			(array_agg(open ORDER BY window_start))[1],
+			MAX(high),
+			MIN(low),
+			(array_agg(close ORDER BY window_start DESC))[1],
+			SUM(volume0),
+			SUM(volume1)
+		FROM stats.evm_swap_pair_candles
+		WHERE
+			runtime = $1 AND interval = $5::text AND
+			window_start >= $2::timestamptz AND window_start < $3::timestamptz
+		GROUP BY pair_address
+		ON CONFLICT (runtime, pair_address, interval, window_start) DO UPDATE
+		SET
+			open = excluded.open,
+			high = excluded.high,
+			low = excluded.low,
+			close = excluded.close,
+			volume0 = excluded.volume0,
+			volume1 = excluded.volume1
+	`
+
+	// QueryUpsertSwapCandlesComputed is the query to record the end of the
+	// latest window for which candles were computed.
+	QueryUpsertSwapCandlesComputed = `
+		INSERT INTO stats.evm_swap_pair_candles_computed (layer, window_end)
+		VALUES ($1, $2)
+		ON CONFLICT (layer) DO UPDATE
+		SET window_end = excluded.window_end
+	`
 )
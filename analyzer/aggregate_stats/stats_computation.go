@@ -31,19 +31,34 @@ type statsComputation struct {
 	// $3 - end of window timestamp
 	// The result should be a uint64 compatible number - the computed stat.
 	statsQuery string
+
+	// Optional. Computes the stats for a window as a batch of queries, instead
+	// of running statsQuery and inserting its result into outputTable. The batch
+	// must record the progress of the computation in outputTable.
+	computeBatch func(ctx context.Context, windowStart time.Time, windowEnd time.Time) (*storage.QueryBatch, error)
+	// Optional. The key under which the progress is recorded in outputTable,
+	// if not the layer.
+	progressKey string
 }
 
 func (s *statsComputation) LatestComputedTs(ctx context.Context) (time.Time, error) {
+	key := s.layer
+	if s.progressKey != "" {
+		key = s.progressKey
+	}
 	var latestStatsTs time.Time
 	err := s.target.QueryRow(
 		ctx,
 		fmt.Sprintf(QueryLatestStatsComputation, s.outputTable),
-		s.layer,
+		key,
 	).Scan(&latestStatsTs)
 	return latestStatsTs, err
 }
 
 func (s *statsComputation) ComputeStats(ctx context.Context, windowStart time.Time, windowEnd time.Time) (*storage.QueryBatch, error) {
+	if s.computeBatch != nil {
+		return s.computeBatch(ctx, windowStart, windowEnd)
+	}
 	batch := &storage.QueryBatch{}
 	row := s.target.QueryRow(
 		ctx,
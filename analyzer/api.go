@@ -13,6 +13,11 @@ var (
 	// ErrLatestBlockNotFound is returned if the analyzer has not indexed any
 	// blocks yet. This indicates to begin from the start of its range.
 	ErrLatestBlockNotFound = errors.New("latest block not found")
+
+	// ErrReorg is returned if the current block does not build on the
+	// previously processed blocks, and the processor has unwound the blocks
+	// since the fork. The analyzer should re-process them.
+	ErrReorg = errors.New("chain reorganization; unwound blocks since the fork")
 )
 
 // Analyzer is a worker that analyzes a subset of the Oasis Network.
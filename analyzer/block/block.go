@@ -10,8 +10,6 @@ import (
 	"math"
 	"time"
 
-	"github.com/jackc/pgx/v5"
-
 	"github.com/oasisprotocol/nexus/analyzer"
 	"github.com/oasisprotocol/nexus/analyzer/queries"
 	"github.com/oasisprotocol/nexus/analyzer/util"
@@ -75,7 +73,7 @@ type blockBasedAnalyzer struct {
 }
 
 // firstUnprocessedBlock returns the first block before which all blocks have been processed.
-// If no blocks have been processed, it returns error pgx.ErrNoRows.
+// If no blocks have been processed, it returns error storage.ErrNoRows.
 func (b *blockBasedAnalyzer) firstUnprocessedBlock(ctx context.Context) (first uint64, err error) {
 	err = b.target.QueryRow(
 		ctx,
@@ -105,7 +103,7 @@ func (b *blockBasedAnalyzer) fetchBatchForProcessing(ctx context.Context, from u
 	var (
 		tx      storage.Tx
 		heights []uint64
-		rows    pgx.Rows
+		rows    storage.QueryResults
 		err     error
 	)
 
@@ -285,7 +283,7 @@ func (b *blockBasedAnalyzer) nodeHeight(ctx context.Context) (int, error) {
 	switch err {
 	case nil:
 		return nodeHeight, nil
-	case pgx.ErrNoRows:
+	case storage.ErrNoRows:
 		return -1, nil
 	default:
 		return -1, fmt.Errorf("error fetching chain height for consensus: %w", err)
@@ -421,6 +419,10 @@ func (b *blockBasedAnalyzer) Start(ctx context.Context) {
 						"height", height,
 						"retry_interval_ms", backoff.Timeout().Milliseconds(),
 					)
+				} else if err == analyzer.ErrReorg {
+					b.logger.Warn("chain reorganization; will re-process unwound blocks",
+						"height", height,
+					)
 				} else {
 					b.logger.Error("error processing block", "height", height, "err", err)
 				}
@@ -9,6 +9,7 @@ import (
 	"math"
 	"os"
 	"reflect"
+	"sort"
 	"strings"
 
 	coreCommon "github.com/oasisprotocol/oasis-core/go/common"
@@ -127,6 +128,11 @@ func (m *processor) PreWork(ctx context.Context) error {
 	}
 	m.logger.Info("inserted static account first active timestamps")
 
+	// Proposals imported from genesis before their parameters were backfilled at import.
+	if m.mode != analyzer.FastSyncMode {
+		return m.backfillProposalParameters(ctx)
+	}
+
 	return nil
 }
 
@@ -244,6 +250,51 @@ func (m *processor) processGenesis(ctx context.Context, genesisDoc *nodeapi.Gene
 	}
 	m.logger.Info("genesis document processed")
 
+	return m.backfillProposalParameters(ctx)
+}
+
+// backfillProposalParameters records the governance parameters of proposals that were
+// not submitted or finalized in a slow-sync block, e.g. proposals from the genesis
+// document, as of the epoch that they were submitted at, or finalized at if they are
+// closed. Proposals from epochs without known heights are left without parameters, as
+// are proposals whose parameters cannot be fetched, e.g. because no archive node of
+// their chain is configured.
+func (m *processor) backfillProposalParameters(ctx context.Context) error {
+	rows, err := m.target.Query(ctx, queries.ConsensusProposalsWithoutParameters)
+	if err != nil {
+		return fmt.Errorf("querying proposals without parameters: %w", err)
+	}
+	defer rows.Close()
+	type proposalHeight struct {
+		id     uint64
+		height int64
+	}
+	var proposals []proposalHeight
+	for rows.Next() {
+		var p proposalHeight
+		if err = rows.Scan(&p.id, &p.height); err != nil {
+			return fmt.Errorf("scanning proposal without parameters: %w", err)
+		}
+		proposals = append(proposals, p)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	batch := &storage.QueryBatch{}
+	for _, p := range proposals {
+		params, err := m.source.GetGovernanceParameters(ctx, p.height)
+		if err != nil {
+			m.logger.Warn("failed to fetch governance parameters of proposal", "proposal_id", p.id, "height", p.height, "err", err)
+			continue
+		}
+		m.queueProposalParameters(batch, p.id, params)
+	}
+	if err := m.target.SendBatch(ctx, batch); err != nil {
+		return fmt.Errorf("backfilling proposal parameters: %w", err)
+	}
+	m.logger.Info("backfilled proposal parameters", "num_proposals", batch.Len())
+
 	return nil
 }
 
@@ -253,38 +304,50 @@ func (m *processor) queueDbUpdates(batch *storage.QueryBatch, data allData) erro
 		m.queueBlockInserts,
 		m.queueEpochInserts,
 		m.queueTransactionInserts,
-		m.queueTxEventInserts,
 	} {
 		if err := f(batch, data.BlockData); err != nil {
 			return err
 		}
 	}
 
+	// Events are collected from all modules first, so that they can be numbered
+	// consistently before they are inserted.
+	events := &blockEvents{}
+	if err := m.queueTxEventInserts(batch, events, data.BlockData); err != nil {
+		return err
+	}
+
 	for _, f := range []func(*storage.QueryBatch, *registryData) error{
 		m.queueEntityEvents,
 		m.queueRuntimeRegistrations,
-		m.queueRegistryEventInserts,
 	} {
 		if err := f(batch, data.RegistryData); err != nil {
 			return err
 		}
 	}
+	if err := m.queueRegistryEventInserts(events, data.RegistryData); err != nil {
+		return err
+	}
 	if err := m.queueNodeEvents(batch, data.RegistryData, uint64(data.BlockData.Epoch)); err != nil {
 		return err
 	}
 
 	for _, f := range []func(*storage.QueryBatch, *stakingData) error{
+		m.queueBalanceHistoryStart,
 		m.queueRegularTransfers,
 		m.queueBurns,
 		m.queueEscrows,
 		m.queueAllowanceChanges,
-		m.queueStakingEventInserts,
 		m.queueDisbursementTransfers,
+		m.queueBalanceHistoryFinish,
 	} {
 		if err := f(batch, data.StakingData); err != nil {
 			return err
 		}
 	}
+	if err := m.queueStakingEventInserts(events, data.StakingData); err != nil {
+		return err
+	}
 
 	for _, f := range []func(*storage.QueryBatch, *schedulerData) error{
 		m.queueValidatorUpdates,
@@ -300,22 +363,31 @@ func (m *processor) queueDbUpdates(batch *storage.QueryBatch, data allData) erro
 		m.queueExecutions,
 		m.queueFinalizations,
 		m.queueVotes,
-		m.queueGovernanceEventInserts,
 	} {
 		if err := f(batch, data.GovernanceData); err != nil {
 			return err
 		}
 	}
+	if err := m.queueGovernanceEventInserts(events, data.GovernanceData); err != nil {
+		return err
+	}
 
-	for _, f := range []func(*storage.QueryBatch, *rootHashData) error{
-		m.queueRootHashMessageUpserts,
-		m.queueRootHashEventInserts,
-	} {
-		if err := f(batch, data.RootHashData); err != nil {
-			return err
-		}
+	if err := m.queueVaultUpdates(batch, data.VaultData); err != nil {
+		return err
+	}
+	if err := m.queueVaultEventInserts(events, data.VaultData, data.BlockData); err != nil {
+		return err
 	}
 
+	if err := m.queueRootHashMessageUpserts(batch, data.RootHashData); err != nil {
+		return err
+	}
+	if err := m.queueRootHashEventInserts(events, data.RootHashData); err != nil {
+		return err
+	}
+
+	events.queueInserts(batch, data.BlockData.Height)
+
 	return nil
 }
 
@@ -356,6 +428,12 @@ func (m *processor) ProcessBlock(ctx context.Context, uheight uint64) error {
 		m.mode == analyzer.FastSyncMode,
 	)
 
+	// Announce the block to API subscribers. Blocks processed in fast-sync
+	// mode are out of order and are not announced.
+	if m.mode != analyzer.FastSyncMode {
+		batch.Queue(queries.NotifyNewBlock, storage.NewBlockNotifyChannel, string(common.LayerConsensus), height)
+	}
+
 	// Apply updates to DB.
 	opName := "process_block_consensus"
 	timer := m.metrics.DatabaseLatencies(m.target.Name(), opName)
@@ -586,7 +664,7 @@ func (m *processor) queueTransactionInserts(batch *storage.QueryBatch, data *con
 }
 
 // Enqueue DB statements to store events that were generated as the result of a TX execution.
-func (m *processor) queueTxEventInserts(batch *storage.QueryBatch, data *consensusBlockData) error {
+func (m *processor) queueTxEventInserts(batch *storage.QueryBatch, events *blockEvents, data *consensusBlockData) error {
 	for i, txr := range data.TransactionsWithResults {
 		txAccounts := []staking.Address{
 			// Always insert sender as a related address, some transactions (e.g. failed ones) might not have
@@ -598,23 +676,9 @@ func (m *processor) queueTxEventInserts(batch *storage.QueryBatch, data *consens
 		for _, event := range txr.Result.Events {
 			eventData := m.extractEventData(event)
 			txAccounts = append(txAccounts, eventData.relatedAddresses...)
-			accounts := extractUniqueAddresses(eventData.relatedAddresses)
-			body, err := json.Marshal(eventData.rawBody)
-			if err != nil {
+			if err := events.add(&eventData, common.Ptr(txr.Transaction.Hash().Hex()), common.Ptr(i)); err != nil {
 				return err
 			}
-
-			batch.Queue(queries.ConsensusEventInsert,
-				string(eventData.ty),
-				string(body),
-				data.Height,
-				txr.Transaction.Hash().Hex(),
-				i,
-				accounts,
-				common.StringOrNil(eventData.roothashRuntimeID),
-				eventData.roothashRuntime,
-				eventData.roothashRuntimeRound,
-			)
 		}
 		uniqueTxAccounts := extractUniqueAddresses(txAccounts)
 		for _, addr := range uniqueTxAccounts {
@@ -742,7 +806,7 @@ func (m *processor) queueNodeEvents(batch *storage.QueryBatch, data *registryDat
 	return nil
 }
 
-func (m *processor) queueRegistryEventInserts(batch *storage.QueryBatch, data *registryData) error {
+func (m *processor) queueRegistryEventInserts(events *blockEvents, data *registryData) error {
 	for _, event := range data.Events {
 		hash := util.SanitizeTxHash(event.TxHash.Hex())
 		if hash != nil {
@@ -751,7 +815,7 @@ func (m *processor) queueRegistryEventInserts(batch *storage.QueryBatch, data *r
 
 		eventData := m.extractEventData(event)
 
-		if err := m.queueSingleEventInserts(batch, &eventData, data.Height); err != nil {
+		if err := events.add(&eventData, nil, nil); err != nil {
 			return err
 		}
 	}
@@ -867,7 +931,7 @@ func (m *processor) queueRootHashMessageUpserts(batch *storage.QueryBatch, data
 	return nil
 }
 
-func (m *processor) queueRootHashEventInserts(batch *storage.QueryBatch, data *rootHashData) error {
+func (m *processor) queueRootHashEventInserts(events *blockEvents, data *rootHashData) error {
 	for _, event := range data.Events {
 		hash := util.SanitizeTxHash(event.TxHash.Hex())
 		if hash != nil {
@@ -876,7 +940,7 @@ func (m *processor) queueRootHashEventInserts(batch *storage.QueryBatch, data *r
 
 		eventData := m.extractEventData(event)
 
-		if err := m.queueSingleEventInserts(batch, &eventData, data.Height); err != nil {
+		if err := events.add(&eventData, nil, nil); err != nil {
 			return err
 		}
 	}
@@ -1126,6 +1190,66 @@ func (m *processor) queueEscrows(batch *storage.QueryBatch, data *stakingData) e
 	return nil
 }
 
+// balanceChangingAccounts returns the accounts whose dead-reckoned balances are
+// changed by the staking events of the block, in a deterministic order.
+func balanceChangingAccounts(data *stakingData) []string {
+	seen := map[string]struct{}{}
+	add := func(addrs ...staking.Address) {
+		for _, addr := range addrs {
+			seen[addr.String()] = struct{}{}
+		}
+	}
+	for _, e := range data.Transfers {
+		add(e.From, e.To)
+	}
+	for _, e := range data.Burns {
+		add(e.Owner)
+	}
+	for _, e := range data.AddEscrows {
+		add(e.Owner, e.Escrow)
+	}
+	for _, e := range data.TakeEscrows {
+		add(e.Owner)
+	}
+	for _, e := range data.DebondingStartEscrows {
+		add(e.Escrow)
+	}
+	for _, e := range data.ReclaimEscrows {
+		add(e.Owner, e.Escrow)
+	}
+	accounts := make([]string, 0, len(seen))
+	for addr := range seen {
+		accounts = append(accounts, addr)
+	}
+	sort.Strings(accounts)
+	return accounts
+}
+
+// queueBalanceHistoryStart snapshots the balances of the accounts affected by
+// the block before the dead-reckoning updates are applied, and
+// queueBalanceHistoryFinish records their changes once the updates are applied.
+// Both must run in the same batch, around the dead-reckoning queries.
+func (m *processor) queueBalanceHistoryStart(batch *storage.QueryBatch, data *stakingData) error {
+	if m.mode != analyzer.SlowSyncMode {
+		// Balances are only dead-reckoned in slow-sync mode.
+		return nil
+	}
+	if accounts := balanceChangingAccounts(data); len(accounts) > 0 {
+		batch.Queue(queries.ConsensusAccountBalanceHistoryStart, data.Height, accounts)
+	}
+	return nil
+}
+
+func (m *processor) queueBalanceHistoryFinish(batch *storage.QueryBatch, data *stakingData) error {
+	if m.mode != analyzer.SlowSyncMode {
+		return nil
+	}
+	if accounts := balanceChangingAccounts(data); len(accounts) > 0 {
+		batch.Queue(queries.ConsensusAccountBalanceHistoryFinish, data.Height, accounts)
+	}
+	return nil
+}
+
 func (m *processor) queueAllowanceChanges(batch *storage.QueryBatch, data *stakingData) error {
 	if m.mode == analyzer.FastSyncMode {
 		// Skip tracking of allowances during fast sync.
@@ -1154,7 +1278,7 @@ func (m *processor) queueAllowanceChanges(batch *storage.QueryBatch, data *staki
 	return nil
 }
 
-func (m *processor) queueStakingEventInserts(batch *storage.QueryBatch, data *stakingData) error {
+func (m *processor) queueStakingEventInserts(events *blockEvents, data *stakingData) error {
 	for _, event := range data.Events {
 		hash := util.SanitizeTxHash(event.TxHash.Hex())
 		if hash != nil {
@@ -1163,7 +1287,7 @@ func (m *processor) queueStakingEventInserts(batch *storage.QueryBatch, data *st
 
 		eventData := m.extractEventData(event)
 
-		if err := m.queueSingleEventInserts(batch, &eventData, data.Height); err != nil {
+		if err := events.add(&eventData, nil, nil); err != nil {
 			return err
 		}
 	}
@@ -1287,11 +1411,27 @@ func (m *processor) queueSubmissions(batch *storage.QueryBatch, data *governance
 		default:
 			m.logger.Warn("unknown proposal content type", "proposal_id", submission.ID, "content", submission.Content)
 		}
+		m.queueProposalParameters(batch, submission.ID, data.Parameters)
 	}
 
 	return nil
 }
 
+// queueProposalParameters records the governance parameters that determine whether the
+// proposal passes. They are recorded at submission and updated at finalization, in case
+// they changed while the proposal was open.
+func (m *processor) queueProposalParameters(batch *storage.QueryBatch, proposalID uint64, params *nodeapi.GovernanceParameters) {
+	if params == nil {
+		return
+	}
+	batch.Queue(queries.ConsensusProposalParametersUpdate,
+		proposalID,
+		params.StakeThreshold,
+		params.Quorum,
+		params.Threshold,
+	)
+}
+
 func (m *processor) queueExecutions(batch *storage.QueryBatch, data *governanceData) error {
 	if m.mode == analyzer.FastSyncMode {
 		// Skip proposal tracking during fast sync.
@@ -1324,6 +1464,7 @@ func (m *processor) queueFinalizations(batch *storage.QueryBatch, data *governan
 			finalization.ID,
 			fmt.Sprintf("%d", finalization.InvalidVotes),
 		)
+		m.queueProposalParameters(batch, finalization.ID, data.Parameters)
 	}
 
 	return nil
@@ -1348,7 +1489,7 @@ func (m *processor) queueVotes(batch *storage.QueryBatch, data *governanceData)
 	return nil
 }
 
-func (m *processor) queueGovernanceEventInserts(batch *storage.QueryBatch, data *governanceData) error {
+func (m *processor) queueGovernanceEventInserts(events *blockEvents, data *governanceData) error {
 	for _, event := range data.Events {
 		hash := util.SanitizeTxHash(event.TxHash.Hex())
 		if hash != nil {
@@ -1357,7 +1498,7 @@ func (m *processor) queueGovernanceEventInserts(batch *storage.QueryBatch, data
 
 		eventData := m.extractEventData(event)
 
-		if err := m.queueSingleEventInserts(batch, &eventData, data.Height); err != nil {
+		if err := events.add(&eventData, nil, nil); err != nil {
 			return err
 		}
 	}
@@ -1365,26 +1506,70 @@ func (m *processor) queueGovernanceEventInserts(batch *storage.QueryBatch, data
 	return nil
 }
 
-func (m *processor) queueSingleEventInserts(batch *storage.QueryBatch, eventData *parsedEvent, height int64) error {
-	accounts := extractUniqueAddresses(eventData.relatedAddresses)
+// blockEvents collects the events of a block from all modules, so that each
+// event can be assigned its index within the block before it is inserted.
+type blockEvents struct {
+	events []blockEvent
+}
+
+type blockEvent struct {
+	data    *parsedEvent
+	body    []byte
+	txHash  *string
+	txIndex *int
+}
+
+// add collects an event. txHash and txIndex identify the transaction that
+// emitted the event, or are nil for events that were not emitted by a transaction.
+// Events must be added in the order in which they were emitted.
+func (e *blockEvents) add(eventData *parsedEvent, txHash *string, txIndex *int) error {
 	body, err := json.Marshal(eventData.rawBody)
 	if err != nil {
 		return err
 	}
+	e.events = append(e.events, blockEvent{
+		data:    eventData,
+		body:    body,
+		txHash:  txHash,
+		txIndex: txIndex,
+	})
+	return nil
+}
 
-	batch.Queue(queries.ConsensusEventInsert,
-		string(eventData.ty),
-		string(body),
-		height,
-		nil,
-		nil,
-		accounts,
-		common.StringOrNil(eventData.roothashRuntimeID),
-		eventData.roothashRuntime,
-		eventData.roothashRuntimeRound,
-	)
+// ordered returns the events in the order in which they are numbered: the events
+// of transactions in transaction order, followed by the events that were not
+// emitted by a transaction (e.g. in begin/end block). This is not the order of
+// execution, since begin block events precede the transactions. Events of the
+// same transaction keep the order in which they were added.
+func (e *blockEvents) ordered() []blockEvent {
+	ordered := make([]blockEvent, len(e.events))
+	copy(ordered, e.events)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i].txIndex, ordered[j].txIndex
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return *a < *b
+	})
+	return ordered
+}
 
-	return nil
+// queueInserts queues the inserts of the collected events, numbered in the order returned by ordered.
+func (e *blockEvents) queueInserts(batch *storage.QueryBatch, height int64) {
+	for i, event := range e.ordered() {
+		batch.Queue(queries.ConsensusEventInsert,
+			string(event.data.ty),
+			string(event.body),
+			height,
+			event.txHash,
+			event.txIndex,
+			extractUniqueAddresses(event.data.relatedAddresses),
+			common.StringOrNil(event.data.roothashRuntimeID),
+			event.data.roothashRuntime,
+			event.data.roothashRuntimeRound,
+			i,
+		)
+	}
 }
 
 func extractUniqueAddresses(accounts []staking.Address) []string {
@@ -1460,6 +1645,18 @@ func (m *processor) extractEventData(event nodeapi.Event) parsedEvent {
 		eventData.relatedAddresses = []staking.Address{event.StakingReclaimEscrow.Owner, event.StakingReclaimEscrow.Escrow}
 	case event.StakingAllowanceChange != nil:
 		eventData.relatedAddresses = []staking.Address{event.StakingAllowanceChange.Owner, event.StakingAllowanceChange.Beneficiary}
+	case event.VaultActionSubmitted != nil:
+		eventData.relatedAddresses = []staking.Address{event.VaultActionSubmitted.Vault, event.VaultActionSubmitted.Submitter}
+	case event.VaultActionCanceled != nil:
+		eventData.relatedAddresses = []staking.Address{event.VaultActionCanceled.Vault}
+	case event.VaultActionExecuted != nil:
+		eventData.relatedAddresses = []staking.Address{event.VaultActionExecuted.Vault}
+	case event.VaultStateChanged != nil:
+		eventData.relatedAddresses = []staking.Address{event.VaultStateChanged.Vault}
+	case event.VaultPolicyUpdated != nil:
+		eventData.relatedAddresses = []staking.Address{event.VaultPolicyUpdated.Vault, event.VaultPolicyUpdated.Address}
+	case event.VaultAuthorityUpdated != nil:
+		eventData.relatedAddresses = []staking.Address{event.VaultAuthorityUpdated.Vault}
 	}
 	return eventData
 }
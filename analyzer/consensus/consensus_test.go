@@ -3,15 +3,25 @@ package consensus
 import (
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/fxamacker/cbor/v2"
 	"github.com/stretchr/testify/require"
 
+	coreStaking "github.com/oasisprotocol/oasis-core/go/staking/api"
+
 	roothashCobalt "github.com/oasisprotocol/nexus/coreapi/v21.1.1/roothash/api"
 	"github.com/oasisprotocol/nexus/coreapi/v22.2.11/consensus/api/transaction"
 	roothashDamask "github.com/oasisprotocol/nexus/coreapi/v22.2.11/roothash/api"
+
+	"github.com/oasisprotocol/nexus/analyzer"
+	"github.com/oasisprotocol/nexus/analyzer/queries"
+	"github.com/oasisprotocol/nexus/common"
+	"github.com/oasisprotocol/nexus/storage"
+	"github.com/oasisprotocol/nexus/storage/oasis/nodeapi"
 )
 
 func TestCobaltTx(t *testing.T) {
@@ -99,3 +109,112 @@ func TestMalformedTxMethod(t *testing.T) {
 	require.NotNil(t, err)
 	require.Equal(t, "unable to cbor-decode consensus tx body: unknown tx method, method: not_a_valid_method, body: deadbeef", err.Error())
 }
+
+// TestBlockEventsQueueInserts tests that events are numbered by transaction,
+// regardless of the order in which the modules' events are collected.
+func TestBlockEventsQueueInserts(t *testing.T) {
+	events := &blockEvents{}
+	add := func(ty EventType, txIndex *int) {
+		txHash := (*string)(nil)
+		if txIndex != nil {
+			txHash = common.Ptr(fmt.Sprintf("tx%d", *txIndex))
+		}
+		require.NoError(t, events.add(&parsedEvent{ty: ty, rawBody: json.RawMessage(`{}`)}, txHash, txIndex))
+	}
+	// Collected in the order of queueDbUpdates: transaction results, then
+	// per-module events without a transaction, then vault events.
+	add("staking.transfer", common.Ptr(0))
+	add("staking.escrow.add", common.Ptr(2))
+	add("staking.escrow.take", common.Ptr(2))
+	add("registry.node", nil)
+	add("staking.burn", nil)
+	add("vault.action_submitted", common.Ptr(1))
+	add("vault.state_changed", nil)
+	add("vault.action_executed", common.Ptr(2))
+
+	batch := &storage.QueryBatch{}
+	events.queueInserts(batch, 10)
+
+	type inserted struct {
+		ty         string
+		txIndex    *int
+		eventIndex int
+	}
+	var actual []inserted
+	for _, q := range batch.Queries() {
+		require.Equal(t, queries.ConsensusEventInsert, q.Cmd)
+		require.Equal(t, int64(10), q.Args[2])
+		actual = append(actual, inserted{q.Args[0].(string), q.Args[4].(*int), q.Args[9].(int)})
+	}
+	require.Equal(t, []inserted{
+		{"staking.transfer", common.Ptr(0), 0},
+		{"vault.action_submitted", common.Ptr(1), 1},
+		{"staking.escrow.add", common.Ptr(2), 2},
+		{"staking.escrow.take", common.Ptr(2), 3},
+		{"vault.action_executed", common.Ptr(2), 4},
+		{"registry.node", nil, 5},
+		{"staking.burn", nil, 6},
+		{"vault.state_changed", nil, 7},
+	}, actual)
+}
+
+func TestBalanceChangingAccounts(t *testing.T) {
+	a := coreStaking.NewModuleAddress("test", "a")
+	b := coreStaking.NewModuleAddress("test", "b")
+	c := coreStaking.NewModuleAddress("test", "c")
+	d := coreStaking.NewModuleAddress("test", "d")
+	e := coreStaking.NewModuleAddress("test", "e")
+	f := coreStaking.NewModuleAddress("test", "f")
+
+	data := &stakingData{
+		Transfers:             []nodeapi.TransferEvent{{From: a, To: b}, {From: b, To: a}},
+		Burns:                 []nodeapi.BurnEvent{{Owner: c}},
+		AddEscrows:            []nodeapi.AddEscrowEvent{{Owner: a, Escrow: d}},
+		TakeEscrows:           []nodeapi.TakeEscrowEvent{{Owner: d}},
+		DebondingStartEscrows: []nodeapi.DebondingStartEscrowEvent{{Owner: f, Escrow: e}},
+		ReclaimEscrows:        []nodeapi.ReclaimEscrowEvent{{Owner: f, Escrow: e}},
+		// Allowance changes do not change balances.
+		AllowanceChanges: []nodeapi.AllowanceChangeEvent{{Owner: coreStaking.NewModuleAddress("test", "g")}},
+	}
+	expected := []string{a.String(), b.String(), c.String(), d.String(), e.String(), f.String()}
+	sort.Strings(expected)
+	// The debonding start only moves the delegator's stake within the escrow account.
+	require.Equal(t, expected, balanceChangingAccounts(data))
+
+	require.Empty(t, balanceChangingAccounts(&stakingData{}))
+}
+
+func TestQueueBalanceHistory(t *testing.T) {
+	a := coreStaking.NewModuleAddress("test", "a")
+	b := coreStaking.NewModuleAddress("test", "b")
+	data := &stakingData{
+		Height:    42,
+		Transfers: []nodeapi.TransferEvent{{From: b, To: a}},
+	}
+	accounts := []string{a.String(), b.String()}
+	sort.Strings(accounts)
+
+	m := &processor{mode: analyzer.SlowSyncMode}
+	batch := &storage.QueryBatch{}
+	require.NoError(t, m.queueBalanceHistoryStart(batch, data))
+	batch.Queue("-- dead-reckoning updates")
+	require.NoError(t, m.queueBalanceHistoryFinish(batch, data))
+	require.Len(t, batch.Queries(), 3)
+	require.Equal(t, queries.ConsensusAccountBalanceHistoryStart, batch.Queries()[0].Cmd)
+	require.Equal(t, []interface{}{int64(42), accounts}, batch.Queries()[0].Args)
+	require.Equal(t, queries.ConsensusAccountBalanceHistoryFinish, batch.Queries()[2].Cmd)
+	require.Equal(t, []interface{}{int64(42), accounts}, batch.Queries()[2].Args)
+
+	// Blocks without balance changes are not recorded.
+	batch = &storage.QueryBatch{}
+	require.NoError(t, m.queueBalanceHistoryStart(batch, &stakingData{Height: 43}))
+	require.NoError(t, m.queueBalanceHistoryFinish(batch, &stakingData{Height: 43}))
+	require.Empty(t, batch.Queries())
+
+	// Balances are not dead-reckoned in fast-sync mode, so there is nothing to record.
+	m = &processor{mode: analyzer.FastSyncMode}
+	batch = &storage.QueryBatch{}
+	require.NoError(t, m.queueBalanceHistoryStart(batch, data))
+	require.NoError(t, m.queueBalanceHistoryFinish(batch, data))
+	require.Empty(t, batch.Queries())
+}
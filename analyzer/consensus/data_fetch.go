@@ -12,6 +12,7 @@ import (
 	beacon "github.com/oasisprotocol/nexus/coreapi/v22.2.11/beacon/api"
 	consensus "github.com/oasisprotocol/nexus/coreapi/v22.2.11/consensus/api"
 	roothash "github.com/oasisprotocol/nexus/coreapi/v22.2.11/roothash/api"
+	staking "github.com/oasisprotocol/nexus/coreapi/v22.2.11/staking/api"
 	"github.com/oasisprotocol/nexus/storage/oasis/nodeapi"
 )
 
@@ -88,10 +89,27 @@ func fetchAllData(ctx context.Context, cc nodeapi.ConsensusApiLite, network sdkC
 		return nil
 	})
 
+	var vaultEvents []nodeapi.Event
+	eg.Go(func() error {
+		events, err := cc.VaultEvents(fetchCtx, height)
+		if err != nil {
+			return err
+		}
+		vaultEvents = events
+		return nil
+	})
+
 	if err := eg.Wait(); err != nil {
 		return nil, err
 	}
 
+	// Vault creation does not emit an event, so the vaults to fetch also depend on the block's transactions.
+	vaultData, err := fetchVaultData(ctx, cc, height, vaultEvents, data.BlockData.TransactionsWithResults)
+	if err != nil {
+		return nil, err
+	}
+	data.VaultData = vaultData
+
 	return &data, nil
 }
 
@@ -307,6 +325,15 @@ func fetchGovernanceData(ctx context.Context, cc nodeapi.ConsensusApiLite, heigh
 			votes = append(votes, *event.GovernanceVote)
 		}
 	}
+
+	var params *nodeapi.GovernanceParameters
+	if len(submissions) > 0 || len(finalizations) > 0 {
+		params, err = cc.GetGovernanceParameters(ctx, height)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &governanceData{
 		Height:                height,
 		Events:                events,
@@ -314,9 +341,66 @@ func fetchGovernanceData(ctx context.Context, cc nodeapi.ConsensusApiLite, heigh
 		ProposalExecutions:    executions,
 		ProposalFinalizations: finalizations,
 		Votes:                 votes,
+		Parameters:            params,
 	}, nil
 }
 
+// fetchVaultData retrieves the state of the vaults that were created or emitted an event
+// at the provided block height, along with their pending actions.
+func fetchVaultData(ctx context.Context, cc nodeapi.ConsensusApiLite, height int64, events []nodeapi.Event, txrs []nodeapi.TransactionWithResults) (*vaultData, error) {
+	touched := make(map[staking.Address]struct{})
+	for _, event := range events {
+		if vault := vaultEventVault(event); vault != nil {
+			touched[*vault] = struct{}{}
+		}
+	}
+	creators := make(map[staking.Address]struct{})
+	for _, txr := range txrs {
+		if !txr.Result.IsSuccess() {
+			continue
+		}
+		tx, err := OpenSignedTxNoVerify(&txr.Transaction)
+		if err != nil {
+			continue // Logged in queueTransactionInserts.
+		}
+		if tx.Method == "vault.Create" {
+			creators[staking.NewAddress(txr.Transaction.Signature.PublicKey)] = struct{}{}
+		}
+	}
+
+	data := vaultData{
+		Height:         height,
+		Events:         events,
+		PendingActions: make(map[staking.Address][]nodeapi.VaultPendingAction),
+	}
+	if len(touched) == 0 && len(creators) == 0 {
+		return &data, nil
+	}
+
+	vaults, err := cc.GetVaults(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+	for _, vault := range vaults {
+		address := vaultAddress(vault.Creator, vault.ID)
+		_, isTouched := touched[address]
+		// The ID of a new vault is not known without replicating the node's logic, so
+		// refresh all vaults of the creator instead.
+		_, isCreated := creators[vault.Creator]
+		if !isTouched && !isCreated {
+			continue
+		}
+		pendingActions, err := cc.GetVaultPendingActions(ctx, height, address)
+		if err != nil {
+			return nil, err
+		}
+		data.Vaults = append(data.Vaults, vault)
+		data.PendingActions[address] = pendingActions
+	}
+
+	return &data, nil
+}
+
 // fetchRootHashData retrieves roothash events and last round results at the
 // provided block height.
 func fetchRootHashData(ctx context.Context, cc nodeapi.ConsensusApiLite, network sdkConfig.Network, height int64) (*rootHashData, error) {
@@ -355,6 +439,7 @@ type allData struct {
 	StakingData    *stakingData
 	SchedulerData  *schedulerData
 	GovernanceData *governanceData
+	VaultData      *vaultData
 }
 
 // consensusBlockData represents data for a consensus block at a given height.
@@ -437,4 +522,18 @@ type governanceData struct {
 	ProposalExecutions    []nodeapi.ProposalExecutedEvent
 	ProposalFinalizations []nodeapi.Proposal
 	Votes                 []nodeapi.VoteEvent
+
+	// The governance parameters at this height. Only fetched if a proposal was submitted or finalized.
+	Parameters *nodeapi.GovernanceParameters
+}
+
+// vaultData represents vault events at a given height, along with the state of the vaults
+// affected by them or created at that height.
+type vaultData struct {
+	Height int64
+
+	Events []nodeapi.Event
+
+	Vaults         []nodeapi.Vault
+	PendingActions map[staking.Address][]nodeapi.VaultPendingAction
 }
@@ -0,0 +1,114 @@
+package consensus
+
+import (
+	"fmt"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	coreStaking "github.com/oasisprotocol/oasis-core/go/staking/api"
+
+	staking "github.com/oasisprotocol/nexus/coreapi/v22.2.11/staking/api"
+
+	"github.com/oasisprotocol/nexus/analyzer/queries"
+	"github.com/oasisprotocol/nexus/storage"
+	"github.com/oasisprotocol/nexus/storage/oasis/nodeapi"
+)
+
+// vaultAddress returns the address of a vault. It mirrors NewVaultAddress in
+// oasis-core's vault/api, which is not part of the vendored types.
+func vaultAddress(creator staking.Address, id uint64) staking.Address {
+	return coreStaking.NewModuleAddress("vault", fmt.Sprintf("vault.%s.%d", creator, id))
+}
+
+// vaultEventVault returns the address of the vault that emitted the event,
+// or nil if the event is not a vault event.
+func vaultEventVault(event nodeapi.Event) *staking.Address {
+	switch {
+	case event.VaultActionSubmitted != nil:
+		return &event.VaultActionSubmitted.Vault
+	case event.VaultActionCanceled != nil:
+		return &event.VaultActionCanceled.Vault
+	case event.VaultActionExecuted != nil:
+		return &event.VaultActionExecuted.Vault
+	case event.VaultStateChanged != nil:
+		return &event.VaultStateChanged.Vault
+	case event.VaultPolicyUpdated != nil:
+		return &event.VaultPolicyUpdated.Vault
+	case event.VaultAuthorityUpdated != nil:
+		return &event.VaultAuthorityUpdated.Vault
+	default:
+		return nil
+	}
+}
+
+func addressStrings(addrs []staking.Address) []string {
+	strs := make([]string, len(addrs))
+	for i, addr := range addrs {
+		strs[i] = addr.String()
+	}
+	return strs
+}
+
+// queueVaultUpdates stores the state of the vaults that were created or emitted an event
+// at the height. Since vault state is fetched from the node rather than dead-reckoned,
+// this is also safe in fast-sync mode; the queries ignore state that is older than
+// what is already stored.
+func (m *processor) queueVaultUpdates(batch *storage.QueryBatch, data *vaultData) error {
+	for _, vault := range data.Vaults {
+		addr := vaultAddress(vault.Creator, vault.ID)
+		address := addr.String()
+		batch.Queue(queries.ConsensusVaultUpsert,
+			address,
+			vault.Creator.String(),
+			vault.ID,
+			vault.State,
+			vault.Nonce,
+			addressStrings(vault.AdminAuthority.Addresses),
+			vault.AdminAuthority.Threshold,
+			addressStrings(vault.SuspendAuthority.Addresses),
+			vault.SuspendAuthority.Threshold,
+			data.Height,
+		)
+		batch.Queue(queries.ConsensusVaultPendingActionsDelete,
+			address,
+			data.Height,
+		)
+		for _, action := range data.PendingActions[addr] {
+			batch.Queue(queries.ConsensusVaultPendingActionInsert,
+				address,
+				action.Nonce,
+				[]byte(action.Action),
+				addressStrings(action.AuthorizedBy),
+				data.Height,
+			)
+		}
+	}
+
+	return nil
+}
+
+// queueVaultEventInserts collects vault events. Unlike events of other modules, vault events
+// are not part of transaction results, so the events of transactions are also collected here.
+func (m *processor) queueVaultEventInserts(events *blockEvents, data *vaultData, blockData *consensusBlockData) error {
+	txIndexes := make(map[hash.Hash]int, len(blockData.TransactionsWithResults))
+	for i, txr := range blockData.TransactionsWithResults {
+		txIndexes[txr.Transaction.Hash()] = i
+	}
+
+	for _, event := range data.Events {
+		eventData := m.extractEventData(event)
+
+		var txHash *string
+		var txIndex *int
+		if i, ok := txIndexes[event.TxHash]; ok {
+			h := event.TxHash.Hex()
+			txHash = &h
+			txIndex = &i
+		}
+
+		if err := events.add(&eventData, txHash, txIndex); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package consensus
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	coreStaking "github.com/oasisprotocol/oasis-core/go/staking/api"
+
+	"github.com/oasisprotocol/nexus/coreapi/v22.2.11/consensus/api/transaction"
+
+	"github.com/oasisprotocol/nexus/analyzer/queries"
+	"github.com/oasisprotocol/nexus/common"
+	"github.com/oasisprotocol/nexus/storage"
+	"github.com/oasisprotocol/nexus/storage/oasis/nodeapi"
+)
+
+// mockVaultSource serves the vaults and their pending actions, and records
+// the vaults whose pending actions were fetched.
+type mockVaultSource struct {
+	nodeapi.ConsensusApiLite
+	vaults         []nodeapi.Vault
+	pendingActions map[nodeapi.Address][]nodeapi.VaultPendingAction
+
+	fetchedVaults         bool
+	fetchedPendingActions []nodeapi.Address
+}
+
+func (s *mockVaultSource) GetVaults(ctx context.Context, height int64) ([]nodeapi.Vault, error) {
+	s.fetchedVaults = true
+	return s.vaults, nil
+}
+
+func (s *mockVaultSource) GetVaultPendingActions(ctx context.Context, height int64, address nodeapi.Address) ([]nodeapi.VaultPendingAction, error) {
+	s.fetchedPendingActions = append(s.fetchedPendingActions, address)
+	return s.pendingActions[address], nil
+}
+
+// testTx returns a transaction with the given method, signed (with an invalid
+// signature) by the given key.
+func testTx(signer signature.PublicKey, method transaction.MethodName, success bool) nodeapi.TransactionWithResults {
+	txr := nodeapi.TransactionWithResults{
+		Transaction: transaction.SignedTransaction{Signed: signature.Signed{
+			Blob:      cbor.Marshal(transaction.Transaction{Method: method}),
+			Signature: signature.Signature{PublicKey: signer},
+		}},
+	}
+	if !success {
+		txr.Result.Error = nodeapi.TxError{Module: "vault", Code: 1}
+	}
+	return txr
+}
+
+func TestVaultAddress(t *testing.T) {
+	// Test vectors of NewVaultAddress in oasis-core's vault/api.
+	a := coreStaking.NewModuleAddress("test", "a")
+	b := coreStaking.NewModuleAddress("test", "b")
+	require.Equal(t, "oasis1qrzxrldg2xazawgyvpqesyueum7gtsmw65u0za68", vaultAddress(a, 0).String())
+	require.Equal(t, "oasis1qq9my0st8dtqdumqg8mcuerg6jzg0202aqw85ayl", vaultAddress(a, 1).String())
+	require.Equal(t, "oasis1qpw4gyvddf044nupz4dan42e2lxjftc2uvhhm245", vaultAddress(b, 0).String())
+	require.Equal(t, "oasis1qrsl7w8py3xpqknla7v785yms09ecst9k5ncvgym", vaultAddress(b, 1).String())
+}
+
+func TestFetchVaultData(t *testing.T) {
+	var creatorKey, failedCreatorKey, otherKey signature.PublicKey
+	creatorKey[0], failedCreatorKey[0], otherKey[0] = 1, 2, 3
+	creator := coreStaking.NewAddress(creatorKey)
+	failedCreator := coreStaking.NewAddress(failedCreatorKey)
+	other := coreStaking.NewAddress(otherKey)
+
+	source := &mockVaultSource{
+		vaults: []nodeapi.Vault{
+			{Creator: creator, ID: 0},
+			{Creator: creator, ID: 1},
+			{Creator: failedCreator, ID: 0},
+			{Creator: other, ID: 0},
+			{Creator: other, ID: 1},
+		},
+		pendingActions: map[nodeapi.Address][]nodeapi.VaultPendingAction{
+			vaultAddress(other, 1): {{Nonce: 7}},
+		},
+	}
+	events := []nodeapi.Event{
+		{VaultActionSubmitted: &nodeapi.VaultActionSubmittedEvent{Vault: vaultAddress(other, 1), Submitter: other, Nonce: 7}},
+		// Not a vault event.
+		{StakingTransfer: &nodeapi.TransferEvent{From: other, To: vaultAddress(other, 0)}},
+	}
+	txrs := []nodeapi.TransactionWithResults{
+		testTx(creatorKey, "vault.Create", true),
+		testTx(failedCreatorKey, "vault.Create", false),
+		testTx(otherKey, "staking.Transfer", true),
+	}
+
+	data, err := fetchVaultData(context.Background(), source, 42, events, txrs)
+	require.NoError(t, err)
+	require.Equal(t, int64(42), data.Height)
+	require.Equal(t, events, data.Events)
+	// All vaults of the creator are refreshed, since the ID of the new vault is not known.
+	// Failed creations and non-vault events do not touch any vault.
+	expected := []nodeapi.Vault{
+		{Creator: creator, ID: 0},
+		{Creator: creator, ID: 1},
+		{Creator: other, ID: 1},
+	}
+	require.Equal(t, expected, data.Vaults)
+	require.Equal(t, []nodeapi.Address{vaultAddress(creator, 0), vaultAddress(creator, 1), vaultAddress(other, 1)}, source.fetchedPendingActions)
+	require.Len(t, data.PendingActions, 3)
+	require.Empty(t, data.PendingActions[vaultAddress(creator, 0)])
+	require.Equal(t, []nodeapi.VaultPendingAction{{Nonce: 7}}, data.PendingActions[vaultAddress(other, 1)])
+
+	// Without vault events or creations, the vaults are not fetched.
+	source = &mockVaultSource{}
+	data, err = fetchVaultData(context.Background(), source, 43, nil, txrs[1:])
+	require.NoError(t, err)
+	require.Empty(t, data.Vaults)
+	require.False(t, source.fetchedVaults)
+}
+
+func TestQueueVaultUpdates(t *testing.T) {
+	creator := coreStaking.NewModuleAddress("test", "creator")
+	admin := coreStaking.NewModuleAddress("test", "admin")
+	suspender := coreStaking.NewModuleAddress("test", "suspender")
+	address := vaultAddress(creator, 3)
+	data := &vaultData{
+		Height: 42,
+		Vaults: []nodeapi.Vault{{
+			Creator:          creator,
+			ID:               3,
+			State:            "active",
+			Nonce:            5,
+			AdminAuthority:   nodeapi.VaultAuthority{Addresses: []nodeapi.Address{admin, suspender}, Threshold: 2},
+			SuspendAuthority: nodeapi.VaultAuthority{Addresses: []nodeapi.Address{suspender}, Threshold: 1},
+		}},
+		PendingActions: map[nodeapi.Address][]nodeapi.VaultPendingAction{
+			address: {
+				{Nonce: 3, AuthorizedBy: []nodeapi.Address{admin}, Action: json.RawMessage(`{"suspend":{}}`)},
+				{Nonce: 4, Action: json.RawMessage(`{"resume":{}}`)},
+			},
+		},
+	}
+
+	m := &processor{}
+	batch := &storage.QueryBatch{}
+	require.NoError(t, m.queueVaultUpdates(batch, data))
+	items := batch.Queries()
+	require.Len(t, items, 4)
+	require.Equal(t, queries.ConsensusVaultUpsert, items[0].Cmd)
+	require.Equal(t, []interface{}{
+		address.String(), creator.String(), uint64(3), "active", uint64(5),
+		[]string{admin.String(), suspender.String()}, uint8(2),
+		[]string{suspender.String()}, uint8(1),
+		int64(42),
+	}, items[0].Args)
+	// The pending actions are replaced, so that executed and canceled actions are removed.
+	require.Equal(t, queries.ConsensusVaultPendingActionsDelete, items[1].Cmd)
+	require.Equal(t, []interface{}{address.String(), int64(42)}, items[1].Args)
+	require.Equal(t, queries.ConsensusVaultPendingActionInsert, items[2].Cmd)
+	require.Equal(t, []interface{}{address.String(), uint64(3), []byte(`{"suspend":{}}`), []string{admin.String()}, int64(42)}, items[2].Args)
+	require.Equal(t, queries.ConsensusVaultPendingActionInsert, items[3].Cmd)
+	require.Equal(t, []interface{}{address.String(), uint64(4), []byte(`{"resume":{}}`), []string{}, int64(42)}, items[3].Args)
+}
+
+func TestQueueVaultEventInserts(t *testing.T) {
+	var signer signature.PublicKey
+	signer[0] = 1
+	txrs := []nodeapi.TransactionWithResults{
+		testTx(signer, "staking.Transfer", true),
+		testTx(signer, "vault.SubmitAction", true),
+	}
+	txHash := txrs[1].Transaction.Hash()
+	vault := vaultAddress(coreStaking.NewAddress(signer), 0)
+	data := &vaultData{
+		Events: []nodeapi.Event{
+			{
+				TxHash:               txHash,
+				Type:                 "vault.action_submitted",
+				RawBody:              json.RawMessage(`{"nonce":1}`),
+				VaultActionSubmitted: &nodeapi.VaultActionSubmittedEvent{Vault: vault, Submitter: coreStaking.NewAddress(signer), Nonce: 1},
+			},
+			// Emitted at the end of the block, e.g. when a vault action is executed.
+			{
+				Type:                "vault.action_executed",
+				RawBody:             json.RawMessage(`{"nonce":1}`),
+				VaultActionExecuted: &nodeapi.VaultActionExecutedEvent{Vault: vault, Nonce: 1},
+			},
+		},
+	}
+
+	m := &processor{}
+	events := &blockEvents{}
+	require.NoError(t, m.queueVaultEventInserts(events, data, &consensusBlockData{TransactionsWithResults: txrs}))
+	require.Len(t, events.events, 2)
+	require.Equal(t, common.Ptr(txHash.Hex()), events.events[0].txHash)
+	require.Equal(t, common.Ptr(1), events.events[0].txIndex)
+	require.Equal(t, []nodeapi.Address{vault, coreStaking.NewAddress(signer)}, events.events[0].data.relatedAddresses)
+	require.Nil(t, events.events[1].txHash)
+	require.Nil(t, events.events[1].txIndex)
+	require.Equal(t, []nodeapi.Address{vault}, events.events[1].data.relatedAddresses)
+}
@@ -0,0 +1,25 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+// https://github.com/Uniswap/v3-core/blob/v1.0.0/contracts/interfaces/IUniswapV3Factory.sol
+pragma solidity >=0.5.0;
+
+interface IUniswapV3Factory {
+    event OwnerChanged(address indexed oldOwner, address indexed newOwner);
+    event PoolCreated(
+        address indexed token0,
+        address indexed token1,
+        uint24 indexed fee,
+        int24 tickSpacing,
+        address pool
+    );
+    event FeeAmountEnabled(uint24 indexed fee, int24 indexed tickSpacing);
+
+    function owner() external view returns (address);
+    function feeAmountTickSpacing(uint24 fee) external view returns (int24);
+    function getPool(address tokenA, address tokenB, uint24 fee) external view returns (address pool);
+
+    function createPool(address tokenA, address tokenB, uint24 fee) external returns (address pool);
+
+    function setOwner(address _owner) external;
+    function enableFeeAmount(uint24 fee, int24 tickSpacing) external;
+}
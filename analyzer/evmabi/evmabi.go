@@ -41,6 +41,14 @@ var ERC721Metadata = MustUnmarshalABI(artifactERC721MetadataJSON)
 var artifactERC721EnumerableJSON []byte
 var ERC721Enumerable = MustUnmarshalABI(artifactERC721EnumerableJSON)
 
+//go:embed contracts/artifacts/ERC1155.json
+var artifactERC1155JSON []byte
+var ERC1155 = MustUnmarshalABI(artifactERC1155JSON)
+
+//go:embed contracts/artifacts/ERC1155Metadata_URI.json
+var artifactERC1155MetadataURIJSON []byte
+var ERC1155MetadataURI = MustUnmarshalABI(artifactERC1155MetadataURIJSON)
+
 //go:embed contracts/artifacts/IUniswapV2Factory.json
 var artifactIUniswapV2FactoryJSON []byte
 var IUniswapV2Factory = MustUnmarshalABI(artifactIUniswapV2FactoryJSON)
@@ -49,6 +57,18 @@ var IUniswapV2Factory = MustUnmarshalABI(artifactIUniswapV2FactoryJSON)
 var artifactIUniswapV2PairJSON []byte
 var IUniswapV2Pair = MustUnmarshalABI(artifactIUniswapV2PairJSON)
 
+//go:embed contracts/artifacts/IUniswapV3Factory.json
+var artifactIUniswapV3FactoryJSON []byte
+var IUniswapV3Factory = MustUnmarshalABI(artifactIUniswapV3FactoryJSON)
+
+//go:embed contracts/artifacts/IUniswapV3Pool.json
+var artifactIUniswapV3PoolJSON []byte
+var IUniswapV3Pool = MustUnmarshalABI(artifactIUniswapV3PoolJSON)
+
 //go:embed contracts/artifacts/WROSE.json
 var artifactWROSEJSON []byte
 var WROSE = MustUnmarshalABI(artifactWROSEJSON)
+
+//go:embed contracts/artifacts/IERC1967.json
+var artifactIERC1967JSON []byte
+var IERC1967 = MustUnmarshalABI(artifactIERC1967JSON)
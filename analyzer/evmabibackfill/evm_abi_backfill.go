@@ -7,12 +7,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	ethCommon "github.com/ethereum/go-ethereum/common"
 	sdkEVM "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/evm"
 
 	"github.com/oasisprotocol/nexus/analyzer"
+	"github.com/oasisprotocol/nexus/analyzer/evmsignatures"
 	"github.com/oasisprotocol/nexus/analyzer/item"
 	"github.com/oasisprotocol/nexus/analyzer/queries"
 	"github.com/oasisprotocol/nexus/analyzer/runtime"
@@ -31,6 +33,12 @@ type processor struct {
 	runtime common.Runtime
 	target  storage.TargetStorage
 	logger  *log.Logger
+
+	// Known function and event signatures, for parsing the calls and events
+	// of contracts without an ABI.
+	signatures *evmsignatures.Database
+	// The download time of the latest ABI added to signatures.
+	signaturesUpdatedAt *time.Time
 }
 
 var _ item.ItemProcessor[*abiEncodedItem] = (*processor)(nil)
@@ -51,7 +59,9 @@ type abiEncodedItem struct {
 	Tx           *abiEncodedTx
 	Event        *abiEncodedEvent
 	ContractAddr string
-	Abi          json.RawMessage
+	// Nil for contracts without an ABI; their calls and events are parsed with
+	// the known signatures instead.
+	Abi json.RawMessage
 }
 
 type abiEncodedArg struct {
@@ -67,10 +77,15 @@ func NewAnalyzer(
 	logger *log.Logger,
 ) (analyzer.Analyzer, error) {
 	logger = logger.With("analyzer", evmAbiAnalyzerPrefix+runtime)
+	signatures, err := evmsignatures.NewDatabase()
+	if err != nil {
+		return nil, err
+	}
 	p := &processor{
-		runtime,
-		target,
-		logger,
+		runtime:    runtime,
+		target:     target,
+		logger:     logger,
+		signatures: signatures,
 	}
 	return item.NewAnalyzer[*abiEncodedItem](
 		evmAbiAnalyzerPrefix+string(runtime),
@@ -130,9 +145,94 @@ func (p *processor) GetItems(ctx context.Context, limit uint64) ([]*abiEncodedIt
 		}
 		items = append(items, &item)
 	}
+	// Short circuit.
+	if len(items) == int(limit) {
+		return items, nil
+	}
+
+	// Then the calls and events of the remaining contracts, using known signatures.
+	if err = p.updateSignatures(ctx); err != nil {
+		return nil, err
+	}
+	unverifiedTxRows, err := p.target.Query(ctx, queries.RuntimeEvmUnverifiedContractTxs, p.runtime, int(limit)-len(items), p.signaturesUpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("querying unverified contract txs: %w", err)
+	}
+	defer unverifiedTxRows.Close()
+	for unverifiedTxRows.Next() {
+		var tx abiEncodedTx
+		var item abiEncodedItem
+		item.Tx = &tx
+		if err = unverifiedTxRows.Scan(
+			&item.ContractAddr,
+			&tx.TxHash,
+			&tx.TxData,
+			&tx.TxRevertReason,
+		); err != nil {
+			return nil, fmt.Errorf("scanning unverified contract tx: %w", err)
+		}
+		items = append(items, &item)
+	}
+	// Short circuit.
+	if len(items) == int(limit) {
+		return items, nil
+	}
+	unverifiedEventRows, err := p.target.Query(ctx, queries.RuntimeEvmUnverifiedContractEvents, p.runtime, int(limit)-len(items), p.signaturesUpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("querying unverified contract evs: %w", err)
+	}
+	defer unverifiedEventRows.Close()
+	for unverifiedEventRows.Next() {
+		var ev abiEncodedEvent
+		var item abiEncodedItem
+		item.Event = &ev
+		if err = unverifiedEventRows.Scan(
+			&item.ContractAddr,
+			&ev.Round,
+			&ev.TxIndex,
+			&ev.EventBody,
+		); err != nil {
+			return nil, fmt.Errorf("scanning unverified contract event: %w", err)
+		}
+		items = append(items, &item)
+	}
 	return items, nil
 }
 
+// updateSignatures extends the known signatures with the ABIs of contracts
+// that were verified since the last update. The calls and events that could
+// not be named with the signatures known when they were parsed are parsed
+// again after such an update.
+func (p *processor) updateSignatures(ctx context.Context) error {
+	rows, err := p.target.Query(ctx, queries.EvmContractAbisSince, p.signaturesUpdatedAt)
+	if err != nil {
+		return fmt.Errorf("querying contract abis: %w", err)
+	}
+	defer rows.Close()
+	numAbis := 0
+	for rows.Next() {
+		var rawAbi json.RawMessage
+		var downloadedAt *time.Time
+		if err = rows.Scan(&rawAbi, &downloadedAt); err != nil {
+			return fmt.Errorf("scanning contract abi: %w", err)
+		}
+		if downloadedAt != nil {
+			p.signaturesUpdatedAt = downloadedAt
+		}
+		contractAbi, err := abi.JSON(bytes.NewReader(rawAbi))
+		if err != nil {
+			// Already logged by ProcessItem for the contract's own calls and events.
+			continue
+		}
+		p.signatures.AddABI(&contractAbi)
+		numAbis++
+	}
+	if numAbis > 0 {
+		p.logger.Info("added contract abis to known signatures", "num_abis", numAbis)
+	}
+	return rows.Err()
+}
+
 // Transaction revert reasons for failed evm transactions have been encoded
 // differently over the course of Oasis history. Older transaction revert
 // reasons were returned as one of
@@ -227,6 +327,10 @@ func (p *processor) parseTxErr(tx *abiEncodedTx, contractAbi abi.ABI) (*string,
 }
 
 func (p *processor) ProcessItem(ctx context.Context, batch *storage.QueryBatch, item *abiEncodedItem) error {
+	if item.Abi == nil {
+		p.processItemWithSignatures(batch, item)
+		return nil
+	}
 	// Unmarshal abi. Note that if the abi unmarshalling fails, we log a warning and proceed to
 	// mark the item as processed so that the analyzer is not blocked. If and when the abi is
 	// redownloaded, the analyzer will re-process the relevant items.
@@ -243,6 +347,7 @@ func (p *processor) ProcessItem(ctx context.Context, batch *storage.QueryBatch,
 				nil,
 				nil,
 				nil,
+				false,
 			)
 		} else if item.Tx != nil {
 			batch.Queue(
@@ -253,6 +358,7 @@ func (p *processor) ProcessItem(ctx context.Context, batch *storage.QueryBatch,
 				nil,
 				nil,
 				nil,
+				false,
 			)
 		}
 		return nil
@@ -274,6 +380,7 @@ func (p *processor) ProcessItem(ctx context.Context, batch *storage.QueryBatch,
 			eventName,
 			eventArgs,
 			eventSig,
+			false,
 		)
 	} else if item.Tx != nil {
 		methodName, methodArgs, err := p.parseTxCall(item.Tx, contractAbi)
@@ -294,12 +401,84 @@ func (p *processor) ProcessItem(ctx context.Context, batch *storage.QueryBatch,
 			methodArgs,
 			errMsg,
 			errArgs,
+			false,
 		)
 	}
 
 	return nil
 }
 
+// Attempts to parse the raw event body into the event name, args, and signature
+// using the known signatures.
+func (p *processor) parseEventWithSignatures(ev *abiEncodedEvent) (*string, []*abiEncodedArg, *ethCommon.Hash, error) {
+	abiEvent, abiEventArgs, err := p.signatures.ParseEvent(ev.EventBody.Topics, ev.EventBody.Data)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error processing event using signatures: %w", err)
+	}
+	eventArgs, err := marshalArgs(abiEvent.Inputs, abiEventArgs)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error processing event args using signatures: %w", err)
+	}
+
+	return &abiEvent.RawName, eventArgs, &abiEvent.ID, nil
+}
+
+// Attempts to parse the raw evm.Call transaction data into the transaction
+// method name and arguments using the known signatures.
+func (p *processor) parseTxCallWithSignatures(tx *abiEncodedTx) (*string, []*abiEncodedArg, error) {
+	method, abiTxArgs, err := p.signatures.ParseData(tx.TxData)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error processing tx using signatures: %w", err)
+	}
+	txArgs, err := marshalArgs(method.Inputs, abiTxArgs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error processing tx args using signatures: %w", err)
+	}
+
+	return &method.RawName, txArgs, nil
+}
+
+// processItemWithSignatures parses the call or event of a contract without an ABI
+// using the known signatures, and flags the results as low confidence.
+// Unlike with an ABI, revert reasons are not parsed.
+func (p *processor) processItemWithSignatures(batch *storage.QueryBatch, item *abiEncodedItem) {
+	if item.Event != nil {
+		eventName, eventArgs, eventSig, err := p.parseEventWithSignatures(item.Event)
+		if err != nil {
+			// Most events of unverified contracts are not known; not worth a warning.
+			p.logger.Debug("error parsing event with signatures", "err", err, "contract_address", item.ContractAddr, "event_round", item.Event.Round, "event_tx_index", item.Event.TxIndex)
+			// Write to the DB regardless of error so we don't keep retrying the same item.
+		}
+		batch.Queue(
+			queries.RuntimeEventEvmParsedFieldsUpdate,
+			p.runtime,
+			item.Event.Round,
+			item.Event.TxIndex,
+			item.Event.EventBody,
+			eventName,
+			eventArgs,
+			eventSig,
+			true,
+		)
+	} else if item.Tx != nil {
+		methodName, methodArgs, err := p.parseTxCallWithSignatures(item.Tx)
+		if err != nil {
+			p.logger.Debug("error parsing tx with signatures", "err", err, "contract_address", item.ContractAddr, "tx_hash", item.Tx.TxHash)
+			// Write to the DB regardless of error so we don't keep retrying the same item.
+		}
+		batch.Queue(
+			queries.RuntimeTransactionEvmParsedFieldsUpdate,
+			p.runtime,
+			item.Tx.TxHash,
+			methodName,
+			methodArgs,
+			nil,
+			nil,
+			true,
+		)
+	}
+}
+
 func marshalArgs(abiArgs abi.Arguments, argVals []interface{}) ([]*abiEncodedArg, error) {
 	if len(abiArgs) != len(argVals) {
 		return nil, fmt.Errorf("number of args does not match abi specification")
@@ -340,5 +519,13 @@ func (p *processor) QueueLength(ctx context.Context) (int, error) {
 	if err := p.target.QueryRow(ctx, fmt.Sprintf("SELECT COUNT(*) FROM (%s) subquery", queries.RuntimeEvmVerifiedContractEvents), p.runtime, 1000).Scan(&evQueueLength); err != nil {
 		return 0, fmt.Errorf("querying number of verified abi events: %w", err)
 	}
-	return txQueueLength + evQueueLength, nil
+	var unverifiedTxQueueLength int
+	if err := p.target.QueryRow(ctx, fmt.Sprintf("SELECT COUNT(*) FROM (%s) subquery", queries.RuntimeEvmUnverifiedContractTxs), p.runtime, 1000, p.signaturesUpdatedAt).Scan(&unverifiedTxQueueLength); err != nil {
+		return 0, fmt.Errorf("querying number of unverified abi txs: %w", err)
+	}
+	var unverifiedEvQueueLength int
+	if err := p.target.QueryRow(ctx, fmt.Sprintf("SELECT COUNT(*) FROM (%s) subquery", queries.RuntimeEvmUnverifiedContractEvents), p.runtime, 1000, p.signaturesUpdatedAt).Scan(&unverifiedEvQueueLength); err != nil {
+		return 0, fmt.Errorf("querying number of unverified abi events: %w", err)
+	}
+	return txQueueLength + evQueueLength + unverifiedTxQueueLength + unverifiedEvQueueLength, nil
 }
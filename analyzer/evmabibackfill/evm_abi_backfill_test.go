@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/oasisprotocol/nexus/analyzer/evmabi"
+	"github.com/oasisprotocol/nexus/analyzer/evmsignatures"
 	"github.com/oasisprotocol/nexus/common"
 	"github.com/oasisprotocol/nexus/log"
 )
@@ -152,6 +153,51 @@ func TestParseTransactionCall(t *testing.T) {
 	}
 }
 
+func TestParseTransactionCallWithSignatures(t *testing.T) {
+	signatures, err := evmsignatures.NewDatabase()
+	require.Nil(t, err)
+	p := &processor{
+		runtime:    common.RuntimeSapphire,
+		target:     nil,
+		logger:     log.NewDefaultLogger("testing"),
+		signatures: signatures,
+	}
+	// WROSE transfer; the bundled signatures do not include parameter names.
+	tx := unmarshalEvmTx(t, "qQWcuwAAAAAAAAAAAAAAAOY8J3oxo9tm0HXYxapOlEKOuxjxAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=", nil)
+	expected := mockParsedTxCall{
+		Name: common.Ptr("transfer"),
+		Args: []*abiEncodedArg{
+			{
+				Name:    "",
+				EvmType: "address",
+				Value:   ethCommon.HexToAddress("0xE63c277a31A3dB66D075D8C5aA4E94428Ebb18F1"),
+			},
+			{
+				Name:    "",
+				EvmType: "uint256",
+				Value:   "0",
+			},
+		},
+	}
+	name, args, err := p.parseTxCallWithSignatures(tx)
+	require.Nil(t, err)
+	verifyTxCall(t, &expected, name, args)
+
+	// With the WROSE ABI added, the parameter names are known.
+	signatures.AddABI(evmabi.WROSE)
+	expected.Args[0].Name = "dst"
+	expected.Args[1].Name = "wad"
+	name, args, err = p.parseTxCallWithSignatures(tx)
+	require.Nil(t, err)
+	verifyTxCall(t, &expected, name, args)
+
+	// Unknown method.
+	name, args, err = p.parseTxCallWithSignatures(unmarshalEvmTx(t, "3q2+7w==", nil))
+	require.NotNil(t, err)
+	require.Nil(t, name)
+	require.Nil(t, args)
+}
+
 func TestParseTxErrorPlaintext(t *testing.T) {
 	abi := evmabi.WROSE
 	p := &processor{
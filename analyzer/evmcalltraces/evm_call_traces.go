@@ -0,0 +1,216 @@
+package evmcalltraces
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/oasisprotocol/nexus/analyzer"
+	"github.com/oasisprotocol/nexus/analyzer/item"
+	"github.com/oasisprotocol/nexus/analyzer/queries"
+	"github.com/oasisprotocol/nexus/analyzer/util/addresses"
+	apiTypes "github.com/oasisprotocol/nexus/api/v1/types"
+	"github.com/oasisprotocol/nexus/common"
+	"github.com/oasisprotocol/nexus/config"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage"
+	"github.com/oasisprotocol/nexus/storage/oasis/nodeapi"
+)
+
+// This analyzer fetches the call traces of the EVM transactions of indexed
+// rounds, and stores the calls, contract creations and self-destructs that
+// contracts made during their execution ("internal calls"). These are not
+// visible in the events or transaction results that the block analyzer
+// processes. Participants of internal calls are related to the transaction,
+// and contracts created by other contracts get their creation_tx.
+//
+// Tracing requires an EVM trace RPC to be configured for the runtime's node
+// (see config.NodeConfig.EVMTraceRPC).
+
+const (
+	evmCallTracesAnalyzerPrefix = "evm_call_traces_"
+)
+
+type processor struct {
+	runtime common.Runtime
+	source  nodeapi.RuntimeApiLite
+	target  storage.TargetStorage
+	logger  *log.Logger
+}
+
+var _ item.ItemProcessor[uint64] = (*processor)(nil)
+
+func NewAnalyzer(
+	runtime common.Runtime,
+	cfg config.ItemBasedAnalyzerConfig,
+	sourceClient nodeapi.RuntimeApiLite,
+	target storage.TargetStorage,
+	logger *log.Logger,
+) (analyzer.Analyzer, error) {
+	logger = logger.With("analyzer", evmCallTracesAnalyzerPrefix+runtime)
+	p := &processor{
+		runtime: runtime,
+		source:  sourceClient,
+		target:  target,
+		logger:  logger,
+	}
+	return item.NewAnalyzer[uint64](
+		evmCallTracesAnalyzerPrefix+string(runtime),
+		cfg,
+		p,
+		target,
+		logger,
+	)
+}
+
+func (p *processor) GetItems(ctx context.Context, limit uint64) ([]uint64, error) {
+	var rounds []uint64
+	rows, err := p.target.Query(ctx, queries.RuntimeEVMTraceRoundsStale, p.runtime, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying rounds to trace: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var round uint64
+		if err = rows.Scan(&round); err != nil {
+			return nil, fmt.Errorf("scanning round: %w", err)
+		}
+		rounds = append(rounds, round)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating rounds to trace: %w", err)
+	}
+	return rounds, nil
+}
+
+type evmTransaction struct {
+	index int
+	hash  string
+}
+
+func (p *processor) ProcessItem(ctx context.Context, batch *storage.QueryBatch, round uint64) error {
+	traces, err := p.source.EVMTraceRound(ctx, round)
+	if err != nil {
+		// Write nothing into the DB; we'll try again later.
+		return fmt.Errorf("tracing round %d: %w", round, err)
+	}
+
+	// Match the traces to the indexed transactions by their Ethereum hash.
+	txs := map[string]evmTransaction{}
+	rows, err := p.target.Query(ctx, queries.RuntimeEVMTransactionsByRound, p.runtime, round)
+	if err != nil {
+		return fmt.Errorf("querying transactions of round %d: %w", round, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tx evmTransaction
+		var ethHash string
+		if err = rows.Scan(&tx.index, &tx.hash, &ethHash); err != nil {
+			return fmt.Errorf("scanning transaction: %w", err)
+		}
+		txs[ethHash] = tx
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("iterating transactions of round %d: %w", round, err)
+	}
+
+	addressPreimages := map[apiTypes.Address]*addresses.PreimageData{}
+	for _, trace := range traces {
+		tx, ok := txs[hex.EncodeToString(trace.TxHash)]
+		if !ok {
+			// Retrying would not help, since the indexed transactions of the round are final.
+			p.logger.Warn("skipping trace of unknown transaction", "round", round, "tx_eth_hash", hex.EncodeToString(trace.TxHash))
+			continue
+		}
+		relatedAddresses := map[apiTypes.Address]struct{}{}
+		for _, call := range flattenCalls(&trace.Call) {
+			fromAddr, err1 := addresses.RegisterRelatedEthAddress(addressPreimages, relatedAddresses, call.frame.From)
+			if err1 != nil {
+				return fmt.Errorf("tx %s call %d: from address: %w", tx.hash, call.index, err1)
+			}
+			var toAddr *apiTypes.Address
+			if len(call.frame.To) != 0 {
+				addr, err1 := addresses.RegisterRelatedEthAddress(addressPreimages, relatedAddresses, call.frame.To)
+				if err1 != nil {
+					return fmt.Errorf("tx %s call %d: to address: %w", tx.hash, call.index, err1)
+				}
+				toAddr = &addr
+			}
+			var callErr *string
+			if call.frame.Error != "" {
+				callErr = &call.frame.Error
+			}
+			batch.Queue(
+				queries.RuntimeInternalCallInsert,
+				p.runtime,
+				round,
+				tx.index,
+				call.index,
+				call.depth,
+				call.frame.Type,
+				fromAddr,
+				toAddr,
+				call.frame.Value,
+				call.frame.Gas,
+				call.frame.GasUsed,
+				call.frame.Input,
+				call.frame.Output,
+				callErr,
+				call.success,
+			)
+			if call.success && toAddr != nil && (call.frame.Type == "CREATE" || call.frame.Type == "CREATE2") {
+				batch.Queue(queries.RuntimeEVMContractInternalCreationUpsert, p.runtime, *toAddr, tx.hash, call.frame.Input)
+				batch.Queue(queries.RuntimeEVMContractCodeAnalysisInsert, p.runtime, *toAddr)
+			}
+		}
+		for addr := range relatedAddresses {
+			batch.Queue(queries.RuntimeRelatedTransactionInsertIfMissing, p.runtime, addr, round, tx.index)
+		}
+	}
+	for addr, preimageData := range addressPreimages {
+		batch.Queue(queries.AddressPreimageInsert, addr, preimageData.ContextIdentifier, preimageData.ContextVersion, preimageData.Data)
+	}
+	batch.Queue(queries.RuntimeEVMTracedRoundInsert, p.runtime, round)
+	return nil
+}
+
+func (p *processor) QueueLength(ctx context.Context) (int, error) {
+	var queueLength int
+	if err := p.target.QueryRow(ctx, queries.RuntimeEVMTraceRoundsStaleCount, p.runtime).Scan(&queueLength); err != nil {
+		return 0, fmt.Errorf("querying number of rounds to trace: %w", err)
+	}
+	return queueLength, nil
+}
+
+// internalCall is a frame of a call trace below the top-level call.
+type internalCall struct {
+	// index is the position of the call in execution order, starting at 0.
+	index int
+	depth int
+	frame *nodeapi.EVMCallFrame
+	// success is false if the call or any of its ancestors failed, in which
+	// case its effects were reverted.
+	success bool
+}
+
+// flattenCalls returns the internal calls of a call trace, in execution order.
+func flattenCalls(top *nodeapi.EVMCallFrame) []internalCall {
+	var calls []internalCall
+	var walk func(frame *nodeapi.EVMCallFrame, depth int, parentSuccess bool)
+	walk = func(frame *nodeapi.EVMCallFrame, depth int, parentSuccess bool) {
+		success := parentSuccess && frame.Error == ""
+		if depth > 0 {
+			calls = append(calls, internalCall{
+				index:   len(calls),
+				depth:   depth,
+				frame:   frame,
+				success: success,
+			})
+		}
+		for i := range frame.Calls {
+			walk(&frame.Calls[i], depth+1, success)
+		}
+	}
+	walk(top, 0, true)
+	return calls
+}
@@ -0,0 +1,47 @@
+package evmcalltraces
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/nexus/storage/oasis/nodeapi"
+)
+
+func TestFlattenCalls(t *testing.T) {
+	// tx -> A
+	//   A -> B (reverted)
+	//     B -> C
+	//   A -> CREATE D
+	top := nodeapi.EVMCallFrame{
+		Type: "CALL",
+		Calls: []nodeapi.EVMCallFrame{
+			{
+				Type:  "CALL",
+				Error: "execution reverted",
+				Calls: []nodeapi.EVMCallFrame{{Type: "STATICCALL"}},
+			},
+			{Type: "CREATE"},
+		},
+	}
+	calls := flattenCalls(&top)
+	require.Len(t, calls, 3)
+
+	for i, expected := range []struct {
+		typ     string
+		depth   int
+		success bool
+	}{
+		{"CALL", 1, false},
+		{"STATICCALL", 2, false}, // Reverted along with its parent.
+		{"CREATE", 1, true},
+	} {
+		require.Equal(t, i, calls[i].index)
+		require.Equal(t, expected.typ, calls[i].frame.Type)
+		require.Equal(t, expected.depth, calls[i].depth)
+		require.Equal(t, expected.success, calls[i].success, "call %d", i)
+	}
+
+	// A transaction without internal calls.
+	require.Empty(t, flattenCalls(&nodeapi.EVMCallFrame{Type: "CALL"}))
+}
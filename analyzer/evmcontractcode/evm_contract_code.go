@@ -5,10 +5,15 @@ import (
 	"fmt"
 
 	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/oasisprotocol/oasis-core/go/common/errors"
+	sdkCore "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/core"
+	sdkEVM "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/evm"
+	sdkTypes "github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
 
 	"github.com/oasisprotocol/nexus/analyzer"
 	"github.com/oasisprotocol/nexus/analyzer/item"
 	"github.com/oasisprotocol/nexus/analyzer/queries"
+	"github.com/oasisprotocol/nexus/analyzer/util/addresses"
 	"github.com/oasisprotocol/nexus/common"
 	"github.com/oasisprotocol/nexus/config"
 	"github.com/oasisprotocol/nexus/log"
@@ -115,10 +120,86 @@ func (p *processor) ProcessItem(ctx context.Context, batch *storage.QueryBatch,
 			candidate.Addr,
 			code,
 		)
+		if err = p.queueProxyImplementation(ctx, batch, candidate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Storage slots where proxies keep the address of their implementation, newest standard first.
+var proxyImplementationSlots = []ethCommon.Hash{
+	// EIP-1967, used by UUPS and transparent proxies: keccak256("eip1967.proxy.implementation") - 1.
+	ethCommon.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc"),
+	// EIP-1822 (UUPS before EIP-1967): keccak256("PROXIABLE").
+	ethCommon.HexToHash("0xc5f16f0fcc639fa48a6947836d9850f504798523bf8c9a3a87d5876cf622bcf7"),
+	// OpenZeppelin proxies before EIP-1967: keccak256("org.zeppelinos.proxy.implementation").
+	ethCommon.HexToHash("0x7050c9e0f4ca769c69bd3a8ef740bc37934f8e2c036e5a723fd8ee048ed3f8c3"),
+}
+
+// queueProxyImplementation records the implementation of the contract if it is a proxy,
+// as found in the standard storage slots. This complements the `Upgraded` events that the
+// block analyzer tracks, for proxies whose events predate the indexed rounds.
+func (p *processor) queueProxyImplementation(ctx context.Context, batch *storage.QueryBatch, candidate *ContractCandidate) error {
+	for _, slot := range proxyImplementationSlots {
+		word, err := p.source.EVMGetStorage(ctx, candidate.DownloadRound, candidate.EthAddr.Bytes(), slot.Bytes())
+		if err != nil {
+			if isStorageUnavailable(err) {
+				// Confidential runtimes (Sapphire) do not expose storage; we rely on events there.
+				p.logger.Debug("runtime does not expose storage, skipping proxy implementation slots", "addr", candidate.Addr, "err", err)
+				return nil
+			}
+			return fmt.Errorf("reading proxy implementation slot %s of %s: %w", slot.Hex(), candidate.Addr, err)
+		}
+		implementationECAddr := ethCommon.BytesToAddress(word)
+		if len(word) != 32 || implementationECAddr == (ethCommon.Address{}) {
+			continue
+		}
+		implementationAddr, err := addresses.FromEthAddress(implementationECAddr.Bytes())
+		if err != nil {
+			return fmt.Errorf("implementation address %s of proxy %s: %w", implementationECAddr.Hex(), candidate.Addr, err)
+		}
+		p.logger.Info("found proxy implementation", "addr", candidate.Addr, "implementation", implementationAddr, "round", candidate.DownloadRound)
+		batch.Queue(
+			queries.AddressPreimageInsert,
+			implementationAddr,
+			sdkTypes.AddressV0Secp256k1EthContext.Identifier,
+			int32(sdkTypes.AddressV0Secp256k1EthContext.Version),
+			implementationECAddr.Bytes(),
+		)
+		batch.Queue(
+			queries.RuntimeEVMProxyImplementationFromStorageInsert,
+			p.runtime,
+			candidate.Addr,
+			candidate.DownloadRound,
+			implementationAddr,
+		)
+		batch.Queue(
+			queries.RuntimeEVMContractCodeAnalysisInsert,
+			p.runtime,
+			implementationAddr,
+		)
+		return nil
 	}
 	return nil
 }
 
+// isStorageUnavailable returns whether err is the runtime's refusal to expose contract
+// storage, as opposed to a transient failure to read it.
+func isStorageUnavailable(err error) bool {
+	module, code := errors.Code(err)
+	switch {
+	case module == sdkEVM.ModuleName && code == 7:
+		// Forbidden; confidential runtimes do not allow reading storage.
+		return true
+	case module == sdkCore.ModuleName && code == 3:
+		// Invalid method; the runtime does not support the storage query.
+		return true
+	default:
+		return false
+	}
+}
+
 func (p *processor) QueueLength(ctx context.Context) (int, error) {
 	var queueLength int
 	if err := p.target.QueryRow(ctx, queries.RuntimeEVMContractCodeAnalysisStaleCount, p.runtime).Scan(&queueLength); err != nil {
@@ -0,0 +1,25 @@
+package evmcontractcode
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/errors"
+)
+
+func TestIsStorageUnavailable(t *testing.T) {
+	// Errors as reconstructed from the node's responses.
+	forbidden := errors.FromCode("evm", 7, "forbidden by node policy")
+	invalidMethod := errors.FromCode("core", 3, "invalid method: evm.Storage")
+
+	require.True(t, isStorageUnavailable(forbidden))
+	require.True(t, isStorageUnavailable(fmt.Errorf("getting storage: %w", forbidden)))
+	require.True(t, isStorageUnavailable(invalidMethod))
+
+	require.False(t, isStorageUnavailable(errors.FromCode("evm", 2, "EVM error")))
+	require.False(t, isStorageUnavailable(context.DeadlineExceeded))
+	require.False(t, isStorageUnavailable(fmt.Errorf("rpc error: connection refused")))
+}
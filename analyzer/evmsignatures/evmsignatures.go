@@ -0,0 +1,257 @@
+// Package evmsignatures implements an offline database of EVM function and
+// event signatures, similar to the 4byte directory. It is used to decode
+// calls to and events of contracts whose ABI is not known, on a best-effort
+// basis.
+package evmsignatures
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/oasisprotocol/nexus/analyzer/runtime/abiparse"
+)
+
+// The bundled signatures; see Load for the format.
+//
+//go:embed signatures.txt
+var bundledSignatures []byte
+
+// Database maps function selectors and event topics to the candidate methods
+// and events that they may have been produced by. Candidates from ABIs come
+// before those from text signatures, as they include parameter names and
+// whether event parameters are indexed.
+//
+// It is safe for concurrent use.
+type Database struct {
+	mu      sync.RWMutex
+	methods map[[4]byte][]candidate[abi.Method]
+	events  map[ethCommon.Hash][]candidate[abi.Event]
+}
+
+type candidate[T any] struct {
+	item T
+	// Whether the candidate comes from an ABI rather than from a text signature.
+	fromABI bool
+}
+
+// NewDatabase returns a database with the bundled signatures.
+func NewDatabase() (*Database, error) {
+	db := &Database{
+		methods: map[[4]byte][]candidate[abi.Method]{},
+		events:  map[ethCommon.Hash][]candidate[abi.Event]{},
+	}
+	if err := db.Load(bytes.NewReader(bundledSignatures)); err != nil {
+		return nil, fmt.Errorf("loading bundled signatures: %w", err)
+	}
+	return db, nil
+}
+
+// Load adds text signatures to the database, one per line, in the form
+// `function transfer(address,uint256)` or `event Transfer(address,address,uint256)`.
+// Empty lines and lines starting with `#` are ignored.
+func (db *Database) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kind, signature, _ := strings.Cut(line, " ")
+		selector, err := abi.ParseSelector(strings.TrimSpace(signature))
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		inputs, err := signatureArguments(selector.Inputs)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		switch kind {
+		case "function":
+			db.addMethod(abi.NewMethod(selector.Name, selector.Name, abi.Function, "", false, false, inputs, nil), false)
+		case "event":
+			db.addEvent(abi.NewEvent(selector.Name, selector.Name, false, inputs), false)
+		default:
+			return fmt.Errorf("line %d: unknown signature kind %q", lineNum, kind)
+		}
+	}
+	return scanner.Err()
+}
+
+// signatureArguments converts the arguments of a parsed text signature into
+// unnamed ABI arguments.
+func signatureArguments(args []abi.ArgumentMarshaling) (abi.Arguments, error) {
+	arguments := make(abi.Arguments, 0, len(args))
+	for _, arg := range args {
+		t, err := abi.NewType(arg.Type, arg.InternalType, arg.Components)
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, abi.Argument{Type: t})
+	}
+	return arguments, nil
+}
+
+// AddABI adds the methods and events of a contract's ABI to the database.
+func (db *Database) AddABI(contractABI *abi.ABI) {
+	for _, method := range contractABI.Methods {
+		db.addMethod(method, true)
+	}
+	for _, event := range contractABI.Events {
+		if event.Anonymous {
+			// Anonymous events have no signature topic.
+			continue
+		}
+		db.addEvent(event, true)
+	}
+}
+
+func (db *Database) addMethod(method abi.Method, fromABI bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var selector [4]byte
+	copy(selector[:], method.ID)
+	db.methods[selector] = addCandidate(db.methods[selector], candidate[abi.Method]{method, fromABI}, func(a, b abi.Method) bool {
+		return a.Sig == b.Sig
+	})
+}
+
+func (db *Database) addEvent(event abi.Event, fromABI bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.events[event.ID] = addCandidate(db.events[event.ID], candidate[abi.Event]{event, fromABI}, func(a, b abi.Event) bool {
+		return a.Sig == b.Sig && indexedMask(a.Inputs) == indexedMask(b.Inputs)
+	})
+}
+
+// addCandidate adds c to candidates, keeping candidates from ABIs first.
+// A candidate from an ABI replaces an equal candidate from a text signature.
+func addCandidate[T any](candidates []candidate[T], c candidate[T], equal func(a, b T) bool) []candidate[T] {
+	for i, existing := range candidates {
+		if equal(existing.item, c.item) {
+			if c.fromABI && !existing.fromABI {
+				candidates = append(candidates[:i], candidates[i+1:]...)
+				break
+			}
+			return candidates
+		}
+	}
+	if !c.fromABI {
+		return append(candidates, c)
+	}
+	i := 0
+	for i < len(candidates) && candidates[i].fromABI {
+		i++
+	}
+	candidates = append(candidates, candidate[T]{})
+	copy(candidates[i+1:], candidates[i:])
+	candidates[i] = c
+	return candidates
+}
+
+func indexedMask(args abi.Arguments) string {
+	var sb strings.Builder
+	for _, arg := range args {
+		if arg.Indexed {
+			sb.WriteByte('1')
+		} else {
+			sb.WriteByte('0')
+		}
+	}
+	return sb.String()
+}
+
+// ParseData parses call data into a method and its arguments, like abiparse.ParseData.
+// It returns the first candidate method whose inputs encode to exactly the call data.
+func (db *Database) ParseData(data []byte) (*abi.Method, []interface{}, error) {
+	if len(data) < 4 {
+		return nil, nil, fmt.Errorf("data (%dB) too short to have method ID", len(data))
+	}
+	var selector [4]byte
+	copy(selector[:], data[:4])
+	db.mu.RLock()
+	candidates := db.methods[selector]
+	db.mu.RUnlock()
+	for _, c := range candidates {
+		method := c.item
+		args, err := method.Inputs.Unpack(data[4:])
+		if err != nil {
+			continue
+		}
+		// Unpacking ignores trailing data, so a mismatching method with a prefix of
+		// the inputs would also unpack. Require the canonical encoding instead.
+		packed, err := method.Inputs.Pack(args...)
+		if err != nil || !bytes.Equal(packed, data[4:]) {
+			continue
+		}
+		return &method, args, nil
+	}
+	return nil, nil, fmt.Errorf("no known method with ID %x matches the data", selector)
+}
+
+// ParseEvent parses an event into its definition and arguments, like abiparse.ParseEvent.
+// Text signatures do not tell which parameters are indexed; for candidates from
+// text signatures, the leading parameters are assumed to be the indexed ones,
+// which is the case for most events.
+func (db *Database) ParseEvent(topics [][]byte, data []byte) (*abi.Event, []interface{}, error) {
+	if len(topics) < 1 {
+		return nil, nil, fmt.Errorf("topics (%d) too short to have event signature", len(topics))
+	}
+	id := ethCommon.BytesToHash(topics[0])
+	db.mu.RLock()
+	candidates := db.events[id]
+	db.mu.RUnlock()
+	numIndexed := len(topics) - 1
+	for _, c := range candidates {
+		event := c.item
+		if !c.fromABI {
+			if numIndexed > len(event.Inputs) {
+				continue
+			}
+			inputs := make(abi.Arguments, len(event.Inputs))
+			copy(inputs, event.Inputs)
+			for i := range inputs {
+				inputs[i].Indexed = i < numIndexed
+			}
+			event = abi.NewEvent(event.Name, event.RawName, false, inputs)
+		}
+		if countIndexed(event.Inputs) != numIndexed {
+			continue
+		}
+		candidateABI := abi.ABI{Events: map[string]abi.Event{event.Name: event}}
+		parsedEvent, args, err := abiparse.ParseEvent(topics, data, &candidateABI)
+		if err != nil {
+			continue
+		}
+		// As for calls, require the canonical encoding of the data.
+		var nonIndexedArgs []interface{}
+		for i, input := range parsedEvent.Inputs {
+			if !input.Indexed {
+				nonIndexedArgs = append(nonIndexedArgs, args[i])
+			}
+		}
+		packed, err := parsedEvent.Inputs.NonIndexed().Pack(nonIndexedArgs...)
+		if err != nil || !bytes.Equal(packed, data) {
+			continue
+		}
+		return parsedEvent, args, nil
+	}
+	return nil, nil, fmt.Errorf("no known event with ID %s matches the event", id.Hex())
+}
+
+func countIndexed(args abi.Arguments) int {
+	n := 0
+	for _, arg := range args {
+		if arg.Indexed {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,99 @@
+package evmsignatures
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/nexus/analyzer/evmabi"
+)
+
+func TestParseDataBundled(t *testing.T) {
+	db, err := NewDatabase()
+	require.NoError(t, err)
+
+	recipient := ethCommon.HexToAddress("0xE63c277a31A3dB66D075D8C5aA4E94428Ebb18F1")
+	data, err := evmabi.ERC20.Pack("transfer", recipient, big.NewInt(1000))
+	require.NoError(t, err)
+
+	method, args, err := db.ParseData(data)
+	require.NoError(t, err)
+	require.Equal(t, "transfer", method.RawName)
+	require.Equal(t, []interface{}{recipient, big.NewInt(1000)}, args)
+	require.Equal(t, "", method.Inputs[0].Name)
+
+	// Trailing data does not match the signature.
+	_, _, err = db.ParseData(append(data, make([]byte, 32)...))
+	require.Error(t, err)
+
+	// Unknown selector.
+	_, _, err = db.ParseData([]byte{0xde, 0xad, 0xbe, 0xef})
+	require.Error(t, err)
+}
+
+func TestParseDataFromABI(t *testing.T) {
+	db, err := NewDatabase()
+	require.NoError(t, err)
+	db.AddABI(evmabi.WROSE)
+
+	data, err := evmabi.WROSE.Pack("withdraw", big.NewInt(5))
+	require.NoError(t, err)
+	method, args, err := db.ParseData(data)
+	require.NoError(t, err)
+	require.Equal(t, "withdraw", method.RawName)
+	// The parameter name comes from the ABI.
+	require.Equal(t, "wad", method.Inputs[0].Name)
+	require.Equal(t, []interface{}{big.NewInt(5)}, args)
+}
+
+func TestParseEventBundled(t *testing.T) {
+	db, err := NewDatabase()
+	require.NoError(t, err)
+
+	transfer := evmabi.ERC20.Events["Transfer"]
+	from := ethCommon.HexToAddress("0x1111111111111111111111111111111111111111")
+	to := ethCommon.HexToAddress("0x2222222222222222222222222222222222222222")
+	data, err := transfer.Inputs.NonIndexed().Pack(big.NewInt(7))
+	require.NoError(t, err)
+	topics := [][]byte{
+		transfer.ID.Bytes(),
+		ethCommon.BytesToHash(from.Bytes()).Bytes(),
+		ethCommon.BytesToHash(to.Bytes()).Bytes(),
+	}
+
+	event, args, err := db.ParseEvent(topics, data)
+	require.NoError(t, err)
+	require.Equal(t, "Transfer", event.RawName)
+	require.Equal(t, transfer.ID, event.ID)
+	require.Equal(t, []interface{}{from, to, big.NewInt(7)}, args)
+
+	// ERC-721 transfers have the same signature, with the token ID indexed too.
+	tokenID := ethCommon.BigToHash(big.NewInt(42)).Bytes()
+	event, args, err = db.ParseEvent(append(topics, tokenID), nil)
+	require.NoError(t, err)
+	require.True(t, event.Inputs[2].Indexed)
+	require.Equal(t, []interface{}{from, to, big.NewInt(42)}, args)
+
+	// Too many topics for any known signature.
+	_, _, err = db.ParseEvent(append(topics, tokenID, tokenID), nil)
+	require.Error(t, err)
+}
+
+func TestLoad(t *testing.T) {
+	db, err := NewDatabase()
+	require.NoError(t, err)
+	require.NoError(t, db.Load(strings.NewReader("# comment\n\nfunction setGreeting(string)\n")))
+
+	data := append(ethCommon.FromHex("0xa4136862"), make([]byte, 64)...)
+	data[4+31] = 0x20
+	method, args, err := db.ParseData(data)
+	require.NoError(t, err)
+	require.Equal(t, "setGreeting", method.RawName)
+	require.Equal(t, []interface{}{""}, args)
+
+	require.Error(t, db.Load(strings.NewReader("constructor foo(uint256)\n")))
+	require.Error(t, db.Load(strings.NewReader("function foo(notatype)\n")))
+}
@@ -197,6 +197,10 @@ func (p *processor) ProcessItem(ctx context.Context, batch *storage.QueryBatch,
 		} else {
 			p.logger.Debug("native balance: nothing to correct", "account_addr", staleTokenBalance.AccountAddr, "balance", balance)
 		}
+	case common.TokenTypeERC1155:
+		if err := p.processMultiTokenBalance(ctx, batch, staleTokenBalance); err != nil {
+			return err
+		}
 	default:
 		// All other ERC-X tokens. Query the token contract.
 		tokenEthAddr, err := client.EVMEthAddrFromPreimage(staleTokenBalance.TokenAddrContextIdentifier, staleTokenBalance.TokenAddrContextVersion, staleTokenBalance.TokenAddrData)
@@ -255,6 +259,92 @@ func (p *processor) ProcessItem(ctx context.Context, batch *storage.QueryBatch,
 	return nil
 }
 
+// processMultiTokenBalance corrects the balances of an ERC-1155 token. ERC-1155
+// has no method for querying an account's balance across all token IDs, so we
+// download the balance of each ID that the account is known to have held, and
+// correct both the per-ID balances and the total by the difference.
+//
+// The reckoned per-ID balances are read here rather than in GetItems, so they
+// are downloaded at the round that they are consistent with, which may be
+// later than staleTokenBalance.DownloadRound. The total is corrected by the sum
+// of per-ID corrections, which keeps any reckoning after that round in place.
+func (p *processor) processMultiTokenBalance(ctx context.Context, batch *storage.QueryBatch, staleTokenBalance *StaleTokenBalance) error {
+	tokenEthAddr, err := client.EVMEthAddrFromPreimage(staleTokenBalance.TokenAddrContextIdentifier, staleTokenBalance.TokenAddrContextVersion, staleTokenBalance.TokenAddrData)
+	if err != nil {
+		return fmt.Errorf("token address: %w", err)
+	}
+	if staleTokenBalance.AccountAddrContextIdentifier == nil || staleTokenBalance.AccountAddrContextVersion == nil || staleTokenBalance.AccountAddrData == nil {
+		return fmt.Errorf("account address: missing preimage for: '%s' (token address: '%s')", staleTokenBalance.AccountAddr, staleTokenBalance.TokenAddr)
+	}
+	accountEthAddr, err := client.EVMEthAddrFromPreimage(*staleTokenBalance.AccountAddrContextIdentifier, *staleTokenBalance.AccountAddrContextVersion, staleTokenBalance.AccountAddrData)
+	if err != nil {
+		return fmt.Errorf("account address: %w", err)
+	}
+
+	rows, err := p.target.Query(ctx, queries.RuntimeEVMNFTBalances, p.runtime, staleTokenBalance.TokenAddr, staleTokenBalance.AccountAddr)
+	if err != nil {
+		return fmt.Errorf("querying NFT balances: %w", err)
+	}
+	defer rows.Close()
+	var ids []*big.Int
+	var reckoned []*big.Int
+	var downloadRound uint64
+	for rows.Next() {
+		var id, balance common.BigInt
+		if err = rows.Scan(&id, &balance, &downloadRound); err != nil {
+			return fmt.Errorf("scanning NFT balance: %w", err)
+		}
+		ids = append(ids, &id.Int)
+		reckoned = append(reckoned, &balance.Int)
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("reading NFT balances: %w", err)
+	}
+	if len(ids) == 0 {
+		p.logger.Debug("EVM multi-token balance: no known IDs", "token_addr", staleTokenBalance.TokenAddr, "account_addr", staleTokenBalance.AccountAddr)
+		return nil
+	}
+
+	balances, err := evm.EVMDownloadNFTBalancesERC1155(ctx, p.logger, p.source, downloadRound, tokenEthAddr, accountEthAddr, ids)
+	if err != nil {
+		return fmt.Errorf("downloading NFT balances %s %s: %w", staleTokenBalance.TokenAddr, staleTokenBalance.AccountAddr, err)
+	}
+	if balances == nil {
+		return nil
+	}
+	totalCorrection := &big.Int{}
+	for i, id := range ids {
+		if balances[i].Cmp(reckoned[i]) == 0 {
+			continue
+		}
+		correction := (&big.Int{}).Sub(balances[i], reckoned[i])
+		batch.Queue(queries.RuntimeEVMNFTBalanceUpdate,
+			p.runtime,
+			staleTokenBalance.TokenAddr,
+			id,
+			staleTokenBalance.AccountAddr,
+			correction.String(),
+		)
+		totalCorrection.Add(totalCorrection, correction)
+	}
+	if totalCorrection.Sign() != 0 {
+		// Expected after fast-sync, where balances are not reckoned.
+		p.logger.Info("correcting reckoned balance of multi-token to downloaded balance",
+			"token_addr", staleTokenBalance.TokenAddr,
+			"account_addr", staleTokenBalance.AccountAddr,
+			"download_round", downloadRound,
+			"correction", totalCorrection,
+		)
+		batch.Queue(queries.RuntimeEVMTokenBalanceUpdate,
+			p.runtime,
+			staleTokenBalance.TokenAddr,
+			staleTokenBalance.AccountAddr,
+			totalCorrection.String(),
+		)
+	}
+	return nil
+}
+
 func (p *processor) QueueLength(ctx context.Context) (int, error) {
 	var queueLength int
 	if err := p.target.QueryRow(ctx, queries.RuntimeEVMTokenBalanceAnalysisStaleCount, p.runtime).Scan(&queueLength); err != nil {
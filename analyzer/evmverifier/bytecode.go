@@ -0,0 +1,77 @@
+package evmverifier
+
+import (
+	"bytes"
+
+	"github.com/oasisprotocol/nexus/analyzer/evmverifier/solc"
+	"github.com/oasisprotocol/nexus/analyzer/evmverifier/sourcify"
+)
+
+// stripMetadata returns the bytecode without the CBOR-encoded metadata that
+// solc appends to it. The metadata contains the hash of the metadata file,
+// which changes with e.g. comments and source file names.
+//
+// The last two bytes of the bytecode are the big-endian length of the CBOR
+// encoding, which is a map. Bytecode without metadata is returned as is.
+func stripMetadata(code []byte) []byte {
+	if len(code) < 2 {
+		return code
+	}
+	cborLength := int(code[len(code)-2])<<8 | int(code[len(code)-1])
+	start := len(code) - 2 - cborLength
+	if cborLength == 0 || start < 0 {
+		return code
+	}
+	// CBOR maps with up to 23 entries start with 0xa0 + the number of entries.
+	if code[start] < 0xa1 || code[start] > 0xb7 {
+		return code
+	}
+	return code[:start]
+}
+
+// libraryCallProtection is the start of the runtime bytecode of libraries: a
+// PUSH20 of the library's address, which is zero in the compiled bytecode and
+// filled in at deployment.
+var libraryCallProtection = append([]byte{0x73}, make([]byte, 20)...)
+
+// matchBytecode compares compiled runtime bytecode with deployed runtime bytecode.
+// Immutable variables, which are zero in compiled bytecode, are ignored.
+// It returns VerificationLevelFull if the bytecodes also match in their metadata,
+// VerificationLevelPartial if they only match without their metadata, and false
+// if they do not match.
+func matchBytecode(compiled []byte, immutables map[string][]solc.ImmutableReference, deployed []byte) (sourcify.VerificationLevel, bool) {
+	if len(compiled) != len(deployed) {
+		// Metadata of different lengths; the bytecode may still match partially.
+		if len(stripMetadata(compiled)) == 0 || !bytes.Equal(stripMetadata(compiled), zeroImmutables(stripMetadata(deployed), compiled, immutables)) {
+			return "", false
+		}
+		return sourcify.VerificationLevelPartial, true
+	}
+
+	deployed = zeroImmutables(deployed, compiled, immutables)
+	switch {
+	case bytes.Equal(compiled, deployed):
+		return sourcify.VerificationLevelFull, true
+	case len(stripMetadata(compiled)) != 0 && bytes.Equal(stripMetadata(compiled), stripMetadata(deployed)):
+		return sourcify.VerificationLevelPartial, true
+	default:
+		return "", false
+	}
+}
+
+// zeroImmutables returns a copy of deployed with the bytes that are set at deployment
+// zeroed, as they are in compiled: the immutable variables, and the address of libraries.
+func zeroImmutables(deployed []byte, compiled []byte, immutables map[string][]solc.ImmutableReference) []byte {
+	deployed = bytes.Clone(deployed)
+	for _, refs := range immutables {
+		for _, ref := range refs {
+			if ref.Start >= 0 && ref.Length >= 0 && ref.Start+ref.Length <= len(deployed) {
+				copy(deployed[ref.Start:ref.Start+ref.Length], make([]byte, ref.Length))
+			}
+		}
+	}
+	if bytes.HasPrefix(compiled, libraryCallProtection) && len(deployed) >= len(libraryCallProtection) && deployed[0] == 0x73 {
+		copy(deployed[1:len(libraryCallProtection)], make([]byte, 20))
+	}
+	return deployed
+}
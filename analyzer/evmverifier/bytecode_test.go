@@ -0,0 +1,71 @@
+package evmverifier
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/nexus/analyzer/evmverifier/solc"
+	"github.com/oasisprotocol/nexus/analyzer/evmverifier/sourcify"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+// withMetadata appends CBOR metadata {"ipfs": <hash>, "solc": <version>} as emitted by solc.
+func withMetadata(t *testing.T, code []byte, ipfsHash string) []byte {
+	metadata := mustDecodeHex(t, "a2646970667358221220"+ipfsHash+"64736f6c63430008180033")
+	return append(bytes.Clone(code), metadata...)
+}
+
+func TestMatchBytecode(t *testing.T) {
+	const hashA = "1111111111111111111111111111111111111111111111111111111111111111"
+	const hashB = "2222222222222222222222222222222222222222222222222222222222222222"
+	code := mustDecodeHex(t, "6080604052348015600f57600080fd5b50")
+
+	require.Equal(t, code, stripMetadata(withMetadata(t, code, hashA)))
+	require.Equal(t, code, stripMetadata(code))
+
+	// Identical bytecode.
+	level, ok := matchBytecode(withMetadata(t, code, hashA), nil, withMetadata(t, code, hashA))
+	require.True(t, ok)
+	require.Equal(t, sourcify.VerificationLevelFull, level)
+
+	// Different metadata hash.
+	level, ok = matchBytecode(withMetadata(t, code, hashA), nil, withMetadata(t, code, hashB))
+	require.True(t, ok)
+	require.Equal(t, sourcify.VerificationLevelPartial, level)
+
+	// Different code.
+	other := bytes.Clone(code)
+	other[3] = 0x41
+	_, ok = matchBytecode(withMetadata(t, code, hashA), nil, withMetadata(t, other, hashA))
+	require.False(t, ok)
+
+	// Bytecode without metadata does not match partially.
+	_, ok = matchBytecode(code, nil, other)
+	require.False(t, ok)
+
+	// Immutables are ignored.
+	compiled := append(bytes.Clone(code), make([]byte, 32)...)
+	deployed := append(bytes.Clone(code), bytes.Repeat([]byte{0xab}, 32)...)
+	immutables := map[string][]solc.ImmutableReference{"7": {{Start: len(code), Length: 32}}}
+	level, ok = matchBytecode(withMetadata(t, compiled, hashA), immutables, withMetadata(t, deployed, hashA))
+	require.True(t, ok)
+	require.Equal(t, sourcify.VerificationLevelFull, level)
+	_, ok = matchBytecode(withMetadata(t, compiled, hashA), nil, withMetadata(t, deployed, hashA))
+	require.False(t, ok)
+
+	// The address of libraries is ignored.
+	library := append(bytes.Clone(libraryCallProtection), code...)
+	deployedLibrary := bytes.Clone(library)
+	copy(deployedLibrary[1:21], bytes.Repeat([]byte{0xcd}, 20))
+	level, ok = matchBytecode(withMetadata(t, library, hashA), nil, withMetadata(t, deployedLibrary, hashA))
+	require.True(t, ok)
+	require.Equal(t, sourcify.VerificationLevelFull, level)
+}
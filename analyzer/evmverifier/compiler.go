@@ -0,0 +1,248 @@
+package evmverifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/oasisprotocol/nexus/analyzer"
+	"github.com/oasisprotocol/nexus/analyzer/evmverifier/solc"
+	"github.com/oasisprotocol/nexus/analyzer/evmverifier/sourcify"
+	"github.com/oasisprotocol/nexus/analyzer/item"
+	"github.com/oasisprotocol/nexus/analyzer/queries"
+	"github.com/oasisprotocol/nexus/common"
+	"github.com/oasisprotocol/nexus/config"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage"
+)
+
+const (
+	evmContractsCompilerAnalyzerPrefix = "evm_contract_compiler_"
+
+	// Compilation is CPU-heavy; process few requests in parallel by default.
+	defaultCompilerBatchSize = 4
+	defaultCompileTimeout    = 2 * time.Minute
+)
+
+// Statuses of verification requests in analysis.evm_contract_verifications.
+const (
+	VerificationStatusPending  = "pending"
+	VerificationStatusVerified = "verified"
+	VerificationStatusFailed   = "failed"
+)
+
+type compilerProcessor struct {
+	runtime        common.Runtime
+	compiler       *solc.Compiler
+	compileTimeout time.Duration
+	target         storage.TargetStorage
+	logger         *log.Logger
+}
+
+var _ item.ItemProcessor[verificationRequest] = (*compilerProcessor)(nil)
+
+// NewCompilerAnalyzer returns an analyzer that processes verification requests submitted
+// through the API: it compiles the submitted sources with local solc binaries, and
+// stores the verification if the result matches the contract's runtime bytecode.
+// Unlike the Sourcify-based analyzer, it works on any chain.
+func NewCompilerAnalyzer(
+	runtime common.Runtime,
+	cfg config.EVMContractCompilerConfig,
+	target storage.TargetStorage,
+	logger *log.Logger,
+) (analyzer.Analyzer, error) {
+	logger = logger.With("analyzer", evmContractsCompilerAnalyzerPrefix+runtime)
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = defaultCompilerBatchSize
+	}
+	if cfg.CompileTimeout == 0 {
+		cfg.CompileTimeout = defaultCompileTimeout
+	}
+
+	compiler, err := solc.NewCompiler(cfg.SolcBinaries)
+	if err != nil {
+		return nil, err
+	}
+	p := &compilerProcessor{
+		runtime:        runtime,
+		compiler:       compiler,
+		compileTimeout: cfg.CompileTimeout,
+		target:         target,
+		logger:         logger,
+	}
+
+	return item.NewAnalyzer[verificationRequest](
+		evmContractsCompilerAnalyzerPrefix+string(runtime),
+		cfg.ItemBasedAnalyzerConfig,
+		p,
+		target,
+		logger,
+	)
+}
+
+// A request to verify a contract, and the contract as known to Nexus.
+type verificationRequest struct {
+	ID              int64
+	Addr            oasisAddress
+	CompilerVersion string
+	ContractName    string
+	Sources         map[string]string
+	Settings        json.RawMessage
+	// The contract's runtime bytecode; nil if not (yet) known.
+	RuntimeBytecode []byte
+	// The contract's current verification level; nil if not verified.
+	VerificationLevel *sourcify.VerificationLevel
+}
+
+func (p *compilerProcessor) GetItems(ctx context.Context, limit uint64) ([]verificationRequest, error) {
+	rows, err := p.target.Query(ctx, queries.RuntimeEVMContractVerificationsPending, p.runtime, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying pending verification requests: %w", err)
+	}
+	defer rows.Close()
+
+	var items []verificationRequest
+	for rows.Next() {
+		var r verificationRequest
+		if err = rows.Scan(
+			&r.ID,
+			&r.Addr,
+			&r.CompilerVersion,
+			&r.ContractName,
+			&r.Sources,
+			&r.Settings,
+			&r.RuntimeBytecode,
+			&r.VerificationLevel,
+		); err != nil {
+			return nil, fmt.Errorf("scanning verification request: %w", err)
+		}
+		items = append(items, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating pending verification requests: %w", err)
+	}
+	return items, nil
+}
+
+func (p *compilerProcessor) ProcessItem(ctx context.Context, batch *storage.QueryBatch, item verificationRequest) error {
+	p.logger.Debug("verifying contract", "request_id", item.ID, "address", item.Addr, "compiler_version", item.CompilerVersion, "contract_name", item.ContractName)
+
+	level, err := p.verify(ctx, batch, item)
+	if err != nil {
+		if ctx.Err() != nil {
+			// Shutting down; leave the request pending.
+			return err
+		}
+		p.logger.Info("contract verification failed", "request_id", item.ID, "address", item.Addr, "err", err)
+		batch.Queue(queries.RuntimeEVMContractVerificationUpdate, item.ID, VerificationStatusFailed, err.Error(), nil)
+		return nil
+	}
+
+	p.logger.Info("verified contract", "request_id", item.ID, "address", item.Addr, "verification_level", level)
+	batch.Queue(queries.RuntimeEVMContractVerificationUpdate, item.ID, VerificationStatusVerified, nil, level)
+	return nil
+}
+
+// verify compiles the request's sources and compares the result with the contract's
+// runtime bytecode. On a match, it queues the verification of the contract.
+// Errors are reported to the submitter.
+func (p *compilerProcessor) verify(ctx context.Context, batch *storage.QueryBatch, item verificationRequest) (sourcify.VerificationLevel, error) {
+	if item.RuntimeBytecode == nil {
+		return "", errors.New("the runtime bytecode of the contract is not known; the address is not a contract, or it has not been indexed yet")
+	}
+	// Allow just the contract name if it is unambiguous.
+	sourcePath, contractName := "", item.ContractName
+	if i := strings.LastIndex(item.ContractName, ":"); i != -1 {
+		sourcePath, contractName = item.ContractName[:i], item.ContractName[i+1:]
+	}
+
+	compileCtx, cancel := context.WithTimeout(ctx, p.compileTimeout)
+	defer cancel()
+	out, err := p.compiler.Compile(compileCtx, item.CompilerVersion, item.Sources, item.Settings)
+	if err != nil {
+		return "", err
+	}
+	contract, err := findContract(out, sourcePath, contractName)
+	if err != nil {
+		return "", err
+	}
+	compiled, err := contract.DeployedBytecode()
+	if err != nil {
+		return "", err
+	}
+	level, ok := matchBytecode(compiled, contract.EVM.DeployedBytecode.ImmutableReferences, item.RuntimeBytecode)
+	if !ok {
+		return "", fmt.Errorf("the compiled runtime bytecode of %s does not match the runtime bytecode of the contract", item.ContractName)
+	}
+
+	// Keep an existing full match over a new partial one.
+	if item.VerificationLevel != nil && *item.VerificationLevel == sourcify.VerificationLevelFull && level == sourcify.VerificationLevelPartial {
+		return level, nil
+	}
+
+	sourceFiles := make([]sourcify.SourceFile, 0, len(item.Sources))
+	for srcPath, content := range item.Sources {
+		sourceFiles = append(sourceFiles, sourcify.SourceFile{
+			Name:    path.Base(srcPath),
+			Path:    srcPath,
+			Content: content,
+		})
+	}
+	sort.Slice(sourceFiles, func(i, j int) bool { return sourceFiles[i].Path < sourceFiles[j].Path })
+	sourceFilesJSON, err := json.Marshal(sourceFiles)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal source files: %w", err)
+	}
+
+	var metadata json.RawMessage
+	if contract.Metadata != "" {
+		metadata = json.RawMessage(contract.Metadata)
+	}
+	batch.Queue(
+		// NOTE: This also updates `verification_info_downloaded_at`, causing the `evm_abi` to re-parse
+		//       the contract's txs and events.
+		queries.RuntimeEVMVerifyContractUpsert,
+		p.runtime,
+		item.Addr,
+		contract.ABI,
+		metadata,
+		sourceFilesJSON,
+		level,
+	)
+	return level, nil
+}
+
+// findContract returns the compiled contract with the given name. If sourcePath is
+// empty, the contract name must be unique across all sources.
+func findContract(out *solc.Output, sourcePath string, contractName string) (*solc.Contract, error) {
+	var found []solc.Contract
+	for p, contracts := range out.Contracts {
+		if sourcePath != "" && p != sourcePath {
+			continue
+		}
+		if c, ok := contracts[contractName]; ok {
+			found = append(found, c)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("contract %s not found in the compiler output", contractName)
+	case 1:
+		return &found[0], nil
+	default:
+		return nil, fmt.Errorf("contract name %s is ambiguous; specify it as <source path>:%s", contractName, contractName)
+	}
+}
+
+func (p *compilerProcessor) QueueLength(ctx context.Context) (int, error) {
+	var queueLength int
+	if err := p.target.QueryRow(ctx, queries.RuntimeEVMContractVerificationsPendingCount, p.runtime).Scan(&queueLength); err != nil {
+		return 0, fmt.Errorf("querying number of pending verification requests: %w", err)
+	}
+	return queueLength, nil
+}
@@ -0,0 +1,168 @@
+// Package solc compiles Solidity sources with local solc binaries, using the
+// compiler's standard JSON interface.
+package solc
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Compiler compiles Solidity sources with a set of local solc binaries.
+type Compiler struct {
+	// binaries maps normalized compiler versions to paths of solc binaries.
+	binaries map[string]string
+}
+
+// NewCompiler returns a compiler that uses the given solc binaries, keyed by
+// compiler version (e.g. "0.8.24" or "v0.8.24+commit.e11b9ed9").
+func NewCompiler(binaries map[string]string) (*Compiler, error) {
+	c := &Compiler{binaries: map[string]string{}}
+	for version, path := range binaries {
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("solc binary for version %s: %w", version, err)
+		}
+		c.binaries[NormalizeVersion(version)] = path
+	}
+	return c, nil
+}
+
+// NormalizeVersion strips the "v" prefix and the build metadata from a compiler
+// version, e.g. "v0.8.24+commit.e11b9ed9" becomes "0.8.24".
+func NormalizeVersion(version string) string {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	version, _, _ = strings.Cut(version, "+")
+	return version
+}
+
+// SupportsVersion returns whether the compiler has a solc binary for the version.
+func (c *Compiler) SupportsVersion(version string) bool {
+	_, ok := c.binaries[NormalizeVersion(version)]
+	return ok
+}
+
+type source struct {
+	Content string `json:"content"`
+}
+
+type input struct {
+	Language string                     `json:"language"`
+	Sources  map[string]source          `json:"sources"`
+	Settings map[string]json.RawMessage `json:"settings"`
+}
+
+// The compiler outputs needed to verify contracts. Requested for all contracts.
+var outputSelection = json.RawMessage(`{"*": {"*": ["abi", "metadata", "evm.deployedBytecode.object", "evm.deployedBytecode.immutableReferences"]}}`)
+
+// Error is an error or warning reported by the compiler.
+type Error struct {
+	Severity         string `json:"severity"`
+	FormattedMessage string `json:"formattedMessage"`
+	Message          string `json:"message"`
+}
+
+// ImmutableReference is the location of an immutable variable in deployed bytecode.
+type ImmutableReference struct {
+	Start  int `json:"start"`
+	Length int `json:"length"`
+}
+
+// Contract is a compiled contract.
+type Contract struct {
+	ABI      json.RawMessage `json:"abi"`
+	Metadata string          `json:"metadata"`
+	EVM      struct {
+		DeployedBytecode struct {
+			Object              string                          `json:"object"`
+			ImmutableReferences map[string][]ImmutableReference `json:"immutableReferences"`
+		} `json:"deployedBytecode"`
+	} `json:"evm"`
+}
+
+// DeployedBytecode returns the runtime bytecode of the contract.
+func (c *Contract) DeployedBytecode() ([]byte, error) {
+	object := c.EVM.DeployedBytecode.Object
+	if strings.Contains(object, "__") {
+		return nil, fmt.Errorf("bytecode contains unlinked library placeholders; set the library addresses in settings.libraries")
+	}
+	return hex.DecodeString(object)
+}
+
+// Output is the output of the compiler.
+type Output struct {
+	Errors []Error `json:"errors"`
+	// Contracts maps source paths to contract names to compiled contracts.
+	Contracts map[string]map[string]Contract `json:"contracts"`
+}
+
+// Compile compiles the sources, keyed by path, with the given compiler version and settings.
+// The settings are the `settings` of the standard JSON input; their outputSelection is ignored.
+// Compilation errors are returned as an error.
+func (c *Compiler) Compile(ctx context.Context, version string, sources map[string]string, settings json.RawMessage) (*Output, error) {
+	binary, ok := c.binaries[NormalizeVersion(version)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported compiler version %s", version)
+	}
+
+	in := input{
+		Language: "Solidity",
+		Sources:  map[string]source{},
+		Settings: map[string]json.RawMessage{},
+	}
+	for path, content := range sources {
+		in.Sources[path] = source{Content: content}
+	}
+	if len(settings) != 0 {
+		if err := json.Unmarshal(settings, &in.Settings); err != nil {
+			return nil, fmt.Errorf("invalid compiler settings: %w", err)
+		}
+	}
+	in.Settings["outputSelection"] = outputSelection
+	inJSON, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+
+	// Run the compiler in an empty directory, so that imports of sources that
+	// were not submitted cannot be resolved from the local filesystem.
+	dir, err := os.MkdirTemp("", "solc")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	cmd := exec.CommandContext(ctx, binary, "--standard-json")
+	cmd.Dir = dir
+	cmd.Stdin = bytes.NewReader(inJSON)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		return nil, fmt.Errorf("running solc %s: %w: %s", version, err, stderr.String())
+	}
+
+	var out Output
+	if err = json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return nil, fmt.Errorf("parsing solc %s output: %w", version, err)
+	}
+	var errs []string
+	for _, e := range out.Errors {
+		switch {
+		case e.Severity != "error":
+		case e.FormattedMessage != "":
+			errs = append(errs, e.FormattedMessage)
+		default:
+			errs = append(errs, e.Message)
+		}
+	}
+	if len(errs) != 0 {
+		return nil, fmt.Errorf("compilation failed: %s", strings.Join(errs, "\n"))
+	}
+
+	return &out, nil
+}
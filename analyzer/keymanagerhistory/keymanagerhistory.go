@@ -0,0 +1,197 @@
+package keymanagerhistory
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+
+	"github.com/oasisprotocol/nexus/analyzer"
+	"github.com/oasisprotocol/nexus/analyzer/item"
+	"github.com/oasisprotocol/nexus/analyzer/queries"
+	"github.com/oasisprotocol/nexus/config"
+	beacon "github.com/oasisprotocol/nexus/coreapi/v22.2.11/beacon/api"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage"
+	"github.com/oasisprotocol/nexus/storage/oasis/nodeapi"
+)
+
+// The key manager history analyzer (1) gets the next epoch to process, (2) downloads the
+// statuses of the key managers and their CHURP instances at the start of that epoch, and
+// (3) saves the statuses that changed since the previous epoch in history.keymanager_statuses
+// and history.keymanager_churp_statuses.
+//
+// Key manager statuses are only updated by the consensus layer at epoch transitions, so
+// sampling them once per epoch captures every change, e.g. master secret rotations and
+// changes of the nodes that serve the key manager.
+//
+// WARNING: Like the validator staking history analyzer, this analyzer processes epochs
+// sequentially, since whether a status changed depends on the previously stored status.
+
+const (
+	keyManagerHistoryAnalyzerName = "keymanager_history"
+)
+
+type processor struct {
+	source     nodeapi.ConsensusApiLite
+	target     storage.TargetStorage
+	startEpoch uint64
+	logger     *log.Logger
+}
+
+var _ item.ItemProcessor[*Epoch] = (*processor)(nil)
+
+func NewAnalyzer(
+	initCtx context.Context,
+	cfg config.ItemBasedAnalyzerConfig,
+	startHeight uint64,
+	sourceClient nodeapi.ConsensusApiLite,
+	target storage.TargetStorage,
+	logger *log.Logger,
+) (analyzer.Analyzer, error) {
+	logger = logger.With("analyzer", keyManagerHistoryAnalyzerName)
+
+	// Find the epoch corresponding to startHeight.
+	if startHeight > math.MaxInt64 {
+		return nil, fmt.Errorf("startHeight %d is too large", startHeight)
+	}
+	epoch, err := sourceClient.GetEpoch(initCtx, int64(startHeight))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch epoch for startHeight %d: %w", startHeight, err)
+	}
+	p := &processor{
+		source:     sourceClient,
+		target:     target,
+		startEpoch: uint64(epoch),
+		logger:     logger,
+	}
+
+	return item.NewAnalyzer[*Epoch](
+		keyManagerHistoryAnalyzerName,
+		cfg,
+		p,
+		target,
+		logger,
+	)
+}
+
+type Epoch struct {
+	epoch       uint64
+	startHeight int64
+}
+
+// Note: limit is ignored here because epochs must be processed sequentially in chronological order.
+func (p *processor) GetItems(ctx context.Context, limit uint64) ([]*Epoch, error) {
+	var epoch Epoch
+	err := p.target.QueryRow(
+		ctx,
+		queries.KeyManagerHistoryUnprocessedEpochs,
+		p.startEpoch,
+		1, // overwrite limit to 1
+	).Scan(
+		&epoch.epoch,
+		&epoch.startHeight,
+	)
+	switch err {
+	case nil:
+		return []*Epoch{&epoch}, nil
+	case storage.ErrNoRows:
+		return []*Epoch{}, nil
+	default:
+		return nil, fmt.Errorf("querying epochs for key manager history: %w", err)
+	}
+}
+
+// epochOrNil returns nil for the invalid epoch, which oasis-core uses to denote a missing epoch.
+func epochOrNil(epoch beacon.EpochTime) *uint64 {
+	if epoch == beacon.EpochInvalid {
+		return nil
+	}
+	e := uint64(epoch)
+	return &e
+}
+
+func nodeIDStrings(ids []signature.PublicKey) []string {
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = id.String()
+	}
+	return strs
+}
+
+func (p *processor) ProcessItem(ctx context.Context, batch *storage.QueryBatch, epoch *Epoch) error {
+	statuses, err := p.source.GetKeyManagerStatuses(ctx, epoch.startHeight)
+	if err != nil {
+		return fmt.Errorf("downloading key manager statuses for height %d: %w", epoch.startHeight, err)
+	}
+	churpStatuses, err := p.source.GetChurpStatuses(ctx, epoch.startHeight)
+	if err != nil {
+		return fmt.Errorf("downloading churp statuses for height %d: %w", epoch.startHeight, err)
+	}
+
+	for _, s := range statuses {
+		// Generation, rotation epoch and RSK are not known before Eden.
+		var generation, rotationEpoch *uint64
+		var rsk *string
+		if s.RSK != nil {
+			generation = &s.Generation
+			rotationEpoch = epochOrNil(s.RotationEpoch)
+			rskStr := s.RSK.String()
+			rsk = &rskStr
+		}
+		batch.Queue(queries.KeyManagerStatusInsert,
+			s.ID.Hex(),
+			epoch.epoch,
+			epoch.startHeight,
+			s.IsInitialized,
+			s.IsSecure,
+			generation,
+			rotationEpoch,
+			hex.EncodeToString(s.Checksum),
+			nodeIDStrings(s.Nodes),
+			rsk,
+			[]byte(s.Policy),
+		)
+	}
+	for _, s := range churpStatuses {
+		var checksum, nextChecksum *string
+		if s.Checksum != nil {
+			c := s.Checksum.Hex()
+			checksum = &c
+		}
+		if s.NextChecksum != nil {
+			c := s.NextChecksum.Hex()
+			nextChecksum = &c
+		}
+		batch.Queue(queries.KeyManagerChurpStatusInsert,
+			s.RuntimeID.Hex(),
+			s.ID,
+			epoch.epoch,
+			epoch.startHeight,
+			s.SuiteID,
+			s.Threshold,
+			s.ExtraShares,
+			uint64(s.HandoffInterval),
+			epochOrNil(s.Handoff),
+			epochOrNil(s.NextHandoff),
+			checksum,
+			nextChecksum,
+			nodeIDStrings(s.Committee),
+			[]byte(s.Policy),
+		)
+	}
+	batch.Queue(queries.EpochKeyManagersProcessedUpdate, epoch.epoch)
+	p.logger.Info("processed epoch", "epoch", epoch.epoch, "num_keymanagers", len(statuses), "num_churps", len(churpStatuses))
+
+	return nil
+}
+
+func (p *processor) QueueLength(ctx context.Context) (int, error) {
+	var queueLength int
+	if err := p.target.QueryRow(ctx, queries.KeyManagerHistoryUnprocessedCount, p.startEpoch).Scan(&queueLength); err != nil {
+		return 0, fmt.Errorf("querying number of unprocessed epochs: %w", err)
+	}
+	return queueLength, nil
+}
@@ -0,0 +1,96 @@
+package keymanagerhistory
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	coreCommon "github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+
+	"github.com/oasisprotocol/nexus/analyzer/queries"
+	"github.com/oasisprotocol/nexus/common"
+	beacon "github.com/oasisprotocol/nexus/coreapi/v22.2.11/beacon/api"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage"
+	"github.com/oasisprotocol/nexus/storage/oasis/nodeapi"
+)
+
+// mockSource serves the key manager and CHURP statuses at the start of an epoch.
+type mockSource struct {
+	nodeapi.ConsensusApiLite
+	statuses      []nodeapi.KeyManagerStatus
+	churpStatuses []nodeapi.ChurpStatus
+}
+
+func (s *mockSource) GetKeyManagerStatuses(ctx context.Context, height int64) ([]nodeapi.KeyManagerStatus, error) {
+	return s.statuses, nil
+}
+
+func (s *mockSource) GetChurpStatuses(ctx context.Context, height int64) ([]nodeapi.ChurpStatus, error) {
+	return s.churpStatuses, nil
+}
+
+func TestEpochOrNil(t *testing.T) {
+	require.Nil(t, epochOrNil(beacon.EpochInvalid))
+	require.Equal(t, common.Ptr(uint64(0)), epochOrNil(0))
+	require.Equal(t, common.Ptr(uint64(42)), epochOrNil(42))
+}
+
+func TestProcessItem(t *testing.T) {
+	var preEdenID, edenID coreCommon.Namespace
+	preEdenID[31], edenID[31] = 1, 2
+	var node1, node2, rsk signature.PublicKey
+	node1[0], node2[0], rsk[0] = 1, 2, 3
+	checksum := hash.NewFromBytes([]byte("checksum"))
+	policy := json.RawMessage(`{"serial":1}`)
+
+	p := &processor{
+		source: &mockSource{
+			statuses: []nodeapi.KeyManagerStatus{
+				// Generation and rotation epoch are zero before Eden, and the RSK is unknown.
+				{ID: preEdenID, IsInitialized: true, IsSecure: true, Checksum: []byte{0xab}, Nodes: []signature.PublicKey{node1}, Policy: policy},
+				{ID: edenID, IsInitialized: true, IsSecure: true, Generation: 3, RotationEpoch: 40, Checksum: []byte{0xcd}, Nodes: []signature.PublicKey{node1, node2}, RSK: &rsk},
+			},
+			churpStatuses: []nodeapi.ChurpStatus{
+				{ID: 1, RuntimeID: edenID, SuiteID: 2, Threshold: 1, ExtraShares: 1, HandoffInterval: 5, Handoff: 40, NextHandoff: 45, Checksum: &checksum, Committee: []signature.PublicKey{node2}, Policy: policy},
+				// A new instance, whose first handoff is not scheduled yet.
+				{ID: 2, RuntimeID: edenID, Handoff: 0, NextHandoff: beacon.EpochInvalid},
+			},
+		},
+		logger: log.NewDefaultLogger("testing"),
+	}
+
+	batch := &storage.QueryBatch{}
+	require.NoError(t, p.ProcessItem(context.Background(), batch, &Epoch{epoch: 42, startHeight: 4200}))
+	items := batch.Queries()
+	require.Len(t, items, 5)
+
+	require.Equal(t, queries.KeyManagerStatusInsert, items[0].Cmd)
+	require.Equal(t, []interface{}{
+		preEdenID.Hex(), uint64(42), int64(4200), true, true,
+		(*uint64)(nil), (*uint64)(nil), "ab", []string{node1.String()}, (*string)(nil), []byte(policy),
+	}, items[0].Args)
+	require.Equal(t, queries.KeyManagerStatusInsert, items[1].Cmd)
+	require.Equal(t, []interface{}{
+		edenID.Hex(), uint64(42), int64(4200), true, true,
+		common.Ptr(uint64(3)), common.Ptr(uint64(40)), "cd", []string{node1.String(), node2.String()}, common.Ptr(rsk.String()), []byte(nil),
+	}, items[1].Args)
+
+	require.Equal(t, queries.KeyManagerChurpStatusInsert, items[2].Cmd)
+	require.Equal(t, []interface{}{
+		edenID.Hex(), uint8(1), uint64(42), int64(4200), uint8(2), uint8(1), uint8(1), uint64(5),
+		common.Ptr(uint64(40)), common.Ptr(uint64(45)), common.Ptr(checksum.Hex()), (*string)(nil), []string{node2.String()}, []byte(policy),
+	}, items[2].Args)
+	require.Equal(t, queries.KeyManagerChurpStatusInsert, items[3].Cmd)
+	require.Equal(t, []interface{}{
+		edenID.Hex(), uint8(2), uint64(42), int64(4200), uint8(0), uint8(0), uint8(0), uint64(0),
+		common.Ptr(uint64(0)), (*uint64)(nil), (*string)(nil), (*string)(nil), []string{}, []byte(nil),
+	}, items[3].Args)
+
+	require.Equal(t, queries.EpochKeyManagersProcessedUpdate, items[4].Cmd)
+	require.Equal(t, []interface{}{uint64(42)}, items[4].Args)
+}
@@ -152,6 +152,11 @@ var (
       SET processed_time = CURRENT_TIMESTAMP, is_fast_sync = $3
       WHERE height = $1 AND analyzer = $2`
 
+	// Announces a newly processed block to API servers. Postgres delivers
+	// the notification only once the enclosing transaction commits.
+	NotifyNewBlock = `
+    SELECT pg_notify($1::text, json_build_object('layer', $2::text, 'height', $3::bigint)::text)`
+
 	NodeHeight = `
     SELECT height
     FROM chain.latest_node_heights
@@ -269,8 +274,8 @@ var (
         schedule = excluded.schedule`
 
 	ConsensusEventInsert = `
-    INSERT INTO chain.events (type, body, tx_block, tx_hash, tx_index, related_accounts, roothash_runtime_id, roothash_runtime, roothash_runtime_round)
-      VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+    INSERT INTO chain.events (type, body, tx_block, tx_hash, tx_index, related_accounts, roothash_runtime_id, roothash_runtime, roothash_runtime_round, event_index)
+      VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
 
 	ConsensusEscrowEventInsert = `
     INSERT INTO history.escrow_events (tx_block, epoch, type, delegatee, delegator, shares, amount, debonding_amount)
@@ -380,6 +385,35 @@ var (
       general_balance = general_balance - $2
     WHERE address = $1`
 
+	// Records the balances of accounts ($2) before the staking events of block $1
+	// are applied. ConsensusAccountBalanceHistoryFinish then turns them into deltas.
+	ConsensusAccountBalanceHistoryStart = `
+    INSERT INTO history.account_balances (address, height, general_balance, escrow_balance_active, escrow_balance_debonding)
+    SELECT
+      addrs.address,
+      $1,
+      COALESCE(a.general_balance, 0),
+      COALESCE(a.escrow_balance_active, 0),
+      COALESCE(a.escrow_balance_debonding, 0)
+    FROM unnest($2::text[]) AS addrs(address)
+    LEFT JOIN chain.accounts AS a ON a.address = addrs.address
+    ON CONFLICT (address, height) DO UPDATE SET
+      general_balance = excluded.general_balance,
+      escrow_balance_active = excluded.escrow_balance_active,
+      escrow_balance_debonding = excluded.escrow_balance_debonding`
+
+	ConsensusAccountBalanceHistoryFinish = `
+    UPDATE history.account_balances AS h
+    SET
+      general_balance = COALESCE(a.general_balance, 0),
+      escrow_balance_active = a.escrow_balance_active,
+      escrow_balance_debonding = a.escrow_balance_debonding,
+      general_balance_delta = COALESCE(a.general_balance, 0) - h.general_balance,
+      escrow_balance_active_delta = a.escrow_balance_active - h.escrow_balance_active,
+      escrow_balance_debonding_delta = a.escrow_balance_debonding - h.escrow_balance_debonding
+    FROM chain.accounts AS a
+    WHERE h.address = ANY($2::text[]) AND h.height = $1 AND a.address = h.address`
+
 	ConsensusAddEscrowBalanceUpsert = `
     INSERT INTO chain.accounts (address, escrow_balance_active, escrow_total_shares_active)
       VALUES ($1, $2, $3)
@@ -504,6 +538,29 @@ var (
     SET invalid_votes = $2
       WHERE id = $1`
 
+	ConsensusProposalParametersUpdate = `
+    UPDATE chain.proposals
+    SET
+      stake_threshold = NULLIF($2::integer, 0),
+      quorum = NULLIF($3::integer, 0),
+      threshold = NULLIF($4::integer, 0)
+    WHERE id = $1`
+
+	// Proposals without recorded governance parameters (e.g. imported from a genesis document),
+	// with the first known height of the epoch at which the parameters apply to them: that of
+	// their finalization if they are closed, otherwise that of their submission.
+	ConsensusProposalsWithoutParameters = `
+    SELECT proposals.id, COALESCE(closed.start_height, created.start_height)
+    FROM chain.proposals
+    LEFT JOIN chain.epochs AS created ON created.id = proposals.created_at
+    LEFT JOIN chain.epochs AS closed ON closed.id = proposals.closes_at AND proposals.state <> 'active'
+    WHERE
+      proposals.stake_threshold IS NULL AND
+      proposals.quorum IS NULL AND
+      proposals.threshold IS NULL AND
+      COALESCE(closed.start_height, created.start_height) IS NOT NULL
+    ORDER BY proposals.id`
+
 	ConsensusVoteUpsert = `
     INSERT INTO chain.votes (proposal, voter, vote, height)
       VALUES ($1, $2, $3, $4)
@@ -511,6 +568,34 @@ var (
 	    vote = excluded.vote,
       height = excluded.height;`
 
+	ConsensusVaultUpsert = `
+    INSERT INTO chain.vaults (address, creator, id, state, nonce, admin_authority_addresses, admin_authority_threshold, suspend_authority_addresses, suspend_authority_threshold, created_at, last_update_height)
+      VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)
+    ON CONFLICT (address) DO UPDATE SET
+      state = CASE WHEN excluded.last_update_height >= chain.vaults.last_update_height THEN excluded.state ELSE chain.vaults.state END,
+      nonce = CASE WHEN excluded.last_update_height >= chain.vaults.last_update_height THEN excluded.nonce ELSE chain.vaults.nonce END,
+      admin_authority_addresses = CASE WHEN excluded.last_update_height >= chain.vaults.last_update_height THEN excluded.admin_authority_addresses ELSE chain.vaults.admin_authority_addresses END,
+      admin_authority_threshold = CASE WHEN excluded.last_update_height >= chain.vaults.last_update_height THEN excluded.admin_authority_threshold ELSE chain.vaults.admin_authority_threshold END,
+      suspend_authority_addresses = CASE WHEN excluded.last_update_height >= chain.vaults.last_update_height THEN excluded.suspend_authority_addresses ELSE chain.vaults.suspend_authority_addresses END,
+      suspend_authority_threshold = CASE WHEN excluded.last_update_height >= chain.vaults.last_update_height THEN excluded.suspend_authority_threshold ELSE chain.vaults.suspend_authority_threshold END,
+      created_at = LEAST(chain.vaults.created_at, excluded.created_at),
+      last_update_height = GREATEST(chain.vaults.last_update_height, excluded.last_update_height)`
+
+	// Pending actions are only replaced if the vault row reflects the state at the given height,
+	// i.e. no newer state has been stored already.
+	ConsensusVaultPendingActionsDelete = `
+    DELETE FROM chain.vault_pending_actions
+    WHERE vault = $1 AND EXISTS (
+      SELECT 1 FROM chain.vaults WHERE address = $1 AND last_update_height = $2
+    )`
+
+	ConsensusVaultPendingActionInsert = `
+    INSERT INTO chain.vault_pending_actions (vault, nonce, action, authorized_by)
+      SELECT $1, $2, $3, $4
+      WHERE EXISTS (
+        SELECT 1 FROM chain.vaults WHERE address = $1 AND last_update_height = $5
+      )`
+
 	ValidatorStakingHistoryUnprocessedEpochs = `
     SELECT epochs.id, epochs.start_height, prev_epoch.validators
     FROM chain.epochs as epochs
@@ -535,6 +620,42 @@ var (
       id = $1 AND
       epoch = $2`
 
+	// Attributes the staking rewards of validator $2 (with address $1) for the epoch before $3
+	// to its delegators $4 with $5 shares each, based on the change of the validator's share price.
+	// Requires the history.validators rows of both epochs.
+	DelegatorRewardsInsert = `
+    INSERT INTO history.delegator_rewards (delegator, delegatee, epoch, shares, reward)
+    SELECT delegations.delegator, $1::text, rewards.epoch, rewards.shares, rewards.reward
+    FROM (
+      SELECT
+          prev.epoch,
+          curr.escrow_balance_active AS curr_balance,
+          curr.escrow_total_shares_active AS curr_shares,
+          prev.escrow_balance_active AS prev_balance,
+          prev.escrow_total_shares_active AS prev_shares
+        FROM history.validators AS curr
+        JOIN history.validators AS prev
+          ON prev.id = curr.id AND prev.epoch = curr.epoch - 1
+        WHERE
+          curr.id = $2::text AND
+          curr.epoch = $3::bigint AND
+          curr.escrow_total_shares_active > 0 AND
+          prev.escrow_total_shares_active > 0
+    ) AS validator
+    CROSS JOIN unnest($4::text[], $5::text[]) AS delegations(delegator, shares)
+    CROSS JOIN LATERAL (
+      SELECT
+          validator.epoch,
+          delegations.shares::numeric AS shares,
+          TRUNC(delegations.shares::numeric * validator.curr_balance / validator.curr_shares) -
+            TRUNC(delegations.shares::numeric * validator.prev_balance / validator.prev_shares) AS reward
+    ) AS rewards
+    WHERE rewards.reward <> 0
+    ON CONFLICT (delegator, epoch, delegatee) DO UPDATE
+    SET
+      shares = excluded.shares,
+      reward = excluded.reward`
+
 	EpochValidatorsUpdate = `
     UPDATE chain.epochs
     SET validators = $2
@@ -549,6 +670,110 @@ var (
       history.epoch IS NULL AND
       epochs.id >= $1`
 
+	KeyManagerHistoryUnprocessedEpochs = `
+    SELECT id, start_height
+    FROM chain.epochs
+    WHERE
+      NOT keymanagers_processed AND
+      id >= $1
+    ORDER BY id
+    LIMIT $2`
+
+	KeyManagerHistoryUnprocessedCount = `
+    SELECT COUNT(*)
+    FROM chain.epochs
+    WHERE
+      NOT keymanagers_processed AND
+      id >= $1`
+
+	EpochKeyManagersProcessedUpdate = `
+    UPDATE chain.epochs
+    SET keymanagers_processed = TRUE
+      WHERE id = $1`
+
+	// Inserts the status of key manager $1 at epoch $2, unless it is the same as
+	// the latest status before the epoch.
+	KeyManagerStatusInsert = `
+    INSERT INTO history.keymanager_statuses (runtime_id, epoch, height, is_initialized, is_secure, generation, rotation_epoch, checksum, nodes, rsk, policy)
+    SELECT $1, $2, $3, $4::boolean, $5::boolean, $6::bigint, $7::bigint, $8::text, $9::text[], $10::text, $11::jsonb
+    WHERE NOT EXISTS (
+      SELECT 1
+      FROM (
+        SELECT *
+        FROM history.keymanager_statuses
+        WHERE runtime_id = $1 AND epoch < $2
+        ORDER BY epoch DESC
+        LIMIT 1
+      ) AS prev
+      WHERE
+        (prev.is_initialized, prev.is_secure, prev.generation::bigint, prev.rotation_epoch::bigint, prev.checksum, prev.nodes::text[], prev.rsk::text, prev.policy) IS NOT DISTINCT FROM
+        ($4::boolean, $5::boolean, $6::bigint, $7::bigint, $8::text, $9::text[], $10::text, $11::jsonb)
+    )
+    ON CONFLICT (runtime_id, epoch) DO NOTHING`
+
+	// Inserts the status of CHURP instance $2 of key manager $1 at epoch $3, unless
+	// it is the same as the latest status before the epoch.
+	KeyManagerChurpStatusInsert = `
+    INSERT INTO history.keymanager_churp_statuses (runtime_id, churp_id, epoch, height, suite_id, threshold, extra_shares, handoff_interval, handoff, next_handoff, checksum, next_checksum, committee, policy)
+    SELECT $1, $2, $3, $4, $5::integer, $6::integer, $7::integer, $8::bigint, $9::bigint, $10::bigint, $11::text, $12::text, $13::text[], $14::jsonb
+    WHERE NOT EXISTS (
+      SELECT 1
+      FROM (
+        SELECT *
+        FROM history.keymanager_churp_statuses
+        WHERE runtime_id = $1 AND churp_id = $2 AND epoch < $3
+        ORDER BY epoch DESC
+        LIMIT 1
+      ) AS prev
+      WHERE
+        (prev.suite_id::integer, prev.threshold::integer, prev.extra_shares::integer, prev.handoff_interval::bigint, prev.handoff::bigint, prev.next_handoff::bigint, prev.checksum::text, prev.next_checksum::text, prev.committee::text[], prev.policy) IS NOT DISTINCT FROM
+        ($5::integer, $6::integer, $7::integer, $8::bigint, $9::bigint, $10::bigint, $11::text, $12::text, $13::text[], $14::jsonb)
+    )
+    ON CONFLICT (runtime_id, churp_id, epoch) DO NOTHING`
+
+	ValidatorUptimeUnprocessedEpochs = `
+    SELECT id, start_height
+    FROM chain.epochs
+    WHERE
+      active_validators IS NULL AND
+      id >= $1
+    ORDER BY id
+    LIMIT $2`
+
+	ValidatorUptimeUnprocessedCount = `
+    SELECT COUNT(*)
+    FROM chain.epochs
+    WHERE
+      active_validators IS NULL AND
+      id >= $1`
+
+	EpochActiveValidatorsUpdate = `
+    UPDATE chain.epochs
+    SET active_validators = $2
+      WHERE id = $1`
+
+	// Computes the uptime of the active validators of epoch $1 from the
+	// signers and proposers of its blocks.
+	ValidatorUptimesUpsert = `
+    INSERT INTO history.validator_uptimes (id, epoch, signed_blocks, missed_blocks, proposed_blocks)
+    SELECT
+        validators.id,
+        epochs.id,
+        COUNT(*) FILTER (WHERE validators.id = ANY(blocks.signer_entity_ids)),
+        COUNT(*) FILTER (WHERE blocks.signer_entity_ids IS NOT NULL AND NOT validators.id = ANY(blocks.signer_entity_ids)),
+        COUNT(*) FILTER (WHERE blocks.proposer_entity_id = validators.id)
+      FROM chain.epochs AS epochs
+      CROSS JOIN LATERAL unnest(epochs.active_validators) AS validators(id)
+      JOIN chain.blocks AS blocks
+        ON blocks.height BETWEEN epochs.start_height AND epochs.end_height
+      WHERE epochs.id = $1
+      GROUP BY validators.id, epochs.id
+    ON CONFLICT (id, epoch) DO UPDATE
+    SET
+      signed_blocks = excluded.signed_blocks,
+      missed_blocks = excluded.missed_blocks,
+      proposed_blocks = excluded.proposed_blocks`
+
 	RuntimeBlockInsert = `
     INSERT INTO chain.runtime_blocks (runtime, round, version, timestamp, block_hash, prev_block_hash, io_root, state_root, messages_hash, in_messages_hash, num_transactions, gas_used, size)
       VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
@@ -619,8 +844,8 @@ var (
       SET total_received = EXCLUDED.total_received`
 
 	RuntimeTransactionInsert = `
-    INSERT INTO chain.runtime_transactions (runtime, round, tx_index, tx_hash, tx_eth_hash, fee, fee_symbol, fee_proxy_module, fee_proxy_id, gas_limit, gas_used, size, timestamp, oasis_encrypted_format, oasis_encrypted_public_key, oasis_encrypted_data_nonce, oasis_encrypted_data_data, oasis_encrypted_result_nonce, oasis_encrypted_result_data, method, body, "to", amount, amount_symbol, evm_encrypted_format, evm_encrypted_public_key, evm_encrypted_data_nonce, evm_encrypted_data_data, evm_encrypted_result_nonce, evm_encrypted_result_data, success, error_module, error_code, error_message_raw, error_message)
-      VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35)`
+    INSERT INTO chain.runtime_transactions (runtime, round, tx_index, tx_hash, tx_eth_hash, fee, fee_symbol, fee_proxy_module, fee_proxy_id, gas_limit, gas_used, size, timestamp, oasis_encrypted_format, oasis_encrypted_public_key, oasis_encrypted_data_nonce, oasis_encrypted_data_data, oasis_encrypted_result_nonce, oasis_encrypted_result_data, method, body, "to", amount, amount_symbol, evm_encrypted_format, evm_encrypted_public_key, evm_encrypted_data_nonce, evm_encrypted_data_data, evm_encrypted_result_nonce, evm_encrypted_result_data, success, error_module, error_code, error_message_raw, error_message, oasis_encrypted_epoch, evm_encrypted_epoch)
+      VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37)`
 
 	// We use COALESCE here to avoid overwriting existing data with null values.
 	RuntimeTransactionEvmParsedFieldsUpdate = `
@@ -630,14 +855,31 @@ var (
       evm_fn_params = COALESCE($4, evm_fn_params),
       error_message = COALESCE($5, error_message),
       error_params = COALESCE($6, error_params),
+      evm_fn_low_confidence = CASE WHEN $3 IS NULL THEN evm_fn_low_confidence ELSE $7 END,
       abi_parsed_at = CURRENT_TIMESTAMP
     WHERE
       runtime = $1 AND
       tx_hash = $2`
 
 	RuntimeEventInsert = `
-    INSERT INTO chain.runtime_events (runtime, round, tx_index, tx_hash, tx_eth_hash, timestamp, type, body, related_accounts, evm_log_name, evm_log_params, evm_log_signature)
-      VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+    INSERT INTO chain.runtime_events (runtime, round, tx_index, tx_hash, tx_eth_hash, timestamp, type, body, related_accounts, evm_log_name, evm_log_params, evm_log_signature, event_index)
+      VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+
+	RuntimeEVMTokenTransferInsert = `
+    INSERT INTO chain.evm_token_transfers (runtime, round, event_index, transfer_index, tx_index, tx_hash, tx_eth_hash, timestamp, token_address, token_type, from_address, to_address, token_id, amount)
+      VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+
+	// Records a balance change, along with the dead-reckoned balance after it
+	// if $7 is true. It must be queued after the balance updates of the round.
+	RuntimeBalanceChangeInsert = `
+    INSERT INTO chain.runtime_balance_changes (runtime, round, account_address, token, delta, timestamp, balance)
+      VALUES ($1, $2, $3, $4, $5, $6,
+        CASE WHEN $7::boolean THEN COALESCE(
+          (SELECT balance FROM chain.runtime_sdk_balances
+            WHERE runtime = $1 AND account_address = $3 AND symbol = $4),
+          (SELECT balance FROM chain.evm_token_balances
+            WHERE runtime = $1 AND account_address = $3 AND token_address = $4)
+        ) END)`
 
 	// We use COALESCE here to avoid overwriting existing data with null values.
 	RuntimeEventEvmParsedFieldsUpdate = `
@@ -646,6 +888,7 @@ var (
       evm_log_name = COALESCE($5, evm_log_name),
       evm_log_params = COALESCE($6, evm_log_params),
       evm_log_signature = COALESCE($7, evm_log_signature),
+      evm_log_low_confidence = CASE WHEN $5 IS NULL THEN evm_log_low_confidence ELSE $8 END,
       abi_parsed_at = CURRENT_TIMESTAMP
     WHERE
       runtime = $1 AND
@@ -750,12 +993,133 @@ var (
       code_analysis.runtime = $1::runtime AND
       code_analysis.is_contract IS NULL`
 
+	RuntimeEVMProxyImplementationUpsert = `
+    INSERT INTO chain.evm_proxy_implementations (runtime, proxy_address, round, implementation_address, source)
+    VALUES ($1, $2, $3, $4, 'event')
+    ON CONFLICT (runtime, proxy_address, round) DO UPDATE
+    SET
+      implementation_address = EXCLUDED.implementation_address,
+      source = EXCLUDED.source,
+      detected_at = CURRENT_TIMESTAMP`
+
+	// Events are more precise than storage reads; keep an event from the same round.
+	RuntimeEVMProxyImplementationFromStorageInsert = `
+    INSERT INTO chain.evm_proxy_implementations (runtime, proxy_address, round, implementation_address, source)
+    VALUES ($1, $2, $3, $4, 'storage')
+    ON CONFLICT (runtime, proxy_address, round) DO NOTHING`
+
+	RuntimeEVMTraceRoundsStale = `
+    SELECT b.round
+    FROM chain.runtime_blocks AS b
+    LEFT JOIN analysis.evm_traced_rounds AS traced ON
+      traced.runtime = b.runtime AND
+      traced.round = b.round
+    WHERE
+      b.runtime = $1 AND
+      b.num_transactions > 0 AND
+      traced.round IS NULL
+    ORDER BY b.round
+    LIMIT $2`
+
+	RuntimeEVMTraceRoundsStaleCount = `
+    SELECT COUNT(*) AS cnt
+    FROM chain.runtime_blocks AS b
+    LEFT JOIN analysis.evm_traced_rounds AS traced ON
+      traced.runtime = b.runtime AND
+      traced.round = b.round
+    WHERE
+      b.runtime = $1 AND
+      b.num_transactions > 0 AND
+      traced.round IS NULL`
+
+	RuntimeEVMTracedRoundInsert = `
+    INSERT INTO analysis.evm_traced_rounds (runtime, round)
+      VALUES ($1, $2)
+    ON CONFLICT (runtime, round) DO NOTHING`
+
+	RuntimeEVMTransactionsByRound = `
+    SELECT tx_index, tx_hash, tx_eth_hash
+    FROM chain.runtime_transactions
+    WHERE runtime = $1 AND round = $2 AND tx_eth_hash IS NOT NULL`
+
+	RuntimeInternalCallInsert = `
+    INSERT INTO chain.runtime_internal_calls
+      (runtime, round, tx_index, call_index, depth, type, from_address, to_address, value, gas, gas_used, input, output, error, success)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+    ON CONFLICT (runtime, round, tx_index, call_index) DO NOTHING`
+
+	// Relates a transaction to an account that took part in one of its
+	// internal calls, unless it is already related. Newly related
+	// transactions are counted in the account's num_txs, with an undo log
+	// entry so that a reorg can revert them.
+	RuntimeRelatedTransactionInsertIfMissing = `
+    WITH inserted AS (
+      INSERT INTO chain.runtime_related_transactions (runtime, account_address, tx_round, tx_index)
+        SELECT $1, $2, $3, $4
+        WHERE NOT EXISTS (
+          SELECT 1 FROM chain.runtime_related_transactions
+          WHERE runtime = $1 AND account_address = $2 AND tx_round = $3 AND tx_index = $4
+        )
+      RETURNING runtime, account_address, tx_round
+    ),
+    undo AS (
+      INSERT INTO analysis.runtime_undo_log (runtime, round, table_name, account_address, undo)
+        SELECT runtime, tx_round, 'runtime_accounts', account_address, jsonb_build_object('num_txs', 1)
+        FROM inserted
+    )
+    INSERT INTO chain.runtime_accounts AS accounts (runtime, address, num_txs)
+      SELECT runtime, account_address, 1 FROM inserted
+    ON CONFLICT (runtime, address) DO UPDATE
+      SET num_txs = accounts.num_txs + 1`
+
+	// Records the creation of a contract by another contract. Unlike
+	// RuntimeEVMContractCreationUpsert, it does not overwrite known values.
+	RuntimeEVMContractInternalCreationUpsert = `
+    INSERT INTO chain.evm_contracts
+      (runtime, contract_address, creation_tx, creation_bytecode)
+    VALUES ($1, $2, $3, $4)
+    ON CONFLICT (runtime, contract_address) DO UPDATE
+    SET
+      creation_tx = COALESCE(chain.evm_contracts.creation_tx, $3),
+      creation_bytecode = COALESCE(chain.evm_contracts.creation_bytecode, $4)`
+
 	RuntimeEVMTokenBalanceUpdate = `
     INSERT INTO chain.evm_token_balances (runtime, token_address, account_address, balance)
       VALUES ($1, $2, $3, $4)
     ON CONFLICT (runtime, token_address, account_address) DO
       UPDATE SET balance = chain.evm_token_balances.balance + $4`
 
+	RuntimeEVMNFTBalanceUpdate = `
+    INSERT INTO chain.evm_nft_balances (runtime, token_address, nft_id, account_address, balance)
+      VALUES ($1, $2, $3, $4, $5)
+    ON CONFLICT (runtime, token_address, nft_id, account_address) DO
+      UPDATE SET balance = chain.evm_nft_balances.balance + $5`
+
+	// Registers a (token ID, account) pair without dead-reckoning its balance.
+	// The balance is downloaded by the evm_token_balances analyzer later.
+	RuntimeEVMNFTBalanceInsert = `
+    INSERT INTO chain.evm_nft_balances (runtime, token_address, nft_id, account_address, balance)
+      VALUES ($1, $2, $3, $4, 0)
+    ON CONFLICT (runtime, token_address, nft_id, account_address) DO NOTHING`
+
+	// Returns the reckoned per-ID balances of an account, together with the
+	// latest processed round, which these balances are consistent with.
+	RuntimeEVMNFTBalances = `
+    SELECT
+      nft_id,
+      balance,
+      (
+        SELECT MAX(height)
+        FROM analysis.processed_blocks
+        WHERE analyzer = ($1::runtime)::text AND processed_time IS NOT NULL
+      ) AS download_round
+    FROM chain.evm_nft_balances
+    WHERE
+      runtime = $1 AND
+      token_address = $2 AND
+      account_address = $3
+    ORDER BY nft_id`
+
 	RuntimeEVMTokenBalanceAnalysisMutateRoundUpsert = `
     INSERT INTO analysis.evm_token_balances
       (runtime, token_address, account_address, last_mutate_round)
@@ -898,6 +1262,14 @@ var (
       ($1, $2, $3, 0, $4)
     ON CONFLICT (runtime, token_address, nft_id) DO NOTHING`
 
+	RuntimeEVMNFTUpdateTransferCount = `
+    UPDATE chain.evm_nfts SET
+      num_transfers = num_transfers + $4
+    WHERE
+      runtime = $1 AND
+      token_address = $2 AND
+      nft_id = $3`
+
 	RuntimeEVMNFTUpdateTransfer = `
     UPDATE chain.evm_nfts SET
       num_transfers = num_transfers + $4,
@@ -1043,22 +1415,420 @@ var (
       account_address = $3`
 
 	RuntimeEVMSwapPairUpsertCreated = `
-    INSERT INTO chain.evm_swap_pair_creations (runtime, factory_address, token0_address, token1_address, pair_address, create_round)
-    VALUES ($1, $2, $3, $4, $5, $6)
-    ON CONFLICT (runtime, factory_address, token0_address, token1_address) DO UPDATE
+    INSERT INTO chain.evm_swap_pair_creations (runtime, factory_address, token0_address, token1_address, fee, pair_address, create_round)
+    VALUES ($1, $2, $3, $4, $5, $6, $7)
+    ON CONFLICT (runtime, factory_address, token0_address, token1_address, fee) DO UPDATE
     SET
       pair_address = excluded.pair_address,
       create_round = excluded.create_round`
 
 	RuntimeEVMSwapPairUpsertSync = `
-    INSERT INTO chain.evm_swap_pairs (runtime, pair_address, reserve0, reserve1, last_sync_round)
-    VALUES ($1, $2, $3, $4, $5)
+    INSERT INTO chain.evm_swap_pairs (runtime, pair_address, reserve0, reserve1, sqrt_price_x96, last_sync_round)
+    VALUES ($1, $2, $3, $4, $5, $6)
     ON CONFLICT (runtime, pair_address) DO UPDATE
     SET
       reserve0 = excluded.reserve0,
       reserve1 = excluded.reserve1,
+      sqrt_price_x96 = excluded.sqrt_price_x96,
       last_sync_round = excluded.last_sync_round`
 
+	RuntimeEVMSwapPairSyncInsert = `
+    INSERT INTO chain.evm_swap_pair_syncs (runtime, round, pair_address, reserve0, reserve1, sqrt_price_x96, volume0, volume1, timestamp)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	// Returns the hash of an indexed runtime block.
+	RuntimeBlockHash = `
+    SELECT block_hash
+    FROM chain.runtime_blocks
+    WHERE runtime = $1 AND round = $2`
+
+	// Returns whether any block after height $2 has been processed in fast-sync mode.
+	IsAnyBlockAfterProcessedByFastSync = `
+    SELECT EXISTS(
+      SELECT 1 FROM analysis.processed_blocks
+      WHERE
+        analyzer = $1 AND
+        height > $2 AND
+        processed_time IS NOT NULL AND
+        is_fast_sync
+    )`
+
+	// Records a delta that was added to a dead-reckoned column ($7) of a row in table $3.
+	RuntimeUndoLogDeltaInsert = `
+    INSERT INTO analysis.runtime_undo_log (runtime, round, table_name, token, account_address, nft_id, undo)
+      VALUES ($1, $2, $3, $4, $5, $6, jsonb_build_object($7::text, $8::numeric))`
+
+	// Records the current owner of an NFT, before it is overwritten, along with
+	// the number of transfers that are about to be added.
+	RuntimeUndoLogEVMNFTTransferInsert = `
+    INSERT INTO analysis.runtime_undo_log (runtime, round, table_name, token, nft_id, undo)
+      SELECT $1, $2, 'evm_nfts', $3, $4, jsonb_build_object('owner', owner, 'num_transfers', $5::numeric)
+      FROM chain.evm_nfts
+      WHERE runtime = $1 AND token_address = $3 AND nft_id = $4`
+
+	// Records the current reserves of a swap pair, before they are overwritten.
+	RuntimeUndoLogEVMSwapPairInsert = `
+    INSERT INTO analysis.runtime_undo_log (runtime, round, table_name, token, undo)
+      SELECT $1, $2, 'evm_swap_pairs', $3, COALESCE(
+        (
+          SELECT jsonb_build_object('reserve0', reserve0, 'reserve1', reserve1, 'sqrt_price_x96', sqrt_price_x96, 'last_sync_round', last_sync_round)
+          FROM chain.evm_swap_pairs
+          WHERE runtime = $1 AND pair_address = $3
+        ),
+        'null'::jsonb
+      )`
+
+	// The queries below record that a row is about to be created, if it does not exist yet.
+	// The entries have a 'null' undo value; unwinding deletes the rows.
+
+	RuntimeUndoLogEVMTokenCreationInsert = `
+    INSERT INTO analysis.runtime_undo_log (runtime, round, table_name, token, undo)
+      SELECT $1, $2, 'evm_tokens', $3, 'null'::jsonb
+      WHERE NOT EXISTS (SELECT 1 FROM chain.evm_tokens WHERE runtime = $1 AND token_address = $3)`
+
+	RuntimeUndoLogEVMNFTCreationInsert = `
+    INSERT INTO analysis.runtime_undo_log (runtime, round, table_name, token, nft_id, undo)
+      SELECT $1, $2, 'evm_nfts', $3, $4, 'null'::jsonb
+      WHERE NOT EXISTS (SELECT 1 FROM chain.evm_nfts WHERE runtime = $1 AND token_address = $3 AND nft_id = $4)`
+
+	RuntimeUndoLogAddressPreimageCreationInsert = `
+    INSERT INTO analysis.runtime_undo_log (runtime, round, table_name, account_address, undo)
+      SELECT $1, $2, 'address_preimages', $3, 'null'::jsonb
+      WHERE NOT EXISTS (SELECT 1 FROM chain.address_preimages WHERE address = $3)`
+
+	// The table is in the analysis schema, unlike the others in the undo log.
+	RuntimeUndoLogEVMTokenBalanceAnalysisCreationInsert = `
+    INSERT INTO analysis.runtime_undo_log (runtime, round, table_name, token, account_address, undo)
+      SELECT $1, $2, 'analysis.evm_token_balances', $3, $4, 'null'::jsonb
+      WHERE NOT EXISTS (
+        SELECT 1 FROM analysis.evm_token_balances
+        WHERE runtime = $1 AND token_address = $3 AND account_address = $4
+      )`
+
+	// Drops undo log entries that are too old to be unwound.
+	RuntimeUndoLogPrune = `
+    DELETE FROM analysis.runtime_undo_log
+    WHERE runtime = $1 AND round < $2`
+
+	// The queries below revert the effects of all rounds after round $2, using the undo log.
+
+	RuntimeUndoNativeBalances = `
+    UPDATE chain.runtime_sdk_balances AS balances
+    SET balance = balances.balance - undo.balance
+    FROM (
+      SELECT account_address, token AS symbol, SUM((undo->>'balance')::NUMERIC) AS balance
+      FROM analysis.runtime_undo_log
+      WHERE runtime = $1 AND round > $2 AND table_name = 'runtime_sdk_balances'
+      GROUP BY account_address, token
+    ) AS undo
+    WHERE
+      balances.runtime = $1 AND
+      balances.account_address = undo.account_address AND
+      balances.symbol = undo.symbol`
+
+	RuntimeUndoAccountStats = `
+    UPDATE chain.runtime_accounts AS accounts
+    SET
+      num_txs = accounts.num_txs - undo.num_txs,
+      gas_for_calling = accounts.gas_for_calling - undo.gas_for_calling,
+      total_sent = accounts.total_sent - undo.total_sent,
+      total_received = accounts.total_received - undo.total_received
+    FROM (
+      SELECT
+        account_address,
+        COALESCE(SUM((undo->>'num_txs')::NUMERIC), 0) AS num_txs,
+        COALESCE(SUM((undo->>'gas_for_calling')::NUMERIC), 0) AS gas_for_calling,
+        COALESCE(SUM((undo->>'total_sent')::NUMERIC), 0) AS total_sent,
+        COALESCE(SUM((undo->>'total_received')::NUMERIC), 0) AS total_received
+      FROM analysis.runtime_undo_log
+      WHERE runtime = $1 AND round > $2 AND table_name = 'runtime_accounts'
+      GROUP BY account_address
+    ) AS undo
+    WHERE
+      accounts.runtime = $1 AND
+      accounts.address = undo.account_address`
+
+	RuntimeUndoEVMTokenBalances = `
+    UPDATE chain.evm_token_balances AS balances
+    SET balance = balances.balance - undo.balance
+    FROM (
+      SELECT token AS token_address, account_address, SUM((undo->>'balance')::NUMERIC) AS balance
+      FROM analysis.runtime_undo_log
+      WHERE runtime = $1 AND round > $2 AND table_name = 'evm_token_balances'
+      GROUP BY token, account_address
+    ) AS undo
+    WHERE
+      balances.runtime = $1 AND
+      balances.token_address = undo.token_address AND
+      balances.account_address = undo.account_address`
+
+	RuntimeUndoEVMNFTBalances = `
+    UPDATE chain.evm_nft_balances AS balances
+    SET balance = balances.balance - undo.balance
+    FROM (
+      SELECT token AS token_address, nft_id, account_address, SUM((undo->>'balance')::NUMERIC) AS balance
+      FROM analysis.runtime_undo_log
+      WHERE runtime = $1 AND round > $2 AND table_name = 'evm_nft_balances'
+      GROUP BY token, nft_id, account_address
+    ) AS undo
+    WHERE
+      balances.runtime = $1 AND
+      balances.token_address = undo.token_address AND
+      balances.nft_id = undo.nft_id AND
+      balances.account_address = undo.account_address`
+
+	RuntimeUndoEVMTokens = `
+    UPDATE chain.evm_tokens AS tokens
+    SET
+      total_supply = tokens.total_supply - undo.total_supply,
+      num_transfers = tokens.num_transfers - undo.num_transfers
+    FROM (
+      SELECT
+        token AS token_address,
+        COALESCE(SUM((undo->>'total_supply')::NUMERIC), 0) AS total_supply,
+        COALESCE(SUM((undo->>'num_transfers')::NUMERIC), 0) AS num_transfers
+      FROM analysis.runtime_undo_log
+      WHERE runtime = $1 AND round > $2 AND table_name = 'evm_tokens'
+      GROUP BY token
+    ) AS undo
+    WHERE
+      tokens.runtime = $1 AND
+      tokens.token_address = undo.token_address`
+
+	RuntimeUndoEVMNFTTransfers = `
+    UPDATE chain.evm_nfts AS nfts
+    SET num_transfers = nfts.num_transfers - undo.num_transfers
+    FROM (
+      SELECT token AS token_address, nft_id, SUM((undo->>'num_transfers')::NUMERIC) AS num_transfers
+      FROM analysis.runtime_undo_log
+      WHERE runtime = $1 AND round > $2 AND table_name = 'evm_nfts'
+      GROUP BY token, nft_id
+    ) AS undo
+    WHERE
+      nfts.runtime = $1 AND
+      nfts.token_address = undo.token_address AND
+      nfts.nft_id = undo.nft_id`
+
+	// Restores the owner from the earliest snapshot, i.e. the one taken just after the fork.
+	RuntimeUndoEVMNFTOwners = `
+    UPDATE chain.evm_nfts AS nfts
+    SET owner = undo.owner
+    FROM (
+      SELECT DISTINCT ON (token, nft_id) token AS token_address, nft_id, undo->>'owner' AS owner
+      FROM analysis.runtime_undo_log
+      WHERE runtime = $1 AND round > $2 AND table_name = 'evm_nfts' AND undo ? 'owner'
+      ORDER BY token, nft_id, id
+    ) AS undo
+    WHERE
+      nfts.runtime = $1 AND
+      nfts.token_address = undo.token_address AND
+      nfts.nft_id = undo.nft_id`
+
+	RuntimeUndoEVMSwapPairs = `
+    WITH undo AS (
+      SELECT DISTINCT ON (token) token AS pair_address, undo
+      FROM analysis.runtime_undo_log
+      WHERE runtime = $1 AND round > $2 AND table_name = 'evm_swap_pairs'
+      ORDER BY token, id
+    ),
+    deleted AS (
+      DELETE FROM chain.evm_swap_pairs AS pairs
+      USING undo
+      WHERE
+        pairs.runtime = $1 AND
+        pairs.pair_address = undo.pair_address AND
+        undo.undo = 'null'::jsonb
+    )
+    UPDATE chain.evm_swap_pairs AS pairs
+    SET
+      reserve0 = (undo.undo->>'reserve0')::NUMERIC,
+      reserve1 = (undo.undo->>'reserve1')::NUMERIC,
+      sqrt_price_x96 = (undo.undo->>'sqrt_price_x96')::NUMERIC,
+      last_sync_round = (undo.undo->>'last_sync_round')::BIGINT
+    FROM undo
+    WHERE
+      pairs.runtime = $1 AND
+      pairs.pair_address = undo.pair_address AND
+      undo.undo != 'null'::jsonb`
+
+	// Deletes the rows that were created after the fork, as recorded in the undo log.
+	// Must run after the other undo queries for the same tables.
+
+	RuntimeUndoEVMTokenCreations = `
+    DELETE FROM chain.evm_tokens AS tokens
+    USING analysis.runtime_undo_log AS undo
+    WHERE
+      undo.runtime = $1 AND undo.round > $2 AND undo.table_name = 'evm_tokens' AND undo.undo = 'null'::jsonb AND
+      tokens.runtime = $1 AND
+      tokens.token_address = undo.token`
+
+	RuntimeUndoEVMNFTCreations = `
+    DELETE FROM chain.evm_nfts AS nfts
+    USING analysis.runtime_undo_log AS undo
+    WHERE
+      undo.runtime = $1 AND undo.round > $2 AND undo.table_name = 'evm_nfts' AND undo.undo = 'null'::jsonb AND
+      nfts.runtime = $1 AND
+      nfts.token_address = undo.token AND
+      nfts.nft_id = undo.nft_id`
+
+	RuntimeUndoAddressPreimageCreations = `
+    DELETE FROM chain.address_preimages AS preimages
+    USING analysis.runtime_undo_log AS undo
+    WHERE
+      undo.runtime = $1 AND undo.round > $2 AND undo.table_name = 'address_preimages' AND undo.undo = 'null'::jsonb AND
+      preimages.address = undo.account_address`
+
+	RuntimeUndoEVMTokenBalanceAnalysisCreations = `
+    DELETE FROM analysis.evm_token_balances AS balances
+    USING analysis.runtime_undo_log AS undo
+    WHERE
+      undo.runtime = $1 AND undo.round > $2 AND undo.table_name = 'analysis.evm_token_balances' AND undo.undo = 'null'::jsonb AND
+      balances.runtime = $1 AND
+      balances.token_address = undo.token AND
+      balances.account_address = undo.account_address`
+
+	// Contracts record their creation transaction, so they are unwound by it
+	// rather than via the undo log; this also covers contracts created by the
+	// EVM call traces analyzer. Deletes the contracts that were created after
+	// the fork, unless their runtime bytecode was already downloaded, in which
+	// case only their creation is forgotten. Must run before the transactions
+	// are deleted.
+	RuntimeUndoEVMContractCreations = `
+    WITH created AS (
+      SELECT contracts.contract_address, contracts.runtime_bytecode IS NULL AS no_bytecode
+      FROM chain.evm_contracts AS contracts
+      JOIN chain.runtime_transactions AS txs ON
+        txs.runtime = contracts.runtime AND
+        txs.tx_hash = contracts.creation_tx
+      WHERE contracts.runtime = $1 AND txs.round > $2
+    ),
+    deleted AS (
+      DELETE FROM chain.evm_contracts AS contracts
+      USING created
+      WHERE
+        contracts.runtime = $1 AND
+        contracts.contract_address = created.contract_address AND
+        created.no_bytecode
+    )
+    UPDATE chain.evm_contracts AS contracts
+    SET creation_tx = NULL, creation_bytecode = NULL
+    FROM created
+    WHERE
+      contracts.runtime = $1 AND
+      contracts.contract_address = created.contract_address AND
+      NOT created.no_bytecode`
+
+	// Makes the EVM token, NFT and balance analyzers re-download data that
+	// they downloaded at a round after the fork.
+	RuntimeUndoEVMTokenDownloads = `
+    UPDATE chain.evm_tokens
+    SET last_download_round = NULL
+    WHERE runtime = $1 AND last_download_round > $2`
+
+	RuntimeUndoEVMNFTDownloads = `
+    UPDATE chain.evm_nfts
+    SET last_download_round = NULL
+    WHERE runtime = $1 AND last_download_round > $2`
+
+	RuntimeUndoEVMTokenBalanceDownloads = `
+    UPDATE analysis.evm_token_balances
+    SET last_download_round = NULL
+    WHERE runtime = $1 AND last_download_round > $2`
+
+	RuntimeUndoLogDelete = `
+    DELETE FROM analysis.runtime_undo_log
+    WHERE runtime = $1 AND round > $2`
+
+	RuntimeRelatedTransactionsDelete = `
+    DELETE FROM chain.runtime_related_transactions
+    WHERE runtime = $1 AND tx_round > $2`
+
+	RuntimeTransactionSignersDelete = `
+    DELETE FROM chain.runtime_transaction_signers
+    WHERE runtime = $1 AND round > $2`
+
+	RuntimeEventsDelete = `
+    DELETE FROM chain.runtime_events
+    WHERE runtime = $1 AND round > $2`
+
+	RuntimeEVMTokenTransfersDelete = `
+    DELETE FROM chain.evm_token_transfers
+    WHERE runtime = $1 AND round > $2`
+
+	RuntimeTransfersDelete = `
+    DELETE FROM chain.runtime_transfers
+    WHERE runtime = $1 AND round > $2`
+
+	RuntimeBalanceChangesDelete = `
+    DELETE FROM chain.runtime_balance_changes
+    WHERE runtime = $1 AND round > $2`
+
+	RuntimeInternalCallsDelete = `
+    DELETE FROM chain.runtime_internal_calls
+    WHERE runtime = $1 AND round > $2`
+
+	RuntimeEVMTracedRoundsDelete = `
+    DELETE FROM analysis.evm_traced_rounds
+    WHERE runtime = $1 AND round > $2`
+
+	RuntimeTransactionsDelete = `
+    DELETE FROM chain.runtime_transactions
+    WHERE runtime = $1 AND round > $2`
+
+	RuntimeBlocksDelete = `
+    DELETE FROM chain.runtime_blocks
+    WHERE runtime = $1 AND round > $2`
+
+	// Candles of windows that end after the fork round may include the syncs of unwound rounds.
+	RuntimeUndoEVMSwapPairCandles = `
+    DELETE FROM stats.evm_swap_pair_candles AS candles
+    USING chain.runtime_blocks AS b
+    WHERE
+      candles.runtime = $1 AND
+      b.runtime = $1 AND b.round = $2 AND
+      candles.window_start + candles.interval::interval > b.timestamp`
+
+	// Rolls back the progress of the candle computations of runtime $1 to the
+	// start of the window of each interval that contains the fork round, so
+	// that the candles deleted by RuntimeUndoEVMSwapPairCandles are recomputed.
+	// Windows are aligned to multiples of their length since the Unix epoch.
+	RuntimeUndoEVMSwapPairCandlesComputed = `
+    WITH fork AS (
+      SELECT timestamp
+      FROM chain.runtime_blocks
+      WHERE runtime = $1::runtime AND round = $2
+    ),
+    intervals AS (
+      SELECT layer, extract(epoch FROM substr(layer, length($1::text) + 2)::interval) AS seconds
+      FROM stats.evm_swap_pair_candles_computed
+      WHERE layer LIKE $1::text || '\_%'
+    )
+    UPDATE stats.evm_swap_pair_candles_computed AS computed
+    SET window_end = LEAST(
+      computed.window_end,
+      to_timestamp(floor(extract(epoch FROM fork.timestamp) / intervals.seconds) * intervals.seconds)
+    )
+    FROM fork, intervals
+    WHERE computed.layer = intervals.layer`
+
+	RuntimeEVMSwapPairSyncsDelete = `
+    DELETE FROM chain.evm_swap_pair_syncs
+    WHERE runtime = $1 AND round > $2`
+
+	RuntimeEVMProxyImplementationsDelete = `
+    DELETE FROM chain.evm_proxy_implementations
+    WHERE runtime = $1 AND round > $2`
+
+	RuntimeEVMSwapPairCreationsDelete = `
+    DELETE FROM chain.evm_swap_pair_creations
+    WHERE runtime = $1 AND create_round > $2`
+
+	// Forgets that blocks after height $2 were processed (or are being processed),
+	// so that the block analyzer picks them up again.
+	ProcessedBlocksDelete = `
+    DELETE FROM analysis.processed_blocks
+    WHERE analyzer = $1 AND height > $2`
+
 	RuntimeEVMUnverfiedContracts = `
     SELECT contracts.contract_address,
       address_preimages.context_identifier,
@@ -1091,49 +1861,119 @@ var (
       source_files = EXCLUDED.source_files,
       verification_level = EXCLUDED.verification_level`
 
+	RuntimeEVMContractVerificationsPending = `
+    SELECT
+      verifications.id,
+      verifications.contract_address,
+      verifications.compiler_version,
+      verifications.contract_name,
+      verifications.sources,
+      verifications.settings,
+      contracts.runtime_bytecode,
+      contracts.verification_level
+    FROM analysis.evm_contract_verifications AS verifications
+    LEFT JOIN chain.evm_contracts AS contracts ON
+      contracts.runtime = verifications.runtime AND
+      contracts.contract_address = verifications.contract_address
+    WHERE
+      verifications.runtime = $1 AND verifications.status = 'pending'
+    ORDER BY verifications.id
+    LIMIT $2`
+
+	RuntimeEVMContractVerificationsPendingCount = `
+    SELECT COUNT(*)
+    FROM analysis.evm_contract_verifications
+    WHERE runtime = $1 AND status = 'pending'`
+
+	RuntimeEVMContractVerificationUpdate = `
+    UPDATE analysis.evm_contract_verifications
+    SET
+      status = $2,
+      error = $3,
+      verification_level = $4,
+      processed_at = CURRENT_TIMESTAMP
+    WHERE id = $1`
+
+	// Contracts whose calls and events can be parsed: those with an ABI, and proxies that
+	// delegated to a contract with an ABI at some point.
+	// The calls and events of proxies are parsed with the ABI of the implementation that the
+	// proxy delegated to at their round, falling back to the proxy's own ABI. Before the first
+	// known implementation of a proxy, the earliest known implementation is used.
 	RuntimeEvmVerifiedContractTxs = `
     WITH abi_contracts AS (
-      SELECT
-        runtime,
-        contract_address AS addr,
-        abi,
-        verification_info_downloaded_at
+      SELECT contract_address AS addr
       FROM chain.evm_contracts
       WHERE
         runtime = $1 AND abi IS NOT NULL
+      UNION
+      SELECT impls.proxy_address AS addr
+      FROM chain.evm_proxy_implementations AS impls
+      JOIN chain.evm_contracts AS impl_contracts ON
+        impl_contracts.runtime = impls.runtime AND
+        impl_contracts.contract_address = impls.implementation_address
+      WHERE
+        impls.runtime = $1 AND impl_contracts.abi IS NOT NULL
     )
     SELECT
       abi_contracts.addr,
-      abi_contracts.abi,
+      COALESCE(impl_contracts.abi, contracts.abi),
       txs.tx_hash,
       decode(txs.body->>'data', 'base64'),
       txs.error_message_raw
     FROM abi_contracts
     JOIN chain.runtime_transactions as txs ON
-      txs.runtime = abi_contracts.runtime AND
+      txs.runtime = $1 AND
       txs.to = abi_contracts.addr AND
       txs.method = 'evm.Call' -- note: does not include evm.Create txs; their payload is never encrypted.
+    LEFT JOIN chain.evm_contracts AS contracts ON
+      contracts.runtime = $1 AND
+      contracts.contract_address = abi_contracts.addr
+    LEFT JOIN LATERAL (
+      SELECT impls.implementation_address, impls.detected_at
+      FROM chain.evm_proxy_implementations AS impls
+      WHERE
+        impls.runtime = $1 AND
+        impls.proxy_address = abi_contracts.addr
+      ORDER BY
+        impls.round <= txs.round DESC,
+        CASE WHEN impls.round <= txs.round THEN impls.round END DESC,
+        impls.round
+      LIMIT 1
+    ) AS impl ON TRUE
+    LEFT JOIN chain.evm_contracts AS impl_contracts ON
+      impl_contracts.runtime = $1 AND
+      impl_contracts.contract_address = impl.implementation_address AND
+      impl_contracts.abi IS NOT NULL
     WHERE
       txs.body IS NOT NULL AND
-      (txs.abi_parsed_at IS NULL OR txs.abi_parsed_at < abi_contracts.verification_info_downloaded_at)
+      COALESCE(impl_contracts.abi, contracts.abi) IS NOT NULL AND
+      (
+        txs.abi_parsed_at IS NULL OR
+        txs.abi_parsed_at < GREATEST(contracts.verification_info_downloaded_at, impl.detected_at, impl_contracts.verification_info_downloaded_at)
+      )
     ORDER BY addr
     LIMIT $2`
 
+	// See RuntimeEvmVerifiedContractTxs.
 	RuntimeEvmVerifiedContractEvents = `
     WITH abi_contracts AS (
-      SELECT
-        runtime,
-        contract_address AS addr,
-        abi,
-        verification_info_downloaded_at
+      SELECT contract_address AS addr
       FROM chain.evm_contracts
       WHERE
         runtime = $1 AND
         abi IS NOT NULL
+      UNION
+      SELECT impls.proxy_address AS addr
+      FROM chain.evm_proxy_implementations AS impls
+      JOIN chain.evm_contracts AS impl_contracts ON
+        impl_contracts.runtime = impls.runtime AND
+        impl_contracts.contract_address = impls.implementation_address
+      WHERE
+        impls.runtime = $1 AND impl_contracts.abi IS NOT NULL
     )
     SELECT
       abi_contracts.addr,
-      abi_contracts.abi,
+      COALESCE(impl_contracts.abi, contracts.abi),
       evs.round,
       evs.tx_index,
       evs.body
@@ -1142,9 +1982,239 @@ var (
       abi_contracts.addr = preimages.address
     JOIN chain.runtime_events as evs ON
       evs.type = 'evm.log' AND
-      evs.runtime = abi_contracts.runtime AND
+      evs.runtime = $1 AND
       decode(body->>'address', 'base64') = preimages.address_data
+    LEFT JOIN chain.evm_contracts AS contracts ON
+      contracts.runtime = $1 AND
+      contracts.contract_address = abi_contracts.addr
+    LEFT JOIN LATERAL (
+      SELECT impls.implementation_address, impls.detected_at
+      FROM chain.evm_proxy_implementations AS impls
+      WHERE
+        impls.runtime = $1 AND
+        impls.proxy_address = abi_contracts.addr
+      ORDER BY
+        impls.round <= evs.round DESC,
+        CASE WHEN impls.round <= evs.round THEN impls.round END DESC,
+        impls.round
+      LIMIT 1
+    ) AS impl ON TRUE
+    LEFT JOIN chain.evm_contracts AS impl_contracts ON
+      impl_contracts.runtime = $1 AND
+      impl_contracts.contract_address = impl.implementation_address AND
+      impl_contracts.abi IS NOT NULL
+    WHERE
+      COALESCE(impl_contracts.abi, contracts.abi) IS NOT NULL AND
+      (
+        evs.abi_parsed_at IS NULL OR
+        evs.abi_parsed_at < GREATEST(contracts.verification_info_downloaded_at, impl.detected_at, impl_contracts.verification_info_downloaded_at)
+      )
+    LIMIT $2`
+
+	// Calls that have not been parsed yet, to contracts without an ABI (see RuntimeEvmVerifiedContractTxs).
+	// These are parsed with the database of known signatures. Calls whose function was not known are
+	// parsed again once the signatures have been extended since, at $3.
+	RuntimeEvmUnverifiedContractTxs = `
+    SELECT
+      txs.to,
+      txs.tx_hash,
+      decode(txs.body->>'data', 'base64'),
+      txs.error_message_raw
+    FROM chain.runtime_transactions AS txs
     WHERE
-      (evs.abi_parsed_at IS NULL OR evs.abi_parsed_at < abi_contracts.verification_info_downloaded_at)
+      txs.runtime = $1 AND
+      txs.method = 'evm.Call' AND
+      (
+        txs.abi_parsed_at IS NULL OR
+        (txs.evm_fn_name IS NULL AND txs.abi_parsed_at < $3::timestamptz)
+      ) AND
+      NOT EXISTS (
+        SELECT 1
+        FROM chain.evm_contracts AS contracts
+        WHERE
+          contracts.runtime = $1 AND
+          contracts.contract_address = txs.to AND
+          contracts.abi IS NOT NULL
+      ) AND
+      NOT EXISTS (
+        SELECT 1
+        FROM chain.evm_proxy_implementations AS impls
+        JOIN chain.evm_contracts AS impl_contracts ON
+          impl_contracts.runtime = impls.runtime AND
+          impl_contracts.contract_address = impls.implementation_address
+        WHERE
+          impls.runtime = $1 AND
+          impls.proxy_address = txs.to AND
+          impl_contracts.abi IS NOT NULL
+      )
     LIMIT $2`
+
+	// Events that have not been parsed yet, neither when they were indexed nor with an ABI,
+	// of contracts without an ABI; or were parsed before the signatures were extended at $3.
+	// See RuntimeEvmUnverifiedContractTxs.
+	RuntimeEvmUnverifiedContractEvents = `
+    SELECT
+      COALESCE(preimages.address, ''),
+      evs.round,
+      evs.tx_index,
+      evs.body
+    FROM chain.runtime_events AS evs
+    LEFT JOIN chain.address_preimages AS preimages ON
+      preimages.address_data = decode(evs.body->>'address', 'base64') AND
+      preimages.context_identifier = 'oasis-runtime-sdk/address: secp256k1eth' AND
+      preimages.context_version = 0
+    WHERE
+      evs.runtime = $1 AND
+      evs.type = 'evm.log' AND
+      evs.evm_log_name IS NULL AND
+      (evs.abi_parsed_at IS NULL OR evs.abi_parsed_at < $3::timestamptz) AND
+      NOT EXISTS (
+        SELECT 1
+        FROM chain.evm_contracts AS contracts
+        WHERE
+          contracts.runtime = $1 AND
+          contracts.contract_address = preimages.address AND
+          contracts.abi IS NOT NULL
+      ) AND
+      NOT EXISTS (
+        SELECT 1
+        FROM chain.evm_proxy_implementations AS impls
+        JOIN chain.evm_contracts AS impl_contracts ON
+          impl_contracts.runtime = impls.runtime AND
+          impl_contracts.contract_address = impls.implementation_address
+        WHERE
+          impls.runtime = $1 AND
+          impls.proxy_address = preimages.address AND
+          impl_contracts.abi IS NOT NULL
+      )
+    LIMIT $2`
+
+	// The ABIs of contracts in all runtimes that were downloaded after $1 (or all, if NULL),
+	// for extending the database of known signatures.
+	EvmContractAbisSince = `
+    SELECT abi, verification_info_downloaded_at
+    FROM chain.evm_contracts
+    WHERE
+      abi IS NOT NULL AND
+      ($1::timestamptz IS NULL OR verification_info_downloaded_at > $1::timestamptz)
+    ORDER BY verification_info_downloaded_at`
+
+	WebhookSubscriptionUpsert = `
+    INSERT INTO analysis.webhook_subscriptions (id, url, layer, addresses, event_types, last_height)
+      VALUES ($1, $2, $3, $4, $5, $6)
+    ON CONFLICT (id) DO UPDATE SET
+      url = excluded.url,
+      layer = excluded.layer,
+      addresses = excluded.addresses,
+      event_types = excluded.event_types,
+      -- The progress of a subscription is only meaningful within its layer.
+      last_height = CASE
+        WHEN analysis.webhook_subscriptions.layer = excluded.layer THEN analysis.webhook_subscriptions.last_height
+        ELSE excluded.last_height
+      END`
+
+	WebhookSubscriptionsDeleteOthers = `
+    DELETE FROM analysis.webhook_subscriptions
+    WHERE NOT (id = ANY($1::text[]))`
+
+	WebhookSubscriptions = `
+    SELECT id, last_height
+    FROM analysis.webhook_subscriptions`
+
+	WebhookSubscriptionSetLastHeight = `
+    UPDATE analysis.webhook_subscriptions
+    SET last_height = $2
+    WHERE id = $1`
+
+	// Parameters: $1 = subscription id, ($2, $3] = block range, $4 = addresses, $5 = event types.
+	WebhookConsensusEventsEnqueue = `
+    INSERT INTO analysis.webhook_deliveries (subscription_id, payload)
+    SELECT
+      $1,
+      jsonb_build_object(
+        'subscription_id', $1::text,
+        'layer', 'consensus',
+        'height', evs.tx_block,
+        'timestamp', b.time,
+        'tx_hash', evs.tx_hash,
+        'event_index', evs.event_index,
+        'type', evs.type,
+        'body', evs.body,
+        'related_accounts', evs.related_accounts
+      )
+    FROM chain.events AS evs
+    JOIN chain.blocks AS b ON b.height = evs.tx_block
+    WHERE
+      evs.tx_block > $2 AND evs.tx_block <= $3 AND
+      (cardinality($4::text[]) = 0 OR evs.related_accounts && $4::text[]) AND
+      (cardinality($5::text[]) = 0 OR evs.type = ANY($5::text[]))
+    ORDER BY evs.tx_block, evs.event_index`
+
+	// Parameters: $1 = subscription id, $2 = runtime, ($3, $4] = round range, $5 = addresses, $6 = event types.
+	WebhookRuntimeEventsEnqueue = `
+    INSERT INTO analysis.webhook_deliveries (subscription_id, payload)
+    SELECT
+      $1,
+      jsonb_build_object(
+        'subscription_id', $1::text,
+        'layer', evs.runtime,
+        'round', evs.round,
+        'timestamp', evs.timestamp,
+        'tx_hash', evs.tx_hash,
+        'eth_tx_hash', evs.tx_eth_hash,
+        'event_index', evs.event_index,
+        'type', evs.type,
+        'body', evs.body,
+        'evm_log_name', evs.evm_log_name,
+        'evm_log_params', evs.evm_log_params,
+        'related_accounts', evs.related_accounts
+      )
+    FROM chain.runtime_events AS evs
+    WHERE
+      evs.runtime = $2 AND evs.round > $3 AND evs.round <= $4 AND
+      (cardinality($5::text[]) = 0 OR evs.related_accounts && $5::text[]) AND
+      (cardinality($6::text[]) = 0 OR evs.type = ANY($6::text[]))
+    ORDER BY evs.round, evs.event_index`
+
+	WebhookDueDeliveries = `
+    SELECT id, subscription_id, payload, num_attempts
+    FROM analysis.webhook_deliveries
+    WHERE next_attempt_time <= CURRENT_TIMESTAMP
+    ORDER BY id
+    LIMIT $1`
+
+	WebhookDueDeliveriesCount = `
+    SELECT COUNT(*)
+    FROM analysis.webhook_deliveries
+    WHERE next_attempt_time <= CURRENT_TIMESTAMP`
+
+	WebhookDeliveryDelete = `
+    DELETE FROM analysis.webhook_deliveries
+    WHERE id = $1`
+
+	WebhookDeliveryRetry = `
+    UPDATE analysis.webhook_deliveries
+    SET
+      num_attempts = $2,
+      next_attempt_time = CURRENT_TIMESTAMP + ($3::bigint * INTERVAL '1 millisecond'),
+      last_error = $4
+    WHERE id = $1`
+
+	WebhookDeliveryDeadLetter = `
+    WITH failed AS (
+      DELETE FROM analysis.webhook_deliveries
+      WHERE id = $1
+      RETURNING id, subscription_id, payload
+    )
+    INSERT INTO analysis.webhook_dead_letters (id, subscription_id, payload, num_attempts, last_error)
+    SELECT id, subscription_id, payload, $2, $3
+    FROM failed
+    ON CONFLICT (id) DO NOTHING`
+
+	AddressLabelsDelete = `
+    DELETE FROM chain.address_labels`
+
+	AddressLabelInsert = `
+    INSERT INTO chain.address_labels (layer, address, name, description, source)
+      VALUES ($1, $2, $3, $4, $5)`
 )
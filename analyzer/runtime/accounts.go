@@ -61,6 +61,7 @@ func (m *processor) queueMint(batch *storage.QueryBatch, round uint64, e account
 			stringifyDenomination(m.sdkPT, e.Amount.Denomination),
 			e.Amount.Amount.String(),
 		)
+		m.undoNativeBalance(batch, round, e.Owner.String(), stringifyDenomination(m.sdkPT, e.Amount.Denomination), e.Amount.Amount.String())
 		if e.Amount.Denomination.IsNative() {
 			batch.Queue(
 				queries.RuntimeAccountTotalReceivedUpsert,
@@ -68,6 +69,7 @@ func (m *processor) queueMint(batch *storage.QueryBatch, round uint64, e account
 				e.Owner.String(),
 				e.Amount.Amount.String(),
 			)
+			m.undoAccountStat(batch, round, e.Owner.String(), "total_received", e.Amount.Amount.String())
 		}
 	}
 }
@@ -91,6 +93,7 @@ func (m *processor) queueBurn(batch *storage.QueryBatch, round uint64, e account
 			stringifyDenomination(m.sdkPT, e.Amount.Denomination),
 			(&big.Int{}).Neg(e.Amount.Amount.ToBigInt()).String(),
 		)
+		m.undoNativeBalance(batch, round, e.Owner.String(), stringifyDenomination(m.sdkPT, e.Amount.Denomination), (&big.Int{}).Neg(e.Amount.Amount.ToBigInt()).String())
 		if e.Amount.Denomination.IsNative() {
 			batch.Queue(
 				queries.RuntimeAccountTotalSentUpsert,
@@ -98,6 +101,7 @@ func (m *processor) queueBurn(batch *storage.QueryBatch, round uint64, e account
 				e.Owner.String(),
 				e.Amount.Amount.String(),
 			)
+			m.undoAccountStat(batch, round, e.Owner.String(), "total_sent", e.Amount.Amount.String())
 		}
 	}
 }
@@ -128,6 +132,8 @@ func (m *processor) queueTransfer(batch *storage.QueryBatch, round uint64, e acc
 			e.To.String(),
 			e.Amount.Amount.String(),
 		)
+		m.undoNativeBalance(batch, round, e.To.String(), stringifyDenomination(m.sdkPT, e.Amount.Denomination), e.Amount.Amount.String())
+		m.undoAccountStat(batch, round, e.To.String(), "total_received", e.Amount.Amount.String())
 	}
 	// Decrease sender's balance.
 	if !(m.mode == analyzer.FastSyncMode && slices.Contains(veryHighTrafficAccounts, e.From)) {
@@ -144,5 +150,7 @@ func (m *processor) queueTransfer(batch *storage.QueryBatch, round uint64, e acc
 			e.From.String(),
 			e.Amount.Amount.String(),
 		)
+		m.undoNativeBalance(batch, round, e.From.String(), stringifyDenomination(m.sdkPT, e.Amount.Denomination), (&big.Int{}).Neg(e.Amount.Amount.ToBigInt()).String())
+		m.undoAccountStat(batch, round, e.From.String(), "total_sent", e.Amount.Amount.String())
 	}
 }
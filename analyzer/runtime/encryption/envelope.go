@@ -5,8 +5,11 @@ import (
 )
 
 type EncryptedData struct {
-	Format      common.CallFormat
-	PublicKey   []byte
+	Format    common.CallFormat
+	PublicKey []byte
+	// The epoch of the ephemeral runtime (calldata) key that the call was
+	// encrypted with, or 0 if it was encrypted with the long-term key.
+	Epoch       uint64
 	DataNonce   []byte
 	DataData    []byte
 	ResultNonce []byte
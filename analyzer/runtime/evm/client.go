@@ -182,6 +182,19 @@ func EVMDownloadNewToken(ctx context.Context, logger *log.Logger, source nodeapi
 			return tokenData, nil
 		}
 
+		// Note: Per spec, every ERC-1155 token has to support ERC-165.
+		supportsERC1155, err1 := detectInterface(ctx, logger, source, round, tokenEthAddr, ERC1155InterfaceID)
+		if err1 != nil {
+			return nil, fmt.Errorf("checking ERC1155 interface: %w", err1)
+		}
+		if supportsERC1155 {
+			tokenData, err2 := evmDownloadTokenERC1155(ctx, logger, source, round, tokenEthAddr)
+			if err2 != nil {
+				return nil, fmt.Errorf("download token ERC-1155: %w", err2)
+			}
+			return tokenData, nil
+		}
+
 		// todo: add support for other token types
 		// see https://github.com/oasisprotocol/nexus/issues/225
 	}
@@ -225,6 +238,11 @@ func EVMDownloadMutatedToken(ctx context.Context, logger *log.Logger, source nod
 		}
 		return mutable, nil
 
+	case common.TokenTypeERC1155:
+		// There is no standard way to query the mutable data of an ERC-1155
+		// token; keep the dead-reckoned values.
+		return &EVMTokenMutableData{}, nil
+
 	// todo: add support for other token types
 	// see https://github.com/oasisprotocol/nexus/issues/225
 
@@ -247,6 +265,13 @@ func EVMDownloadNewNFT(ctx context.Context, logger *log.Logger, source nodeapi.R
 		}
 		return nftData, nil
 
+	case common.TokenTypeERC1155:
+		nftData, err := evmDownloadNFTERC1155(ctx, logger, source, ipfsClient, round, tokenEthAddr, id)
+		if err != nil {
+			return nil, fmt.Errorf("download NFT ERC-1155: %w", err)
+		}
+		return nftData, nil
+
 	default:
 		logger.Info("new NFT is not a supported token type",
 			"round", round,
@@ -279,6 +304,11 @@ func EVMDownloadTokenBalance(ctx context.Context, logger *log.Logger, source nod
 		}
 		return balance, nil
 
+	case common.TokenTypeERC1155:
+		// ERC-1155 has no method for querying an account's balance across
+		// all token IDs. Keep the balance dead-reckoned from transfer events.
+		return nil, nil
+
 	// todo: add support for other token types
 	// see https://github.com/oasisprotocol/nexus/issues/225
 
@@ -139,6 +139,7 @@ func EVMMaybeUnmarshalEncryptedData(data []byte, result *[]byte) (*encryption.En
 			return nil, nil, fmt.Errorf("outer call format %s unmarshal body: %w", call.Format, err)
 		}
 		encryptedData.PublicKey = callEnvelope.Pk[:]
+		encryptedData.Epoch = callEnvelope.Epoch
 		encryptedData.DataNonce = callEnvelope.Nonce[:]
 		encryptedData.DataData = callEnvelope.Data
 	// Plain txs have no encrypted fields to extract.
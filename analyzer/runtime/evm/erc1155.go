@@ -0,0 +1,109 @@
+package evm
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/oasisprotocol/oasis-core/go/common/errors"
+
+	"github.com/oasisprotocol/nexus/analyzer/evmabi"
+	"github.com/oasisprotocol/nexus/analyzer/evmnfts/ipfsclient"
+	"github.com/oasisprotocol/nexus/common"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage/oasis/nodeapi"
+)
+
+func evmDownloadTokenERC1155(ctx context.Context, logger *log.Logger, source nodeapi.RuntimeApiLite, round uint64, tokenEthAddr []byte) (*EVMTokenData, error) {
+	tokenData := EVMTokenData{
+		Type: common.TokenTypeERC1155,
+		// ERC-1155 has no notion of total supply across token IDs, and there
+		// is no standard method to query it. We dead-reckon it from mints
+		// and burns instead.
+		EVMTokenMutableData: &EVMTokenMutableData{},
+	}
+	// ERC-1155 does not standardize a name or symbol, but many collections
+	// expose the same optional methods as ERC-721 Metadata. These may fail.
+	if err := evmCallWithABI(ctx, source, round, tokenEthAddr, evmabi.ERC721Metadata, &tokenData.Name, "name"); err != nil {
+		if !errors.Is(err, EVMDeterministicError{}) {
+			return nil, fmt.Errorf("calling name: %w", err)
+		}
+		logDeterministicError(logger, round, tokenEthAddr, "ERC721Metadata", "name", err)
+	}
+	if err := evmCallWithABI(ctx, source, round, tokenEthAddr, evmabi.ERC721Metadata, &tokenData.Symbol, "symbol"); err != nil {
+		if !errors.Is(err, EVMDeterministicError{}) {
+			return nil, fmt.Errorf("calling symbol: %w", err)
+		}
+		logDeterministicError(logger, round, tokenEthAddr, "ERC721Metadata", "symbol", err)
+	}
+	return &tokenData, nil
+}
+
+// erc1155SubstituteID substitutes the `{id}` placeholder in an ERC-1155
+// metadata URI. Per spec, the ID is in lowercase hex, zero-padded to 64
+// characters, without a 0x prefix.
+func erc1155SubstituteID(uri string, id *big.Int) string {
+	return strings.ReplaceAll(uri, "{id}", fmt.Sprintf("%064x", id))
+}
+
+func evmDownloadNFTERC1155(ctx context.Context, logger *log.Logger, source nodeapi.RuntimeApiLite, ipfsClient ipfsclient.Client, round uint64, tokenEthAddr []byte, id *big.Int) (*EVMNFTData, error) {
+	var nftData EVMNFTData
+	supportsMetadataURI, err := detectInterface(ctx, logger, source, round, tokenEthAddr, ERC1155MetadataURIInterfaceID)
+	if err != nil {
+		return nil, fmt.Errorf("checking ERC1155Metadata_URI interface: %w", err)
+	}
+	if !supportsMetadataURI {
+		return &nftData, nil
+	}
+	var metadataURI string
+	if err = evmCallWithABI(ctx, source, round, tokenEthAddr, evmabi.ERC1155MetadataURI, &metadataURI, "uri", id); err != nil {
+		if !errors.Is(err, EVMDeterministicError{}) {
+			return nil, fmt.Errorf("calling uri: %w", err)
+		}
+		logDeterministicError(logger, round, tokenEthAddr, "ERC1155Metadata_URI", "uri", err,
+			"nft_id", id,
+		)
+		return &nftData, nil
+	}
+	// The ERC-1155 Metadata URI JSON Schema is a superset of the ERC-721
+	// one, so the same fields can be extracted.
+	if err = evmDownloadNFTMetadata(ctx, logger, ipfsClient, &nftData, tokenEthAddr, id, erc1155SubstituteID(metadataURI, id)); err != nil {
+		return nil, err
+	}
+	return &nftData, nil
+}
+
+// EVMDownloadNFTBalancesERC1155 tries to download the balances of a given
+// account for the given IDs of an ERC-1155 token. If it transiently fails to
+// download the balances, it returns with a non-nil error. If it
+// deterministically cannot download the balances, it returns nil with nil
+// error as well.
+func EVMDownloadNFTBalancesERC1155(ctx context.Context, logger *log.Logger, source nodeapi.RuntimeApiLite, round uint64, tokenEthAddr []byte, accountEthAddr []byte, ids []*big.Int) ([]*big.Int, error) {
+	accountECAddr := ethCommon.BytesToAddress(accountEthAddr)
+	owners := make([]ethCommon.Address, len(ids))
+	for i := range owners {
+		owners[i] = accountECAddr
+	}
+	var balances []*big.Int
+	if err := evmCallWithABI(ctx, source, round, tokenEthAddr, evmabi.ERC1155, &balances, "balanceOfBatch", owners, ids); err != nil {
+		if !errors.Is(err, EVMDeterministicError{}) {
+			return nil, fmt.Errorf("calling balanceOfBatch: %w", err)
+		}
+		logDeterministicError(logger, round, tokenEthAddr, "ERC1155", "balanceOfBatch", err,
+			"account_eth_addr_hex", hex.EncodeToString(accountEthAddr),
+			"num_ids", len(ids),
+		)
+		return nil, nil
+	}
+	if len(balances) != len(ids) {
+		err := EVMDeterministicError{fmt.Errorf("balanceOfBatch returned %d balances for %d ids", len(balances), len(ids))}
+		logDeterministicError(logger, round, tokenEthAddr, "ERC1155", "balanceOfBatch", err,
+			"account_eth_addr_hex", hex.EncodeToString(accountEthAddr),
+		)
+		return nil, nil
+	}
+	return balances, nil
+}
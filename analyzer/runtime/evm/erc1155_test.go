@@ -0,0 +1,18 @@
+package evm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestERC1155SubstituteID(t *testing.T) {
+	// Example from the ERC-1155 spec.
+	require.Equal(t,
+		"https://token-cdn-domain/000000000000000000000000000000000000000000000000000000000004cce0.json",
+		erc1155SubstituteID("https://token-cdn-domain/{id}.json", big.NewInt(314592)),
+	)
+	// URIs without a placeholder are left as is.
+	require.Equal(t, "ipfs://QmHash/1.json", erc1155SubstituteID("ipfs://QmHash/1.json", big.NewInt(1)))
+}
@@ -31,6 +31,8 @@ var (
 	ERC721TokenReceiverInterfaceID = InterfaceID(evmabi.ERC721TokenReceiver)
 	ERC721MetadataInterfaceID      = InterfaceID(evmabi.ERC721Metadata)
 	ERC721EnumerableInterfaceID    = InterfaceID(evmabi.ERC721Enumerable)
+	ERC1155InterfaceID             = InterfaceID(evmabi.ERC1155)
+	ERC1155MetadataURIInterfaceID  = InterfaceID(evmabi.ERC1155MetadataURI)
 )
 
 const ERC165GasLimit uint64 = 30_000
@@ -14,4 +14,6 @@ func TestInterfaceID(t *testing.T) {
 	require.Equal(t, "150b7a02", hex.EncodeToString(ERC721TokenReceiverInterfaceID))
 	require.Equal(t, "5b5e139f", hex.EncodeToString(ERC721MetadataInterfaceID))
 	require.Equal(t, "780e9d63", hex.EncodeToString(ERC721EnumerableInterfaceID))
+	require.Equal(t, "d9b67a26", hex.EncodeToString(ERC1155InterfaceID))
+	require.Equal(t, "0e89341c", hex.EncodeToString(ERC1155MetadataURIInterfaceID))
 }
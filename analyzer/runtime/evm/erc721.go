@@ -93,6 +93,13 @@ func evmDownloadNFTERC721Metadata(ctx context.Context, logger *log.Logger, sourc
 		)
 		return nil
 	}
+	return evmDownloadNFTMetadata(ctx, logger, ipfsClient, nftData, tokenEthAddr, id, metadataURI)
+}
+
+// evmDownloadNFTMetadata fetches the metadata document at metadataURI and
+// fills in the metadata fields of nftData. Failing to fetch or parse the
+// document is not considered an error.
+func evmDownloadNFTMetadata(ctx context.Context, logger *log.Logger, ipfsClient ipfsclient.Client, nftData *EVMNFTData, tokenEthAddr []byte, id *big.Int, metadataURI string) error {
 	nftData.MetadataURI = &metadataURI
 	logger.Info("downloading metadata",
 		"token_eth_addr", hex.EncodeToString(tokenEthAddr),
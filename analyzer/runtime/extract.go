@@ -101,6 +101,18 @@ type EventData struct {
 	EvmLogSignature  *ethCommon.Hash
 	EvmLogParams     []*apiTypes.EvmAbiParam
 	RelatedAddresses map[apiTypes.Address]struct{}
+	TokenTransfers   []*TokenTransfer // decoded ERC-20/721/1155 transfers; several for an ERC-1155 TransferBatch
+}
+
+// TokenTransfer is a transfer of an EVM token, decoded from a Transfer,
+// TransferSingle or TransferBatch event.
+type TokenTransfer struct {
+	TokenAddress apiTypes.Address
+	TokenType    common.TokenType
+	From         *apiTypes.Address // nil for mints
+	To           *apiTypes.Address // nil for burns
+	TokenID      *big.Int          // nil for ERC-20 transfers
+	Amount       *big.Int          // 1 for ERC-721 transfers
 }
 
 // ScopedSdkEvent is a one-of container for SDK events.
@@ -120,9 +132,21 @@ type TokenChangeKey struct {
 	AccountAddress apiTypes.Address
 }
 
+// BalanceChangeKey identifies a balance in the balance history: that of a
+// native runtime denomination or of an ERC-20 token, held by an account.
+type BalanceChangeKey struct {
+	AccountAddress apiTypes.Address
+	// Token is the symbol of the native runtime denomination (see
+	// stringifyDenomination), or the Oasis address of the smart contract of
+	// the ERC-20 token.
+	Token string
+}
+
 type NFTKey struct {
 	TokenAddress apiTypes.Address
-	TokenID      *big.Int
+	// TokenID is the decimal token ID. A *big.Int would compare by pointer,
+	// so transfers of the same ID would not be combined.
+	TokenID string
 }
 
 type PossibleNFT struct {
@@ -134,12 +158,28 @@ type PossibleNFT struct {
 	Burned bool
 	// NewOwner has the latest owner if NumTransfers is more than zero.
 	NewOwner apiTypes.Address
+	// MultiOwner is true for ERC-1155 tokens, where an ID can be held by
+	// several accounts at once. Burned and NewOwner are not set for these;
+	// the per-account balances are tracked in NFTBalanceChanges instead.
+	MultiOwner bool
+}
+
+type NFTBalanceChangeKey struct {
+	TokenAddress apiTypes.Address
+	// TokenID is the decimal token ID. A *big.Int would compare by pointer,
+	// so transfers of the same ID would not be combined.
+	TokenID        string
+	AccountAddress apiTypes.Address
 }
 
 type SwapCreationKey struct {
 	Factory apiTypes.Address
 	Token0  apiTypes.Address
 	Token1  apiTypes.Address
+	// Fee is the fee tier of a Uniswap V3 pool, in hundredths of a bip. A V3
+	// factory can create a pool per fee tier for the same tokens. Zero for
+	// Uniswap V2 pairs.
+	Fee uint32
 }
 
 type PossibleSwapCreation struct {
@@ -147,8 +187,21 @@ type PossibleSwapCreation struct {
 }
 
 type PossibleSwapSync struct {
+	// For Uniswap V3 pools, these are the virtual reserves, i.e. the reserves
+	// a Uniswap V2 pair with the pool's active liquidity would have at the
+	// pool's price.
 	Reserve0 *big.Int
 	Reserve1 *big.Int
+	// SqrtPriceX96 is the square root of the price of token0 in units of
+	// token1, as a Q64.96 fixed point number. Nil for Uniswap V2 pairs.
+	SqrtPriceX96 *big.Int
+}
+
+// SwapVolume is the amount of each token of a swap pair that was swapped
+// (in or out) in a round.
+type SwapVolume struct {
+	Amount0 *big.Int
+	Amount1 *big.Int
 }
 
 type BlockData struct {
@@ -160,10 +213,14 @@ type BlockData struct {
 	EventData           []*EventData
 	AddressPreimages    map[apiTypes.Address]*addresses.PreimageData
 	TokenBalanceChanges map[TokenChangeKey]*big.Int
+	BalanceChanges      map[BalanceChangeKey]*big.Int              // for the balance history; from accounts and ERC-20 events only
 	PossibleTokens      map[apiTypes.Address]*evm.EVMPossibleToken // key is oasis bech32 address
 	PossibleNFTs        map[NFTKey]*PossibleNFT
+	NFTBalanceChanges   map[NFTBalanceChangeKey]*big.Int
 	SwapCreations       map[SwapCreationKey]*PossibleSwapCreation
 	SwapSyncs           map[apiTypes.Address]*PossibleSwapSync
+	SwapVolumes         map[apiTypes.Address]*SwapVolume
+	ProxyUpgrades       map[apiTypes.Address]apiTypes.Address // proxy address -> latest implementation address
 }
 
 // Function naming conventions in this file:
@@ -176,7 +233,7 @@ type BlockData struct {
 //   effects. suitable for processing smaller pieces of data that contribute to aggregated structures
 
 func findPossibleNFT(possibleNFTs map[NFTKey]*PossibleNFT, contractAddr apiTypes.Address, tokenID *big.Int) *PossibleNFT {
-	key := NFTKey{contractAddr, tokenID}
+	key := NFTKey{contractAddr, tokenID.String()}
 	possibleNFT, ok := possibleNFTs[key]
 	if !ok {
 		possibleNFT = &PossibleNFT{}
@@ -196,6 +253,50 @@ func registerNFTTransfer(nftChanges map[NFTKey]*PossibleNFT, contractAddr apiTyp
 	possibleNFT.NewOwner = newOwner
 }
 
+func findNFTBalanceChange(nftBalanceChanges map[NFTBalanceChangeKey]*big.Int, contractAddr apiTypes.Address, tokenID *big.Int, accountAddr apiTypes.Address) *big.Int {
+	key := NFTBalanceChangeKey{contractAddr, tokenID.String(), accountAddr}
+	change, ok := nftBalanceChanges[key]
+	if !ok {
+		change = &big.Int{}
+		nftBalanceChanges[key] = change
+	}
+	return change
+}
+
+// registerMultiTokenTransfer registers the transfer of `value` units of ERC-1155 token `tokenID`. Pass a nil
+// fromAddr for mints and a nil toAddr for burns.
+func registerMultiTokenTransfer(blockData *BlockData, contractAddr apiTypes.Address, tokenID *big.Int, value *big.Int, fromAddr *apiTypes.Address, toAddr *apiTypes.Address) {
+	if fromAddr != nil {
+		registerTokenDecrease(blockData.TokenBalanceChanges, contractAddr, *fromAddr, value)
+		change := findNFTBalanceChange(blockData.NFTBalanceChanges, contractAddr, tokenID, *fromAddr)
+		change.Sub(change, value)
+	}
+	if toAddr != nil {
+		registerTokenIncrease(blockData.TokenBalanceChanges, contractAddr, *toAddr, value)
+		change := findNFTBalanceChange(blockData.NFTBalanceChanges, contractAddr, tokenID, *toAddr)
+		change.Add(change, value)
+	}
+	if _, ok := blockData.PossibleTokens[contractAddr]; !ok {
+		blockData.PossibleTokens[contractAddr] = &evm.EVMPossibleToken{}
+	}
+	pt := blockData.PossibleTokens[contractAddr]
+	// Mints, burns, and zero-value transfers all count as transfers.
+	pt.NumTransfersChange++
+	// ERC-1155 has no totalSupply() method, so we dead-reckon the total
+	// supply across all IDs from mints and burns.
+	if fromAddr == nil && toAddr != nil && value.Cmp(&big.Int{}) != 0 {
+		pt.TotalSupplyChange.Add(&pt.TotalSupplyChange, value)
+		pt.Mutated = true
+	}
+	if fromAddr != nil && toAddr == nil && value.Cmp(&big.Int{}) != 0 {
+		pt.TotalSupplyChange.Sub(&pt.TotalSupplyChange, value)
+		pt.Mutated = true
+	}
+	possibleNFT := findPossibleNFT(blockData.PossibleNFTs, contractAddr, tokenID)
+	possibleNFT.NumTransfers++
+	possibleNFT.MultiOwner = true
+}
+
 func findTokenChange(tokenChanges map[TokenChangeKey]*big.Int, contractAddr apiTypes.Address, accountAddr apiTypes.Address) *big.Int {
 	key := TokenChangeKey{contractAddr, accountAddr}
 	change, ok := tokenChanges[key]
@@ -216,6 +317,89 @@ func registerTokenDecrease(tokenChanges map[TokenChangeKey]*big.Int, contractAdd
 	change.Sub(change, amount)
 }
 
+// registerBalanceChange registers a change of the account's balance of the
+// token for the balance history. Pass a negative amount for a decrease.
+func registerBalanceChange(balanceChanges map[BalanceChangeKey]*big.Int, accountAddr apiTypes.Address, token string, amount *big.Int) {
+	key := BalanceChangeKey{accountAddr, token}
+	change, ok := balanceChanges[key]
+	if !ok {
+		change = &big.Int{}
+		balanceChanges[key] = change
+	}
+	change.Add(change, amount)
+}
+
+// registerSwapVolume adds the amounts swapped in and out of a swap pair to the pair's volume in the block.
+func registerSwapVolume(swapVolumes map[apiTypes.Address]*SwapVolume, pairAddr apiTypes.Address, amount0In *big.Int, amount1In *big.Int, amount0Out *big.Int, amount1Out *big.Int) {
+	volume, ok := swapVolumes[pairAddr]
+	if !ok {
+		volume = &SwapVolume{Amount0: &big.Int{}, Amount1: &big.Int{}}
+		swapVolumes[pairAddr] = volume
+	}
+	volume.Amount0.Add(volume.Amount0, amount0In)
+	volume.Amount0.Add(volume.Amount0, amount0Out)
+	volume.Amount1.Add(volume.Amount1, amount1In)
+	volume.Amount1.Add(volume.Amount1, amount1Out)
+}
+
+// uniswapV3SwapAmounts splits the signed amounts of a Uniswap V3 swap into the
+// amounts swapped in and out of the pool. Positive amounts went into the pool.
+func uniswapV3SwapAmounts(amount *big.Int) (*big.Int, *big.Int) {
+	if amount.Sign() >= 0 {
+		return amount, &big.Int{}
+	}
+	return &big.Int{}, new(big.Int).Neg(amount)
+}
+
+// q96 is 2^96, the scaling factor of Uniswap V3's Q64.96 fixed point numbers.
+var q96 = new(big.Int).Lsh(big.NewInt(1), 96)
+
+// uniswapV3VirtualReserves returns the reserves that a Uniswap V2 pair would
+// have with the given liquidity at the given price. Their ratio
+// reserve1 / reserve0 is the price of token0 in units of token1, so prices can
+// be derived the same way for both kinds of pools.
+func uniswapV3VirtualReserves(sqrtPriceX96 *big.Int, liquidity *big.Int) (*big.Int, *big.Int) {
+	if sqrtPriceX96.Sign() == 0 {
+		return &big.Int{}, &big.Int{}
+	}
+	// reserve0 = liquidity / sqrtPrice, reserve1 = liquidity * sqrtPrice
+	reserve0 := new(big.Int).Mul(liquidity, q96)
+	reserve0.Quo(reserve0, sqrtPriceX96)
+	reserve1 := new(big.Int).Mul(liquidity, sqrtPriceX96)
+	reserve1.Quo(reserve1, q96)
+	return reserve0, reserve1
+}
+
+// registerMultiTokenTransferParties registers the addresses involved in an ERC-1155 transfer as related to the
+// event and transaction. It returns nil for fromAddr in case of a mint and nil for toAddr in case of a burn.
+func registerMultiTokenTransferParties(addressPreimages map[apiTypes.Address]*addresses.PreimageData, relatedAccountAddresses map[apiTypes.Address]struct{}, eventRelatedAddresses map[apiTypes.Address]struct{}, operatorECAddr ethCommon.Address, fromECAddr ethCommon.Address, toECAddr ethCommon.Address) (*apiTypes.Address, *apiTypes.Address, error) {
+	var fromAddr, toAddr *apiTypes.Address
+	if !bytes.Equal(operatorECAddr.Bytes(), eth.ZeroEthAddr) {
+		operatorAddr, err := addresses.RegisterRelatedEthAddress(addressPreimages, relatedAccountAddresses, operatorECAddr.Bytes())
+		if err != nil {
+			return nil, nil, fmt.Errorf("operator: %w", err)
+		}
+		eventRelatedAddresses[operatorAddr] = struct{}{}
+	}
+	if !bytes.Equal(fromECAddr.Bytes(), eth.ZeroEthAddr) {
+		addr, err := addresses.RegisterRelatedEthAddress(addressPreimages, relatedAccountAddresses, fromECAddr.Bytes())
+		if err != nil {
+			return nil, nil, fmt.Errorf("from: %w", err)
+		}
+		eventRelatedAddresses[addr] = struct{}{}
+		fromAddr = &addr
+	}
+	if !bytes.Equal(toECAddr.Bytes(), eth.ZeroEthAddr) {
+		addr, err := addresses.RegisterRelatedEthAddress(addressPreimages, relatedAccountAddresses, toECAddr.Bytes())
+		if err != nil {
+			return nil, nil, fmt.Errorf("to: %w", err)
+		}
+		eventRelatedAddresses[addr] = struct{}{}
+		toAddr = &addr
+	}
+	return fromAddr, toAddr, nil
+}
+
 func ExtractRound(blockHeader nodeapi.RuntimeBlockHeader, txrs []nodeapi.RuntimeTransactionWithResults, rawEvents []nodeapi.RuntimeEvent, sdkPT *sdkConfig.ParaTime, logger *log.Logger) (*BlockData, error) { //nolint:gocyclo
 	blockData := BlockData{
 		Header:              blockHeader,
@@ -224,10 +408,14 @@ func ExtractRound(blockHeader nodeapi.RuntimeBlockHeader, txrs []nodeapi.Runtime
 		EventData:           []*EventData{},
 		AddressPreimages:    map[apiTypes.Address]*addresses.PreimageData{},
 		TokenBalanceChanges: map[TokenChangeKey]*big.Int{},
+		BalanceChanges:      map[BalanceChangeKey]*big.Int{},
 		PossibleTokens:      map[apiTypes.Address]*evm.EVMPossibleToken{},
 		PossibleNFTs:        map[NFTKey]*PossibleNFT{},
+		NFTBalanceChanges:   map[NFTBalanceChangeKey]*big.Int{},
 		SwapCreations:       map[SwapCreationKey]*PossibleSwapCreation{},
 		SwapSyncs:           map[apiTypes.Address]*PossibleSwapSync{},
+		SwapVolumes:         map[apiTypes.Address]*SwapVolume{},
+		ProxyUpgrades:       map[apiTypes.Address]apiTypes.Address{},
 	}
 
 	// Extract info from non-tx events.
@@ -237,7 +425,7 @@ func ExtractRound(blockHeader nodeapi.RuntimeBlockHeader, txrs []nodeapi.Runtime
 			rawNonTxEvents = append(rawNonTxEvents, e)
 		}
 	}
-	nonTxEvents, err := extractEvents(&blockData, map[apiTypes.Address]struct{}{}, rawNonTxEvents)
+	nonTxEvents, err := extractEvents(&blockData, map[apiTypes.Address]struct{}{}, rawNonTxEvents, sdkPT)
 	if err != nil {
 		return nil, fmt.Errorf("extract non-tx events: %w", err)
 	}
@@ -521,7 +709,7 @@ func ExtractRound(blockHeader nodeapi.RuntimeBlockHeader, txrs []nodeapi.Runtime
 		for i, e := range txr.Events {
 			txEvents[i] = (nodeapi.RuntimeEvent)(*e)
 		}
-		extractedTxEvents, err := extractEvents(&blockData, blockTransactionData.RelatedAccountAddresses, txEvents)
+		extractedTxEvents, err := extractEvents(&blockData, blockTransactionData.RelatedAccountAddresses, txEvents, sdkPT)
 		if err != nil {
 			return nil, fmt.Errorf("tx %d: %w", txIndex, err)
 		}
@@ -661,7 +849,7 @@ func tryParseErrorMessage(errorModule string, errorCode uint32, msg string) *str
 	return &sanitizedMsg
 }
 
-func extractEvents(blockData *BlockData, relatedAccountAddresses map[apiTypes.Address]struct{}, eventsRaw []nodeapi.RuntimeEvent) ([]*EventData, error) { //nolint:gocyclo
+func extractEvents(blockData *BlockData, relatedAccountAddresses map[apiTypes.Address]struct{}, eventsRaw []nodeapi.RuntimeEvent, sdkPT *sdkConfig.ParaTime) ([]*EventData, error) { //nolint:gocyclo
 	extractedEvents := []*EventData{}
 	if err := VisitSdkEvents(eventsRaw, &SdkEventHandler{
 		Core: func(event *core.Event) error {
@@ -685,6 +873,10 @@ func extractEvents(blockData *BlockData, relatedAccountAddresses map[apiTypes.Ad
 				if err1 != nil {
 					return fmt.Errorf("to: %w", err1)
 				}
+				symbol := stringifyDenomination(sdkPT, event.Transfer.Amount.Denomination)
+				amount := event.Transfer.Amount.Amount.ToBigInt()
+				registerBalanceChange(blockData.BalanceChanges, fromAddr, symbol, (&big.Int{}).Neg(amount))
+				registerBalanceChange(blockData.BalanceChanges, toAddr, symbol, amount)
 				eventData := EventData{
 					Type:             apiTypes.RuntimeEventTypeAccountsTransfer,
 					Body:             event.Transfer,
@@ -698,6 +890,7 @@ func extractEvents(blockData *BlockData, relatedAccountAddresses map[apiTypes.Ad
 				if err1 != nil {
 					return fmt.Errorf("owner: %w", err1)
 				}
+				registerBalanceChange(blockData.BalanceChanges, ownerAddr, stringifyDenomination(sdkPT, event.Burn.Amount.Denomination), (&big.Int{}).Neg(event.Burn.Amount.Amount.ToBigInt()))
 				eventData := EventData{
 					Type:             apiTypes.RuntimeEventTypeAccountsBurn,
 					Body:             event.Burn,
@@ -711,6 +904,7 @@ func extractEvents(blockData *BlockData, relatedAccountAddresses map[apiTypes.Ad
 				if err1 != nil {
 					return fmt.Errorf("owner: %w", err1)
 				}
+				registerBalanceChange(blockData.BalanceChanges, ownerAddr, stringifyDenomination(sdkPT, event.Mint.Amount.Denomination), event.Mint.Amount.Amount.ToBigInt())
 				eventData := EventData{
 					Type:             apiTypes.RuntimeEventTypeAccountsMint,
 					Body:             event.Mint,
@@ -829,6 +1023,11 @@ func extractEvents(blockData *BlockData, relatedAccountAddresses map[apiTypes.Ad
 				ERC20Transfer: func(fromECAddr ethCommon.Address, toECAddr ethCommon.Address, value *big.Int) error {
 					fromZero := bytes.Equal(fromECAddr.Bytes(), eth.ZeroEthAddr)
 					toZero := bytes.Equal(toECAddr.Bytes(), eth.ZeroEthAddr)
+					transfer := TokenTransfer{
+						TokenAddress: eventAddr,
+						TokenType:    common.TokenTypeERC20,
+						Amount:       value,
+					}
 					if !fromZero {
 						fromAddr, err2 := addresses.RegisterRelatedEthAddress(blockData.AddressPreimages, relatedAccountAddresses, fromECAddr.Bytes())
 						if err2 != nil {
@@ -836,6 +1035,8 @@ func extractEvents(blockData *BlockData, relatedAccountAddresses map[apiTypes.Ad
 						}
 						eventData.RelatedAddresses[fromAddr] = struct{}{}
 						registerTokenDecrease(blockData.TokenBalanceChanges, eventAddr, fromAddr, value)
+						registerBalanceChange(blockData.BalanceChanges, fromAddr, string(eventAddr), (&big.Int{}).Neg(value))
+						transfer.From = &fromAddr
 					}
 					if !toZero {
 						toAddr, err2 := addresses.RegisterRelatedEthAddress(blockData.AddressPreimages, relatedAccountAddresses, toECAddr.Bytes())
@@ -844,7 +1045,10 @@ func extractEvents(blockData *BlockData, relatedAccountAddresses map[apiTypes.Ad
 						}
 						eventData.RelatedAddresses[toAddr] = struct{}{}
 						registerTokenIncrease(blockData.TokenBalanceChanges, eventAddr, toAddr, value)
+						registerBalanceChange(blockData.BalanceChanges, toAddr, string(eventAddr), value)
+						transfer.To = &toAddr
 					}
+					eventData.TokenTransfers = append(eventData.TokenTransfers, &transfer)
 					if _, ok := blockData.PossibleTokens[eventAddr]; !ok {
 						blockData.PossibleTokens[eventAddr] = &evm.EVMPossibleToken{}
 					}
@@ -965,6 +1169,19 @@ func extractEvents(blockData *BlockData, relatedAccountAddresses map[apiTypes.Ad
 					registerNFTExist(blockData.PossibleNFTs, eventAddr, tokenID)
 					// Mints, burns, and zero-value transfers all count as transfers.
 					registerNFTTransfer(blockData.PossibleNFTs, eventAddr, tokenID, toZero, toAddr)
+					transfer := TokenTransfer{
+						TokenAddress: eventAddr,
+						TokenType:    common.TokenTypeERC721,
+						TokenID:      tokenID,
+						Amount:       big.NewInt(1),
+					}
+					if !fromZero {
+						transfer.From = &fromAddr
+					}
+					if !toZero {
+						transfer.To = &toAddr
+					}
+					eventData.TokenTransfers = append(eventData.TokenTransfers, &transfer)
 					eventData.EvmLogName = common.Ptr(evmabi.ERC721.Events["Transfer"].Name)
 					eventData.EvmLogSignature = common.Ptr(ethCommon.BytesToHash(event.Topics[0]))
 					eventData.EvmLogParams = []*apiTypes.EvmAbiParam{
@@ -1069,6 +1286,127 @@ func extractEvents(blockData *BlockData, relatedAccountAddresses map[apiTypes.Ad
 					}
 					return nil
 				},
+				ERC1155TransferSingle: func(operatorECAddr ethCommon.Address, fromECAddr ethCommon.Address, toECAddr ethCommon.Address, id *big.Int, value *big.Int) error {
+					fromAddr, toAddr, err2 := registerMultiTokenTransferParties(blockData.AddressPreimages, relatedAccountAddresses, eventData.RelatedAddresses, operatorECAddr, fromECAddr, toECAddr)
+					if err2 != nil {
+						return err2
+					}
+					registerMultiTokenTransfer(blockData, eventAddr, id, value, fromAddr, toAddr)
+					eventData.TokenTransfers = append(eventData.TokenTransfers, &TokenTransfer{
+						TokenAddress: eventAddr,
+						TokenType:    common.TokenTypeERC1155,
+						From:         fromAddr,
+						To:           toAddr,
+						TokenID:      id,
+						Amount:       value,
+					})
+					eventData.EvmLogName = common.Ptr(evmabi.ERC1155.Events["TransferSingle"].Name)
+					eventData.EvmLogSignature = common.Ptr(ethCommon.BytesToHash(event.Topics[0]))
+					eventData.EvmLogParams = []*apiTypes.EvmAbiParam{
+						{
+							Name:    "operator",
+							EvmType: "address",
+							Value:   operatorECAddr,
+						},
+						{
+							Name:    "from",
+							EvmType: "address",
+							Value:   fromECAddr,
+						},
+						{
+							Name:    "to",
+							EvmType: "address",
+							Value:   toECAddr,
+						},
+						{
+							Name:    "id",
+							EvmType: "uint256",
+							// JSON supports encoding big integers, but many clients (javascript, jq, etc.)
+							// will incorrectly parse them as floats. So we encode uint256 as a string instead.
+							Value: id.String(),
+						},
+						{
+							Name:    "value",
+							EvmType: "uint256",
+							Value:   value.String(),
+						},
+					}
+					return nil
+				},
+				ERC1155TransferBatch: func(operatorECAddr ethCommon.Address, fromECAddr ethCommon.Address, toECAddr ethCommon.Address, ids []*big.Int, values []*big.Int) error {
+					fromAddr, toAddr, err2 := registerMultiTokenTransferParties(blockData.AddressPreimages, relatedAccountAddresses, eventData.RelatedAddresses, operatorECAddr, fromECAddr, toECAddr)
+					if err2 != nil {
+						return err2
+					}
+					idStrings := make([]string, 0, len(ids))
+					valueStrings := make([]string, 0, len(values))
+					for i := range ids {
+						registerMultiTokenTransfer(blockData, eventAddr, ids[i], values[i], fromAddr, toAddr)
+						eventData.TokenTransfers = append(eventData.TokenTransfers, &TokenTransfer{
+							TokenAddress: eventAddr,
+							TokenType:    common.TokenTypeERC1155,
+							From:         fromAddr,
+							To:           toAddr,
+							TokenID:      ids[i],
+							Amount:       values[i],
+						})
+						idStrings = append(idStrings, ids[i].String())
+						valueStrings = append(valueStrings, values[i].String())
+					}
+					eventData.EvmLogName = common.Ptr(evmabi.ERC1155.Events["TransferBatch"].Name)
+					eventData.EvmLogSignature = common.Ptr(ethCommon.BytesToHash(event.Topics[0]))
+					eventData.EvmLogParams = []*apiTypes.EvmAbiParam{
+						{
+							Name:    "operator",
+							EvmType: "address",
+							Value:   operatorECAddr,
+						},
+						{
+							Name:    "from",
+							EvmType: "address",
+							Value:   fromECAddr,
+						},
+						{
+							Name:    "to",
+							EvmType: "address",
+							Value:   toECAddr,
+						},
+						{
+							Name:    "ids",
+							EvmType: "uint256[]",
+							// JSON supports encoding big integers, but many clients (javascript, jq, etc.)
+							// will incorrectly parse them as floats. So we encode uint256 as a string instead.
+							Value: idStrings,
+						},
+						{
+							Name:    "values",
+							EvmType: "uint256[]",
+							Value:   valueStrings,
+						},
+					}
+					return nil
+				},
+				ERC1155URI: func(value string, id *big.Int) error {
+					if _, ok := blockData.PossibleTokens[eventAddr]; !ok {
+						blockData.PossibleTokens[eventAddr] = &evm.EVMPossibleToken{}
+					}
+					registerNFTExist(blockData.PossibleNFTs, eventAddr, id)
+					eventData.EvmLogName = common.Ptr(evmabi.ERC1155.Events["URI"].Name)
+					eventData.EvmLogSignature = common.Ptr(ethCommon.BytesToHash(event.Topics[0]))
+					eventData.EvmLogParams = []*apiTypes.EvmAbiParam{
+						{
+							Name:    "value",
+							EvmType: "string",
+							Value:   value,
+						},
+						{
+							Name:    "id",
+							EvmType: "uint256",
+							Value:   id.String(),
+						},
+					}
+					return nil
+				},
 				IUniswapV2FactoryPairCreated: func(token0ECAddr ethCommon.Address, token1ECAddr ethCommon.Address, pairECAddr ethCommon.Address, allPairsLength *big.Int) error {
 					token0Addr, err := addresses.RegisterRelatedEthAddress(blockData.AddressPreimages, relatedAccountAddresses, token0ECAddr.Bytes())
 					if err != nil {
@@ -1203,6 +1541,7 @@ func extractEvents(blockData *BlockData, relatedAccountAddresses map[apiTypes.Ad
 						return fmt.Errorf("to: %w", err)
 					}
 					eventData.RelatedAddresses[toAddr] = struct{}{}
+					registerSwapVolume(blockData.SwapVolumes, eventAddr, amount0In, amount1In, amount0Out, amount1Out)
 					eventData.EvmLogName = common.Ptr(evmabi.IUniswapV2Pair.Events["Swap"].Name)
 					eventData.EvmLogSignature = common.Ptr(ethCommon.BytesToHash(event.Topics[0]))
 					eventData.EvmLogParams = []*apiTypes.EvmAbiParam{
@@ -1272,6 +1611,251 @@ func extractEvents(blockData *BlockData, relatedAccountAddresses map[apiTypes.Ad
 					}
 					return nil
 				},
+				IUniswapV3FactoryPoolCreated: func(token0ECAddr ethCommon.Address, token1ECAddr ethCommon.Address, fee *big.Int, tickSpacing *big.Int, poolECAddr ethCommon.Address) error {
+					token0Addr, err := addresses.RegisterRelatedEthAddress(blockData.AddressPreimages, relatedAccountAddresses, token0ECAddr.Bytes())
+					if err != nil {
+						return fmt.Errorf("token0: %w", err)
+					}
+					eventData.RelatedAddresses[token0Addr] = struct{}{}
+					token1Addr, err := addresses.RegisterRelatedEthAddress(blockData.AddressPreimages, relatedAccountAddresses, token1ECAddr.Bytes())
+					if err != nil {
+						return fmt.Errorf("token1: %w", err)
+					}
+					eventData.RelatedAddresses[token1Addr] = struct{}{}
+					poolAddr, err := addresses.RegisterRelatedEthAddress(blockData.AddressPreimages, relatedAccountAddresses, poolECAddr.Bytes())
+					if err != nil {
+						return fmt.Errorf("pool: %w", err)
+					}
+					eventData.RelatedAddresses[poolAddr] = struct{}{}
+					blockData.SwapCreations[SwapCreationKey{
+						Factory: eventAddr,
+						Token0:  token0Addr,
+						Token1:  token1Addr,
+						Fee:     uint32(fee.Uint64()),
+					}] = &PossibleSwapCreation{
+						Pair: poolAddr,
+					}
+					eventData.EvmLogName = common.Ptr(evmabi.IUniswapV3Factory.Events["PoolCreated"].Name)
+					eventData.EvmLogSignature = common.Ptr(ethCommon.BytesToHash(event.Topics[0]))
+					eventData.EvmLogParams = []*apiTypes.EvmAbiParam{
+						{
+							Name:    "token0",
+							EvmType: "address",
+							Value:   token0ECAddr,
+						},
+						{
+							Name:    "token1",
+							EvmType: "address",
+							Value:   token1ECAddr,
+						},
+						{
+							Name:    "fee",
+							EvmType: "uint24",
+							// JSON supports encoding big integers, but many clients (javascript, jq, etc.)
+							// will incorrectly parse them as floats. So we encode uint256 as a string instead.
+							Value: fee.String(),
+						},
+						{
+							Name:    "tickSpacing",
+							EvmType: "int24",
+							// JSON supports encoding big integers, but many clients (javascript, jq, etc.)
+							// will incorrectly parse them as floats. So we encode uint256 as a string instead.
+							Value: tickSpacing.String(),
+						},
+						{
+							Name:    "pool",
+							EvmType: "address",
+							Value:   poolECAddr,
+						},
+					}
+					return nil
+				},
+				IUniswapV3PoolMint: func(senderECAddr ethCommon.Address, ownerECAddr ethCommon.Address, tickLower *big.Int, tickUpper *big.Int, amount *big.Int, amount0 *big.Int, amount1 *big.Int) error {
+					senderAddr, err := addresses.RegisterRelatedEthAddress(blockData.AddressPreimages, relatedAccountAddresses, senderECAddr.Bytes())
+					if err != nil {
+						return fmt.Errorf("sender: %w", err)
+					}
+					eventData.RelatedAddresses[senderAddr] = struct{}{}
+					ownerAddr, err := addresses.RegisterRelatedEthAddress(blockData.AddressPreimages, relatedAccountAddresses, ownerECAddr.Bytes())
+					if err != nil {
+						return fmt.Errorf("owner: %w", err)
+					}
+					eventData.RelatedAddresses[ownerAddr] = struct{}{}
+					eventData.EvmLogName = common.Ptr(evmabi.IUniswapV3Pool.Events["Mint"].Name)
+					eventData.EvmLogSignature = common.Ptr(ethCommon.BytesToHash(event.Topics[0]))
+					eventData.EvmLogParams = []*apiTypes.EvmAbiParam{
+						{
+							Name:    "sender",
+							EvmType: "address",
+							Value:   senderECAddr,
+						},
+						{
+							Name:    "owner",
+							EvmType: "address",
+							Value:   ownerECAddr,
+						},
+						{
+							Name:    "tickLower",
+							EvmType: "int24",
+							// JSON supports encoding big integers, but many clients (javascript, jq, etc.)
+							// will incorrectly parse them as floats. So we encode uint256 as a string instead.
+							Value: tickLower.String(),
+						},
+						{
+							Name:    "tickUpper",
+							EvmType: "int24",
+							// JSON supports encoding big integers, but many clients (javascript, jq, etc.)
+							// will incorrectly parse them as floats. So we encode uint256 as a string instead.
+							Value: tickUpper.String(),
+						},
+						{
+							Name:    "amount",
+							EvmType: "uint128",
+							// JSON supports encoding big integers, but many clients (javascript, jq, etc.)
+							// will incorrectly parse them as floats. So we encode uint256 as a string instead.
+							Value: amount.String(),
+						},
+						{
+							Name:    "amount0",
+							EvmType: "uint256",
+							// JSON supports encoding big integers, but many clients (javascript, jq, etc.)
+							// will incorrectly parse them as floats. So we encode uint256 as a string instead.
+							Value: amount0.String(),
+						},
+						{
+							Name:    "amount1",
+							EvmType: "uint256",
+							// JSON supports encoding big integers, but many clients (javascript, jq, etc.)
+							// will incorrectly parse them as floats. So we encode uint256 as a string instead.
+							Value: amount1.String(),
+						},
+					}
+					return nil
+				},
+				IUniswapV3PoolBurn: func(ownerECAddr ethCommon.Address, tickLower *big.Int, tickUpper *big.Int, amount *big.Int, amount0 *big.Int, amount1 *big.Int) error {
+					ownerAddr, err := addresses.RegisterRelatedEthAddress(blockData.AddressPreimages, relatedAccountAddresses, ownerECAddr.Bytes())
+					if err != nil {
+						return fmt.Errorf("owner: %w", err)
+					}
+					eventData.RelatedAddresses[ownerAddr] = struct{}{}
+					eventData.EvmLogName = common.Ptr(evmabi.IUniswapV3Pool.Events["Burn"].Name)
+					eventData.EvmLogSignature = common.Ptr(ethCommon.BytesToHash(event.Topics[0]))
+					eventData.EvmLogParams = []*apiTypes.EvmAbiParam{
+						{
+							Name:    "owner",
+							EvmType: "address",
+							Value:   ownerECAddr,
+						},
+						{
+							Name:    "tickLower",
+							EvmType: "int24",
+							// JSON supports encoding big integers, but many clients (javascript, jq, etc.)
+							// will incorrectly parse them as floats. So we encode uint256 as a string instead.
+							Value: tickLower.String(),
+						},
+						{
+							Name:    "tickUpper",
+							EvmType: "int24",
+							// JSON supports encoding big integers, but many clients (javascript, jq, etc.)
+							// will incorrectly parse them as floats. So we encode uint256 as a string instead.
+							Value: tickUpper.String(),
+						},
+						{
+							Name:    "amount",
+							EvmType: "uint128",
+							// JSON supports encoding big integers, but many clients (javascript, jq, etc.)
+							// will incorrectly parse them as floats. So we encode uint256 as a string instead.
+							Value: amount.String(),
+						},
+						{
+							Name:    "amount0",
+							EvmType: "uint256",
+							// JSON supports encoding big integers, but many clients (javascript, jq, etc.)
+							// will incorrectly parse them as floats. So we encode uint256 as a string instead.
+							Value: amount0.String(),
+						},
+						{
+							Name:    "amount1",
+							EvmType: "uint256",
+							// JSON supports encoding big integers, but many clients (javascript, jq, etc.)
+							// will incorrectly parse them as floats. So we encode uint256 as a string instead.
+							Value: amount1.String(),
+						},
+					}
+					return nil
+				},
+				IUniswapV3PoolSwap: func(senderECAddr ethCommon.Address, recipientECAddr ethCommon.Address, amount0 *big.Int, amount1 *big.Int, sqrtPriceX96 *big.Int, liquidity *big.Int, tick *big.Int) error {
+					senderAddr, err := addresses.RegisterRelatedEthAddress(blockData.AddressPreimages, relatedAccountAddresses, senderECAddr.Bytes())
+					if err != nil {
+						return fmt.Errorf("sender: %w", err)
+					}
+					eventData.RelatedAddresses[senderAddr] = struct{}{}
+					recipientAddr, err := addresses.RegisterRelatedEthAddress(blockData.AddressPreimages, relatedAccountAddresses, recipientECAddr.Bytes())
+					if err != nil {
+						return fmt.Errorf("recipient: %w", err)
+					}
+					eventData.RelatedAddresses[recipientAddr] = struct{}{}
+					amount0In, amount0Out := uniswapV3SwapAmounts(amount0)
+					amount1In, amount1Out := uniswapV3SwapAmounts(amount1)
+					registerSwapVolume(blockData.SwapVolumes, eventAddr, amount0In, amount1In, amount0Out, amount1Out)
+					// V3 pools don't emit Sync events; the price after each swap is in the Swap event.
+					reserve0, reserve1 := uniswapV3VirtualReserves(sqrtPriceX96, liquidity)
+					blockData.SwapSyncs[eventAddr] = &PossibleSwapSync{
+						Reserve0:     reserve0,
+						Reserve1:     reserve1,
+						SqrtPriceX96: sqrtPriceX96,
+					}
+					eventData.EvmLogName = common.Ptr(evmabi.IUniswapV3Pool.Events["Swap"].Name)
+					eventData.EvmLogSignature = common.Ptr(ethCommon.BytesToHash(event.Topics[0]))
+					eventData.EvmLogParams = []*apiTypes.EvmAbiParam{
+						{
+							Name:    "sender",
+							EvmType: "address",
+							Value:   senderECAddr,
+						},
+						{
+							Name:    "recipient",
+							EvmType: "address",
+							Value:   recipientECAddr,
+						},
+						{
+							Name:    "amount0",
+							EvmType: "int256",
+							// JSON supports encoding big integers, but many clients (javascript, jq, etc.)
+							// will incorrectly parse them as floats. So we encode uint256 as a string instead.
+							Value: amount0.String(),
+						},
+						{
+							Name:    "amount1",
+							EvmType: "int256",
+							// JSON supports encoding big integers, but many clients (javascript, jq, etc.)
+							// will incorrectly parse them as floats. So we encode uint256 as a string instead.
+							Value: amount1.String(),
+						},
+						{
+							Name:    "sqrtPriceX96",
+							EvmType: "uint160",
+							// JSON supports encoding big integers, but many clients (javascript, jq, etc.)
+							// will incorrectly parse them as floats. So we encode uint256 as a string instead.
+							Value: sqrtPriceX96.String(),
+						},
+						{
+							Name:    "liquidity",
+							EvmType: "uint128",
+							// JSON supports encoding big integers, but many clients (javascript, jq, etc.)
+							// will incorrectly parse them as floats. So we encode uint256 as a string instead.
+							Value: liquidity.String(),
+						},
+						{
+							Name:    "tick",
+							EvmType: "int24",
+							// JSON supports encoding big integers, but many clients (javascript, jq, etc.)
+							// will incorrectly parse them as floats. So we encode uint256 as a string instead.
+							Value: tick.String(),
+						},
+					}
+					return nil
+				},
 				WROSEDeposit: func(ownerECAddr ethCommon.Address, amount *big.Int) error {
 					wrapperAddr := eventAddr // the WROSE wrapper contract is implicitly the address that emitted the contract
 
@@ -1281,6 +1865,7 @@ func extractEvents(blockData *BlockData, relatedAccountAddresses map[apiTypes.Ad
 					}
 					eventData.RelatedAddresses[ownerAddr] = struct{}{}
 					registerTokenIncrease(blockData.TokenBalanceChanges, wrapperAddr, ownerAddr, amount)
+					registerBalanceChange(blockData.BalanceChanges, ownerAddr, string(wrapperAddr), amount)
 					registerTokenIncrease(blockData.TokenBalanceChanges, evm.NativeRuntimeTokenAddress, wrapperAddr, amount)
 					registerTokenDecrease(blockData.TokenBalanceChanges, evm.NativeRuntimeTokenAddress, ownerAddr, amount)
 
@@ -1328,6 +1913,7 @@ func extractEvents(blockData *BlockData, relatedAccountAddresses map[apiTypes.Ad
 					}
 					eventData.RelatedAddresses[ownerAddr] = struct{}{}
 					registerTokenDecrease(blockData.TokenBalanceChanges, wrapperAddr, ownerAddr, amount)
+					registerBalanceChange(blockData.BalanceChanges, ownerAddr, string(wrapperAddr), (&big.Int{}).Neg(amount))
 					registerTokenIncrease(blockData.TokenBalanceChanges, evm.NativeRuntimeTokenAddress, ownerAddr, amount)
 					registerTokenDecrease(blockData.TokenBalanceChanges, evm.NativeRuntimeTokenAddress, wrapperAddr, amount)
 
@@ -1353,6 +1939,26 @@ func extractEvents(blockData *BlockData, relatedAccountAddresses map[apiTypes.Ad
 					}
 					return nil
 				},
+				IERC1967Upgraded: func(implementationECAddr ethCommon.Address) error {
+					implementationAddr, err2 := addresses.RegisterRelatedEthAddress(blockData.AddressPreimages, relatedAccountAddresses, implementationECAddr.Bytes())
+					if err2 != nil {
+						return fmt.Errorf("implementation: %w", err2)
+					}
+					eventData.RelatedAddresses[implementationAddr] = struct{}{}
+					// Events are visited in order; the last upgrade in the round wins.
+					blockData.ProxyUpgrades[eventAddr] = implementationAddr
+
+					eventData.EvmLogName = common.Ptr(evmabi.IERC1967.Events["Upgraded"].Name)
+					eventData.EvmLogSignature = common.Ptr(ethCommon.BytesToHash(event.Topics[0]))
+					eventData.EvmLogParams = []*apiTypes.EvmAbiParam{
+						{
+							Name:    "implementation",
+							EvmType: "address",
+							Value:   implementationECAddr,
+						},
+					}
+					return nil
+				},
 			}); err1 != nil {
 				return err1
 			}
@@ -2,13 +2,23 @@ package runtime
 
 import (
 	"encoding/base64"
+	"math/big"
 	"testing"
 
+	ethCommon "github.com/ethereum/go-ethereum/common"
 	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
 	sdkConfig "github.com/oasisprotocol/oasis-sdk/client-sdk/go/config"
+	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/accounts"
+	sdkEVM "github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/evm"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/types"
 	"github.com/stretchr/testify/require"
 
+	"github.com/oasisprotocol/nexus/analyzer/evmabi"
+	"github.com/oasisprotocol/nexus/analyzer/runtime/evm"
+	"github.com/oasisprotocol/nexus/analyzer/util/eth"
+	apiTypes "github.com/oasisprotocol/nexus/api/v1/types"
 	"github.com/oasisprotocol/nexus/common"
 	"github.com/oasisprotocol/nexus/log"
 	"github.com/oasisprotocol/nexus/storage/oasis/nodeapi"
@@ -281,3 +291,347 @@ func verifyTxData(t *testing.T, expected *mockTxData, actual *BlockTransactionDa
 		require.Nil(t, actual.Error)
 	}
 }
+
+func TestVisitERC1155TransferBatch(t *testing.T) {
+	transferBatch := evmabi.ERC1155.Events["TransferBatch"]
+	operator := ethCommon.HexToAddress("0x1111111111111111111111111111111111111111")
+	to := ethCommon.HexToAddress("0x2222222222222222222222222222222222222222")
+	ids := []*big.Int{big.NewInt(1), big.NewInt(7)}
+	values := []*big.Int{big.NewInt(10), big.NewInt(3)}
+	data, err := transferBatch.Inputs.NonIndexed().Pack(ids, values)
+	require.NoError(t, err)
+	event := sdkEVM.Event{
+		Address: ethCommon.HexToAddress("0x3333333333333333333333333333333333333333").Bytes(),
+		Topics: [][]byte{
+			transferBatch.ID.Bytes(),
+			ethCommon.BytesToHash(operator.Bytes()).Bytes(),
+			ethCommon.BytesToHash(eth.ZeroEthAddr).Bytes(), // Mint.
+			ethCommon.BytesToHash(to.Bytes()).Bytes(),
+		},
+		Data: data,
+	}
+
+	called := false
+	require.NoError(t, VisitEVMEvent(&event, &EVMEventHandler{
+		ERC1155TransferBatch: func(gotOperator ethCommon.Address, gotFrom ethCommon.Address, gotTo ethCommon.Address, gotIDs []*big.Int, gotValues []*big.Int) error {
+			called = true
+			require.Equal(t, operator, gotOperator)
+			require.Equal(t, ethCommon.Address{}, gotFrom)
+			require.Equal(t, to, gotTo)
+			require.Equal(t, ids, gotIDs)
+			require.Equal(t, values, gotValues)
+			return nil
+		},
+	}))
+	require.True(t, called)
+}
+
+func TestRegisterMultiTokenTransfer(t *testing.T) {
+	blockData := BlockData{
+		TokenBalanceChanges: map[TokenChangeKey]*big.Int{},
+		PossibleTokens:      map[apiTypes.Address]*evm.EVMPossibleToken{},
+		PossibleNFTs:        map[NFTKey]*PossibleNFT{},
+		NFTBalanceChanges:   map[NFTBalanceChangeKey]*big.Int{},
+	}
+	token := apiTypes.Address("oasis1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqtoken")
+	alice := apiTypes.Address("oasis1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqalice")
+	bob := apiTypes.Address("oasis1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqbob")
+
+	// Mint 10 to alice, then transfer 4 from alice to bob. Each event decodes its own copy of the ID.
+	registerMultiTokenTransfer(&blockData, token, big.NewInt(5), big.NewInt(10), nil, &alice)
+	registerMultiTokenTransfer(&blockData, token, big.NewInt(5), big.NewInt(4), &alice, &bob)
+
+	require.Equal(t, big.NewInt(6), blockData.TokenBalanceChanges[TokenChangeKey{token, alice}])
+	require.Equal(t, big.NewInt(4), blockData.TokenBalanceChanges[TokenChangeKey{token, bob}])
+	require.Len(t, blockData.NFTBalanceChanges, 2)
+	require.Equal(t, big.NewInt(6), blockData.NFTBalanceChanges[NFTBalanceChangeKey{token, "5", alice}])
+	require.Equal(t, big.NewInt(4), blockData.NFTBalanceChanges[NFTBalanceChangeKey{token, "5", bob}])
+	possibleToken := blockData.PossibleTokens[token]
+	require.Equal(t, uint64(2), possibleToken.NumTransfersChange)
+	require.Equal(t, big.NewInt(10), &possibleToken.TotalSupplyChange)
+	require.True(t, possibleToken.Mutated)
+	possibleNFT := blockData.PossibleNFTs[NFTKey{token, "5"}]
+	require.Equal(t, 2, possibleNFT.NumTransfers)
+	require.True(t, possibleNFT.MultiOwner)
+	require.Len(t, blockData.PossibleNFTs, 1)
+}
+
+func TestRegisterSwapVolume(t *testing.T) {
+	swapVolumes := map[apiTypes.Address]*SwapVolume{}
+	pair := apiTypes.Address("oasis1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqpair")
+
+	// Swap 10 token0 for 3 token1, then 2 token1 for 5 token0.
+	registerSwapVolume(swapVolumes, pair, big.NewInt(10), big.NewInt(0), big.NewInt(0), big.NewInt(3))
+	registerSwapVolume(swapVolumes, pair, big.NewInt(0), big.NewInt(2), big.NewInt(5), big.NewInt(0))
+
+	require.Equal(t, big.NewInt(15), swapVolumes[pair].Amount0)
+	require.Equal(t, big.NewInt(5), swapVolumes[pair].Amount1)
+}
+
+func TestVisitUniswapV3PoolSwap(t *testing.T) {
+	swap := evmabi.IUniswapV3Pool.Events["Swap"]
+	sender := ethCommon.HexToAddress("0x1111111111111111111111111111111111111111")
+	recipient := ethCommon.HexToAddress("0x2222222222222222222222222222222222222222")
+	sqrtPriceX96 := new(big.Int).Lsh(big.NewInt(2), 96)
+	data, err := swap.Inputs.NonIndexed().Pack(big.NewInt(10), big.NewInt(-38), sqrtPriceX96, big.NewInt(1000), big.NewInt(-13863))
+	require.NoError(t, err)
+	event := sdkEVM.Event{
+		Address: ethCommon.HexToAddress("0x3333333333333333333333333333333333333333").Bytes(),
+		Topics: [][]byte{
+			swap.ID.Bytes(),
+			ethCommon.BytesToHash(sender.Bytes()).Bytes(),
+			ethCommon.BytesToHash(recipient.Bytes()).Bytes(),
+		},
+		Data: data,
+	}
+
+	called := false
+	require.NoError(t, VisitEVMEvent(&event, &EVMEventHandler{
+		IUniswapV2PairSwap: func(_ ethCommon.Address, _ *big.Int, _ *big.Int, _ *big.Int, _ *big.Int, _ ethCommon.Address) error {
+			t.Fatal("uniswap v3 swap visited as a v2 swap")
+			return nil
+		},
+		IUniswapV3PoolSwap: func(gotSender ethCommon.Address, gotRecipient ethCommon.Address, amount0 *big.Int, amount1 *big.Int, gotSqrtPriceX96 *big.Int, liquidity *big.Int, tick *big.Int) error {
+			called = true
+			require.Equal(t, sender, gotSender)
+			require.Equal(t, recipient, gotRecipient)
+			require.Equal(t, big.NewInt(10), amount0)
+			require.Equal(t, big.NewInt(-38), amount1)
+			require.Equal(t, sqrtPriceX96, gotSqrtPriceX96)
+			require.Equal(t, big.NewInt(1000), liquidity)
+			require.Equal(t, big.NewInt(-13863), tick)
+			return nil
+		},
+	}))
+	require.True(t, called)
+}
+
+func TestUniswapV3SwapAmounts(t *testing.T) {
+	amountIn, amountOut := uniswapV3SwapAmounts(big.NewInt(10))
+	require.Equal(t, big.NewInt(10), amountIn)
+	require.Zero(t, amountOut.Sign())
+	amountIn, amountOut = uniswapV3SwapAmounts(big.NewInt(-38))
+	require.Zero(t, amountIn.Sign())
+	require.Equal(t, big.NewInt(38), amountOut)
+}
+
+func TestUniswapV3VirtualReserves(t *testing.T) {
+	// A price of 4 token1 per token0.
+	sqrtPriceX96 := new(big.Int).Lsh(big.NewInt(2), 96)
+	reserve0, reserve1 := uniswapV3VirtualReserves(sqrtPriceX96, big.NewInt(1000))
+	require.Equal(t, big.NewInt(500), reserve0)
+	require.Equal(t, big.NewInt(2000), reserve1)
+
+	// An uninitialized pool.
+	reserve0, reserve1 = uniswapV3VirtualReserves(&big.Int{}, big.NewInt(1000))
+	require.Zero(t, reserve0.Sign())
+	require.Zero(t, reserve1.Sign())
+}
+
+func TestExtractBalanceChanges(t *testing.T) {
+	alice := types.NewAddressForModule("test", []byte("alice"))
+	bob := types.NewAddressForModule("test", []byte("bob"))
+	native := func(amount int64) types.BaseUnits {
+		return types.NewBaseUnits(*quantity.NewFromUint64(uint64(amount)), types.NativeDenomination)
+	}
+	rawEvents := []nodeapi.RuntimeEvent{
+		{
+			Module: accounts.ModuleName,
+			Code:   accounts.MintEventCode,
+			Value:  cbor.Marshal(accounts.MintEvent{Owner: alice, Amount: native(10)}),
+			TxHash: &hash.Hash{},
+		},
+		{
+			Module: accounts.ModuleName,
+			Code:   accounts.TransferEventCode,
+			Value:  cbor.Marshal(accounts.TransferEvent{From: alice, To: bob, Amount: native(4)}),
+			TxHash: &hash.Hash{},
+		},
+		{
+			Module: accounts.ModuleName,
+			Code:   accounts.BurnEventCode,
+			Value:  cbor.Marshal(accounts.BurnEvent{Owner: bob, Amount: native(1)}),
+			TxHash: &hash.Hash{},
+		},
+	}
+	blockData, err := ExtractRound(nodeapi.RuntimeBlockHeader{}, nil, rawEvents, sapphireParatime, log.NewDefaultLogger("testing"))
+	require.NoError(t, err)
+
+	require.Len(t, blockData.BalanceChanges, 2)
+	require.Equal(t, "6", blockData.BalanceChanges[BalanceChangeKey{apiTypes.Address(alice.String()), "ROSE"}].String())
+	require.Equal(t, "3", blockData.BalanceChanges[BalanceChangeKey{apiTypes.Address(bob.String()), "ROSE"}].String())
+}
+
+func TestExtractTokenTransfers(t *testing.T) {
+	token := ethCommon.HexToAddress("0x3333333333333333333333333333333333333333")
+	alice := ethCommon.HexToAddress("0x1111111111111111111111111111111111111111")
+	bob := ethCommon.HexToAddress("0x2222222222222222222222222222222222222222")
+	erc20Transfer := evmabi.ERC20.Events["Transfer"]
+	erc20Data, err := erc20Transfer.Inputs.NonIndexed().Pack(big.NewInt(10))
+	require.NoError(t, err)
+	transferBatch := evmabi.ERC1155.Events["TransferBatch"]
+	batchData, err := transferBatch.Inputs.NonIndexed().Pack([]*big.Int{big.NewInt(1), big.NewInt(7)}, []*big.Int{big.NewInt(5), big.NewInt(3)})
+	require.NoError(t, err)
+	rawEvents := []nodeapi.RuntimeEvent{
+		{
+			Module: sdkEVM.ModuleName,
+			Code:   1,
+			Value: cbor.Marshal(sdkEVM.Event{
+				Address: token.Bytes(),
+				Topics: [][]byte{
+					erc20Transfer.ID.Bytes(),
+					ethCommon.BytesToHash(eth.ZeroEthAddr).Bytes(), // Mint.
+					ethCommon.BytesToHash(alice.Bytes()).Bytes(),
+				},
+				Data: erc20Data,
+			}),
+			TxHash: &hash.Hash{},
+		},
+		{
+			Module: sdkEVM.ModuleName,
+			Code:   1,
+			Value: cbor.Marshal(sdkEVM.Event{
+				Address: token.Bytes(),
+				Topics: [][]byte{
+					transferBatch.ID.Bytes(),
+					ethCommon.BytesToHash(alice.Bytes()).Bytes(),
+					ethCommon.BytesToHash(alice.Bytes()).Bytes(),
+					ethCommon.BytesToHash(bob.Bytes()).Bytes(),
+				},
+				Data: batchData,
+			}),
+			TxHash: &hash.Hash{},
+		},
+	}
+	blockData, err := ExtractRound(nodeapi.RuntimeBlockHeader{}, nil, rawEvents, sapphireParatime, log.NewDefaultLogger("testing"))
+	require.NoError(t, err)
+	require.Len(t, blockData.EventData, 2)
+
+	mints := blockData.EventData[0].TokenTransfers
+	require.Len(t, mints, 1)
+	require.Equal(t, common.TokenTypeERC20, mints[0].TokenType)
+	require.Nil(t, mints[0].From)
+	require.NotNil(t, mints[0].To)
+	require.Nil(t, mints[0].TokenID)
+	require.Equal(t, big.NewInt(10), mints[0].Amount)
+
+	batch := blockData.EventData[1].TokenTransfers
+	require.Len(t, batch, 2)
+	for i, expected := range []struct{ id, amount int64 }{{1, 5}, {7, 3}} {
+		require.Equal(t, common.TokenTypeERC1155, batch[i].TokenType)
+		require.Equal(t, mints[0].To, batch[i].From)
+		require.NotEqual(t, mints[0].To, batch[i].To)
+		require.Equal(t, big.NewInt(expected.id), batch[i].TokenID)
+		require.Equal(t, big.NewInt(expected.amount), batch[i].Amount)
+	}
+}
+
+func TestVisitERC1967Upgraded(t *testing.T) {
+	upgraded := evmabi.IERC1967.Events["Upgraded"]
+	implementation := ethCommon.HexToAddress("0x4444444444444444444444444444444444444444")
+	event := sdkEVM.Event{
+		Address: ethCommon.HexToAddress("0x5555555555555555555555555555555555555555").Bytes(),
+		Topics: [][]byte{
+			upgraded.ID.Bytes(),
+			ethCommon.BytesToHash(implementation.Bytes()).Bytes(),
+		},
+	}
+
+	called := false
+	require.NoError(t, VisitEVMEvent(&event, &EVMEventHandler{
+		IERC1967Upgraded: func(gotImplementation ethCommon.Address) error {
+			called = true
+			require.Equal(t, implementation, gotImplementation)
+			return nil
+		},
+	}))
+	require.True(t, called)
+}
+
+func TestEventsInNumberingOrder(t *testing.T) {
+	nonTx1 := &EventData{Type: "core.gas_used"}
+	nonTx2 := &EventData{Type: "consensus_accounts.deposit"}
+	tx0 := &EventData{TxIndex: common.Ptr(0), Type: "accounts.transfer"}
+	tx1a := &EventData{TxIndex: common.Ptr(1), Type: "evm.log"}
+	tx1b := &EventData{TxIndex: common.Ptr(1), Type: "accounts.transfer"}
+
+	// ExtractRound collects the events without a transaction first.
+	ordered := eventsInNumberingOrder([]*EventData{nonTx1, nonTx2, tx0, tx1a, tx1b})
+	require.Equal(t, []*EventData{tx0, tx1a, tx1b, nonTx1, nonTx2}, ordered)
+}
+
+// TestExtractSwapSyncs tests that a round with two swaps in a Uniswap V2 pair
+// records the reserves after the last swap and the total amounts swapped.
+func TestExtractSwapSyncs(t *testing.T) {
+	pair := ethCommon.HexToAddress("0x4444444444444444444444444444444444444444")
+	router := ethCommon.HexToAddress("0x5555555555555555555555555555555555555555")
+	alice := ethCommon.HexToAddress("0x1111111111111111111111111111111111111111")
+	bigInt := func(s string) *big.Int {
+		n, ok := new(big.Int).SetString(s, 10)
+		require.True(t, ok)
+		return n
+	}
+	swap := evmabi.IUniswapV2Pair.Events["Swap"]
+	sync := evmabi.IUniswapV2Pair.Events["Sync"]
+	swapEvent := func(amount0In, amount1In, amount0Out, amount1Out *big.Int) nodeapi.RuntimeEvent {
+		data, err := swap.Inputs.NonIndexed().Pack(amount0In, amount1In, amount0Out, amount1Out)
+		require.NoError(t, err)
+		return nodeapi.RuntimeEvent{
+			Module: sdkEVM.ModuleName,
+			Code:   1,
+			Value: cbor.Marshal(sdkEVM.Event{
+				Address: pair.Bytes(),
+				Topics: [][]byte{
+					swap.ID.Bytes(),
+					ethCommon.BytesToHash(router.Bytes()).Bytes(),
+					ethCommon.BytesToHash(alice.Bytes()).Bytes(),
+				},
+				Data: data,
+			}),
+			TxHash: &hash.Hash{},
+		}
+	}
+	syncEvent := func(reserve0, reserve1 *big.Int) nodeapi.RuntimeEvent {
+		data, err := sync.Inputs.NonIndexed().Pack(reserve0, reserve1)
+		require.NoError(t, err)
+		return nodeapi.RuntimeEvent{
+			Module: sdkEVM.ModuleName,
+			Code:   1,
+			Value: cbor.Marshal(sdkEVM.Event{
+				Address: pair.Bytes(),
+				Topics:  [][]byte{sync.ID.Bytes()},
+				Data:    data,
+			}),
+			TxHash: &hash.Hash{},
+		}
+	}
+
+	// A pair of an 18-decimal token0 and a 6-decimal token1, with reserves of
+	// 1,000,000 token0 and 50,000 token1. First 1,000 token0 are swapped for
+	// token1, then 100 token1 for token0, with the 0.3% fee of Uniswap V2.
+	rawEvents := []nodeapi.RuntimeEvent{
+		swapEvent(bigInt("1000000000000000000000"), big.NewInt(0), big.NewInt(0), big.NewInt(49800349)),
+		syncEvent(bigInt("1001000000000000000000000"), big.NewInt(49950199651)),
+		swapEvent(big.NewInt(0), big.NewInt(100000000), bigInt("1994003997928215546423"), big.NewInt(0)),
+		syncEvent(bigInt("999005996002071784453577"), big.NewInt(50050199651)),
+	}
+	blockData, err := ExtractRound(nodeapi.RuntimeBlockHeader{}, nil, rawEvents, sapphireParatime, log.NewDefaultLogger("testing"))
+	require.NoError(t, err)
+	require.Len(t, blockData.EventData, 4)
+
+	require.Len(t, blockData.SwapSyncs, 1)
+	require.Len(t, blockData.SwapVolumes, 1)
+	for pairAddr, swapSync := range blockData.SwapSyncs {
+		require.Equal(t, bigInt("999005996002071784453577"), swapSync.Reserve0)
+		require.Equal(t, big.NewInt(50050199651), swapSync.Reserve1)
+
+		// The price of token0 in whole units of token1.
+		price, _ := new(big.Float).Quo(new(big.Float).SetInt(swapSync.Reserve1), new(big.Float).SetInt(swapSync.Reserve0)).Float64()
+		require.InDelta(t, 0.0501, price*1e12, 0.0001)
+
+		volume := blockData.SwapVolumes[pairAddr]
+		require.NotNil(t, volume)
+		require.Equal(t, bigInt("2994003997928215546423"), volume.Amount0)
+		require.Equal(t, big.NewInt(149800349), volume.Amount1)
+	}
+}
@@ -23,6 +23,7 @@ func OasisMaybeUnmarshalEncryptedData(call *sdkTypes.Call, callResult *sdkTypes.
 			return nil, fmt.Errorf("outer call format %s unmarshal body: %w", call.Format, err)
 		}
 		encryptedData.PublicKey = callEnvelope.Pk[:]
+		encryptedData.Epoch = callEnvelope.Epoch
 		encryptedData.DataNonce = callEnvelope.Nonce[:]
 		encryptedData.DataData = callEnvelope.Data
 	// Plain txs have no encrypted fields to extract.
@@ -0,0 +1,228 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oasisprotocol/nexus/analyzer"
+	"github.com/oasisprotocol/nexus/analyzer/queries"
+	"github.com/oasisprotocol/nexus/storage"
+	"github.com/oasisprotocol/nexus/storage/oasis/nodeapi"
+)
+
+// maxUnwindRounds is the maximum number of rounds that we can unwind after a reorg,
+// i.e. the number of most recent rounds for which we keep the undo log.
+// A deeper reorg requires manual intervention (re-indexing the runtime).
+const maxUnwindRounds = 100
+
+// checkReorg checks whether the block with the given header builds on the
+// previously indexed round. If not, it unwinds the indexed rounds since the
+// fork, and returns analyzer.ErrReorg to signal that they need to be
+// re-processed.
+//
+// Rounds processed in fast-sync mode are historical and assumed final, so
+// this is a no-op in fast-sync mode.
+func (m *processor) checkReorg(ctx context.Context, header *nodeapi.RuntimeBlockHeader) error {
+	if m.mode == analyzer.FastSyncMode || header.Round == 0 {
+		return nil
+	}
+	var prevHash string
+	switch err := m.target.QueryRow(ctx, queries.RuntimeBlockHash, m.runtime, header.Round-1).Scan(&prevHash); err {
+	case nil:
+	case storage.ErrNoRows:
+		// The previous round was not indexed, e.g. because this is the first round of the range.
+		return nil
+	default:
+		return fmt.Errorf("fetching hash of round %d: %w", header.Round-1, err)
+	}
+	if prevHash == header.PreviousHash.Hex() {
+		return nil
+	}
+
+	forkRound, err := m.findForkRound(ctx, header.Round-1)
+	if err != nil {
+		return fmt.Errorf("round %d does not build on indexed round %d: %w", header.Round, header.Round-1, err)
+	}
+	m.logger.Warn("indexed rounds are not part of the chain; unwinding them",
+		"round", header.Round,
+		"expected_prev_hash", header.PreviousHash.Hex(),
+		"indexed_prev_hash", prevHash,
+		"fork_round", forkRound,
+		"num_unwound_rounds", header.Round-1-forkRound,
+	)
+	if err := m.unwindRounds(ctx, forkRound); err != nil {
+		return fmt.Errorf("unwinding rounds after %d: %w", forkRound, err)
+	}
+	return analyzer.ErrReorg
+}
+
+// findForkRound returns the latest indexed round, at or before `round`, whose
+// hash matches the one reported by the node.
+func (m *processor) findForkRound(ctx context.Context, round uint64) (uint64, error) {
+	for depth := uint64(0); depth < maxUnwindRounds && depth <= round; depth++ {
+		var indexedHash string
+		switch err := m.target.QueryRow(ctx, queries.RuntimeBlockHash, m.runtime, round-depth).Scan(&indexedHash); err {
+		case nil:
+		case storage.ErrNoRows:
+			// We reached the start of the indexed rounds.
+			return 0, fmt.Errorf("no common ancestor with the node in the indexed rounds (searched down to round %d)", round-depth)
+		default:
+			return 0, fmt.Errorf("fetching hash of round %d: %w", round-depth, err)
+		}
+		header, err := m.source.GetBlockHeader(ctx, round-depth)
+		if err != nil {
+			return 0, fmt.Errorf("fetching header of round %d: %w", round-depth, err)
+		}
+		if indexedHash == header.Hash.Hex() {
+			return round - depth, nil
+		}
+	}
+	return 0, fmt.Errorf("no common ancestor with the node in the last %d indexed rounds", maxUnwindRounds)
+}
+
+// unwindRounds reverts the effects of all indexed rounds after `forkRound`,
+// and marks them as not processed. It does so in a single DB transaction.
+func (m *processor) unwindRounds(ctx context.Context, forkRound uint64) error {
+	var fastSynced bool
+	if err := m.target.QueryRow(ctx, queries.IsAnyBlockAfterProcessedByFastSync, string(m.runtime), forkRound).Scan(&fastSynced); err != nil {
+		return err
+	}
+	if fastSynced {
+		return fmt.Errorf("some rounds after %d were processed in fast-sync mode and cannot be unwound", forkRound)
+	}
+
+	batch := &storage.QueryBatch{}
+
+	// Revert the effects on tables that are not keyed by round.
+	batch.Queue(queries.RuntimeUndoNativeBalances, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeUndoAccountStats, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeUndoEVMTokenBalances, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeUndoEVMNFTBalances, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeUndoEVMTokens, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeUndoEVMNFTTransfers, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeUndoEVMNFTOwners, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeUndoEVMSwapPairs, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeUndoEVMTokenCreations, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeUndoEVMNFTCreations, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeUndoAddressPreimageCreations, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeUndoEVMTokenBalanceAnalysisCreations, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeUndoEVMContractCreations, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeUndoLogDelete, m.runtime, forkRound)
+
+	// Have the aggregate stats analyzer recompute the price candles since the fork.
+	// Both queries need the fork round's block, which is not deleted.
+	batch.Queue(queries.RuntimeUndoEVMSwapPairCandles, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeUndoEVMSwapPairCandlesComputed, m.runtime, forkRound)
+
+	// Data that was downloaded from the EVM at an unwound round may be wrong.
+	batch.Queue(queries.RuntimeUndoEVMTokenDownloads, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeUndoEVMNFTDownloads, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeUndoEVMTokenBalanceDownloads, m.runtime, forkRound)
+
+	// Delete the rounds themselves. Children first, for clarity; FKs are deferred anyway.
+	batch.Queue(queries.RuntimeRelatedTransactionsDelete, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeTransactionSignersDelete, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeEventsDelete, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeEVMTokenTransfersDelete, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeTransfersDelete, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeBalanceChangesDelete, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeInternalCallsDelete, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeTransactionsDelete, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeEVMSwapPairCreationsDelete, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeEVMSwapPairSyncsDelete, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeEVMProxyImplementationsDelete, m.runtime, forkRound)
+	batch.Queue(queries.RuntimeBlocksDelete, m.runtime, forkRound)
+
+	// Have the block analyzer pick up the rounds again, and the call traces analyzer after it.
+	batch.Queue(queries.ProcessedBlocksDelete, string(m.runtime), forkRound)
+	batch.Queue(queries.RuntimeEVMTracedRoundsDelete, m.runtime, forkRound)
+
+	return m.target.SendBatch(ctx, batch)
+}
+
+// The undo* helpers below extend `batch` with an undo log entry for a change
+// to a table that is not keyed by round. They must be called next to the
+// corresponding change; helpers that snapshot the current value must be
+// queued before it. In fast-sync mode, they do nothing.
+
+// undoDelta records that `delta` was added to `column` of the row in `table`
+// identified by the given key.
+func (m *processor) undoDelta(batch *storage.QueryBatch, round uint64, table string, token interface{}, accountAddress interface{}, nftID interface{}, column string, delta interface{}) {
+	if m.mode == analyzer.FastSyncMode {
+		return
+	}
+	batch.Queue(queries.RuntimeUndoLogDeltaInsert, m.runtime, round, table, token, accountAddress, nftID, column, delta)
+}
+
+func (m *processor) undoNativeBalance(batch *storage.QueryBatch, round uint64, accountAddress string, symbol string, delta string) {
+	m.undoDelta(batch, round, "runtime_sdk_balances", symbol, accountAddress, nil, "balance", delta)
+}
+
+func (m *processor) undoAccountStat(batch *storage.QueryBatch, round uint64, accountAddress string, column string, delta interface{}) {
+	m.undoDelta(batch, round, "runtime_accounts", nil, accountAddress, nil, column, delta)
+}
+
+func (m *processor) undoEVMTokenBalance(batch *storage.QueryBatch, round uint64, tokenAddress string, accountAddress string, delta string) {
+	m.undoDelta(batch, round, "evm_token_balances", tokenAddress, accountAddress, nil, "balance", delta)
+}
+
+func (m *processor) undoEVMNFTBalance(batch *storage.QueryBatch, round uint64, tokenAddress string, nftID interface{}, accountAddress string, delta string) {
+	m.undoDelta(batch, round, "evm_nft_balances", tokenAddress, accountAddress, nftID, "balance", delta)
+}
+
+// undoEVMNFTTransfer snapshots the owner of an NFT and records the number of
+// transfers that are about to be added to it. The NFT must already exist.
+func (m *processor) undoEVMNFTTransfer(batch *storage.QueryBatch, round uint64, tokenAddress string, nftID interface{}, numTransfers int) {
+	if m.mode == analyzer.FastSyncMode {
+		return
+	}
+	batch.Queue(queries.RuntimeUndoLogEVMNFTTransferInsert, m.runtime, round, tokenAddress, nftID, numTransfers)
+}
+
+// undoEVMSwapPair snapshots the reserves of a swap pair.
+func (m *processor) undoEVMSwapPair(batch *storage.QueryBatch, round uint64, pairAddress string) {
+	if m.mode == analyzer.FastSyncMode {
+		return
+	}
+	batch.Queue(queries.RuntimeUndoLogEVMSwapPairInsert, m.runtime, round, pairAddress)
+}
+
+// The undo*Creation helpers record that a row is about to be created, if it
+// does not exist yet, so that unwinding deletes it.
+
+func (m *processor) undoEVMTokenCreation(batch *storage.QueryBatch, round uint64, tokenAddress string) {
+	if m.mode == analyzer.FastSyncMode {
+		return
+	}
+	batch.Queue(queries.RuntimeUndoLogEVMTokenCreationInsert, m.runtime, round, tokenAddress)
+}
+
+func (m *processor) undoEVMNFTCreation(batch *storage.QueryBatch, round uint64, tokenAddress string, nftID interface{}) {
+	if m.mode == analyzer.FastSyncMode {
+		return
+	}
+	batch.Queue(queries.RuntimeUndoLogEVMNFTCreationInsert, m.runtime, round, tokenAddress, nftID)
+}
+
+func (m *processor) undoAddressPreimageCreation(batch *storage.QueryBatch, round uint64, address string) {
+	if m.mode == analyzer.FastSyncMode {
+		return
+	}
+	batch.Queue(queries.RuntimeUndoLogAddressPreimageCreationInsert, m.runtime, round, address)
+}
+
+func (m *processor) undoEVMTokenBalanceAnalysisCreation(batch *storage.QueryBatch, round uint64, tokenAddress string, accountAddress string) {
+	if m.mode == analyzer.FastSyncMode {
+		return
+	}
+	batch.Queue(queries.RuntimeUndoLogEVMTokenBalanceAnalysisCreationInsert, m.runtime, round, tokenAddress, accountAddress)
+}
+
+// queueUndoLogPrune extends `batch` with a query that drops undo log entries
+// for rounds that are too old to be unwound.
+func (m *processor) queueUndoLogPrune(batch *storage.QueryBatch, round uint64) {
+	if m.mode == analyzer.FastSyncMode || round < maxUnwindRounds {
+		return
+	}
+	batch.Queue(queries.RuntimeUndoLogPrune, m.runtime, round-maxUnwindRounds)
+}
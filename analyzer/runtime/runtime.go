@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sort"
 	"strings"
 	"time"
 
@@ -202,6 +203,11 @@ func (m *processor) ProcessBlock(ctx context.Context, round uint64) error {
 	}
 	fetchTimer.ObserveDuration() // We make no observation in case of a data fetch error; those timings are misleading.
 
+	// Make sure the round builds on what we indexed so far.
+	if err = m.checkReorg(ctx, blockHeader); err != nil {
+		return err
+	}
+
 	// Preprocess data.
 	analysisTimer := m.metrics.BlockAnalysisLatencies()
 	blockData, err := ExtractRound(*blockHeader, transactionsWithResults, rawEvents, m.sdkPT, m.logger)
@@ -213,6 +219,8 @@ func (m *processor) ProcessBlock(ctx context.Context, round uint64) error {
 	batch := &storage.QueryBatch{}
 	m.queueDbUpdates(batch, blockData)
 	m.queueAccountsEvents(batch, blockData)
+	m.queueBalanceHistory(batch, blockData)
+	m.queueUndoLogPrune(batch, round)
 	analysisTimer.ObserveDuration()
 
 	// Update indexing progress.
@@ -223,6 +231,12 @@ func (m *processor) ProcessBlock(ctx context.Context, round uint64) error {
 		m.mode == analyzer.FastSyncMode,
 	)
 
+	// Announce the block to API subscribers. Blocks processed in fast-sync
+	// mode are out of order and are not announced.
+	if m.mode != analyzer.FastSyncMode {
+		batch.Queue(queries.NotifyNewBlock, storage.NewBlockNotifyChannel, string(m.runtime), round)
+	}
+
 	// Perform one-off fixes: Refetch native balances that are known to be stale at a fixed height.
 	if err := static.QueueEVMKnownStaleAccounts(batch, m.chain, m.runtime, round, m.logger); err != nil {
 		return fmt.Errorf("queue eden accounts: %w", err)
@@ -244,6 +258,7 @@ func (m *processor) queueTransactionInsert(batch *storage.QueryBatch, round uint
 	var (
 		oasisEncryptedFormat      *common.CallFormat
 		oasisEncryptedPublicKey   *[]byte
+		oasisEncryptedEpoch       *uint64
 		oasisEncryptedDataNonce   *[]byte
 		oasisEncryptedDataData    *[]byte
 		oasisEncryptedResultNonce *[]byte
@@ -252,6 +267,7 @@ func (m *processor) queueTransactionInsert(batch *storage.QueryBatch, round uint
 	if transactionData.OasisEncrypted != nil {
 		oasisEncryptedFormat = &transactionData.OasisEncrypted.Format
 		oasisEncryptedPublicKey = &transactionData.OasisEncrypted.PublicKey
+		oasisEncryptedEpoch = &transactionData.OasisEncrypted.Epoch
 		oasisEncryptedDataNonce = &transactionData.OasisEncrypted.DataNonce
 		oasisEncryptedDataData = &transactionData.OasisEncrypted.DataData
 		oasisEncryptedResultNonce = &transactionData.OasisEncrypted.ResultNonce
@@ -260,6 +276,7 @@ func (m *processor) queueTransactionInsert(batch *storage.QueryBatch, round uint
 	var (
 		evmEncryptedFormat      *common.CallFormat
 		evmEncryptedPublicKey   *[]byte
+		evmEncryptedEpoch       *uint64
 		evmEncryptedDataNonce   *[]byte
 		evmEncryptedDataData    *[]byte
 		evmEncryptedResultNonce *[]byte
@@ -268,6 +285,7 @@ func (m *processor) queueTransactionInsert(batch *storage.QueryBatch, round uint
 	if transactionData.EVMEncrypted != nil {
 		evmEncryptedFormat = &transactionData.EVMEncrypted.Format
 		evmEncryptedPublicKey = &transactionData.EVMEncrypted.PublicKey
+		evmEncryptedEpoch = &transactionData.EVMEncrypted.Epoch
 		evmEncryptedDataNonce = &transactionData.EVMEncrypted.DataNonce
 		evmEncryptedDataData = &transactionData.EVMEncrypted.DataData
 		evmEncryptedResultNonce = &transactionData.EVMEncrypted.ResultNonce
@@ -320,9 +338,56 @@ func (m *processor) queueTransactionInsert(batch *storage.QueryBatch, round uint
 		errorCode,
 		errorMessageRaw,
 		errorMessage,
+		oasisEncryptedEpoch,
+		evmEncryptedEpoch,
 	)
 }
 
+// eventsInNumberingOrder returns the events of a round in the order in which they
+// are numbered: the events of transactions in transaction order, followed by
+// the events that were not emitted by a transaction. This matches the order in
+// which events were listed before they were numbered.
+func eventsInNumberingOrder(events []*EventData) []*EventData {
+	ordered := make([]*EventData, 0, len(events))
+	for _, e := range events {
+		if e.TxIndex != nil {
+			ordered = append(ordered, e)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return *ordered[i].TxIndex < *ordered[j].TxIndex
+	})
+	for _, e := range events {
+		if e.TxIndex == nil {
+			ordered = append(ordered, e)
+		}
+	}
+	return ordered
+}
+
+// queueBalanceHistory extends `batch` with the balance changes of the round,
+// for the balance history. Unlike the balances themselves, these are recorded
+// in fast-sync mode too, since each round only inserts its own rows. In
+// slow-sync mode, they also record the balances after the round, so it must be
+// called after the balance updates are queued.
+func (m *processor) queueBalanceHistory(batch *storage.QueryBatch, data *BlockData) {
+	for key, change := range data.BalanceChanges {
+		if change.Sign() == 0 {
+			continue
+		}
+		batch.Queue(
+			queries.RuntimeBalanceChangeInsert,
+			m.runtime,
+			data.Header.Round,
+			key.AccountAddress,
+			key.Token,
+			change.String(),
+			data.Header.Timestamp,
+			m.mode != analyzer.FastSyncMode,
+		)
+	}
+}
+
 // queueDbUpdates extends `batch` with queries that reflect `data`.
 func (m *processor) queueDbUpdates(batch *storage.QueryBatch, data *BlockData) {
 	// Block metadata.
@@ -364,6 +429,7 @@ func (m *processor) queueDbUpdates(batch *storage.QueryBatch, data *BlockData) {
 				// DB deadlocks with as few as 2 parallel analyzers.
 				// We recalculate the number of transactions for all accounts at the end of fast-sync, by aggregating the tx data.
 				batch.Queue(queries.RuntimeAccountNumTxsUpsert, m.runtime, addr, 1)
+				m.undoAccountStat(batch, data.Header.Round, string(addr), "num_txs", 1)
 			}
 		}
 		m.queueTransactionInsert(batch, data.Header.Round, data.Header.Timestamp, transactionData)
@@ -395,12 +461,13 @@ func (m *processor) queueDbUpdates(batch *storage.QueryBatch, data *BlockData) {
 					transactionData.To,
 					transactionData.GasUsed,
 				)
+				m.undoAccountStat(batch, data.Header.Round, string(*transactionData.To), "gas_for_calling", transactionData.GasUsed)
 			}
 		}
 	}
 
 	// Insert events.
-	for _, eventData := range data.EventData {
+	for i, eventData := range eventsInNumberingOrder(data.EventData) {
 		eventRelatedAddresses := addresses.SliceFromSet(eventData.RelatedAddresses)
 		batch.Queue(
 			queries.RuntimeEventInsert,
@@ -416,11 +483,32 @@ func (m *processor) queueDbUpdates(batch *storage.QueryBatch, data *BlockData) {
 			eventData.EvmLogName,
 			eventData.EvmLogParams,
 			eventData.EvmLogSignature,
+			i,
 		)
+		for j, transfer := range eventData.TokenTransfers {
+			batch.Queue(
+				queries.RuntimeEVMTokenTransferInsert,
+				m.runtime,
+				data.Header.Round,
+				i,
+				j,
+				eventData.TxIndex,
+				eventData.TxHash,
+				eventData.TxEthHash,
+				data.Header.Timestamp,
+				transfer.TokenAddress,
+				transfer.TokenType,
+				transfer.From,
+				transfer.To,
+				transfer.TokenID,
+				transfer.Amount,
+			)
+		}
 	}
 
 	// Insert address preimages.
 	for addr, preimageData := range data.AddressPreimages {
+		m.undoAddressPreimageCreation(batch, data.Header.Round, string(addr))
 		batch.Queue(queries.AddressPreimageInsert, addr, preimageData.ContextIdentifier, preimageData.ContextVersion, preimageData.Data)
 	}
 
@@ -439,10 +527,17 @@ func (m *processor) queueDbUpdates(batch *storage.QueryBatch, data *BlockData) {
 		}
 		if m.mode != analyzer.FastSyncMode {
 			// In slow-sync mode, directly update or dead-reckon values.
+			m.undoEVMTokenCreation(batch, data.Header.Round, string(addr))
 			batch.Queue(
 				queries.RuntimeEVMTokenDeltaUpsert,
 				m.runtime, addr, totalSupplyChange, numTransfersChange, lastMutateRound,
 			)
+			if possibleToken.TotalSupplyChange.Cmp(&big.Int{}) != 0 {
+				m.undoDelta(batch, data.Header.Round, "evm_tokens", string(addr), nil, nil, "total_supply", totalSupplyChange)
+			}
+			if numTransfersChange != 0 {
+				m.undoDelta(batch, data.Header.Round, "evm_tokens", string(addr), nil, nil, "num_transfers", numTransfersChange)
+			}
 		} else {
 			// In fast-sync mode, just record the intent to update the values.
 			batch.Queue(
@@ -458,14 +553,17 @@ func (m *processor) queueDbUpdates(batch *storage.QueryBatch, data *BlockData) {
 		if change != big.NewInt(0) && m.mode != analyzer.FastSyncMode {
 			if key.TokenAddress == evm.NativeRuntimeTokenAddress {
 				batch.Queue(queries.RuntimeNativeBalanceUpsert, m.runtime, key.AccountAddress, nativeTokenSymbol(m.sdkPT), change.String())
+				m.undoNativeBalance(batch, data.Header.Round, string(key.AccountAddress), nativeTokenSymbol(m.sdkPT), change.String())
 			} else {
 				batch.Queue(queries.RuntimeEVMTokenBalanceUpdate, m.runtime, key.TokenAddress, key.AccountAddress, change.String())
+				m.undoEVMTokenBalance(batch, data.Header.Round, string(key.TokenAddress), string(key.AccountAddress), change.String())
 			}
 		}
 		// Even for a (suspected) non-change, notify the evm_token_balances analyzer to
 		// verify the correct balance by querying the EVM.
 		if m.mode == analyzer.SlowSyncMode {
 			// In slow-sync mode, directly update `last_mutate_round`.
+			m.undoEVMTokenBalanceAnalysisCreation(batch, data.Header.Round, string(key.TokenAddress), string(key.AccountAddress))
 			batch.Queue(
 				queries.RuntimeEVMTokenBalanceAnalysisMutateRoundUpsert,
 				m.runtime, key.TokenAddress, key.AccountAddress, data.Header.Round,
@@ -481,6 +579,7 @@ func (m *processor) queueDbUpdates(batch *storage.QueryBatch, data *BlockData) {
 
 	// Insert NFTs.
 	for key, possibleNFT := range data.PossibleNFTs {
+		m.undoEVMNFTCreation(batch, data.Header.Round, string(key.TokenAddress), key.TokenID)
 		batch.Queue(
 			queries.RuntimeEVMNFTUpsert,
 			m.runtime,
@@ -488,11 +587,22 @@ func (m *processor) queueDbUpdates(batch *storage.QueryBatch, data *BlockData) {
 			key.TokenID,
 			data.Header.Round,
 		)
-		if possibleNFT.NumTransfers > 0 {
+		if possibleNFT.NumTransfers > 0 && possibleNFT.MultiOwner {
+			// ERC-1155 token IDs don't have a single owner; see NFT balances below.
+			m.undoDelta(batch, data.Header.Round, "evm_nfts", string(key.TokenAddress), nil, key.TokenID, "num_transfers", possibleNFT.NumTransfers)
+			batch.Queue(
+				queries.RuntimeEVMNFTUpdateTransferCount,
+				m.runtime,
+				key.TokenAddress,
+				key.TokenID,
+				possibleNFT.NumTransfers,
+			)
+		} else if possibleNFT.NumTransfers > 0 {
 			var newOwner *apiTypes.Address
 			if !possibleNFT.Burned {
 				newOwner = &possibleNFT.NewOwner
 			}
+			m.undoEVMNFTTransfer(batch, data.Header.Round, string(key.TokenAddress), key.TokenID, possibleNFT.NumTransfers)
 			batch.Queue(
 				queries.RuntimeEVMNFTUpdateTransfer,
 				m.runtime,
@@ -504,6 +614,19 @@ func (m *processor) queueDbUpdates(batch *storage.QueryBatch, data *BlockData) {
 		}
 	}
 
+	// Update per-ID balances of multi-tokens (ERC-1155).
+	for key, change := range data.NFTBalanceChanges {
+		if m.mode != analyzer.FastSyncMode {
+			// In slow-sync mode, dead-reckon the balance.
+			batch.Queue(queries.RuntimeEVMNFTBalanceUpdate, m.runtime, key.TokenAddress, key.TokenID, key.AccountAddress, change.String())
+			m.undoEVMNFTBalance(batch, data.Header.Round, string(key.TokenAddress), key.TokenID, string(key.AccountAddress), change.String())
+		} else {
+			// In fast-sync mode, just record the ID so that the evm_token_balances
+			// analyzer downloads its balance.
+			batch.Queue(queries.RuntimeEVMNFTBalanceInsert, m.runtime, key.TokenAddress, key.TokenID, key.AccountAddress)
+		}
+	}
+
 	// Insert swap pairs.
 	for creationKey, creation := range data.SwapCreations {
 		batch.Queue(
@@ -512,18 +635,55 @@ func (m *processor) queueDbUpdates(batch *storage.QueryBatch, data *BlockData) {
 			creationKey.Factory,
 			creationKey.Token0,
 			creationKey.Token1,
+			creationKey.Fee,
 			creation.Pair,
 			data.Header.Round,
 		)
 	}
 	for pairAddress, sync := range data.SwapSyncs {
+		m.undoEVMSwapPair(batch, data.Header.Round, string(pairAddress))
 		batch.Queue(
 			queries.RuntimeEVMSwapPairUpsertSync,
 			m.runtime,
 			pairAddress,
 			sync.Reserve0,
 			sync.Reserve1,
+			sync.SqrtPriceX96,
 			data.Header.Round,
 		)
+		// Also keep the history of reserves, for price candles.
+		volume := data.SwapVolumes[pairAddress]
+		if volume == nil {
+			volume = &SwapVolume{Amount0: &big.Int{}, Amount1: &big.Int{}}
+		}
+		batch.Queue(
+			queries.RuntimeEVMSwapPairSyncInsert,
+			m.runtime,
+			data.Header.Round,
+			pairAddress,
+			sync.Reserve0,
+			sync.Reserve1,
+			sync.SqrtPriceX96,
+			volume.Amount0,
+			volume.Amount1,
+			data.Header.Timestamp,
+		)
+	}
+	// Track the implementations of proxies.
+	for proxyAddress, implementationAddress := range data.ProxyUpgrades {
+		batch.Queue(
+			queries.RuntimeEVMProxyImplementationUpsert,
+			m.runtime,
+			proxyAddress,
+			data.Header.Round,
+			implementationAddress,
+		)
+		// Fetch the implementation's bytecode, so that it can be verified. The proxy
+		// only delegatecalls it, so it might not be a contract candidate otherwise.
+		batch.Queue(
+			queries.RuntimeEVMContractCodeAnalysisInsert,
+			m.runtime,
+			implementationAddress,
+		)
 	}
 }
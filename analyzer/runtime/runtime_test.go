@@ -18,6 +18,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	"github.com/oasisprotocol/oasis-core/go/common/quantity"
 	"github.com/oasisprotocol/oasis-core/go/staking/api"
 	"github.com/oasisprotocol/oasis-sdk/client-sdk/go/modules/consensusaccounts"
@@ -31,6 +32,7 @@ import (
 	"github.com/oasisprotocol/nexus/common"
 	"github.com/oasisprotocol/nexus/config"
 	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage"
 	"github.com/oasisprotocol/nexus/storage/oasis/nodeapi"
 	"github.com/oasisprotocol/nexus/storage/postgres"
 	pgTestUtil "github.com/oasisprotocol/nexus/storage/postgres/testutil"
@@ -63,6 +65,7 @@ func setupDB(t *testing.T) *postgres.Client {
 
 // A mock implementation of RuntimeApiLite that returns predefined data.
 type mockNode struct {
+	Headers     map[uint64]*nodeapi.RuntimeBlockHeader             // round -> header; optional
 	Txs         map[uint64][]nodeapi.RuntimeTransactionWithResults // round -> txs
 	NonTxEvents map[uint64][]nodeapi.RuntimeEvent                  // round -> events
 }
@@ -79,13 +82,26 @@ func (*mockNode) EVMGetCode(ctx context.Context, round uint64, address []byte) (
 	panic("unimplemented") // not needed for testing the block analyzer
 }
 
+// EVMGetStorage implements nodeapi.RuntimeApiLite.
+func (*mockNode) EVMGetStorage(ctx context.Context, round uint64, address []byte, index []byte) ([]byte, error) {
+	panic("unimplemented") // not needed for testing the block analyzer
+}
+
 // EVMSimulateCall implements nodeapi.RuntimeApiLite.
 func (*mockNode) EVMSimulateCall(ctx context.Context, round uint64, gasPrice []byte, gasLimit uint64, caller []byte, address []byte, value []byte, data []byte) (*nodeapi.FallibleResponse, error) {
 	panic("unimplemented") // not needed for testing the block analyzer
 }
 
+// EVMTraceRound implements nodeapi.RuntimeApiLite.
+func (*mockNode) EVMTraceRound(ctx context.Context, round uint64) ([]nodeapi.EVMTransactionTrace, error) {
+	panic("unimplemented") // not needed for testing the block analyzer
+}
+
 // GetBlockHeader implements nodeapi.RuntimeApiLite.
-func (*mockNode) GetBlockHeader(ctx context.Context, round uint64) (*nodeapi.RuntimeBlockHeader, error) {
+func (mock *mockNode) GetBlockHeader(ctx context.Context, round uint64) (*nodeapi.RuntimeBlockHeader, error) {
+	if header, ok := mock.Headers[round]; ok {
+		return header, nil
+	}
 	return &nodeapi.RuntimeBlockHeader{
 		Round: round,
 	}, nil
@@ -137,6 +153,10 @@ func setupAnalyzer(t *testing.T, testDb *postgres.Client, node *mockNode) analyz
 		minRound = min(minRound, int(round))
 		maxRound = max(maxRound, int(round))
 	}
+	for round := range node.Headers {
+		minRound = min(minRound, int(round))
+		maxRound = max(maxRound, int(round))
+	}
 	if minRound > maxRound {
 		panic("no data in mock node")
 	}
@@ -223,3 +243,109 @@ func TestImplicitTo(t *testing.T) {
 	require.NoError(t, err, "db fetch")
 	require.Equal(t, sdkTesting.Alice.Address.String(), to, "unexpected `to` value for tx")
 }
+
+// The timestamp of round 0 of headerChain; later rounds are 2 minutes apart.
+var headerChainStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Returns a chain of headers for rounds [0, len(seeds)), where the hash of each
+// block is derived from the corresponding seed.
+func headerChain(seeds ...string) map[uint64]*nodeapi.RuntimeBlockHeader {
+	headers := map[uint64]*nodeapi.RuntimeBlockHeader{}
+	var prevHash hash.Hash
+	for round, seed := range seeds {
+		header := &nodeapi.RuntimeBlockHeader{
+			Round:        uint64(round),
+			Hash:         hash.NewFromBytes([]byte(seed)),
+			PreviousHash: prevHash,
+			Timestamp:    headerChainStart.Add(time.Duration(round) * 2 * time.Minute),
+		}
+		headers[uint64(round)] = header
+		prevHash = header.Hash
+	}
+	return headers
+}
+
+func TestReorg(t *testing.T) {
+	ctx := context.Background()
+	db := setupDB(t)
+
+	// Index rounds 0-2, with a tx in round 2.
+	runToCompletion(ctx, setupAnalyzer(t, db, &mockNode{
+		Headers: headerChain("a0", "a1", "a2"),
+		Txs: map[uint64][]nodeapi.RuntimeTransactionWithResults{
+			2: {
+				simpleRuntimeTxWithResults(
+					sdkTesting.Alice.SigSpec,
+					"consensus.Deposit",
+					consensusaccounts.Deposit{Amount: sdkTypes.NewBaseUnits(*quantity.NewFromUint64(0), sdkTypes.NativeDenomination)},
+				),
+			},
+		},
+	}))
+	var numTxs uint64
+	require.NoError(t, db.QueryRow(ctx, "SELECT num_txs FROM chain.runtime_accounts WHERE address = $1", sdkTesting.Alice.Address.String()).Scan(&numTxs), "db fetch")
+	require.Equal(t, uint64(1), numTxs, "unexpected num_txs before reorg")
+	var numPreimages int
+	require.NoError(t, db.QueryRow(ctx, "SELECT COUNT(*) FROM chain.address_preimages WHERE address = $1", sdkTesting.Alice.Address.String()).Scan(&numPreimages), "db fetch")
+	require.Equal(t, 1, numPreimages, "unexpected number of preimages before reorg")
+
+	// Price candles computed before the reorg, and the progress of their computation.
+	candles := []struct {
+		interval    string
+		windowStart time.Time
+	}{
+		{"5m", headerChainStart.Add(-5 * time.Minute)},
+		{"5m", headerChainStart}, // Contains the unwound round 2.
+		{"1d", headerChainStart}, // Contains the unwound round 2.
+	}
+	batch := &storage.QueryBatch{}
+	for _, c := range candles {
+		batch.Queue(`
+			INSERT INTO stats.evm_swap_pair_candles (runtime, pair_address, interval, window_start, open, high, low, close, volume0, volume1)
+			VALUES ('pontusx_dev', $1, $2, $3, 1, 1, 1, 1, 0, 0)`,
+			sdkTesting.Alice.Address.String(), c.interval, c.windowStart)
+	}
+	computed := map[string]time.Time{
+		"pontusx_dev_5m": headerChainStart.Add(10 * time.Minute),
+		"pontusx_dev_1h": headerChainStart.Add(-time.Hour),
+		"pontusx_dev_1d": headerChainStart.Add(24 * time.Hour),
+		"emerald_5m":     headerChainStart.Add(10 * time.Minute),
+	}
+	for layer, windowEnd := range computed {
+		batch.Queue(`INSERT INTO stats.evm_swap_pair_candles_computed (layer, window_end) VALUES ($1, $2)`, layer, windowEnd)
+	}
+	require.NoError(t, db.SendBatch(ctx, batch), "inserting candles")
+
+	// Round 2 is replaced by a round without txs, and the chain continues from there.
+	headers := headerChain("a0", "a1", "b2", "b3")
+	runToCompletion(ctx, setupAnalyzer(t, db, &mockNode{Headers: headers}))
+
+	// Check that the new round 2 was indexed, and the effects of the old one were reverted.
+	var blockHash string
+	require.NoError(t, db.QueryRow(ctx, "SELECT block_hash FROM chain.runtime_blocks WHERE round = 2").Scan(&blockHash), "db fetch")
+	require.Equal(t, headers[2].Hash.Hex(), blockHash, "unexpected hash of round 2")
+	var numTransactions int
+	require.NoError(t, db.QueryRow(ctx, "SELECT COUNT(*) FROM chain.runtime_transactions").Scan(&numTransactions), "db fetch")
+	require.Equal(t, 0, numTransactions, "txs of unwound round were not deleted")
+	require.NoError(t, db.QueryRow(ctx, "SELECT num_txs FROM chain.runtime_accounts WHERE address = $1", sdkTesting.Alice.Address.String()).Scan(&numTxs), "db fetch")
+	require.Equal(t, uint64(0), numTxs, "num_txs of unwound round was not reverted")
+	require.NoError(t, db.QueryRow(ctx, "SELECT COUNT(*) FROM chain.address_preimages WHERE address = $1", sdkTesting.Alice.Address.String()).Scan(&numPreimages), "db fetch")
+	require.Equal(t, 0, numPreimages, "preimage first seen in unwound round was not deleted")
+
+	// Candles of windows that contain the unwound round were deleted, and are recomputed
+	// from the start of the window of the fork round (round 1, at 00:02).
+	var windowStart time.Time
+	require.NoError(t, db.QueryRow(ctx, "SELECT window_start FROM stats.evm_swap_pair_candles").Scan(&windowStart), "db fetch")
+	require.Equal(t, candles[0].windowStart, windowStart.UTC(), "unexpected remaining candle")
+	expectedComputed := map[string]time.Time{
+		"pontusx_dev_5m": headerChainStart,
+		"pontusx_dev_1h": headerChainStart.Add(-time.Hour), // Not computed past the fork yet.
+		"pontusx_dev_1d": headerChainStart,
+		"emerald_5m":     headerChainStart.Add(10 * time.Minute), // Another runtime.
+	}
+	for layer, expected := range expectedComputed {
+		var windowEnd time.Time
+		require.NoError(t, db.QueryRow(ctx, "SELECT window_end FROM stats.evm_swap_pair_candles_computed WHERE layer = $1", layer).Scan(&windowEnd), "db fetch")
+		require.Equal(t, expected, windowEnd.UTC(), "unexpected progress of %s", layer)
+	}
+}
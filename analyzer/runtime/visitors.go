@@ -198,18 +198,28 @@ type EVMEventHandler struct {
 	ERC20Approval                func(owner ethCommon.Address, spender ethCommon.Address, value *big.Int) error
 	ERC721Transfer               func(from ethCommon.Address, to ethCommon.Address, tokenID *big.Int) error
 	ERC721Approval               func(owner ethCommon.Address, approved ethCommon.Address, tokenID *big.Int) error
-	ERC721ApprovalForAll         func(owner ethCommon.Address, operator ethCommon.Address, approved bool) error
+	ERC721ApprovalForAll         func(owner ethCommon.Address, operator ethCommon.Address, approved bool) error // Also matches ERC-1155 ApprovalForAll, which has the same signature.
+	ERC1155TransferSingle        func(operator ethCommon.Address, from ethCommon.Address, to ethCommon.Address, id *big.Int, value *big.Int) error
+	ERC1155TransferBatch         func(operator ethCommon.Address, from ethCommon.Address, to ethCommon.Address, ids []*big.Int, values []*big.Int) error
+	ERC1155URI                   func(value string, id *big.Int) error
 	IUniswapV2FactoryPairCreated func(token0 ethCommon.Address, token1 ethCommon.Address, pair ethCommon.Address, allPairsLength *big.Int) error
 	IUniswapV2PairMint           func(sender ethCommon.Address, amount0 *big.Int, amount1 *big.Int) error
 	IUniswapV2PairBurn           func(sender ethCommon.Address, amount0 *big.Int, amount1 *big.Int, to ethCommon.Address) error
 	IUniswapV2PairSwap           func(sender ethCommon.Address, amount0In *big.Int, amount1In *big.Int, amount0Out *big.Int, amount1Out *big.Int, to ethCommon.Address) error
 	IUniswapV2PairSync           func(reserve0 *big.Int, reserve1 *big.Int) error
+	IUniswapV3FactoryPoolCreated func(token0 ethCommon.Address, token1 ethCommon.Address, fee *big.Int, tickSpacing *big.Int, pool ethCommon.Address) error
+	IUniswapV3PoolMint           func(sender ethCommon.Address, owner ethCommon.Address, tickLower *big.Int, tickUpper *big.Int, amount *big.Int, amount0 *big.Int, amount1 *big.Int) error
+	IUniswapV3PoolBurn           func(owner ethCommon.Address, tickLower *big.Int, tickUpper *big.Int, amount *big.Int, amount0 *big.Int, amount1 *big.Int) error
+	IUniswapV3PoolSwap           func(sender ethCommon.Address, recipient ethCommon.Address, amount0 *big.Int, amount1 *big.Int, sqrtPriceX96 *big.Int, liquidity *big.Int, tick *big.Int) error
 	// `owner` wrapped/deposited runtime's native token (ROSE) into the wrapper contract (creating WROSE).
 	// `value` ROSE is transferred from `owner` to the wrapper (= event-emitting contract). Caller's WROSE balance increases by `value`.
 	WROSEDeposit func(owner ethCommon.Address, value *big.Int) error
 	// `owner` unwrapped/withdrew runtime's native token (ROSE) into the wrapper contract (burning WROSE).
 	// Caller's WROSE balance decreases by `value`. `value` ROSE is transferred from the wrapper (= event-emitting contract) to `owner`.
 	WROSEWithdrawal func(owner ethCommon.Address, value *big.Int) error
+	// The event-emitting proxy contract now delegates its calls to `implementation`.
+	// Emitted by EIP-1967 proxies, including UUPS and transparent proxies.
+	IERC1967Upgraded func(implementation ethCommon.Address) error
 }
 
 func eventMatches(evmEvent *evm.Event, ethEvent abi.Event) bool {
@@ -302,6 +312,56 @@ func VisitEVMEvent(event *evm.Event, handler *EVMEventHandler) error { //nolint:
 				return fmt.Errorf("handle erc721 approval for all: %w", err)
 			}
 		}
+	case eventMatches(event, evmabi.ERC1155.Events["TransferSingle"]):
+		if handler.ERC1155TransferSingle != nil {
+			_, args, err := abiparse.ParseEvent(event.Topics, event.Data, evmabi.ERC1155)
+			if err != nil {
+				return fmt.Errorf("parse erc1155 transfer single: %w", err)
+			}
+			if err = handler.ERC1155TransferSingle(
+				args[0].(ethCommon.Address),
+				args[1].(ethCommon.Address),
+				args[2].(ethCommon.Address),
+				args[3].(*big.Int),
+				args[4].(*big.Int),
+			); err != nil {
+				return fmt.Errorf("handle erc1155 transfer single: %w", err)
+			}
+		}
+	case eventMatches(event, evmabi.ERC1155.Events["TransferBatch"]):
+		if handler.ERC1155TransferBatch != nil {
+			_, args, err := abiparse.ParseEvent(event.Topics, event.Data, evmabi.ERC1155)
+			if err != nil {
+				return fmt.Errorf("parse erc1155 transfer batch: %w", err)
+			}
+			ids := args[3].([]*big.Int)
+			values := args[4].([]*big.Int)
+			if len(ids) != len(values) {
+				return fmt.Errorf("parse erc1155 transfer batch: %d ids but %d values", len(ids), len(values))
+			}
+			if err = handler.ERC1155TransferBatch(
+				args[0].(ethCommon.Address),
+				args[1].(ethCommon.Address),
+				args[2].(ethCommon.Address),
+				ids,
+				values,
+			); err != nil {
+				return fmt.Errorf("handle erc1155 transfer batch: %w", err)
+			}
+		}
+	case eventMatches(event, evmabi.ERC1155.Events["URI"]):
+		if handler.ERC1155URI != nil {
+			_, args, err := abiparse.ParseEvent(event.Topics, event.Data, evmabi.ERC1155)
+			if err != nil {
+				return fmt.Errorf("parse erc1155 uri: %w", err)
+			}
+			if err = handler.ERC1155URI(
+				args[0].(string),
+				args[1].(*big.Int),
+			); err != nil {
+				return fmt.Errorf("handle erc1155 uri: %w", err)
+			}
+		}
 	case eventMatches(event, evmabi.IUniswapV2Factory.Events["PairCreated"]):
 		if handler.IUniswapV2FactoryPairCreated != nil {
 			_, args, err := abiparse.ParseEvent(event.Topics, event.Data, evmabi.IUniswapV2Factory)
@@ -376,6 +436,75 @@ func VisitEVMEvent(event *evm.Event, handler *EVMEventHandler) error { //nolint:
 				return fmt.Errorf("handle uniswap v2 pair sync: %w", err)
 			}
 		}
+	case eventMatches(event, evmabi.IUniswapV3Factory.Events["PoolCreated"]):
+		if handler.IUniswapV3FactoryPoolCreated != nil {
+			_, args, err := abiparse.ParseEvent(event.Topics, event.Data, evmabi.IUniswapV3Factory)
+			if err != nil {
+				return fmt.Errorf("parse uniswap v3 factory pool created: %w", err)
+			}
+			if err = handler.IUniswapV3FactoryPoolCreated(
+				args[0].(ethCommon.Address),
+				args[1].(ethCommon.Address),
+				args[2].(*big.Int),
+				args[3].(*big.Int),
+				args[4].(ethCommon.Address),
+			); err != nil {
+				return fmt.Errorf("handle uniswap v3 factory pool created: %w", err)
+			}
+		}
+	case eventMatches(event, evmabi.IUniswapV3Pool.Events["Mint"]):
+		if handler.IUniswapV3PoolMint != nil {
+			_, args, err := abiparse.ParseEvent(event.Topics, event.Data, evmabi.IUniswapV3Pool)
+			if err != nil {
+				return fmt.Errorf("parse uniswap v3 pool mint: %w", err)
+			}
+			if err = handler.IUniswapV3PoolMint(
+				args[0].(ethCommon.Address),
+				args[1].(ethCommon.Address),
+				args[2].(*big.Int),
+				args[3].(*big.Int),
+				args[4].(*big.Int),
+				args[5].(*big.Int),
+				args[6].(*big.Int),
+			); err != nil {
+				return fmt.Errorf("handle uniswap v3 pool mint: %w", err)
+			}
+		}
+	case eventMatches(event, evmabi.IUniswapV3Pool.Events["Burn"]):
+		if handler.IUniswapV3PoolBurn != nil {
+			_, args, err := abiparse.ParseEvent(event.Topics, event.Data, evmabi.IUniswapV3Pool)
+			if err != nil {
+				return fmt.Errorf("parse uniswap v3 pool burn: %w", err)
+			}
+			if err = handler.IUniswapV3PoolBurn(
+				args[0].(ethCommon.Address),
+				args[1].(*big.Int),
+				args[2].(*big.Int),
+				args[3].(*big.Int),
+				args[4].(*big.Int),
+				args[5].(*big.Int),
+			); err != nil {
+				return fmt.Errorf("handle uniswap v3 pool burn: %w", err)
+			}
+		}
+	case eventMatches(event, evmabi.IUniswapV3Pool.Events["Swap"]):
+		if handler.IUniswapV3PoolSwap != nil {
+			_, args, err := abiparse.ParseEvent(event.Topics, event.Data, evmabi.IUniswapV3Pool)
+			if err != nil {
+				return fmt.Errorf("parse uniswap v3 pool swap: %w", err)
+			}
+			if err = handler.IUniswapV3PoolSwap(
+				args[0].(ethCommon.Address),
+				args[1].(ethCommon.Address),
+				args[2].(*big.Int),
+				args[3].(*big.Int),
+				args[4].(*big.Int),
+				args[5].(*big.Int),
+				args[6].(*big.Int),
+			); err != nil {
+				return fmt.Errorf("handle uniswap v3 pool swap: %w", err)
+			}
+		}
 	// Signature: 0xe1fffcc4923d04b559f4d29a8bfc6cda04eb5b0d3c460751c2402c5c5cc9109c (hex) or 4f/8xJI9BLVZ9NKai/xs2gTrWw08RgdRwkAsXFzJEJw= (base64)
 	case eventMatches(event, evmabi.WROSE.Events["Deposit"]):
 		if handler.WROSEDeposit != nil {
@@ -404,6 +533,19 @@ func VisitEVMEvent(event *evm.Event, handler *EVMEventHandler) error { //nolint:
 				return fmt.Errorf("handle wrose withdrawal: %w", err)
 			}
 		}
+	// Signature: 0xbc7cd75a20ee27fd9adebab32041f755214dbc6bffa90cc0225b39da2e5c2d3b (hex) or vHzXWiDuJ/2a3rqzIEH3VSFNvGv/qQzAIls52i5cLTs= (base64)
+	case eventMatches(event, evmabi.IERC1967.Events["Upgraded"]):
+		if handler.IERC1967Upgraded != nil {
+			_, args, err := abiparse.ParseEvent(event.Topics, event.Data, evmabi.IERC1967)
+			if err != nil {
+				return fmt.Errorf("parse erc1967 upgraded: %w", err)
+			}
+			if err = handler.IERC1967Upgraded(
+				args[0].(ethCommon.Address),
+			); err != nil {
+				return fmt.Errorf("handle erc1967 upgraded: %w", err)
+			}
+		}
 	}
 	return nil
 }
@@ -0,0 +1,74 @@
+package runtime_encryption_stats
+
+import (
+	"context"
+	"time"
+
+	"github.com/oasisprotocol/nexus/analyzer"
+	"github.com/oasisprotocol/nexus/analyzer/item"
+	"github.com/oasisprotocol/nexus/config"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage"
+)
+
+const (
+	analyzerName = "runtime_encryption_stats"
+
+	// The views aggregate all EVM calls, albeit from partial indexes, so refresh them less
+	// often than the accounts list.
+	defaultInterval = 10 * time.Minute
+)
+
+// The materialized views with statistics on encrypted calls, in all runtimes.
+var encryptionStatsViewRefreshQueries = []string{
+	`REFRESH MATERIALIZED VIEW CONCURRENTLY views.runtime_encryption_contracts`,
+	`REFRESH MATERIALIZED VIEW CONCURRENTLY views.runtime_encrypted_calls`,
+	`REFRESH MATERIALIZED VIEW CONCURRENTLY views.runtime_encrypted_call_errors`,
+	`REFRESH MATERIALIZED VIEW CONCURRENTLY views.runtime_encryption_key_epochs`,
+}
+
+type processor struct {
+	target storage.TargetStorage
+	logger *log.Logger
+}
+
+var _ item.ItemProcessor[struct{}] = (*processor)(nil)
+
+func NewAnalyzer(
+	cfg config.ItemBasedAnalyzerConfig,
+	target storage.TargetStorage,
+	logger *log.Logger,
+) (analyzer.Analyzer, error) {
+	if cfg.Interval == 0 {
+		cfg.Interval = defaultInterval
+	}
+	logger = logger.With("analyzer", analyzerName)
+	p := &processor{
+		target: target,
+		logger: logger,
+	}
+
+	return item.NewAnalyzer(
+		analyzerName,
+		cfg,
+		p,
+		target,
+		logger,
+	)
+}
+
+func (p *processor) GetItems(ctx context.Context, limit uint64) ([]struct{}, error) {
+	return []struct{}{{}}, nil
+}
+
+func (p *processor) ProcessItem(ctx context.Context, batch *storage.QueryBatch, item struct{}) error {
+	for _, query := range encryptionStatsViewRefreshQueries {
+		batch.Queue(query)
+	}
+	return nil
+}
+
+func (p *processor) QueueLength(ctx context.Context) (int, error) {
+	// The concept of a work queue does not apply to this analyzer
+	return 0, nil
+}
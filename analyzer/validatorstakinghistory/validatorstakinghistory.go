@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"math"
 
-	"github.com/jackc/pgx/v5"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
 
 	"github.com/oasisprotocol/nexus/analyzer"
@@ -26,6 +25,10 @@ import (
 // validators and take the union with the set of all tracked validators from the previous epoch.
 // Notably, we do not track validator history for an entity before it first becomes an active validator.
 //
+// From the change of a validator's share price between consecutive epochs, we also derive the
+// staking rewards of each of its delegators (see history.delegator_rewards). This writes a row
+// per delegation per epoch, which makes history.delegator_rewards the largest history table.
+//
 // WARNING: This analyzer SHOULD NOT run while block analyzers are in fast sync. This analyzer expects that
 // when a row appears in `chain.epochs`, its first block is already processed. In fast sync, blocks are processed
 // out of order. (As of 2024-06, fast-sync actually does not update chain.epochs directly so it might not cause bugs
@@ -101,7 +104,7 @@ func (p *processor) GetItems(ctx context.Context, limit uint64) ([]*Epoch, error
 	switch err {
 	case nil:
 		// continue
-	case pgx.ErrNoRows:
+	case storage.ErrNoRows:
 		return []*Epoch{}, nil
 	default:
 		return nil, fmt.Errorf("querying epochs for validator history: %w", err)
@@ -182,6 +185,21 @@ func (p *processor) ProcessItem(ctx context.Context, batch *storage.QueryBatch,
 			acct.Escrow.Debonding.TotalShares,
 			len(delegations),
 		)
+		// Attribute the staking rewards of the previous epoch, which were granted in the first
+		// block of this epoch, to the current delegators.
+		delegators := make([]string, 0, len(delegations))
+		shares := make([]string, 0, len(delegations))
+		for delegator, d := range delegations {
+			delegators = append(delegators, delegator.String())
+			shares = append(shares, d.Shares.String())
+		}
+		batch.Queue(queries.DelegatorRewardsInsert,
+			addr.String(),
+			vID.String(),
+			epoch.epoch,
+			delegators,
+			shares,
+		)
 		// Update staking rewards for this validator in the previous epoch.
 		if ev, exists := stakingRewards[addr]; exists {
 			batch.Queue(queries.ValidatorStakingRewardUpdate,
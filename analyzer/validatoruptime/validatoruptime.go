@@ -0,0 +1,158 @@
+package validatoruptime
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+
+	"github.com/oasisprotocol/nexus/analyzer"
+	"github.com/oasisprotocol/nexus/analyzer/item"
+	"github.com/oasisprotocol/nexus/analyzer/queries"
+	"github.com/oasisprotocol/nexus/config"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage"
+	"github.com/oasisprotocol/nexus/storage/oasis/nodeapi"
+)
+
+// The validator uptime analyzer (1) gets the next epoch to process, (2) downloads the
+// active validator set at the start of that epoch, and (3) saves it in chain.epochs,
+// together with the signed, missed and proposed block counts of the active validators
+// of the previous epoch, which is over by then.
+//
+// The counts are computed from the signers and proposers of the blocks in chain.blocks.
+// A validator missed a block if it was in the active validator set of the block's epoch,
+// but did not sign the block. Since the signers of a block are only known once the next
+// block is processed, the analyzer needs the first block of an epoch to be processed
+// before it counts the blocks of the previous epoch.
+//
+// WARNING: Like the validator staking history analyzer, this analyzer SHOULD NOT run while
+// block analyzers are in fast sync. It processes epochs sequentially and expects that when
+// a row appears in `chain.epochs`, all blocks before its first block are already processed.
+
+const (
+	validatorUptimeAnalyzerName = "validator_uptime"
+)
+
+type processor struct {
+	source     nodeapi.ConsensusApiLite
+	target     storage.TargetStorage
+	startEpoch uint64
+	logger     *log.Logger
+}
+
+var _ item.ItemProcessor[*Epoch] = (*processor)(nil)
+
+func NewAnalyzer(
+	initCtx context.Context,
+	cfg config.ItemBasedAnalyzerConfig,
+	startHeight uint64,
+	sourceClient nodeapi.ConsensusApiLite,
+	target storage.TargetStorage,
+	logger *log.Logger,
+) (analyzer.Analyzer, error) {
+	logger = logger.With("analyzer", validatorUptimeAnalyzerName)
+
+	// Find the epoch corresponding to startHeight.
+	if startHeight > math.MaxInt64 {
+		return nil, fmt.Errorf("startHeight %d is too large", startHeight)
+	}
+	epoch, err := sourceClient.GetEpoch(initCtx, int64(startHeight))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch epoch for startHeight %d: %w", startHeight, err)
+	}
+	p := &processor{
+		source:     sourceClient,
+		target:     target,
+		startEpoch: uint64(epoch),
+		logger:     logger,
+	}
+
+	return item.NewAnalyzer[*Epoch](
+		validatorUptimeAnalyzerName,
+		cfg,
+		p,
+		target,
+		logger,
+	)
+}
+
+type Epoch struct {
+	epoch       uint64
+	startHeight int64
+}
+
+// Note: limit is ignored here because epochs must be processed sequentially in chronological order.
+func (p *processor) GetItems(ctx context.Context, limit uint64) ([]*Epoch, error) {
+	var epoch Epoch
+	err := p.target.QueryRow(
+		ctx,
+		queries.ValidatorUptimeUnprocessedEpochs,
+		p.startEpoch,
+		1, // overwrite limit to 1
+	).Scan(
+		&epoch.epoch,
+		&epoch.startHeight,
+	)
+	switch err {
+	case nil:
+		return []*Epoch{&epoch}, nil
+	case storage.ErrNoRows:
+		return []*Epoch{}, nil
+	default:
+		return nil, fmt.Errorf("querying epochs for validator uptime: %w", err)
+	}
+}
+
+func (p *processor) ProcessItem(ctx context.Context, batch *storage.QueryBatch, epoch *Epoch) error {
+	validators, err := p.source.GetValidators(ctx, epoch.startHeight)
+	if err != nil {
+		return fmt.Errorf("downloading validators for height %d: %w", epoch.startHeight, err)
+	}
+	nodes, err := p.source.GetNodes(ctx, epoch.startHeight)
+	if err != nil {
+		return fmt.Errorf("downloading nodes for height %d: %w", epoch.startHeight, err)
+	}
+	nodeToEntity := make(map[signature.PublicKey]signature.PublicKey)
+	for _, n := range nodes {
+		nodeToEntity[n.ID] = n.EntityID
+	}
+
+	// The ID returned by validator objects is the node ID, but blocks reference validators by entity ID.
+	entityIDs := make([]string, 0, len(validators))
+	for _, v := range validators {
+		entityID, ok := nodeToEntity[v.ID]
+		if !ok {
+			p.logger.Warn("could not convert validator node id to entity id (node not found)",
+				"epoch", epoch.epoch,
+				"height", epoch.startHeight,
+				"node_id", v.ID,
+			)
+			continue
+		}
+		entityIDs = append(entityIDs, entityID.String())
+	}
+
+	// The previous epoch is over, count the blocks that its active validators signed.
+	// This is a no-op if the active validators of the previous epoch are not known,
+	// e.g. for the first processed epoch.
+	if epoch.epoch > 0 {
+		batch.Queue(queries.ValidatorUptimesUpsert, epoch.epoch-1)
+	}
+	batch.Queue(queries.EpochActiveValidatorsUpdate,
+		epoch.epoch,
+		entityIDs,
+	)
+	p.logger.Info("processed epoch", "epoch", epoch.epoch, "num_validators", len(entityIDs))
+
+	return nil
+}
+
+func (p *processor) QueueLength(ctx context.Context) (int, error) {
+	var queueLength int
+	if err := p.target.QueryRow(ctx, queries.ValidatorUptimeUnprocessedCount, p.startEpoch).Scan(&queueLength); err != nil {
+		return 0, fmt.Errorf("querying number of unprocessed epochs: %w", err)
+	}
+	return queueLength, nil
+}
@@ -0,0 +1,67 @@
+package validatoruptime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+
+	"github.com/oasisprotocol/nexus/analyzer/queries"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage"
+	"github.com/oasisprotocol/nexus/storage/oasis/nodeapi"
+)
+
+// mockSource serves the validators and nodes at the start of an epoch.
+type mockSource struct {
+	nodeapi.ConsensusApiLite
+	validators []nodeapi.Validator
+	nodes      []nodeapi.Node
+}
+
+func (s *mockSource) GetValidators(ctx context.Context, height int64) ([]nodeapi.Validator, error) {
+	return s.validators, nil
+}
+
+func (s *mockSource) GetNodes(ctx context.Context, height int64) ([]nodeapi.Node, error) {
+	return s.nodes, nil
+}
+
+func testKey(b byte) signature.PublicKey {
+	var k signature.PublicKey
+	k[0] = b
+	return k
+}
+
+func TestProcessItem(t *testing.T) {
+	entity1, node1 := testKey(1), testKey(11)
+	entity2, node2 := testKey(2), testKey(12)
+	unknownNode := testKey(13)
+	p := &processor{
+		source: &mockSource{
+			validators: []nodeapi.Validator{{ID: node1}, {ID: node2}, {ID: unknownNode}},
+			nodes:      []nodeapi.Node{{ID: node1, EntityID: entity1}, {ID: node2, EntityID: entity2}},
+		},
+		logger: log.NewDefaultLogger("testing"),
+	}
+
+	// The uptimes of the previous epoch are computed before its successor's validators are saved.
+	batch := &storage.QueryBatch{}
+	require.NoError(t, p.ProcessItem(context.Background(), batch, &Epoch{epoch: 5, startHeight: 500}))
+	items := batch.Queries()
+	require.Len(t, items, 2)
+	require.Equal(t, queries.ValidatorUptimesUpsert, items[0].Cmd)
+	require.Equal(t, []interface{}{uint64(4)}, items[0].Args)
+	// Validators whose node is not registered are skipped, since blocks reference entities.
+	require.Equal(t, queries.EpochActiveValidatorsUpdate, items[1].Cmd)
+	require.Equal(t, []interface{}{uint64(5), []string{entity1.String(), entity2.String()}}, items[1].Args)
+
+	// There is no epoch before the first one.
+	batch = &storage.QueryBatch{}
+	require.NoError(t, p.ProcessItem(context.Background(), batch, &Epoch{epoch: 0, startHeight: 1}))
+	items = batch.Queries()
+	require.Len(t, items, 1)
+	require.Equal(t, queries.EpochActiveValidatorsUpdate, items[0].Cmd)
+}
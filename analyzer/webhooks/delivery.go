@@ -0,0 +1,79 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// DeliveryIDHeader is the ID of the delivery. It is the same for all
+	// attempts to deliver an event, so receivers can use it to deduplicate.
+	DeliveryIDHeader = "X-Nexus-Delivery"
+	// TimestampHeader is the time of the attempt, in unix seconds.
+	TimestampHeader = "X-Nexus-Timestamp"
+	// SignatureHeader is `sha256=` followed by the hex-encoded
+	// HMAC-SHA256 of `<timestamp>.<body>`, keyed with the subscription secret.
+	SignatureHeader = "X-Nexus-Signature"
+
+	// Responses are not used; read only this much of them so that the
+	// connection can be reused.
+	maxResponseBytes = 64 * 1024
+)
+
+// Sign returns the value of the SignatureHeader for a request with the
+// given timestamp and body.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// sender POSTs events to webhook URLs.
+//
+// Unlike pubclient, it can connect to any host: the URLs come from the
+// config, not from the chain, and are commonly on a private network.
+type sender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+func newSender(timeout time.Duration) *sender {
+	return &sender{
+		client: &http.Client{Timeout: timeout},
+		now:    time.Now,
+	}
+}
+
+// send makes one attempt to deliver the body to the URL. Any non-2xx
+// response is an error; redirects are followed.
+func (s *sender) send(ctx context.Context, url string, secret string, deliveryID uint64, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	timestamp := s.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeliveryIDHeader, strconv.FormatUint(deliveryID, 10))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,326 @@
+// Package webhooks implements an analyzer that notifies external services
+// about newly indexed events of watched accounts, e.g. transfers, slashing
+// (`staking.escrow.take`) or governance proposal updates.
+//
+// Each subscription from the config watches the events of one layer. Once the
+// block analyzer of that layer has committed a block, the events of the block
+// whose type and related accounts match the subscription are queued in
+// analysis.webhook_deliveries. Matching the new blocks of a subscription and
+// delivering a queued event are both work items of the analyzer. Each queued event is then POSTed to the
+// subscription URL as a JSON object, signed with the subscription secret (see
+// SignatureHeader). Failed deliveries are retried with exponential backoff, and
+// moved to analysis.webhook_dead_letters after MaxAttempts attempts.
+//
+// Delivery is at-least-once, and events are not necessarily delivered in order.
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/oasisprotocol/nexus/analyzer"
+	"github.com/oasisprotocol/nexus/analyzer/item"
+	"github.com/oasisprotocol/nexus/analyzer/queries"
+	"github.com/oasisprotocol/nexus/common"
+	"github.com/oasisprotocol/nexus/config"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage"
+)
+
+const (
+	WebhooksAnalyzerName = "webhooks"
+
+	defaultMaxAttempts   = 8
+	defaultRetryDelay    = 10 * time.Second
+	defaultMaxRetryDelay = time.Hour
+	defaultTimeout       = 10 * time.Second
+	defaultInterval      = 5 * time.Second
+
+	// Max number of blocks (or rounds) whose events are matched against a
+	// subscription in one batch, so that a subscription that has fallen
+	// behind catches up in bounded steps.
+	matchBatchSize = 1000
+)
+
+// Item is a work item of the webhooks analyzer. Exactly one of its fields is set.
+type Item struct {
+	Match    *Match
+	Delivery *Delivery
+}
+
+// Match is a range of blocks (or rounds) whose events are to be matched
+// against a subscription.
+type Match struct {
+	SubscriptionID string
+	// The range is (From, To].
+	From uint64
+	To   uint64
+}
+
+// Delivery is a queued notification about one event.
+type Delivery struct {
+	ID             uint64
+	SubscriptionID string
+	Payload        json.RawMessage
+	NumAttempts    uint64
+}
+
+type processor struct {
+	subscriptions map[string]config.WebhookSubscriptionConfig
+	sender        *sender
+	maxAttempts   uint64
+	retryDelay    time.Duration
+	maxRetryDelay time.Duration
+	target        storage.TargetStorage
+	logger        *log.Logger
+}
+
+var _ item.ItemProcessor[*Item] = (*processor)(nil)
+
+func NewAnalyzer(
+	initCtx context.Context,
+	cfg config.WebhooksConfig,
+	target storage.TargetStorage,
+	logger *log.Logger,
+) (analyzer.Analyzer, error) {
+	logger.Info("Starting webhooks analyzer")
+	if cfg.Interval == 0 {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.MaxAttempts == 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.RetryDelay == 0 {
+		cfg.RetryDelay = defaultRetryDelay
+	}
+	if cfg.MaxRetryDelay == 0 {
+		cfg.MaxRetryDelay = defaultMaxRetryDelay
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	logger = logger.With("analyzer", WebhooksAnalyzerName)
+	p := &processor{
+		subscriptions: map[string]config.WebhookSubscriptionConfig{},
+		sender:        newSender(cfg.Timeout),
+		maxAttempts:   cfg.MaxAttempts,
+		retryDelay:    cfg.RetryDelay,
+		maxRetryDelay: cfg.MaxRetryDelay,
+		target:        target,
+		logger:        logger,
+	}
+	for _, sub := range cfg.Subscriptions {
+		p.subscriptions[sub.ID] = sub
+	}
+	if err := p.syncSubscriptions(initCtx); err != nil {
+		return nil, fmt.Errorf("syncing webhook subscriptions: %w", err)
+	}
+
+	return item.NewAnalyzer[*Item](
+		WebhooksAnalyzerName,
+		cfg.ItemBasedAnalyzerConfig,
+		p,
+		target,
+		logger,
+	)
+}
+
+// syncSubscriptions makes the subscriptions in the DB match the config.
+// New subscriptions start at the indexed tip of their layer, i.e. they are
+// only notified about events indexed from now on.
+func (p *processor) syncSubscriptions(ctx context.Context) error {
+	batch := &storage.QueryBatch{}
+	ids := []string{}
+	for id, sub := range p.subscriptions {
+		tip, err := p.indexedTip(ctx, sub.Layer)
+		if err != nil {
+			return err
+		}
+		batch.Queue(queries.WebhookSubscriptionUpsert,
+			id,
+			sub.URL,
+			string(sub.Layer),
+			nonNil(sub.Addresses),
+			nonNil(sub.EventTypes),
+			tip,
+		)
+		ids = append(ids, id)
+	}
+	batch.Queue(queries.WebhookSubscriptionsDeleteOthers, ids)
+	return p.target.SendBatch(ctx, batch)
+}
+
+// indexedTip returns the height (or round) up to which all blocks of the
+// layer have been committed by its block analyzer, or 0 if none have.
+func (p *processor) indexedTip(ctx context.Context, layer common.Layer) (uint64, error) {
+	var firstUnprocessed *uint64
+	if err := p.target.QueryRow(ctx, queries.FirstUnprocessedBlock, string(layer)).Scan(&firstUnprocessed); err != nil {
+		return 0, fmt.Errorf("querying indexed tip of %s: %w", layer, err)
+	}
+	if firstUnprocessed == nil || *firstUnprocessed == 0 {
+		return 0, nil
+	}
+	return *firstUnprocessed - 1, nil
+}
+
+// pendingMatches returns the blocks that were committed since each
+// subscription was last matched, in bounded steps.
+func (p *processor) pendingMatches(ctx context.Context) ([]*Item, error) {
+	rows, err := p.target.Query(ctx, queries.WebhookSubscriptions)
+	if err != nil {
+		return nil, fmt.Errorf("querying subscriptions: %w", err)
+	}
+	defer rows.Close()
+	lastHeights := map[string]uint64{}
+	for rows.Next() {
+		var id string
+		var lastHeight uint64
+		if err = rows.Scan(&id, &lastHeight); err != nil {
+			return nil, fmt.Errorf("scanning subscription: %w", err)
+		}
+		lastHeights[id] = lastHeight
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating subscriptions: %w", err)
+	}
+	rows.Close()
+
+	tips := map[common.Layer]uint64{}
+	var items []*Item
+	for id, lastHeight := range lastHeights {
+		sub, ok := p.subscriptions[id]
+		if !ok {
+			continue
+		}
+		tip, ok := tips[sub.Layer]
+		if !ok {
+			if tip, err = p.indexedTip(ctx, sub.Layer); err != nil {
+				return nil, err
+			}
+			tips[sub.Layer] = tip
+		}
+		if tip <= lastHeight {
+			continue
+		}
+		items = append(items, &Item{Match: &Match{
+			SubscriptionID: id,
+			From:           lastHeight,
+			To:             min(tip, lastHeight+matchBatchSize),
+		}})
+	}
+	return items, nil
+}
+
+func (p *processor) GetItems(ctx context.Context, limit uint64) ([]*Item, error) {
+	items, err := p.pendingMatches(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("matching events to subscriptions: %w", err)
+	}
+
+	rows, err := p.target.Query(ctx, queries.WebhookDueDeliveries, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying due deliveries: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var d Delivery
+		if err = rows.Scan(
+			&d.ID,
+			&d.SubscriptionID,
+			&d.Payload,
+			&d.NumAttempts,
+		); err != nil {
+			return nil, fmt.Errorf("scanning delivery: %w", err)
+		}
+		items = append(items, &Item{Delivery: &d})
+	}
+	return items, nil
+}
+
+func (p *processor) ProcessItem(ctx context.Context, batch *storage.QueryBatch, it *Item) error {
+	if it.Match != nil {
+		return p.processMatch(batch, it.Match)
+	}
+	return p.processDelivery(ctx, batch, it.Delivery)
+}
+
+// processMatch queues deliveries for the matching events, and advances the
+// subscription past the matched blocks.
+func (p *processor) processMatch(batch *storage.QueryBatch, m *Match) error {
+	sub, ok := p.subscriptions[m.SubscriptionID]
+	if !ok {
+		return fmt.Errorf("unknown subscription %s", m.SubscriptionID)
+	}
+	if sub.Layer == common.LayerConsensus {
+		batch.Queue(queries.WebhookConsensusEventsEnqueue, m.SubscriptionID, m.From, m.To, nonNil(sub.Addresses), nonNil(sub.EventTypes))
+	} else {
+		batch.Queue(queries.WebhookRuntimeEventsEnqueue, m.SubscriptionID, string(sub.Layer), m.From, m.To, nonNil(sub.Addresses), nonNil(sub.EventTypes))
+	}
+	batch.Queue(queries.WebhookSubscriptionSetLastHeight, m.SubscriptionID, m.To)
+	return nil
+}
+
+// processDelivery attempts to deliver the event. A failed attempt is recorded
+// for a later retry rather than returned as an error.
+func (p *processor) processDelivery(ctx context.Context, batch *storage.QueryBatch, d *Delivery) error {
+	sub, ok := p.subscriptions[d.SubscriptionID]
+	if !ok {
+		// Deliveries of subscriptions that are not in the config are deleted
+		// on startup, along with the subscriptions, so this should not happen.
+		// Do not retry the delivery, which would block the queue.
+		p.logger.Warn("dropping webhook delivery of unknown subscription",
+			"subscription_id", d.SubscriptionID,
+			"delivery_id", d.ID,
+		)
+		batch.Queue(queries.WebhookDeliveryDeadLetter, d.ID, d.NumAttempts, "unknown subscription")
+		return nil
+	}
+	err := p.sender.send(ctx, sub.URL, sub.Secret, d.ID, d.Payload)
+	if err == nil {
+		batch.Queue(queries.WebhookDeliveryDelete, d.ID)
+		return nil
+	}
+
+	numAttempts := d.NumAttempts + 1
+	p.logger.Warn("webhook delivery failed",
+		"subscription_id", d.SubscriptionID,
+		"delivery_id", d.ID,
+		"num_attempts", numAttempts,
+		"err", err,
+	)
+	if numAttempts >= p.maxAttempts {
+		batch.Queue(queries.WebhookDeliveryDeadLetter, d.ID, numAttempts, err.Error())
+		return nil
+	}
+	batch.Queue(queries.WebhookDeliveryRetry, d.ID, numAttempts, p.backoff(numAttempts).Milliseconds(), err.Error())
+	return nil
+}
+
+// backoff returns the delay before the next attempt after numAttempts
+// failed ones.
+func (p *processor) backoff(numAttempts uint64) time.Duration {
+	delay := p.retryDelay
+	for i := uint64(1); i < numAttempts && delay < p.maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, p.maxRetryDelay)
+}
+
+func (p *processor) QueueLength(ctx context.Context) (int, error) {
+	var queueLength int
+	if err := p.target.QueryRow(ctx, queries.WebhookDueDeliveriesCount).Scan(&queueLength); err != nil {
+		return 0, fmt.Errorf("querying number of due deliveries: %w", err)
+	}
+	return queueLength, nil
+}
+
+// nonNil returns an empty slice for nil, so that it is stored as an empty
+// array rather than NULL.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
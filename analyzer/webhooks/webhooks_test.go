@@ -0,0 +1,203 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/nexus/analyzer/queries"
+	"github.com/oasisprotocol/nexus/common"
+	"github.com/oasisprotocol/nexus/config"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage"
+)
+
+const testSecret = "s3cr3t"
+
+// receivedRequest is a request received by the stub webhook receiver.
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// stubReceiver is a local webhook receiver that responds with the given
+// status codes in turn, and records the requests it receives.
+type stubReceiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	requests []receivedRequest
+}
+
+func newStubReceiver(t *testing.T, statuses ...int) *stubReceiver {
+	r := &stubReceiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		status := r.statuses[min(len(r.requests), len(r.statuses)-1)]
+		r.requests = append(r.requests, receivedRequest{header: req.Header.Clone(), body: body})
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *stubReceiver) received() []receivedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests
+}
+
+func newTestProcessor(url string) *processor {
+	s := newSender(time.Second)
+	s.now = func() time.Time { return time.Unix(1_700_000_000, 0) }
+	return &processor{
+		subscriptions: map[string]config.WebhookSubscriptionConfig{
+			"treasury": {
+				ID:     "treasury",
+				URL:    url,
+				Secret: testSecret,
+				Layer:  common.LayerConsensus,
+			},
+			"dex": {
+				ID:         "dex",
+				URL:        url,
+				Secret:     testSecret,
+				Layer:      common.LayerSapphire,
+				Addresses:  []string{"oasis1qpg2xuz46g53737343r20yxeddhlvc2ldqsjh70p"},
+				EventTypes: []string{"evm.log"},
+			},
+		},
+		sender:        s,
+		maxAttempts:   3,
+		retryDelay:    10 * time.Second,
+		maxRetryDelay: 30 * time.Second,
+		logger:        log.NewDefaultLogger("testing"),
+	}
+}
+
+func testDelivery(numAttempts uint64) *Delivery {
+	return &Delivery{
+		ID:             42,
+		SubscriptionID: "treasury",
+		Payload:        []byte(`{"layer":"consensus","height":100,"type":"staking.escrow.take"}`),
+		NumAttempts:    numAttempts,
+	}
+}
+
+func TestDeliverySigned(t *testing.T) {
+	receiver := newStubReceiver(t, http.StatusOK)
+	p := newTestProcessor(receiver.URL)
+	d := testDelivery(0)
+
+	batch := &storage.QueryBatch{}
+	require.NoError(t, p.ProcessItem(context.Background(), batch, &Item{Delivery: d}))
+
+	require.Len(t, receiver.received(), 1)
+	req := receiver.received()[0]
+	require.Equal(t, []byte(d.Payload), req.body)
+	require.Equal(t, "application/json", req.header.Get("Content-Type"))
+	require.Equal(t, "42", req.header.Get(DeliveryIDHeader))
+	require.Equal(t, "1700000000", req.header.Get(TimestampHeader))
+
+	// A receiver can verify the signature with the shared secret.
+	timestamp, err := strconv.ParseInt(req.header.Get(TimestampHeader), 10, 64)
+	require.NoError(t, err)
+	require.Equal(t, Sign(testSecret, timestamp, req.body), req.header.Get(SignatureHeader))
+	require.NotEqual(t, Sign("wrong", timestamp, req.body), req.header.Get(SignatureHeader))
+
+	// The delivered event is removed from the queue.
+	require.Len(t, batch.Queries(), 1)
+	require.Equal(t, queries.WebhookDeliveryDelete, batch.Queries()[0].Cmd)
+	require.Equal(t, []interface{}{uint64(42)}, batch.Queries()[0].Args)
+}
+
+func TestDeliveryRetried(t *testing.T) {
+	receiver := newStubReceiver(t, http.StatusServiceUnavailable)
+	p := newTestProcessor(receiver.URL)
+
+	batch := &storage.QueryBatch{}
+	require.NoError(t, p.ProcessItem(context.Background(), batch, &Item{Delivery: testDelivery(1)}))
+
+	require.Len(t, receiver.received(), 1)
+	require.Len(t, batch.Queries(), 1)
+	require.Equal(t, queries.WebhookDeliveryRetry, batch.Queries()[0].Cmd)
+	require.Equal(t, []interface{}{uint64(42), uint64(2), int64(20_000), "HTTP 503"}, batch.Queries()[0].Args)
+}
+
+func TestDeliveryDeadLettered(t *testing.T) {
+	receiver := newStubReceiver(t, http.StatusNotFound)
+	p := newTestProcessor(receiver.URL)
+
+	batch := &storage.QueryBatch{}
+	require.NoError(t, p.ProcessItem(context.Background(), batch, &Item{Delivery: testDelivery(2)}))
+
+	require.Len(t, batch.Queries(), 1)
+	require.Equal(t, queries.WebhookDeliveryDeadLetter, batch.Queries()[0].Cmd)
+	require.Equal(t, []interface{}{uint64(42), uint64(3), "HTTP 404"}, batch.Queries()[0].Args)
+}
+
+func TestDeliveryUnreachable(t *testing.T) {
+	receiver := newStubReceiver(t, http.StatusOK)
+	p := newTestProcessor(receiver.URL)
+	receiver.Close()
+
+	batch := &storage.QueryBatch{}
+	require.NoError(t, p.ProcessItem(context.Background(), batch, &Item{Delivery: testDelivery(0)}))
+
+	require.Len(t, batch.Queries(), 1)
+	require.Equal(t, queries.WebhookDeliveryRetry, batch.Queries()[0].Cmd)
+}
+
+func TestDeliveryOfUnknownSubscription(t *testing.T) {
+	receiver := newStubReceiver(t, http.StatusOK)
+	p := newTestProcessor(receiver.URL)
+	d := testDelivery(1)
+	d.SubscriptionID = "removed"
+
+	batch := &storage.QueryBatch{}
+	require.NoError(t, p.ProcessItem(context.Background(), batch, &Item{Delivery: d}))
+
+	// The delivery is dead-lettered rather than retried forever.
+	require.Empty(t, receiver.received())
+	require.Len(t, batch.Queries(), 1)
+	require.Equal(t, queries.WebhookDeliveryDeadLetter, batch.Queries()[0].Cmd)
+	require.Equal(t, []interface{}{uint64(42), uint64(1), "unknown subscription"}, batch.Queries()[0].Args)
+}
+
+func TestMatch(t *testing.T) {
+	p := newTestProcessor("")
+
+	batch := &storage.QueryBatch{}
+	require.NoError(t, p.ProcessItem(context.Background(), batch, &Item{Match: &Match{SubscriptionID: "treasury", From: 10, To: 20}}))
+	require.Len(t, batch.Queries(), 2)
+	require.Equal(t, queries.WebhookConsensusEventsEnqueue, batch.Queries()[0].Cmd)
+	require.Equal(t, []interface{}{"treasury", uint64(10), uint64(20), []string{}, []string{}}, batch.Queries()[0].Args)
+	require.Equal(t, queries.WebhookSubscriptionSetLastHeight, batch.Queries()[1].Cmd)
+	require.Equal(t, []interface{}{"treasury", uint64(20)}, batch.Queries()[1].Args)
+
+	batch = &storage.QueryBatch{}
+	require.NoError(t, p.ProcessItem(context.Background(), batch, &Item{Match: &Match{SubscriptionID: "dex", From: 0, To: 5}}))
+	require.Len(t, batch.Queries(), 2)
+	require.Equal(t, queries.WebhookRuntimeEventsEnqueue, batch.Queries()[0].Cmd)
+	require.Equal(t, []interface{}{"dex", "sapphire", uint64(0), uint64(5), []string{"oasis1qpg2xuz46g53737343r20yxeddhlvc2ldqsjh70p"}, []string{"evm.log"}}, batch.Queries()[0].Args)
+	require.Equal(t, queries.WebhookSubscriptionSetLastHeight, batch.Queries()[1].Cmd)
+	require.Equal(t, []interface{}{"dex", uint64(5)}, batch.Queries()[1].Args)
+}
+
+func TestBackoff(t *testing.T) {
+	p := newTestProcessor("")
+	require.Equal(t, 10*time.Second, p.backoff(1))
+	require.Equal(t, 20*time.Second, p.backoff(2))
+	require.Equal(t, 30*time.Second, p.backoff(3))
+	require.Equal(t, 30*time.Second, p.backoff(100))
+}
@@ -23,6 +23,9 @@ var (
 	// ErrNotFound is returned when handling a request for an item that
 	// does not exist in the DB.
 	ErrNotFound = errors.New("item not found")
+	// ErrUnauthorized is returned when a request that requires an API key
+	// does not have a valid one.
+	ErrUnauthorized = errors.New("missing or invalid api key")
 )
 
 type ErrStorageError struct{ Err error }
@@ -50,6 +53,8 @@ func HttpCodeForError(err error) int {
 		return http.StatusBadRequest
 	case errors.Is(err, ErrNotFound):
 		return http.StatusNotFound
+	case errors.Is(err, ErrUnauthorized):
+		return http.StatusUnauthorized
 	case errType == reflect.TypeOf(ErrStorageError{}):
 		return http.StatusInternalServerError
 	case (errType == reflect.TypeOf(apiTypes.InvalidParamFormatError{}) ||
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"crypto/subtle"
 	"net/http"
 	"reflect"
 	"strings"
@@ -262,6 +263,54 @@ func ParseBigIntParamsMiddleware(next apiTypes.StrictHandlerFunc, _operationID s
 	}
 }
 
+// MaxRequestBodyMiddleware limits the size of request bodies to maxBytes.
+// Reading beyond the limit fails, which makes the request fail to parse.
+func MaxRequestBodyMiddleware(maxBytes int64) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireAPIKeyMiddleware rejects requests to `/{runtime}/evm_contract_verifications`,
+// i.e. submissions of contract sources for verification, without one of the keys in an
+// `Authorization: Bearer <key>` header. Other requests are passed through unchanged.
+func RequireAPIKeyMiddleware(baseURL string, keys []string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isContractVerificationSubmission(strings.TrimPrefix(r.URL.Path, baseURL)) && !hasAPIKey(r, keys) {
+				HumanReadableJsonErrorHandler(w, r, ErrUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isContractVerificationSubmission returns whether path, relative to the base URL,
+// is that of `/{runtime}/evm_contract_verifications`.
+func isContractVerificationSubmission(path string) bool {
+	parts := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	return len(parts) == 3 && parts[0] == "" && parts[1] != "" && parts[2] == "evm_contract_verifications"
+}
+
+func hasAPIKey(r *http.Request, keys []string) bool {
+	key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || key == "" {
+		return false
+	}
+	for _, k := range keys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(k)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
 // RuntimeFromURLMiddleware extracts the runtime from the URL and sets it in the request context.
 // The runtime is expected to be the first part of the path after the `baseURL` (e.g. "/v1").
 func RuntimeFromURLMiddleware(baseURL string) func(next http.Handler) http.Handler {
@@ -296,15 +345,15 @@ func RuntimeFromURLMiddleware(baseURL string) func(next http.Handler) http.Handl
 	}
 }
 
-// CorsMiddleware is a restrictive CORS middleware that only allows GET requests.
+// CorsMiddleware is a restrictive CORS middleware that only allows GET and POST requests.
 //
-// NOTE: To support other methods (e.g. POST), we'd also need to support OPTIONS
-// preflight requests, in which case this would have to be the outermost handler
-// to run; the openapi-generated handler will reject OPTIONS requests because
-// they are not in the openapi spec.
+// POST is allowed for the GraphQL endpoint. The middleware answers the OPTIONS
+// preflight requests for it itself; the openapi-generated handler would reject
+// them because they are not in the openapi spec.
 var CorsMiddleware func(http.Handler) http.Handler = cors.New(cors.Options{
 	AllowedMethods: []string{
 		http.MethodGet,
+		http.MethodPost,
 	},
 	AllowCredentials: false,
 }).Handler
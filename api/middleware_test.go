@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireAPIKeyMiddleware(t *testing.T) {
+	handler := RequireAPIKeyMiddleware("/v1", []string{"key1", "key2"})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		}),
+	)
+
+	const submissionPath = "/v1/sapphire/evm_contract_verifications"
+	for _, tc := range []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		expected      int
+	}{
+		{"submission without key", http.MethodPost, submissionPath, "", http.StatusUnauthorized},
+		{"submission with key", http.MethodPost, submissionPath, "Bearer key1", http.StatusAccepted},
+		{"submission with other key", http.MethodPost, submissionPath, "Bearer key2", http.StatusAccepted},
+		{"submission with trailing slash", http.MethodPost, submissionPath + "/", "", http.StatusUnauthorized},
+		{"submission with wrong key", http.MethodPost, submissionPath, "Bearer key3", http.StatusUnauthorized},
+		{"submission with key prefix", http.MethodPost, submissionPath, "Bearer key", http.StatusUnauthorized},
+		{"submission with empty key", http.MethodPost, submissionPath, "Bearer ", http.StatusUnauthorized},
+		{"submission with other scheme", http.MethodPost, submissionPath, "Basic key1", http.StatusUnauthorized},
+		{"request status without key", http.MethodGet, submissionPath + "/1", "", http.StatusAccepted},
+		{"other post without key", http.MethodPost, "/v1/graphql", "", http.StatusAccepted},
+		{"other runtime route without key", http.MethodPost, "/v1/sapphire/evm_tokens", "", http.StatusAccepted},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			require.Equal(t, tc.expected, rec.Code)
+		})
+	}
+}
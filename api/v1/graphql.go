@@ -0,0 +1,145 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+
+	apiCommon "github.com/oasisprotocol/nexus/api"
+	"github.com/oasisprotocol/nexus/config"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage/client"
+)
+
+const (
+	// Page size of paginated fields, like in the REST API.
+	graphqlDefaultLimit = 100
+	graphqlMaxLimit     = 1000
+	// Default limits on the cost of queries. They allow e.g. fetching an
+	// account with a full default page of each of its delegations and
+	// transactions, but not arbitrarily many such pages.
+	graphqlDefaultMaxComplexity = 10_000
+	graphqlDefaultMaxDepth      = 8
+	// Maximum size of the body of POST requests.
+	graphqlMaxRequestBytes = 1 << 20
+)
+
+// GraphQLServer serves a GraphQL API over the indexed data, so that clients
+// can fetch related entities (e.g. an account with its delegations and
+// transactions) with a single request. It uses the same StorageClient
+// methods as the REST API to resolve fields.
+//
+// To protect the DB, queries are rejected before they are executed if their
+// estimated cost or nesting depth exceeds the configured limits.
+type GraphQLServer struct {
+	schema        graphql.Schema
+	maxComplexity int
+	maxDepth      int
+	logger        log.Logger
+}
+
+func NewGraphQLServer(client client.StorageClient, logger log.Logger, cfg config.GraphQLConfig) (*GraphQLServer, error) {
+	schema, err := newGraphQLSchema(client)
+	if err != nil {
+		return nil, fmt.Errorf("creating graphql schema: %w", err)
+	}
+	s := &GraphQLServer{
+		schema:        schema,
+		maxComplexity: cfg.MaxComplexity,
+		maxDepth:      cfg.MaxDepth,
+		logger:        logger,
+	}
+	if s.maxComplexity == 0 {
+		s.maxComplexity = graphqlDefaultMaxComplexity
+	}
+	if s.maxDepth == 0 {
+		s.maxDepth = graphqlDefaultMaxDepth
+	}
+	return s, nil
+}
+
+// graphqlRequest is a GraphQL request, as sent in the body of POST requests
+// or in the query parameters of GET requests.
+type graphqlRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// ServeHTTP handles GraphQL requests over HTTP. Errors in the query are
+// reported in the `errors` field of the response, as per the GraphQL spec;
+// only malformed HTTP requests result in an error status code.
+func (s *GraphQLServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req graphqlRequest
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if q.Has("variables") {
+			if err := json.Unmarshal([]byte(q.Get("variables")), &req.Variables); err != nil {
+				apiCommon.HumanReadableJsonErrorHandler(w, r, fmt.Errorf("%w: malformed variables: %v", apiCommon.ErrBadRequest, err))
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, graphqlMaxRequestBytes)).Decode(&req); err != nil {
+			apiCommon.HumanReadableJsonErrorHandler(w, r, fmt.Errorf("%w: malformed request body: %v", apiCommon.ErrBadRequest, err))
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if req.Query == "" {
+		apiCommon.HumanReadableJsonErrorHandler(w, r, fmt.Errorf("%w: missing query", apiCommon.ErrBadRequest))
+		return
+	}
+
+	result := s.execute(r.Context(), req)
+
+	w.Header().Set("content-type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		s.logger.Error("failed to write graphql response", "err", err)
+	}
+}
+
+// execute parses and validates the query, checks its cost against the
+// limits and, if it is within them, executes it.
+func (s *GraphQLServer) execute(ctx context.Context, req graphqlRequest) *graphql.Result {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	if validation := graphql.ValidateDocument(&s.schema, doc, nil); !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}
+	}
+
+	cost, err := estimateQueryCost(&s.schema, doc, req.OperationName, req.Variables, graphqlDefaultLimit, graphqlMaxLimit)
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	switch {
+	case cost.Depth > s.maxDepth:
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(fmt.Errorf("query depth %d exceeds the maximum of %d", cost.Depth, s.maxDepth))}
+	case cost.Complexity > s.maxComplexity:
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(fmt.Errorf("query complexity %d exceeds the maximum of %d; request fewer fields or smaller pages (`limit`)", cost.Complexity, s.maxComplexity))}
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+}
@@ -0,0 +1,201 @@
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Name of the argument that sets the page size of paginated fields.
+const graphqlLimitArg = "limit"
+
+// queryCost is the estimated cost of (a part of) a GraphQL query.
+type queryCost struct {
+	// Complexity is the estimated number of fields that the query resolves.
+	// Each field costs 1; the cost of the fields nested in a paginated field
+	// (i.e. one with a `limit` argument) is multiplied by its page size.
+	Complexity int
+	// Depth is the maximum nesting depth of fields.
+	Depth int
+}
+
+// queryCostEstimator estimates the cost of an operation in a validated
+// GraphQL document before it is executed, so that expensive queries can be
+// rejected without touching the DB.
+type queryCostEstimator struct {
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	// Values of the variables, as provided by the client and as defaulted
+	// by the operation.
+	variables map[string]interface{}
+	// Page size of paginated fields whose `limit` argument has no default.
+	defaultLimit int
+	// Page sizes above this are clamped by the resolvers; cap the estimate too.
+	maxLimit int
+}
+
+// estimateQueryCost returns the cost of the operation with the given name
+// (or of the only operation) in the document. The document must have passed
+// validation against the schema.
+func estimateQueryCost(schema *graphql.Schema, doc *ast.Document, operationName string, variables map[string]interface{}, defaultLimit int, maxLimit int) (queryCost, error) {
+	e := queryCostEstimator{
+		schema:       schema,
+		fragments:    map[string]*ast.FragmentDefinition{},
+		variables:    map[string]interface{}{},
+		defaultLimit: defaultLimit,
+		maxLimit:     maxLimit,
+	}
+	var op *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			e.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				if op != nil {
+					return queryCost{}, fmt.Errorf("must provide operation name if query contains multiple operations")
+				}
+				op = def
+			}
+		}
+	}
+	if op == nil {
+		return queryCost{}, fmt.Errorf("unknown operation named %q", operationName)
+	}
+
+	for _, v := range op.VariableDefinitions {
+		if v.DefaultValue != nil {
+			e.variables[v.Variable.Name.Value] = v.DefaultValue.GetValue()
+		}
+	}
+	for name, value := range variables {
+		e.variables[name] = value
+	}
+
+	var root *graphql.Object
+	switch op.Operation {
+	case ast.OperationTypeQuery:
+		root = schema.QueryType()
+	case ast.OperationTypeMutation:
+		root = schema.MutationType()
+	case ast.OperationTypeSubscription:
+		root = schema.SubscriptionType()
+	}
+	if root == nil {
+		return queryCost{}, fmt.Errorf("schema does not support %s operations", op.Operation)
+	}
+	return e.selectionSetCost(op.SelectionSet, root), nil
+}
+
+// selectionSetCost returns the cost of resolving the selection set on an
+// object of the given type.
+func (e *queryCostEstimator) selectionSetCost(set *ast.SelectionSet, parentType graphql.Named) queryCost {
+	var total queryCost
+	if set == nil {
+		return total
+	}
+	for _, sel := range set.Selections {
+		var c queryCost
+		switch sel := sel.(type) {
+		case *ast.Field:
+			c = e.fieldCost(sel, parentType)
+		case *ast.InlineFragment:
+			fragmentType := parentType
+			if sel.TypeCondition != nil {
+				fragmentType = e.schema.Type(sel.TypeCondition.Name.Value)
+			}
+			c = e.selectionSetCost(sel.SelectionSet, fragmentType)
+		case *ast.FragmentSpread:
+			// Validation guarantees that the fragment exists and that
+			// fragments do not form cycles.
+			if fragment, ok := e.fragments[sel.Name.Value]; ok {
+				c = e.selectionSetCost(fragment.SelectionSet, e.schema.Type(fragment.TypeCondition.Name.Value))
+			}
+		}
+		total.Complexity += c.Complexity
+		if c.Depth > total.Depth {
+			total.Depth = c.Depth
+		}
+	}
+	return total
+}
+
+// fieldCost returns the cost of resolving the field, including the fields
+// nested in it, on an object of the given type.
+func (e *queryCostEstimator) fieldCost(field *ast.Field, parentType graphql.Named) queryCost {
+	cost := queryCost{Complexity: 1, Depth: 1}
+	// Introspection fields (e.g. __schema) are not backed by the DB, and
+	// their cost is bounded by the size of the schema.
+	if strings.HasPrefix(field.Name.Value, "__") {
+		return cost
+	}
+	fielded, ok := parentType.(interface {
+		Fields() graphql.FieldDefinitionMap
+	})
+	if !ok {
+		return cost
+	}
+	def, ok := fielded.Fields()[field.Name.Value]
+	if !ok {
+		return cost
+	}
+
+	nested := e.selectionSetCost(field.SelectionSet, graphql.GetNamed(def.Type))
+	cost.Complexity += e.pageSize(field, def) * nested.Complexity
+	cost.Depth += nested.Depth
+	return cost
+}
+
+// pageSize returns the number of items that a paginated field is expected
+// to return, or 1 for fields that are not paginated.
+func (e *queryCostEstimator) pageSize(field *ast.Field, def *graphql.FieldDefinition) int {
+	var limitArg *graphql.Argument
+	for _, arg := range def.Args {
+		if arg.Name() == graphqlLimitArg {
+			limitArg = arg
+		}
+	}
+	if limitArg == nil {
+		return 1
+	}
+
+	limit := e.defaultLimit
+	if l, ok := limitArg.DefaultValue.(int); ok {
+		limit = l
+	}
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != graphqlLimitArg {
+			continue
+		}
+		var value interface{}
+		switch v := arg.Value.(type) {
+		case *ast.Variable:
+			value = e.variables[v.Name.Value]
+		default:
+			value = v.GetValue()
+		}
+		switch v := value.(type) {
+		case string: // Literal ints are kept as strings in the AST.
+			if l, err := strconv.Atoi(v); err == nil {
+				limit = l
+			}
+		case float64: // JSON-encoded variables are decoded as floats.
+			limit = int(v)
+		case int:
+			limit = v
+		}
+	}
+
+	switch {
+	case limit < 1:
+		// Still count the nested fields once, e.g. the total count of a list.
+		return 1
+	case limit > e.maxLimit:
+		return e.maxLimit
+	default:
+		return limit
+	}
+}
@@ -0,0 +1,482 @@
+package v1
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	apiTypes "github.com/oasisprotocol/nexus/api/v1/types"
+	"github.com/oasisprotocol/nexus/common"
+	staking "github.com/oasisprotocol/nexus/coreapi/v22.2.11/staking/api"
+	"github.com/oasisprotocol/nexus/storage/client"
+)
+
+// The GraphQL types mirror the schemas of the REST API, and use the same
+// (snake_case) field names. The fields of the types are resolved from the
+// structs returned by the StorageClient, by matching the JSON names of the
+// struct fields; only the fields that link to other entities have dedicated
+// resolvers.
+
+// bigIntScalar is an arbitrary-precision integer, serialized as a string
+// like in the REST API.
+var bigIntScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "BigInt",
+	Description: "An arbitrary-precision integer, serialized as a string of decimal digits.",
+	Serialize: func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case common.BigInt:
+			return v.String()
+		case string:
+			return v
+		default:
+			return nil
+		}
+	},
+})
+
+// dateTimeScalar is a timestamp, serialized in RFC 3339 format.
+var dateTimeScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "DateTime",
+	Description: "A timestamp in RFC 3339 format.",
+	Serialize: func(value interface{}) interface{} {
+		if t, ok := value.(time.Time); ok {
+			return t.UTC().Format(time.RFC3339Nano)
+		}
+		return nil
+	},
+})
+
+// bytesScalar is binary data, serialized in base64 like in the REST API.
+var bytesScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Bytes",
+	Description: "Binary data, encoded in base64.",
+	Serialize: func(value interface{}) interface{} {
+		if b, ok := value.([]byte); ok {
+			return base64.StdEncoding.EncodeToString(b)
+		}
+		return nil
+	},
+})
+
+// jsonScalar is an arbitrary JSON value, e.g. the body of a transaction.
+var jsonScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "JSON",
+	Description: "An arbitrary JSON value.",
+	Serialize: func(value interface{}) interface{} {
+		return value
+	},
+})
+
+var runtimeEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "Runtime",
+	Values: graphql.EnumValueConfigMap{
+		"emerald":     &graphql.EnumValueConfig{Value: common.RuntimeEmerald},
+		"sapphire":    &graphql.EnumValueConfig{Value: common.RuntimeSapphire},
+		"pontusxtest": &graphql.EnumValueConfig{Value: common.RuntimePontusxTest},
+		"pontusxdev":  &graphql.EnumValueConfig{Value: common.RuntimePontusxDev},
+	},
+})
+
+// resolveField is the default resolver of the fields of the object types.
+// It also dereferences pointers, which the built-in scalars do not do.
+func resolveField(p graphql.ResolveParams) (interface{}, error) {
+	value, err := graphql.DefaultResolveFn(p)
+	if err != nil || value == nil {
+		return value, err
+	}
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	return v.Interface(), nil
+}
+
+// newObject creates an object type whose fields are resolved by resolveField
+// unless they have a dedicated resolver.
+func newObject(name string, description string, fields graphql.Fields) *graphql.Object {
+	for _, f := range fields {
+		if f.Resolve == nil {
+			f.Resolve = resolveField
+		}
+	}
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name:        name,
+		Description: description,
+		Fields:      fields,
+	})
+}
+
+func field(t graphql.Output, description string) *graphql.Field {
+	return &graphql.Field{Type: t, Description: description}
+}
+
+// paginationArgs are the arguments of paginated fields. The page size is
+// taken into account by the query cost estimate.
+func paginationArgs() graphql.FieldConfigArgument {
+	return graphql.FieldConfigArgument{
+		graphqlLimitArg: &graphql.ArgumentConfig{
+			Type:         graphql.Int,
+			DefaultValue: graphqlDefaultLimit,
+			Description:  fmt.Sprintf("The maximum number of items to return, at most %d.", graphqlMaxLimit),
+		},
+		"offset": &graphql.ArgumentConfig{
+			Type:         graphql.Int,
+			DefaultValue: 0,
+			Description:  "The number of items to skip before starting to collect the result set.",
+		},
+	}
+}
+
+// pagination returns the page requested by the pagination arguments,
+// with the limit clamped like in the REST API.
+func pagination(p graphql.ResolveParams) (limit *uint64, offset *uint64, err error) {
+	l, _ := p.Args[graphqlLimitArg].(int)
+	o, _ := p.Args["offset"].(int)
+	if o < 0 {
+		return nil, nil, fmt.Errorf("offset must not be negative")
+	}
+	switch {
+	case l < 1:
+		l = 1
+	case l > graphqlMaxLimit:
+		l = graphqlMaxLimit
+	}
+	return common.Ptr(uint64(l)), common.Ptr(uint64(o)), nil
+}
+
+// listFields returns the fields of a list of items of the given type,
+// with the metadata that all lists of the REST API have.
+func listFields(itemsName string, itemType graphql.Type) graphql.Fields {
+	return graphql.Fields{
+		itemsName:                field(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(itemType))), ""),
+		"total_count":            field(graphql.NewNonNull(graphql.Int), "The total number of records that match the query, i.e. the number of records the query would return with limit=infinity."),
+		"is_total_count_clipped": field(graphql.NewNonNull(graphql.Boolean), "Whether total_count is clipped for performance reasons."),
+	}
+}
+
+// runtimeAccount is the source value of the RuntimeAccount type. It also
+// carries the runtime, which the resolvers of nested fields need.
+type runtimeAccount struct {
+	*client.RuntimeAccount
+	runtime common.Runtime
+}
+
+// Resolve implements graphql.FieldResolver, so that the fields of the
+// account are resolved from the embedded struct.
+func (a runtimeAccount) Resolve(p graphql.ResolveParams) (interface{}, error) {
+	p.Source = a.RuntimeAccount
+	return resolveField(p)
+}
+
+// withRuntime returns a context for StorageClient methods that serve
+// runtime-specific endpoints; the REST API sets the runtime from the URL.
+func withRuntime(ctx context.Context, runtime common.Runtime) context.Context {
+	return context.WithValue(ctx, common.RuntimeContextKey, runtime)
+}
+
+// newGraphQLSchema creates the GraphQL schema, using the methods of the
+// StorageClient as resolvers.
+func newGraphQLSchema(dbClient client.StorageClient) (graphql.Schema, error) {
+	bigInt := graphql.NewNonNull(bigIntScalar)
+	nonNullString := graphql.NewNonNull(graphql.String)
+	nonNullInt := graphql.NewNonNull(graphql.Int)
+
+	txErrorType := newObject("TxError", "An error that a transaction failed with.", graphql.Fields{
+		"module":  field(graphql.String, "The module of a failed transaction."),
+		"code":    field(nonNullInt, "The status code of a failed transaction."),
+		"message": field(graphql.String, "The message of a failed transaction."),
+	})
+
+	accountStatsType := newObject("AccountStats", "Statistics about an account.", graphql.Fields{
+		"total_sent":     field(bigIntScalar, "The total amount of native tokens sent, in base units."),
+		"total_received": field(bigIntScalar, "The total amount of native tokens received, in base units."),
+		"num_txns":       field(nonNullInt, "The total number of transactions this account was involved with."),
+	})
+
+	// Consensus.
+
+	allowanceType := newObject("Allowance", "An allowance of an account.", graphql.Fields{
+		"address": field(nonNullString, "The allowed account."),
+		"amount":  field(bigInt, "The amount allowed for the allowed account."),
+	})
+
+	delegationType := newObject("Delegation", "A delegation of an account to a validator.", graphql.Fields{
+		"amount":    field(bigInt, "The amount of tokens delegated in base units."),
+		"shares":    field(bigInt, "The shares of tokens delegated."),
+		"validator": field(nonNullString, "The delegatee (validator) address."),
+		"delegator": field(nonNullString, "The delegator address."),
+	})
+	delegationListType := newObject("DelegationList", "A list of delegations.", listFields("delegations", delegationType))
+
+	debondingDelegationType := newObject("DebondingDelegation", "A debonding delegation of an account to a validator.", graphql.Fields{
+		"amount":     field(bigInt, "The amount of tokens delegated in base units."),
+		"shares":     field(bigInt, "The shares of tokens delegated."),
+		"validator":  field(nonNullString, "The delegatee (validator) address."),
+		"delegator":  field(nonNullString, "The delegator address."),
+		"debond_end": field(nonNullInt, "The epoch at which the debonding ends."),
+	})
+	debondingDelegationListType := newObject("DebondingDelegationList", "A list of debonding delegations.", listFields("debonding_delegations", debondingDelegationType))
+
+	transactionType := newObject("Transaction", "A consensus transaction.", graphql.Fields{
+		"block":     field(nonNullInt, "The block height at which this transaction was executed."),
+		"index":     field(nonNullInt, "0-based index of this transaction in its block."),
+		"timestamp": field(graphql.NewNonNull(dateTimeScalar), "The second-granular consensus time of this tx's block."),
+		"hash":      field(nonNullString, "The cryptographic hash of this transaction's encoding."),
+		"sender":    field(nonNullString, "The address of who sent this transaction."),
+		"nonce":     field(nonNullInt, "The nonce used with this transaction, to prevent replay."),
+		"fee":       field(bigInt, "The fee that this transaction's sender committed to pay to execute it."),
+		"gas_limit": field(bigInt, "The maximum gas that a transaction can use."),
+		"method":    field(nonNullString, "The method that was called."),
+		"body":      field(jsonScalar, "The method call body."),
+		"success":   field(graphql.NewNonNull(graphql.Boolean), "Whether this transaction successfully executed."),
+		"error":     field(txErrorType, "Error details of a failed transaction."),
+	})
+	transactionListType := newObject("TransactionList", "A list of consensus transactions.", listFields("transactions", transactionType))
+
+	// Runtimes.
+
+	addressPreimageType := newObject("AddressPreimage", "The data from which an address was derived.", graphql.Fields{
+		"context":         field(nonNullString, "The method by which the address was derived."),
+		"context_version": field(graphql.Int, "Version of the `context`."),
+		"address_data":    field(graphql.NewNonNull(bytesScalar), "The hashed-in data, e.g. an Ethereum address."),
+	})
+
+	runtimeSdkBalanceType := newObject("RuntimeSdkBalance", "A balance of a token tracked by the runtime's SDK.", graphql.Fields{
+		"balance":        field(bigInt, "Number of tokens held, in base units."),
+		"token_symbol":   field(nonNullString, "The token ticker symbol."),
+		"token_decimals": field(nonNullInt, "The number of decimals of precision for this token."),
+	})
+
+	runtimeEvmBalanceType := newObject("RuntimeEvmBalance", "A balance of an EVM token.", graphql.Fields{
+		"balance":                 field(bigInt, "Number of tokens held, in base units."),
+		"token_contract_addr":     field(nonNullString, "The Oasis address of this token's contract."),
+		"token_contract_addr_eth": field(nonNullString, "The EVM address of this token's contract."),
+		"token_symbol":            field(graphql.String, "The token ticker symbol."),
+		"token_name":              field(graphql.String, "The name of the token."),
+		"token_type":              field(nonNullString, "The type of the token, e.g. ERC20."),
+		"token_decimals":          field(nonNullInt, "The number of decimals of precision for this token."),
+	})
+
+	runtimeTransactionType := newObject("RuntimeTransaction", "A runtime transaction.", graphql.Fields{
+		"round":                 field(nonNullInt, "The block round at which this transaction was executed."),
+		"index":                 field(nonNullInt, "0-based index of this transaction in the block."),
+		"timestamp":             field(graphql.NewNonNull(dateTimeScalar), "The second-granular consensus time of this tx's block."),
+		"hash":                  field(nonNullString, "The Oasis cryptographic hash of this transaction's encoding."),
+		"eth_hash":              field(graphql.String, "The Ethereum cryptographic hash of this transaction's encoding, if it is an Ethereum transaction."),
+		"sender_0":              field(nonNullString, "The Oasis address of this transaction's 0th signer."),
+		"sender_0_eth":          field(graphql.String, "The Ethereum address of this transaction's 0th signer, if available."),
+		"nonce_0":               field(nonNullInt, "The nonce used with this transaction's 0th signer, to prevent replay."),
+		"fee":                   field(bigInt, "The fee that this transaction's sender committed to pay to execute it."),
+		"fee_symbol":            field(nonNullString, "The denomination of the fee."),
+		"charged_fee":           field(bigInt, "The fee that was charged for the transaction execution."),
+		"gas_limit":             field(nonNullInt, "The maximum gas that this transaction's sender committed to use to execute it."),
+		"gas_used":              field(nonNullInt, "The total gas used by the transaction."),
+		"size":                  field(nonNullInt, "The size of the transaction in bytes."),
+		"method":                field(graphql.String, "The method that was called."),
+		"body":                  field(jsonScalar, "The method call body."),
+		"to":                    field(graphql.String, "A reasonable \"to\" Oasis address associated with this transaction, if applicable."),
+		"to_eth":                field(graphql.String, "A reasonable \"to\" Ethereum address associated with this transaction, if applicable."),
+		"amount":                field(bigIntScalar, "A reasonable \"amount\" associated with this transaction, if applicable."),
+		"amount_symbol":         field(graphql.String, "The denomination of the \"amount\" field."),
+		"success":               field(graphql.Boolean, "Whether this transaction successfully executed. Can be absent for encrypted transactions."),
+		"evm_fn_name":           field(graphql.String, "The name of the smart contract function called by the transaction."),
+		"evm_fn_low_confidence": field(graphql.Boolean, "Present and true if evm_fn_name was decoded with known function signatures, because the contract's ABI is not known."),
+		"error":                 field(txErrorType, "Error details of a failed transaction."),
+	})
+	runtimeTransactionListType := newObject("RuntimeTransactionList", "A list of runtime transactions.", listFields("transactions", runtimeTransactionType))
+
+	runtimeAccountType := newObject("RuntimeAccount", "A runtime account.", graphql.Fields{
+		"address":          field(nonNullString, "The staking address for this account."),
+		"address_preimage": field(addressPreimageType, "The data from which the address was derived, if known."),
+		"balances":         field(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(runtimeSdkBalanceType))), "The balances of this account in each runtime, as managed by the runtime's SDK."),
+		"evm_balances":     field(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(runtimeEvmBalanceType))), "Data on the EVM tokens held by this account. Excludes NFTs."),
+		"stats":            field(graphql.NewNonNull(accountStatsType), "Statistics about the account."),
+		"transactions": &graphql.Field{
+			Type:        graphql.NewNonNull(runtimeTransactionListType),
+			Description: "The most recent transactions that this account was involved in.",
+			Args:        paginationArgs(),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				acct := p.Source.(runtimeAccount)
+				limit, offset, err := pagination(p)
+				if err != nil {
+					return nil, err
+				}
+				return dbClient.RuntimeTransactions(withRuntime(p.Context, acct.runtime), apiTypes.GetRuntimeTransactionsParams{
+					Limit:  limit,
+					Offset: offset,
+					Rel:    &acct.Address,
+				}, nil)
+			},
+		},
+	})
+
+	fetchRuntimeAccount := func(ctx context.Context, runtime common.Runtime, address staking.Address) (interface{}, error) {
+		acct, err := dbClient.RuntimeAccount(withRuntime(ctx, runtime), address)
+		if err != nil {
+			return nil, err
+		}
+		return runtimeAccount{acct, runtime}, nil
+	}
+
+	accountType := newObject("Account", "A consensus account.", graphql.Fields{
+		"address":                       field(nonNullString, "The staking address for this account."),
+		"nonce":                         field(nonNullInt, "A nonce used to prevent replay."),
+		"available":                     field(bigInt, "The available balance, in base units."),
+		"escrow":                        field(bigInt, "The active escrow balance, in base units."),
+		"debonding":                     field(bigInt, "The debonding escrow balance, in base units."),
+		"delegations_balance":           field(bigInt, "The balance of this account's (outgoing) delegations, in base units."),
+		"debonding_delegations_balance": field(bigInt, "The balance of this account's (outgoing) debonding delegations, in base units."),
+		"first_activity":                field(dateTimeScalar, "The second-granular consensus time of the block in which this account was first active."),
+		"allowances":                    field(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(allowanceType))), "The allowances made by this account."),
+		"stats":                         field(graphql.NewNonNull(accountStatsType), "Statistics about the account."),
+		"delegations": &graphql.Field{
+			Type:        graphql.NewNonNull(delegationListType),
+			Description: "The active delegations of this account.",
+			Args:        paginationArgs(),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				address, err := sourceAddress(p)
+				if err != nil {
+					return nil, err
+				}
+				limit, offset, err := pagination(p)
+				if err != nil {
+					return nil, err
+				}
+				return dbClient.Delegations(p.Context, address, apiTypes.GetConsensusAccountsAddressDelegationsParams{Limit: limit, Offset: offset})
+			},
+		},
+		"debonding_delegations": &graphql.Field{
+			Type:        graphql.NewNonNull(debondingDelegationListType),
+			Description: "The debonding delegations of this account.",
+			Args:        paginationArgs(),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				address, err := sourceAddress(p)
+				if err != nil {
+					return nil, err
+				}
+				limit, offset, err := pagination(p)
+				if err != nil {
+					return nil, err
+				}
+				return dbClient.DebondingDelegations(p.Context, address, apiTypes.GetConsensusAccountsAddressDebondingDelegationsParams{Limit: limit, Offset: offset})
+			},
+		},
+		"transactions": &graphql.Field{
+			Type:        graphql.NewNonNull(transactionListType),
+			Description: "The most recent consensus transactions that this account was involved in.",
+			Args:        paginationArgs(),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				acct := p.Source.(*client.Account)
+				limit, offset, err := pagination(p)
+				if err != nil {
+					return nil, err
+				}
+				return dbClient.Transactions(p.Context, apiTypes.GetConsensusTransactionsParams{
+					Limit:  limit,
+					Offset: offset,
+					Rel:    &acct.Address,
+				}, nil)
+			},
+		},
+		"runtime_account": &graphql.Field{
+			Type:        graphql.NewNonNull(runtimeAccountType),
+			Description: "The account with the same address in a runtime, e.g. for its runtime balances. Use aliases to fetch it for multiple runtimes.",
+			Args: graphql.FieldConfigArgument{
+				"runtime": &graphql.ArgumentConfig{Type: graphql.NewNonNull(runtimeEnum)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				address, err := sourceAddress(p)
+				if err != nil {
+					return nil, err
+				}
+				return fetchRuntimeAccount(p.Context, p.Args["runtime"].(common.Runtime), address)
+			},
+		},
+	})
+
+	evmTokenType := newObject("EvmToken", "An EVM token.", graphql.Fields{
+		"contract_addr":     field(nonNullString, "The Oasis address of this token's contract."),
+		"eth_contract_addr": field(nonNullString, "The Ethereum address of this token's contract."),
+		"name":              field(graphql.String, "Name of the token, as provided by token contract's `name()` method."),
+		"symbol":            field(graphql.String, "Symbol of the token, as provided by token contract's `symbol()` method."),
+		"decimals":          field(graphql.Int, "The number of least significant digits in base units that should be displayed as decimals when displaying tokens."),
+		"type":              field(nonNullString, "The type of the token, e.g. ERC20."),
+		"total_supply":      field(bigIntScalar, "The total number of base units available."),
+		"num_transfers":     field(graphql.Int, "The total number of transfers of this token."),
+		"num_holders":       field(nonNullInt, "The number of addresses that have a nonzero balance of this token."),
+		"is_verified":       field(graphql.NewNonNull(graphql.Boolean), "Whether the contract has been successfully verified by Sourcify."),
+	})
+	evmTokenListType := newObject("EvmTokenList", "A list of EVM tokens.", listFields("evm_tokens", evmTokenType))
+
+	evmTokensArgs := paginationArgs()
+	evmTokensArgs["runtime"] = &graphql.ArgumentConfig{Type: graphql.NewNonNull(runtimeEnum)}
+	evmTokensArgs["name"] = &graphql.ArgumentConfig{Type: graphql.String, Description: "A filter on the name, the name or symbol must contain this value as a substring."}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"account": &graphql.Field{
+				Type:        graphql.NewNonNull(accountType),
+				Description: "A consensus account.",
+				Args: graphql.FieldConfigArgument{
+					"address": &graphql.ArgumentConfig{Type: nonNullString, Description: "The staking address of the account."},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					var address staking.Address
+					if err := address.UnmarshalText([]byte(p.Args["address"].(string))); err != nil {
+						return nil, fmt.Errorf("invalid address: %w", err)
+					}
+					return dbClient.Account(p.Context, address, apiTypes.GetConsensusAccountsAddressParams{})
+				},
+			},
+			"runtime_account": &graphql.Field{
+				Type:        graphql.NewNonNull(runtimeAccountType),
+				Description: "A runtime account.",
+				Args: graphql.FieldConfigArgument{
+					"runtime": &graphql.ArgumentConfig{Type: graphql.NewNonNull(runtimeEnum)},
+					"address": &graphql.ArgumentConfig{Type: nonNullString, Description: "The Oasis or Ethereum address of the account."},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					address, err := apiTypes.UnmarshalToOcAddress(common.Ptr(p.Args["address"].(string)))
+					if err != nil {
+						return nil, err
+					}
+					return fetchRuntimeAccount(p.Context, p.Args["runtime"].(common.Runtime), *address)
+				},
+			},
+			"evm_tokens": &graphql.Field{
+				Type:        graphql.NewNonNull(evmTokenListType),
+				Description: "The EVM tokens of a runtime, sorted by the number of holders.",
+				Args:        evmTokensArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					limit, offset, err := pagination(p)
+					if err != nil {
+						return nil, err
+					}
+					params := apiTypes.GetRuntimeEvmTokensParams{Limit: limit, Offset: offset}
+					if name, ok := p.Args["name"].(string); ok {
+						params.Name = &name
+					}
+					return dbClient.RuntimeTokens(withRuntime(p.Context, p.Args["runtime"].(common.Runtime)), params, nil)
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
+
+// sourceAddress returns the address of the consensus account that is the
+// source of the field being resolved.
+func sourceAddress(p graphql.ResolveParams) (staking.Address, error) {
+	var address staking.Address
+	err := address.UnmarshalText([]byte(p.Source.(*client.Account).Address))
+	return address, err
+}
@@ -0,0 +1,150 @@
+package v1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/nexus/config"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage/client"
+)
+
+func TestEstimateQueryCost(t *testing.T) {
+	schema, err := newGraphQLSchema(client.StorageClient{})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		expected  queryCost
+	}{
+		{
+			name:     "scalar fields",
+			query:    `{ account(address: "a") { nonce escrow } }`,
+			expected: queryCost{Complexity: 3, Depth: 2},
+		},
+		{
+			name:     "paginated field with literal limit",
+			query:    `{ account(address: "a") { delegations(limit: 10) { total_count delegations { amount } } } }`,
+			expected: queryCost{Complexity: 1 + 1 + 10*(1+2), Depth: 4},
+		},
+		{
+			name:     "paginated field with default limit",
+			query:    `{ account(address: "a") { delegations { total_count } } }`,
+			expected: queryCost{Complexity: 1 + 1 + graphqlDefaultLimit, Depth: 3},
+		},
+		{
+			name:      "paginated field with limit from variable",
+			query:     `query q($n: Int) { account(address: "a") { delegations(limit: $n) { total_count } } }`,
+			variables: map[string]interface{}{"n": float64(5)},
+			expected:  queryCost{Complexity: 1 + 1 + 5, Depth: 3},
+		},
+		{
+			name:     "paginated field with limit from variable default",
+			query:    `query q($n: Int = 7) { account(address: "a") { delegations(limit: $n) { total_count } } }`,
+			expected: queryCost{Complexity: 1 + 1 + 7, Depth: 3},
+		},
+		{
+			name:     "limit above the maximum is clamped",
+			query:    `{ account(address: "a") { delegations(limit: 5000) { total_count } } }`,
+			expected: queryCost{Complexity: 1 + 1 + graphqlMaxLimit, Depth: 3},
+		},
+		{
+			name:     "zero limit counts nested fields once",
+			query:    `{ account(address: "a") { delegations(limit: 0) { total_count } } }`,
+			expected: queryCost{Complexity: 1 + 1 + 1, Depth: 3},
+		},
+		{
+			name:     "nested pages multiply",
+			query:    `{ account(address: "a") { runtime_account(runtime: emerald) { transactions(limit: 20) { transactions { hash error { code } } } } } }`,
+			expected: queryCost{Complexity: 1 + 1 + 1 + 20*(1+3), Depth: 6},
+		},
+		{
+			name:     "fragments",
+			query:    `{ account(address: "a") { ...f ... on Account { escrow } } } fragment f on Account { nonce available }`,
+			expected: queryCost{Complexity: 4, Depth: 2},
+		},
+		{
+			name:     "aliases are counted separately",
+			query:    `{ a: account(address: "a") { nonce } b: account(address: "b") { nonce } }`,
+			expected: queryCost{Complexity: 4, Depth: 2},
+		},
+		{
+			name:     "introspection",
+			query:    `{ __schema { types { name fields { name } } } }`,
+			expected: queryCost{Complexity: 1, Depth: 1},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(tc.query)})})
+			require.NoError(t, err)
+			cost, err := estimateQueryCost(&schema, doc, "", tc.variables, graphqlDefaultLimit, graphqlMaxLimit)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, cost)
+		})
+	}
+}
+
+func TestEstimateQueryCostOperationName(t *testing.T) {
+	schema, err := newGraphQLSchema(client.StorageClient{})
+	require.NoError(t, err)
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(
+		`query a { account(address: "a") { nonce } } query b { account(address: "a") { nonce escrow } }`,
+	)})})
+	require.NoError(t, err)
+
+	cost, err := estimateQueryCost(&schema, doc, "b", nil, graphqlDefaultLimit, graphqlMaxLimit)
+	require.NoError(t, err)
+	require.Equal(t, queryCost{Complexity: 3, Depth: 2}, cost)
+
+	_, err = estimateQueryCost(&schema, doc, "", nil, graphqlDefaultLimit, graphqlMaxLimit)
+	require.Error(t, err, "ambiguous operation accepted")
+	_, err = estimateQueryCost(&schema, doc, "c", nil, graphqlDefaultLimit, graphqlMaxLimit)
+	require.Error(t, err, "unknown operation accepted")
+}
+
+// TestGraphQLRejectsExpensiveQueries tests that queries over the limits are
+// rejected before they are executed; executing them would need a DB.
+func TestGraphQLRejectsExpensiveQueries(t *testing.T) {
+	s, err := NewGraphQLServer(client.StorageClient{}, *log.NewDefaultLogger("graphql-test"), config.GraphQLConfig{MaxComplexity: 100, MaxDepth: 4})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name  string
+		query string
+		error string
+	}{
+		{
+			name:  "too complex",
+			query: `{ account(address: "a") { delegations(limit: 50) { delegations { amount shares } } } }`,
+			error: "query complexity 152 exceeds the maximum of 100; request fewer fields or smaller pages (`limit`)",
+		},
+		{
+			name:  "too complex with default limit",
+			query: `{ account(address: "a") { transactions { total_count } } }`,
+			error: "query complexity 102 exceeds the maximum of 100; request fewer fields or smaller pages (`limit`)",
+		},
+		{
+			name:  "too deep",
+			query: `{ account(address: "a") { runtime_account(runtime: emerald) { transactions(limit: 1) { transactions { error { code } } } } } }`,
+			error: "query depth 6 exceeds the maximum of 4",
+		},
+		{
+			name:  "runtime without a client",
+			query: `{ runtime_account(runtime: cipher, address: "a") { address } }`,
+			error: `Argument "runtime" has invalid value cipher.` + "\n" + `Expected type "Runtime", found cipher.`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result := s.execute(context.Background(), graphqlRequest{Query: tc.query})
+			require.Nil(t, result.Data)
+			require.Len(t, result.Errors, 1)
+			require.Equal(t, tc.error, result.Errors[0].Message)
+		})
+	}
+}
@@ -0,0 +1,535 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	apiCommon "github.com/oasisprotocol/nexus/api"
+	apiTypes "github.com/oasisprotocol/nexus/api/v1/types"
+	"github.com/oasisprotocol/nexus/common"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage"
+	"github.com/oasisprotocol/nexus/storage/client"
+)
+
+const (
+	// Number of block notifications buffered per subscriber. A subscriber
+	// that falls further behind is disconnected.
+	streamSubscriberBufferSize = 64
+	// How often to send a comment line to idle streams, so that proxies
+	// do not close the connection.
+	streamKeepAliveInterval = 30 * time.Second
+	// How long to wait before re-subscribing to block notifications after
+	// the DB connection fails.
+	streamListenRetryInterval = 5 * time.Second
+	// Page size used when fetching the contents of a single block.
+	streamPageSize = uint64(1000)
+)
+
+// StreamServer pushes newly indexed blocks, transactions and events to
+// clients using Server-Sent Events. The analyzers announce every block they
+// commit in slow-sync mode; for each announced block, the server fetches the
+// matching items using the same queries (and filters) as the corresponding
+// list endpoints, and writes them to every interested client.
+//
+// Items are fetched once per block and subscription, not once per client:
+// clients that stream the same kind of items with the same filters share a
+// subscription, and the fetched items are fanned out to all of them.
+//
+// The streaming endpoints are served outside of the oapi-codegen strict
+// handler, which cannot express long-lived responses.
+type StreamServer struct {
+	dbClient client.StorageClient
+	logger   log.Logger
+
+	mu          sync.Mutex
+	subscribers map[*streamSubscriber]struct{}
+}
+
+// streamSubscription describes the items that a client streams.
+type streamSubscription struct {
+	// The layer whose blocks the client is interested in.
+	layer string
+	// Identifies the kind of streamed items and the filters applied to them.
+	// Subscribers with the same layer and key receive the same items.
+	key string
+	// The Server-Sent Events event type of the streamed items.
+	eventName string
+	// Returns the items of a newly processed block.
+	fetch blockFetcher
+}
+
+// streamMessage holds the JSON-encoded items of a single block.
+type streamMessage struct {
+	height int64
+	items  [][]byte
+}
+
+type streamSubscriber struct {
+	streamSubscription
+	ch chan streamMessage
+}
+
+func NewStreamServer(client client.StorageClient, logger log.Logger) *StreamServer {
+	return &StreamServer{
+		dbClient:    client,
+		logger:      logger,
+		subscribers: map[*streamSubscriber]struct{}{},
+	}
+}
+
+// Run listens for new block notifications and dispatches them to subscribers.
+// It blocks until the context is canceled.
+func (s *StreamServer) Run(ctx context.Context) {
+	for {
+		err := s.dbClient.ListenNewBlocks(ctx, func(n storage.NewBlockNotification) {
+			s.publish(ctx, n)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		s.logger.Warn("listening for new blocks failed; retrying", "err", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(streamListenRetryInterval):
+		}
+	}
+}
+
+// Routes registers the streaming endpoints with the router. The router is
+// expected to populate the runtime in the request context, like the router
+// of the main API handler does.
+func (s *StreamServer) Routes(r chi.Router, baseURL string) {
+	r.Get(baseURL+"/consensus/blocks/stream", s.consensusBlocks)
+	r.Get(baseURL+"/consensus/transactions/stream", s.consensusTransactions)
+	r.Get(baseURL+"/consensus/events/stream", s.consensusEvents)
+	r.Get(baseURL+"/{runtime}/blocks/stream", s.runtimeBlocks)
+	r.Get(baseURL+"/{runtime}/transactions/stream", s.runtimeTransactions)
+	r.Get(baseURL+"/{runtime}/events/stream", s.runtimeEvents)
+}
+
+// publish fetches the items of the announced block once for every distinct
+// subscription of the block's layer, and sends them to the subscribers.
+func (s *StreamServer) publish(ctx context.Context, n storage.NewBlockNotification) {
+	s.mu.Lock()
+	groups := map[string][]*streamSubscriber{}
+	for sub := range s.subscribers {
+		if sub.layer == n.Layer {
+			groups[sub.key] = append(groups[sub.key], sub)
+		}
+	}
+	s.mu.Unlock()
+
+	for key, subs := range groups {
+		msg, err := s.fetchMessage(ctx, subs[0].streamSubscription, n.Height)
+		if err != nil {
+			s.logger.Warn("failed to fetch streamed block contents", "layer", n.Layer, "stream", key, "height", n.Height, "err", err)
+		}
+		s.mu.Lock()
+		for _, sub := range subs {
+			if _, ok := s.subscribers[sub]; !ok {
+				// Unsubscribed while the items were being fetched.
+				continue
+			}
+			if err != nil {
+				// End the stream rather than silently skip a block; the client can reconnect.
+				s.removeLocked(sub)
+				continue
+			}
+			select {
+			case sub.ch <- msg:
+			default:
+				// Do not let a slow client hold up the others. Closing the
+				// channel ends its stream; the client can reconnect.
+				s.logger.Info("dropping slow stream subscriber", "layer", n.Layer, "stream", key)
+				s.removeLocked(sub)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *StreamServer) fetchMessage(ctx context.Context, sub streamSubscription, height int64) (streamMessage, error) {
+	items, err := sub.fetch(ctx, height)
+	if err != nil {
+		return streamMessage{}, err
+	}
+	msg := streamMessage{height: height, items: make([][]byte, 0, len(items))}
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return streamMessage{}, fmt.Errorf("marshaling streamed item: %w", err)
+		}
+		msg.items = append(msg.items, data)
+	}
+	return msg, nil
+}
+
+func (s *StreamServer) subscribe(sub streamSubscription) *streamSubscriber {
+	subscriber := &streamSubscriber{
+		streamSubscription: sub,
+		ch:                 make(chan streamMessage, streamSubscriberBufferSize),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers[subscriber] = struct{}{}
+	return subscriber
+}
+
+func (s *StreamServer) unsubscribe(sub *streamSubscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscribers[sub]; ok {
+		s.removeLocked(sub)
+	}
+}
+
+// removeLocked removes a subscriber and ends its stream. The caller must hold s.mu.
+func (s *StreamServer) removeLocked(sub *streamSubscriber) {
+	delete(s.subscribers, sub)
+	close(sub.ch)
+}
+
+// blockFetcher returns the items of a newly processed block that should be
+// sent to the client.
+type blockFetcher func(ctx context.Context, height int64) ([]any, error)
+
+// streamKey identifies a kind of streamed items together with the values of
+// the query params that filter them.
+func streamKey(kind string, q url.Values, filterParams ...string) string {
+	filters := url.Values{}
+	for _, name := range filterParams {
+		if q.Has(name) {
+			filters[name] = q[name]
+		}
+	}
+	return kind + "?" + filters.Encode()
+}
+
+// fetchAllPages pages through the items of a single block; a block may
+// contain more items than the maximum page size of the list endpoints.
+func fetchAllPages[T any](fetchPage func(limit *uint64, offset *uint64) ([]T, error)) ([]any, error) {
+	items := []any{}
+	for offset := uint64(0); ; offset += streamPageSize {
+		page, err := fetchPage(common.Ptr(streamPageSize), common.Ptr(offset))
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page {
+			items = append(items, item)
+		}
+		if uint64(len(page)) < streamPageSize {
+			return items, nil
+		}
+	}
+}
+
+// serveStream writes the items of every new block that match the
+// subscription to the client, as Server-Sent Events of type sub.eventName.
+// The id of each event is the block height (or round).
+func (s *StreamServer) serveStream(w http.ResponseWriter, r *http.Request, sub streamSubscription) {
+	rc := http.NewResponseController(w)
+	// Streams are long-lived; lift the write timeout of the server.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		apiCommon.HumanReadableJsonErrorHandler(w, r, fmt.Errorf("streaming not supported: %w", err))
+		return
+	}
+
+	subscriber := s.subscribe(sub)
+	defer s.unsubscribe(subscriber)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Disable response buffering in nginx-based reverse proxies.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(streamKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case msg, ok := <-subscriber.ch:
+			if !ok {
+				return
+			}
+			for _, data := range msg.items {
+				if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", msg.height, sub.eventName, data); err != nil {
+					return
+				}
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// runtimeLayer validates the runtime in the URL and returns the name of
+// its layer. The runtime is also available from the request context.
+func runtimeLayer(r *http.Request) (string, error) {
+	runtime := apiTypes.Runtime(chi.URLParam(r, "runtime"))
+	if !runtime.IsValid() {
+		return "", &apiTypes.InvalidParamFormatError{ParamName: "runtime", Err: fmt.Errorf("not a valid enum value: %s", runtime)}
+	}
+	return string(runtime), nil
+}
+
+func stringParam(q url.Values, name string) *string {
+	if !q.Has(name) {
+		return nil
+	}
+	return common.Ptr(q.Get(name))
+}
+
+func stakingAddressParam(q url.Values, name string) (*apiTypes.StakingAddress, error) {
+	if !q.Has(name) {
+		return nil, nil
+	}
+	var a apiTypes.StakingAddress
+	if err := a.UnmarshalText([]byte(q.Get(name))); err != nil {
+		return nil, &apiTypes.InvalidParamFormatError{ParamName: name, Err: err}
+	}
+	return &a, nil
+}
+
+func ethOrOasisAddressParam(q url.Values, name string) (*string, error) {
+	p := stringParam(q, name)
+	if _, err := apiTypes.UnmarshalToOcAddress(p); err != nil {
+		return nil, &apiTypes.InvalidParamFormatError{ParamName: name, Err: err}
+	}
+	return p, nil
+}
+
+func (s *StreamServer) consensusBlocks(w http.ResponseWriter, r *http.Request) {
+	s.serveStream(w, r, streamSubscription{
+		layer:     string(common.LayerConsensus),
+		key:       streamKey("blocks", nil),
+		eventName: "block",
+		fetch: func(ctx context.Context, height int64) ([]any, error) {
+			block, err := s.dbClient.Block(ctx, height)
+			if err != nil {
+				return nil, err
+			}
+			return []any{block}, nil
+		},
+	})
+}
+
+func (s *StreamServer) consensusTransactions(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	sender, err := stakingAddressParam(q, "sender")
+	if err != nil {
+		apiCommon.HumanReadableJsonErrorHandler(w, r, err)
+		return
+	}
+	var method *apiTypes.ConsensusTxMethod
+	if q.Has("method") {
+		method = common.Ptr(apiTypes.ConsensusTxMethod(q.Get("method")))
+		if !method.IsValid() {
+			apiCommon.HumanReadableJsonErrorHandler(w, r, &apiTypes.InvalidParamFormatError{ParamName: "method", Err: fmt.Errorf("not a valid enum value: %s", *method)})
+			return
+		}
+	}
+	rel := stringParam(q, "rel")
+
+	s.serveStream(w, r, streamSubscription{
+		layer:     string(common.LayerConsensus),
+		key:       streamKey("transactions", q, "sender", "method", "rel"),
+		eventName: "transaction",
+		fetch: func(ctx context.Context, height int64) ([]any, error) {
+			return fetchAllPages(func(limit *uint64, offset *uint64) ([]client.Transaction, error) {
+				txs, err := s.dbClient.Transactions(ctx, apiTypes.GetConsensusTransactionsParams{
+					Limit:  limit,
+					Offset: offset,
+					Block:  &height,
+					Method: method,
+					Sender: sender,
+					Rel:    rel,
+				}, nil)
+				if err != nil {
+					return nil, err
+				}
+				return txs.Transactions, nil
+			})
+		},
+	})
+}
+
+func (s *StreamServer) consensusEvents(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	rel, err := stakingAddressParam(q, "rel")
+	if err != nil {
+		apiCommon.HumanReadableJsonErrorHandler(w, r, err)
+		return
+	}
+	var eventType *apiTypes.ConsensusEventType
+	if q.Has("type") {
+		eventType = common.Ptr(apiTypes.ConsensusEventType(q.Get("type")))
+		if !eventType.IsValid() {
+			apiCommon.HumanReadableJsonErrorHandler(w, r, &apiTypes.InvalidParamFormatError{ParamName: "type", Err: fmt.Errorf("not a valid enum value: %s", *eventType)})
+			return
+		}
+	}
+
+	s.serveStream(w, r, streamSubscription{
+		layer:     string(common.LayerConsensus),
+		key:       streamKey("events", q, "rel", "type"),
+		eventName: "event",
+		fetch: func(ctx context.Context, height int64) ([]any, error) {
+			return fetchAllPages(func(limit *uint64, offset *uint64) ([]client.Event, error) {
+				events, err := s.dbClient.Events(ctx, apiTypes.GetConsensusEventsParams{
+					Limit:  limit,
+					Offset: offset,
+					Block:  &height,
+					Type:   eventType,
+					Rel:    rel,
+				})
+				if err != nil {
+					return nil, err
+				}
+				return events.Events, nil
+			})
+		},
+	})
+}
+
+func (s *StreamServer) runtimeBlocks(w http.ResponseWriter, r *http.Request) {
+	layer, err := runtimeLayer(r)
+	if err != nil {
+		apiCommon.HumanReadableJsonErrorHandler(w, r, err)
+		return
+	}
+
+	s.serveStream(w, r, streamSubscription{
+		layer:     layer,
+		key:       streamKey("blocks", nil),
+		eventName: "block",
+		fetch: func(ctx context.Context, round int64) ([]any, error) {
+			return fetchAllPages(func(limit *uint64, offset *uint64) ([]client.RuntimeBlock, error) {
+				blocks, err := s.dbClient.RuntimeBlocks(ctx, apiTypes.GetRuntimeBlocksParams{
+					Limit:  limit,
+					Offset: offset,
+					From:   &round,
+					To:     &round,
+				})
+				if err != nil {
+					return nil, err
+				}
+				return blocks.Blocks, nil
+			})
+		},
+	})
+}
+
+func (s *StreamServer) runtimeTransactions(w http.ResponseWriter, r *http.Request) {
+	layer, err := runtimeLayer(r)
+	if err != nil {
+		apiCommon.HumanReadableJsonErrorHandler(w, r, err)
+		return
+	}
+	rel, err := ethOrOasisAddressParam(r.URL.Query(), "rel")
+	if err != nil {
+		apiCommon.HumanReadableJsonErrorHandler(w, r, err)
+		return
+	}
+
+	s.serveStream(w, r, streamSubscription{
+		layer:     layer,
+		key:       streamKey("transactions", r.URL.Query(), "rel"),
+		eventName: "transaction",
+		fetch: func(ctx context.Context, round int64) ([]any, error) {
+			return fetchAllPages(func(limit *uint64, offset *uint64) ([]client.RuntimeTransaction, error) {
+				txs, err := s.dbClient.RuntimeTransactions(ctx, apiTypes.GetRuntimeTransactionsParams{
+					Limit:  limit,
+					Offset: offset,
+					Block:  &round,
+					Rel:    rel,
+				}, nil)
+				if err != nil {
+					return nil, err
+				}
+				return txs.Transactions, nil
+			})
+		},
+	})
+}
+
+func (s *StreamServer) runtimeEvents(w http.ResponseWriter, r *http.Request) {
+	layer, err := runtimeLayer(r)
+	if err != nil {
+		apiCommon.HumanReadableJsonErrorHandler(w, r, err)
+		return
+	}
+	q := r.URL.Query()
+	rel, err := ethOrOasisAddressParam(q, "rel")
+	if err != nil {
+		apiCommon.HumanReadableJsonErrorHandler(w, r, err)
+		return
+	}
+	contractAddress, err := ethOrOasisAddressParam(q, "contract_address")
+	if err != nil {
+		apiCommon.HumanReadableJsonErrorHandler(w, r, err)
+		return
+	}
+	var eventType *apiTypes.RuntimeEventType
+	if q.Has("type") {
+		eventType = common.Ptr(apiTypes.RuntimeEventType(q.Get("type")))
+		if !eventType.IsValid() {
+			apiCommon.HumanReadableJsonErrorHandler(w, r, &apiTypes.InvalidParamFormatError{ParamName: "type", Err: fmt.Errorf("not a valid enum value: %s", *eventType)})
+			return
+		}
+	}
+	evmLogSignature := stringParam(q, "evm_log_signature")
+	nftID := stringParam(q, "nft_id")
+	// Reject filter combinations that RuntimeEvents would reject for every block.
+	switch {
+	case nftID != nil && contractAddress == nil:
+		apiCommon.HumanReadableJsonErrorHandler(w, r, &apiTypes.InvalidParamFormatError{ParamName: "nft_id", Err: fmt.Errorf("must be used with 'contract_address'")})
+		return
+	case contractAddress != nil && nftID == nil && evmLogSignature == nil:
+		apiCommon.HumanReadableJsonErrorHandler(w, r, &apiTypes.InvalidParamFormatError{ParamName: "contract_address", Err: fmt.Errorf("must be used with either 'nft_id' or 'evm_log_signature'")})
+		return
+	default:
+	}
+
+	s.serveStream(w, r, streamSubscription{
+		layer:     layer,
+		key:       streamKey("events", q, "rel", "contract_address", "type", "evm_log_signature", "nft_id"),
+		eventName: "event",
+		fetch: func(ctx context.Context, round int64) ([]any, error) {
+			return fetchAllPages(func(limit *uint64, offset *uint64) ([]client.RuntimeEvent, error) {
+				events, err := s.dbClient.RuntimeEvents(ctx, apiTypes.GetRuntimeEventsParams{
+					Limit:           limit,
+					Offset:          offset,
+					Block:           &round,
+					Type:            eventType,
+					Rel:             rel,
+					EvmLogSignature: evmLogSignature,
+					ContractAddress: contractAddress,
+					NftId:           nftID,
+				})
+				if err != nil {
+					return nil, err
+				}
+				return events.Events, nil
+			})
+		},
+	})
+}
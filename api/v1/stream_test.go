@@ -0,0 +1,171 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage"
+	"github.com/oasisprotocol/nexus/storage/client"
+)
+
+func newTestStreamServer() *StreamServer {
+	return NewStreamServer(client.StorageClient{}, *log.NewDefaultLogger("stream-test"))
+}
+
+// countingFetcher returns a fetcher that returns items and counts its calls.
+func countingFetcher(calls *int, items ...any) blockFetcher {
+	return func(_ context.Context, _ int64) ([]any, error) {
+		*calls++
+		return items, nil
+	}
+}
+
+// receive returns the message waiting for the subscriber, if any.
+func receive(sub *streamSubscriber) (streamMessage, bool, bool) {
+	select {
+	case msg, ok := <-sub.ch:
+		return msg, ok, true
+	default:
+		return streamMessage{}, false, false
+	}
+}
+
+// TestStreamPublishFetchesOncePerSubscription tests that clients with the
+// same subscription share a single fetch of the block contents.
+func TestStreamPublishFetchesOncePerSubscription(t *testing.T) {
+	s := newTestStreamServer()
+	calls := 0
+	sub := streamSubscription{layer: "consensus", key: "blocks?", eventName: "block", fetch: countingFetcher(&calls, map[string]int{"height": 5})}
+	a := s.subscribe(sub)
+	b := s.subscribe(sub)
+
+	s.publish(context.Background(), storage.NewBlockNotification{Layer: "consensus", Height: 5})
+
+	require.Equal(t, 1, calls)
+	for _, subscriber := range []*streamSubscriber{a, b} {
+		msg, ok, received := receive(subscriber)
+		require.True(t, received)
+		require.True(t, ok)
+		require.Equal(t, int64(5), msg.height)
+		require.Equal(t, [][]byte{[]byte(`{"height":5}`)}, msg.items)
+	}
+}
+
+// TestStreamPublishFilters tests that clients only receive the items of their
+// own layer and filters.
+func TestStreamPublishFilters(t *testing.T) {
+	s := newTestStreamServer()
+	var senderCalls, allCalls, runtimeCalls int
+	bySender := s.subscribe(streamSubscription{layer: "consensus", key: "transactions?sender=a", eventName: "transaction", fetch: countingFetcher(&senderCalls, "tx1")})
+	all := s.subscribe(streamSubscription{layer: "consensus", key: "transactions?", eventName: "transaction", fetch: countingFetcher(&allCalls, "tx1", "tx2")})
+	runtime := s.subscribe(streamSubscription{layer: "emerald", key: "transactions?", eventName: "transaction", fetch: countingFetcher(&runtimeCalls, "rtx")})
+
+	s.publish(context.Background(), storage.NewBlockNotification{Layer: "consensus", Height: 7})
+
+	require.Equal(t, 1, senderCalls)
+	require.Equal(t, 1, allCalls)
+	require.Equal(t, 0, runtimeCalls)
+
+	msg, _, received := receive(bySender)
+	require.True(t, received)
+	require.Equal(t, [][]byte{[]byte(`"tx1"`)}, msg.items)
+
+	msg, _, received = receive(all)
+	require.True(t, received)
+	require.Equal(t, [][]byte{[]byte(`"tx1"`), []byte(`"tx2"`)}, msg.items)
+
+	_, _, received = receive(runtime)
+	require.False(t, received, "subscriber of another layer received a message")
+}
+
+// TestStreamPublishFetchError tests that a failed fetch ends the streams of
+// the affected subscription only.
+func TestStreamPublishFetchError(t *testing.T) {
+	s := newTestStreamServer()
+	calls := 0
+	failing := s.subscribe(streamSubscription{layer: "consensus", key: "events?type=a", eventName: "event", fetch: func(_ context.Context, _ int64) ([]any, error) {
+		return nil, fmt.Errorf("db unavailable")
+	}})
+	healthy := s.subscribe(streamSubscription{layer: "consensus", key: "events?", eventName: "event", fetch: countingFetcher(&calls, "ev")})
+
+	s.publish(context.Background(), storage.NewBlockNotification{Layer: "consensus", Height: 1})
+
+	_, ok, received := receive(failing)
+	require.True(t, received)
+	require.False(t, ok, "stream of failed subscription not closed")
+
+	_, ok, received = receive(healthy)
+	require.True(t, received)
+	require.True(t, ok)
+	require.Len(t, s.subscribers, 1)
+}
+
+// TestStreamPublishDropsSlowSubscriber tests that a client that does not keep
+// up is disconnected instead of blocking the others.
+func TestStreamPublishDropsSlowSubscriber(t *testing.T) {
+	s := newTestStreamServer()
+	calls := 0
+	sub := streamSubscription{layer: "consensus", key: "blocks?", eventName: "block", fetch: countingFetcher(&calls, "b")}
+	slow := s.subscribe(sub)
+
+	for h := int64(0); h <= streamSubscriberBufferSize; h++ {
+		s.publish(context.Background(), storage.NewBlockNotification{Layer: "consensus", Height: h})
+	}
+
+	require.Empty(t, s.subscribers)
+	for i := 0; i < streamSubscriberBufferSize; i++ {
+		_, ok, received := receive(slow)
+		require.True(t, received)
+		require.True(t, ok)
+	}
+	_, ok, received := receive(slow)
+	require.True(t, received)
+	require.False(t, ok, "stream of slow subscriber not closed")
+
+	// Unsubscribing after being dropped must not close the channel again.
+	s.unsubscribe(slow)
+}
+
+func TestStreamKey(t *testing.T) {
+	q := url.Values{"type": {"staking.transfer"}, "rel": {"oasis1"}, "limit": {"10"}}
+	require.Equal(t, "events?rel=oasis1&type=staking.transfer", streamKey("events", q, "type", "rel"))
+	require.Equal(t, streamKey("events", q, "type", "rel"), streamKey("events", q, "rel", "type"))
+	require.Equal(t, "blocks?", streamKey("blocks", nil))
+	require.NotEqual(t, streamKey("events", q, "type"), streamKey("events", url.Values{}, "type"))
+}
+
+// TestStreamRejectsInvalidFilters tests that filter combinations that would
+// fail for every block are rejected before the stream starts.
+func TestStreamRejectsInvalidFilters(t *testing.T) {
+	s := newTestStreamServer()
+	r := chi.NewRouter()
+	s.Routes(r, "/v1")
+
+	for _, tc := range []struct {
+		name string
+		url  string
+	}{
+		{"invalid runtime", "/v1/notaruntime/events/stream"},
+		{"nft_id without contract_address", "/v1/emerald/events/stream?nft_id=1"},
+		{"contract_address without nft_id or evm_log_signature", "/v1/emerald/events/stream?contract_address=oasis1qpg2xuz46g53737343r20yxeddhlvc2ldqsjh70p"},
+		{"invalid consensus event type", "/v1/consensus/events/stream?type=nonsense"},
+		{"invalid sender", "/v1/consensus/transactions/stream?sender=nonsense"},
+		{"invalid consensus tx method", "/v1/consensus/transactions/stream?method=staking.Nonsense"},
+		{"invalid runtime event type", "/v1/emerald/events/stream?type=nonsense"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.url, nil))
+			require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+			require.Empty(t, s.subscribers)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	apiCommon "github.com/oasisprotocol/nexus/api"
 	apiTypes "github.com/oasisprotocol/nexus/api/v1/types"
 	"github.com/oasisprotocol/nexus/common"
 	"github.com/oasisprotocol/nexus/log"
@@ -17,14 +18,18 @@ import (
 type StrictServerImpl struct {
 	dbClient client.StorageClient
 	logger   log.Logger
+
+	// Whether EVM contract sources can be submitted for verification.
+	contractVerification bool
 }
 
 var _ apiTypes.StrictServerInterface = (*StrictServerImpl)(nil)
 
-func NewStrictServerImpl(client client.StorageClient, logger log.Logger) *StrictServerImpl {
+func NewStrictServerImpl(client client.StorageClient, logger log.Logger, contractVerification bool) *StrictServerImpl {
 	return &StrictServerImpl{
-		dbClient: client,
-		logger:   logger,
+		dbClient:             client,
+		logger:               logger,
+		contractVerification: contractVerification,
 	}
 }
 
@@ -39,6 +44,14 @@ func (srv *StrictServerImpl) GetStatus(ctx context.Context, request apiTypes.Get
 	return apiTypes.GetStatus200JSONResponse(*status), nil
 }
 
+func (srv *StrictServerImpl) GetSearch(ctx context.Context, request apiTypes.GetSearchRequestObject) (apiTypes.GetSearchResponseObject, error) {
+	results, err := srv.dbClient.Search(ctx, request.Params)
+	if err != nil {
+		return nil, err
+	}
+	return apiTypes.GetSearch200JSONResponse(*results), nil
+}
+
 func (srv *StrictServerImpl) GetConsensusAccounts(ctx context.Context, request apiTypes.GetConsensusAccountsRequestObject) (apiTypes.GetConsensusAccountsResponseObject, error) {
 	accounts, err := srv.dbClient.Accounts(ctx, request.Params)
 	if err != nil {
@@ -48,13 +61,29 @@ func (srv *StrictServerImpl) GetConsensusAccounts(ctx context.Context, request a
 }
 
 func (srv *StrictServerImpl) GetConsensusAccountsAddress(ctx context.Context, request apiTypes.GetConsensusAccountsAddressRequestObject) (apiTypes.GetConsensusAccountsAddressResponseObject, error) {
-	account, err := srv.dbClient.Account(ctx, request.Address)
+	account, err := srv.dbClient.Account(ctx, request.Address, request.Params)
 	if err != nil {
 		return nil, err
 	}
 	return apiTypes.GetConsensusAccountsAddress200JSONResponse(*account), nil
 }
 
+func (srv *StrictServerImpl) GetConsensusAccountsAddressBalanceHistory(ctx context.Context, request apiTypes.GetConsensusAccountsAddressBalanceHistoryRequestObject) (apiTypes.GetConsensusAccountsAddressBalanceHistoryResponseObject, error) {
+	history, err := srv.dbClient.AccountBalanceHistory(ctx, request.Address, request.Params)
+	if err != nil {
+		return nil, err
+	}
+	return apiTypes.GetConsensusAccountsAddressBalanceHistory200JSONResponse(*history), nil
+}
+
+func (srv *StrictServerImpl) GetConsensusAccountsAddressRewards(ctx context.Context, request apiTypes.GetConsensusAccountsAddressRewardsRequestObject) (apiTypes.GetConsensusAccountsAddressRewardsResponseObject, error) {
+	rewards, err := srv.dbClient.DelegatorRewards(ctx, request.Address, request.Params)
+	if err != nil {
+		return nil, err
+	}
+	return apiTypes.GetConsensusAccountsAddressRewards200JSONResponse(*rewards), nil
+}
+
 func (srv *StrictServerImpl) GetConsensusAccountsAddressDebondingDelegations(ctx context.Context, request apiTypes.GetConsensusAccountsAddressDebondingDelegationsRequestObject) (apiTypes.GetConsensusAccountsAddressDebondingDelegationsResponseObject, error) {
 	delegations, err := srv.dbClient.DebondingDelegations(ctx, request.Address, request.Params)
 	if err != nil {
@@ -199,6 +228,46 @@ func (srv *StrictServerImpl) GetConsensusProposalsProposalIdVotes(ctx context.Co
 	return apiTypes.GetConsensusProposalsProposalIdVotes200JSONResponse(*votes), nil
 }
 
+func (srv *StrictServerImpl) GetConsensusProposalsProposalIdTallyHistory(ctx context.Context, request apiTypes.GetConsensusProposalsProposalIdTallyHistoryRequestObject) (apiTypes.GetConsensusProposalsProposalIdTallyHistoryResponseObject, error) {
+	history, err := srv.dbClient.ProposalTallyHistory(ctx, request.ProposalId, request.Params)
+	if err != nil {
+		return nil, err
+	}
+	return apiTypes.GetConsensusProposalsProposalIdTallyHistory200JSONResponse(*history), nil
+}
+
+func (srv *StrictServerImpl) GetConsensusVaults(ctx context.Context, request apiTypes.GetConsensusVaultsRequestObject) (apiTypes.GetConsensusVaultsResponseObject, error) {
+	vaults, err := srv.dbClient.Vaults(ctx, request.Params)
+	if err != nil {
+		return nil, err
+	}
+	return apiTypes.GetConsensusVaults200JSONResponse(*vaults), nil
+}
+
+func (srv *StrictServerImpl) GetConsensusVaultsAddress(ctx context.Context, request apiTypes.GetConsensusVaultsAddressRequestObject) (apiTypes.GetConsensusVaultsAddressResponseObject, error) {
+	vault, err := srv.dbClient.Vault(ctx, request.Address)
+	if err != nil {
+		return nil, err
+	}
+	return apiTypes.GetConsensusVaultsAddress200JSONResponse(*vault), nil
+}
+
+func (srv *StrictServerImpl) GetConsensusKeymanagers(ctx context.Context, request apiTypes.GetConsensusKeymanagersRequestObject) (apiTypes.GetConsensusKeymanagersResponseObject, error) {
+	keyManagers, err := srv.dbClient.KeyManagers(ctx, request.Params)
+	if err != nil {
+		return nil, err
+	}
+	return apiTypes.GetConsensusKeymanagers200JSONResponse(*keyManagers), nil
+}
+
+func (srv *StrictServerImpl) GetConsensusKeymanagersRuntimeIdHistory(ctx context.Context, request apiTypes.GetConsensusKeymanagersRuntimeIdHistoryRequestObject) (apiTypes.GetConsensusKeymanagersRuntimeIdHistoryResponseObject, error) {
+	history, err := srv.dbClient.KeyManagerHistory(ctx, request.RuntimeId, request.Params)
+	if err != nil {
+		return nil, err
+	}
+	return apiTypes.GetConsensusKeymanagersRuntimeIdHistory200JSONResponse(*history), nil
+}
+
 func (srv *StrictServerImpl) GetLayerStatsTxVolume(ctx context.Context, request apiTypes.GetLayerStatsTxVolumeRequestObject) (apiTypes.GetLayerStatsTxVolumeResponseObject, error) {
 	// Additional param validation.
 	if !request.Layer.IsValid() {
@@ -212,6 +281,58 @@ func (srv *StrictServerImpl) GetLayerStatsTxVolume(ctx context.Context, request
 	return apiTypes.GetLayerStatsTxVolume200JSONResponse(*volumeList), nil
 }
 
+func (srv *StrictServerImpl) GetLayerLabels(ctx context.Context, request apiTypes.GetLayerLabelsRequestObject) (apiTypes.GetLayerLabelsResponseObject, error) {
+	// Additional param validation.
+	if !request.Layer.IsValid() {
+		return nil, &apiTypes.InvalidParamFormatError{ParamName: "layer", Err: fmt.Errorf("not a valid enum value: %s", request.Layer)}
+	}
+
+	labelList, err := srv.dbClient.AddressLabels(ctx, request.Layer, request.Params)
+	if err != nil {
+		return nil, err
+	}
+	return apiTypes.GetLayerLabels200JSONResponse(*labelList), nil
+}
+
+func (srv *StrictServerImpl) GetLayerStatsEncryption(ctx context.Context, request apiTypes.GetLayerStatsEncryptionRequestObject) (apiTypes.GetLayerStatsEncryptionResponseObject, error) {
+	// Additional param validation.
+	if !request.Layer.IsValid() {
+		return nil, &apiTypes.InvalidParamFormatError{ParamName: "layer", Err: fmt.Errorf("not a valid enum value: %s", request.Layer)}
+	}
+
+	stats, err := srv.dbClient.EncryptionStats(ctx, request.Layer)
+	if err != nil {
+		return nil, err
+	}
+	return apiTypes.GetLayerStatsEncryption200JSONResponse(*stats), nil
+}
+
+func (srv *StrictServerImpl) GetLayerStatsEncryptionRounds(ctx context.Context, request apiTypes.GetLayerStatsEncryptionRoundsRequestObject) (apiTypes.GetLayerStatsEncryptionRoundsResponseObject, error) {
+	// Additional param validation.
+	if !request.Layer.IsValid() {
+		return nil, &apiTypes.InvalidParamFormatError{ParamName: "layer", Err: fmt.Errorf("not a valid enum value: %s", request.Layer)}
+	}
+
+	rounds, err := srv.dbClient.EncryptionRounds(ctx, request.Layer, request.Params)
+	if err != nil {
+		return nil, err
+	}
+	return apiTypes.GetLayerStatsEncryptionRounds200JSONResponse(*rounds), nil
+}
+
+func (srv *StrictServerImpl) GetLayerStatsEncryptionContracts(ctx context.Context, request apiTypes.GetLayerStatsEncryptionContractsRequestObject) (apiTypes.GetLayerStatsEncryptionContractsResponseObject, error) {
+	// Additional param validation.
+	if !request.Layer.IsValid() {
+		return nil, &apiTypes.InvalidParamFormatError{ParamName: "layer", Err: fmt.Errorf("not a valid enum value: %s", request.Layer)}
+	}
+
+	contracts, err := srv.dbClient.EncryptionContracts(ctx, request.Layer, request.Params)
+	if err != nil {
+		return nil, err
+	}
+	return apiTypes.GetLayerStatsEncryptionContracts200JSONResponse(*contracts), nil
+}
+
 func (srv *StrictServerImpl) GetLayerStatsActiveAccounts(ctx context.Context, request apiTypes.GetLayerStatsActiveAccountsRequestObject) (apiTypes.GetLayerStatsActiveAccountsResponseObject, error) {
 	// Additional param validation.
 	if !request.Layer.IsValid() {
@@ -278,6 +399,14 @@ func (srv *StrictServerImpl) GetConsensusValidatorsAddressHistory(ctx context.Co
 	return apiTypes.GetConsensusValidatorsAddressHistory200JSONResponse(*history), nil
 }
 
+func (srv *StrictServerImpl) GetConsensusValidatorsAddressUptime(ctx context.Context, request apiTypes.GetConsensusValidatorsAddressUptimeRequestObject) (apiTypes.GetConsensusValidatorsAddressUptimeResponseObject, error) {
+	uptime, err := srv.dbClient.ValidatorUptime(ctx, request.Address, request.Params)
+	if err != nil {
+		return nil, err
+	}
+	return apiTypes.GetConsensusValidatorsAddressUptime200JSONResponse(*uptime), nil
+}
+
 func (srv *StrictServerImpl) GetRuntimeBlocks(ctx context.Context, request apiTypes.GetRuntimeBlocksRequestObject) (apiTypes.GetRuntimeBlocksResponseObject, error) {
 	blocks, err := srv.dbClient.RuntimeBlocks(ctx, request.Params)
 	if err != nil {
@@ -326,12 +455,44 @@ func (srv *StrictServerImpl) GetRuntimeEvmTokensAddressHolders(ctx context.Conte
 	return apiTypes.GetRuntimeEvmTokensAddressHolders200JSONResponse(*holders), nil
 }
 
+func (srv *StrictServerImpl) GetRuntimeEvmTokensAddressPriceHistory(ctx context.Context, request apiTypes.GetRuntimeEvmTokensAddressPriceHistoryRequestObject) (apiTypes.GetRuntimeEvmTokensAddressPriceHistoryResponseObject, error) {
+	ocAddr, err := apiTypes.UnmarshalToOcAddress(&request.Address)
+	if err != nil {
+		return nil, err
+	}
+	if ocAddr == nil {
+		return nil, fmt.Errorf("missing required param: address")
+	}
+	history, err := srv.dbClient.RuntimeTokenPriceHistory(ctx, request.Params, *ocAddr)
+	if err != nil {
+		return nil, err
+	}
+	return apiTypes.GetRuntimeEvmTokensAddressPriceHistory200JSONResponse(*history), nil
+}
+
+func (srv *StrictServerImpl) GetRuntimeEvmTokensAddressTransfers(ctx context.Context, request apiTypes.GetRuntimeEvmTokensAddressTransfersRequestObject) (apiTypes.GetRuntimeEvmTokensAddressTransfersResponseObject, error) {
+	ocAddr, err := apiTypes.UnmarshalToOcAddress(&request.Address)
+	if err != nil {
+		return nil, err
+	}
+	ocAddrCounterparty, err := apiTypes.UnmarshalToOcAddress(request.Params.Counterparty)
+	if err != nil {
+		return nil, err
+	}
+	p := request.Params
+	transfers, err := srv.dbClient.RuntimeTokenTransfers(ctx, p.Limit, p.Offset, p.Cursor, ocAddr, nil, ocAddrCounterparty, p.After, p.Before)
+	if err != nil {
+		return nil, err
+	}
+	return apiTypes.GetRuntimeEvmTokensAddressTransfers200JSONResponse(*transfers), nil
+}
+
 func (srv *StrictServerImpl) GetRuntimeEvmTokensAddressNfts(ctx context.Context, request apiTypes.GetRuntimeEvmTokensAddressNftsRequestObject) (apiTypes.GetRuntimeEvmTokensAddressNftsResponseObject, error) {
 	ocAddr, err := apiTypes.UnmarshalToOcAddress(&request.Address)
 	if err != nil {
 		return nil, err
 	}
-	nfts, err := srv.dbClient.RuntimeEVMNFTs(ctx, request.Params.Limit, request.Params.Offset, ocAddr, nil, nil)
+	nfts, err := srv.dbClient.RuntimeEVMNFTs(ctx, request.Params.Limit, request.Params.Offset, request.Params.Cursor, ocAddr, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -343,7 +504,7 @@ func (srv *StrictServerImpl) GetRuntimeEvmTokensAddressNftsId(ctx context.Contex
 	if err != nil {
 		return nil, err
 	}
-	nfts, err := srv.dbClient.RuntimeEVMNFTs(ctx, common.Ptr(uint64(1)), common.Ptr(uint64(0)), ocAddr, &request.Id, nil)
+	nfts, err := srv.dbClient.RuntimeEVMNFTs(ctx, common.Ptr(uint64(1)), common.Ptr(uint64(0)), nil, ocAddr, &request.Id, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -372,6 +533,14 @@ func (srv *StrictServerImpl) GetRuntimeTransactionsTxHash(ctx context.Context, r
 	return apiTypes.GetRuntimeTransactionsTxHash200JSONResponse(*transactions), nil
 }
 
+func (srv *StrictServerImpl) GetRuntimeTransactionsTxHashInternalCalls(ctx context.Context, request apiTypes.GetRuntimeTransactionsTxHashInternalCallsRequestObject) (apiTypes.GetRuntimeTransactionsTxHashInternalCallsResponseObject, error) {
+	calls, err := srv.dbClient.RuntimeInternalCalls(ctx, request.Params, request.TxHash)
+	if err != nil {
+		return nil, err
+	}
+	return apiTypes.GetRuntimeTransactionsTxHashInternalCalls200JSONResponse(*calls), nil
+}
+
 func (srv *StrictServerImpl) GetRuntimeEvents(ctx context.Context, request apiTypes.GetRuntimeEventsRequestObject) (apiTypes.GetRuntimeEventsResponseObject, error) {
 	events, err := srv.dbClient.RuntimeEvents(ctx, request.Params)
 	if err != nil {
@@ -397,6 +566,18 @@ func (srv *StrictServerImpl) GetRuntimeAccountsAddress(ctx context.Context, requ
 	return apiTypes.GetRuntimeAccountsAddress200JSONResponse(*account), nil
 }
 
+func (srv *StrictServerImpl) GetRuntimeAccountsAddressBalanceHistory(ctx context.Context, request apiTypes.GetRuntimeAccountsAddressBalanceHistoryRequestObject) (apiTypes.GetRuntimeAccountsAddressBalanceHistoryResponseObject, error) {
+	ocAddr, err := apiTypes.UnmarshalToOcAddress(&request.Address)
+	if err != nil {
+		return nil, err
+	}
+	history, err := srv.dbClient.RuntimeAccountBalanceHistory(ctx, *ocAddr, request.Params)
+	if err != nil {
+		return nil, err
+	}
+	return apiTypes.GetRuntimeAccountsAddressBalanceHistory200JSONResponse(*history), nil
+}
+
 func (srv *StrictServerImpl) GetRuntimeAccountsAddressNfts(ctx context.Context, request apiTypes.GetRuntimeAccountsAddressNftsRequestObject) (apiTypes.GetRuntimeAccountsAddressNftsResponseObject, error) {
 	ocAddrOwner, err := apiTypes.UnmarshalToOcAddress(&request.Address)
 	if err != nil {
@@ -406,13 +587,63 @@ func (srv *StrictServerImpl) GetRuntimeAccountsAddressNfts(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
-	nfts, err := srv.dbClient.RuntimeEVMNFTs(ctx, request.Params.Limit, request.Params.Offset, ocAddrToken, nil, ocAddrOwner)
+	nfts, err := srv.dbClient.RuntimeEVMNFTs(ctx, request.Params.Limit, request.Params.Offset, request.Params.Cursor, ocAddrToken, nil, ocAddrOwner)
 	if err != nil {
 		return nil, err
 	}
 	return apiTypes.GetRuntimeAccountsAddressNfts200JSONResponse(*nfts), nil
 }
 
+func (srv *StrictServerImpl) GetRuntimeAccountsAddressTokenTransfers(ctx context.Context, request apiTypes.GetRuntimeAccountsAddressTokenTransfersRequestObject) (apiTypes.GetRuntimeAccountsAddressTokenTransfersResponseObject, error) {
+	ocAddr, err := apiTypes.UnmarshalToOcAddress(&request.Address)
+	if err != nil {
+		return nil, err
+	}
+	ocAddrToken, err := apiTypes.UnmarshalToOcAddress(request.Params.TokenAddress)
+	if err != nil {
+		return nil, err
+	}
+	ocAddrCounterparty, err := apiTypes.UnmarshalToOcAddress(request.Params.Counterparty)
+	if err != nil {
+		return nil, err
+	}
+	p := request.Params
+	transfers, err := srv.dbClient.RuntimeTokenTransfers(ctx, p.Limit, p.Offset, p.Cursor, ocAddrToken, ocAddr, ocAddrCounterparty, p.After, p.Before)
+	if err != nil {
+		return nil, err
+	}
+	return apiTypes.GetRuntimeAccountsAddressTokenTransfers200JSONResponse(*transfers), nil
+}
+
+func (srv *StrictServerImpl) PostRuntimeEvmContractVerifications(ctx context.Context, request apiTypes.PostRuntimeEvmContractVerificationsRequestObject) (apiTypes.PostRuntimeEvmContractVerificationsResponseObject, error) {
+	if !srv.contractVerification {
+		return nil, fmt.Errorf("%w: contract verification is not enabled", apiCommon.ErrBadRequest)
+	}
+	if request.Body == nil {
+		return nil, fmt.Errorf("%w: missing request body", apiCommon.ErrBadRequest)
+	}
+	ocAddr, err := apiTypes.UnmarshalToOcAddress(&request.Body.ContractAddress)
+	if err != nil {
+		return nil, fmt.Errorf("%w: contract_address: %v", apiCommon.ErrBadRequest, err)
+	}
+	verification, err := srv.dbClient.SubmitRuntimeEvmContractVerification(ctx, *request.Body, ocAddr)
+	if err != nil {
+		return nil, err
+	}
+	return apiTypes.PostRuntimeEvmContractVerifications202JSONResponse(*verification), nil
+}
+
+func (srv *StrictServerImpl) GetRuntimeEvmContractVerificationsId(ctx context.Context, request apiTypes.GetRuntimeEvmContractVerificationsIdRequestObject) (apiTypes.GetRuntimeEvmContractVerificationsIdResponseObject, error) {
+	verification, err := srv.dbClient.RuntimeEvmContractVerification(ctx, request.Id)
+	if err != nil {
+		return nil, err
+	}
+	if verification == nil {
+		return apiTypes.GetRuntimeEvmContractVerificationsId404JSONResponse{}, nil
+	}
+	return apiTypes.GetRuntimeEvmContractVerificationsId200JSONResponse(*verification), nil
+}
+
 func (srv *StrictServerImpl) GetRuntimeStatus(ctx context.Context, request apiTypes.GetRuntimeStatusRequestObject) (apiTypes.GetRuntimeStatusResponseObject, error) {
 	if !request.Runtime.IsValid() {
 		return nil, &apiTypes.InvalidParamFormatError{ParamName: "runtime", Err: fmt.Errorf("not a valid enum value: %s", request.Runtime)}
@@ -34,7 +34,72 @@ func (c ConsensusEventType) IsValid() bool {
 		ConsensusEventTypeGovernanceProposalSubmitted,
 		ConsensusEventTypeGovernanceProposalExecuted,
 		ConsensusEventTypeGovernanceProposalFinalized,
-		ConsensusEventTypeGovernanceVote:
+		ConsensusEventTypeGovernanceVote,
+		ConsensusEventTypeVaultActionSubmitted,
+		ConsensusEventTypeVaultActionCanceled,
+		ConsensusEventTypeVaultActionExecuted,
+		ConsensusEventTypeVaultStateChanged,
+		ConsensusEventTypeVaultPolicyUpdated,
+		ConsensusEventTypeVaultAuthorityUpdated:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c ConsensusTxMethod) IsValid() bool {
+	switch c {
+	case ConsensusTxMethodBeaconPVSSCommit,
+		ConsensusTxMethodBeaconPVSSReveal,
+		ConsensusTxMethodBeaconVRFProve,
+		ConsensusTxMethodConsensusMeta,
+		ConsensusTxMethodGovernanceCastVote,
+		ConsensusTxMethodGovernanceSubmitProposal,
+		ConsensusTxMethodKeymanagerPublishEphemeralSecret,
+		ConsensusTxMethodKeymanagerPublishMasterSecret,
+		ConsensusTxMethodKeymanagerUpdatePolicy,
+		ConsensusTxMethodKeymanagerchurpApply,
+		ConsensusTxMethodKeymanagerchurpConfirm,
+		ConsensusTxMethodKeymanagerchurpCreate,
+		ConsensusTxMethodKeymanagerchurpUpdate,
+		ConsensusTxMethodRegistryDeregisterEntity,
+		ConsensusTxMethodRegistryProveFreshness,
+		ConsensusTxMethodRegistryRegisterEntity,
+		ConsensusTxMethodRegistryRegisterNode,
+		ConsensusTxMethodRegistryRegisterRuntime,
+		ConsensusTxMethodRegistryUnfreezeNode,
+		ConsensusTxMethodRoothashEvidence,
+		ConsensusTxMethodRoothashExecutorCommit,
+		ConsensusTxMethodRoothashExecutorProposerTimeout,
+		ConsensusTxMethodRoothashSubmitMsg,
+		ConsensusTxMethodStakingAddEscrow,
+		ConsensusTxMethodStakingAllow,
+		ConsensusTxMethodStakingAmendCommissionSchedule,
+		ConsensusTxMethodStakingBurn,
+		ConsensusTxMethodStakingReclaimEscrow,
+		ConsensusTxMethodStakingTransfer,
+		ConsensusTxMethodStakingWithdraw,
+		ConsensusTxMethodVaultAuthorizeAction,
+		ConsensusTxMethodVaultCancelAction,
+		ConsensusTxMethodVaultCreate:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c RuntimeEventType) IsValid() bool {
+	switch c {
+	case RuntimeEventTypeAccountsTransfer,
+		RuntimeEventTypeAccountsBurn,
+		RuntimeEventTypeAccountsMint,
+		RuntimeEventTypeConsensusAccountsDeposit,
+		RuntimeEventTypeConsensusAccountsWithdraw,
+		RuntimeEventTypeConsensusAccountsDelegate,
+		RuntimeEventTypeConsensusAccountsUndelegateStart,
+		RuntimeEventTypeConsensusAccountsUndelegateDone,
+		RuntimeEventTypeCoreGasUsed,
+		RuntimeEventTypeEvmLog:
 		return true
 	default:
 		return false
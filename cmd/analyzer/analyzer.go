@@ -19,21 +19,27 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/oasisprotocol/nexus/analyzer"
+	"github.com/oasisprotocol/nexus/analyzer/addresslabels"
 	"github.com/oasisprotocol/nexus/analyzer/aggregate_stats"
 	"github.com/oasisprotocol/nexus/analyzer/consensus"
 	"github.com/oasisprotocol/nexus/analyzer/consensus_accounts_list"
 	"github.com/oasisprotocol/nexus/analyzer/evmabibackfill"
+	"github.com/oasisprotocol/nexus/analyzer/evmcalltraces"
 	"github.com/oasisprotocol/nexus/analyzer/evmcontractcode"
 	"github.com/oasisprotocol/nexus/analyzer/evmnfts"
 	"github.com/oasisprotocol/nexus/analyzer/evmnfts/ipfsclient"
 	"github.com/oasisprotocol/nexus/analyzer/evmtokenbalances"
 	"github.com/oasisprotocol/nexus/analyzer/evmtokens"
 	"github.com/oasisprotocol/nexus/analyzer/evmverifier"
+	"github.com/oasisprotocol/nexus/analyzer/keymanagerhistory"
 	"github.com/oasisprotocol/nexus/analyzer/metadata_registry"
 	nodestats "github.com/oasisprotocol/nexus/analyzer/node_stats"
 	"github.com/oasisprotocol/nexus/analyzer/runtime"
+	"github.com/oasisprotocol/nexus/analyzer/runtime_encryption_stats"
 	"github.com/oasisprotocol/nexus/analyzer/util"
 	"github.com/oasisprotocol/nexus/analyzer/validatorstakinghistory"
+	"github.com/oasisprotocol/nexus/analyzer/validatoruptime"
+	"github.com/oasisprotocol/nexus/analyzer/webhooks"
 	"github.com/oasisprotocol/nexus/cache/httpproxy"
 	cmdCommon "github.com/oasisprotocol/nexus/cmd/common"
 	"github.com/oasisprotocol/nexus/common"
@@ -570,6 +576,42 @@ func NewService(cfg *config.AnalysisConfig) (*Service, error) { //nolint:gocyclo
 			return evmcontractcode.NewAnalyzer(common.RuntimePontusxDev, cfg.Analyzers.PontusxDevContractCode.ItemBasedAnalyzerConfig, sourceClient, dbClient, logger)
 		})
 	}
+	if cfg.Analyzers.EmeraldCallTraces != nil {
+		analyzers, err = addAnalyzer(analyzers, err, syncTagEmerald, func() (A, error) {
+			sourceClient, err1 := sources.Runtime(ctx, common.RuntimeEmerald)
+			if err1 != nil {
+				return nil, err1
+			}
+			return evmcalltraces.NewAnalyzer(common.RuntimeEmerald, cfg.Analyzers.EmeraldCallTraces.ItemBasedAnalyzerConfig, sourceClient, dbClient, logger)
+		})
+	}
+	if cfg.Analyzers.SapphireCallTraces != nil {
+		analyzers, err = addAnalyzer(analyzers, err, syncTagSapphire, func() (A, error) {
+			sourceClient, err1 := sources.Runtime(ctx, common.RuntimeSapphire)
+			if err1 != nil {
+				return nil, err1
+			}
+			return evmcalltraces.NewAnalyzer(common.RuntimeSapphire, cfg.Analyzers.SapphireCallTraces.ItemBasedAnalyzerConfig, sourceClient, dbClient, logger)
+		})
+	}
+	if cfg.Analyzers.PontusxTestCallTraces != nil {
+		analyzers, err = addAnalyzer(analyzers, err, syncTagPontusxTest, func() (A, error) {
+			sourceClient, err1 := sources.Runtime(ctx, common.RuntimePontusxTest)
+			if err1 != nil {
+				return nil, err1
+			}
+			return evmcalltraces.NewAnalyzer(common.RuntimePontusxTest, cfg.Analyzers.PontusxTestCallTraces.ItemBasedAnalyzerConfig, sourceClient, dbClient, logger)
+		})
+	}
+	if cfg.Analyzers.PontusxDevCallTraces != nil {
+		analyzers, err = addAnalyzer(analyzers, err, syncTagPontusxDev, func() (A, error) {
+			sourceClient, err1 := sources.Runtime(ctx, common.RuntimePontusxDev)
+			if err1 != nil {
+				return nil, err1
+			}
+			return evmcalltraces.NewAnalyzer(common.RuntimePontusxDev, cfg.Analyzers.PontusxDevCallTraces.ItemBasedAnalyzerConfig, sourceClient, dbClient, logger)
+		})
+	}
 	if cfg.Analyzers.EmeraldContractVerifier != nil {
 		analyzers, err = addAnalyzer(analyzers, err, syncTagEmerald, func() (A, error) {
 			return evmverifier.NewAnalyzer(cfg.Source.ChainName, common.RuntimeEmerald, cfg.Analyzers.EmeraldContractVerifier.ItemBasedAnalyzerConfig, cfg.Analyzers.EmeraldContractVerifier.SourcifyServerUrl, dbClient, logger)
@@ -590,6 +632,26 @@ func NewService(cfg *config.AnalysisConfig) (*Service, error) { //nolint:gocyclo
 			return evmverifier.NewAnalyzer(cfg.Source.ChainName, common.RuntimePontusxDev, cfg.Analyzers.PontusxDevContractVerifier.ItemBasedAnalyzerConfig, cfg.Analyzers.PontusxDevContractVerifier.SourcifyServerUrl, dbClient, logger)
 		})
 	}
+	if cfg.Analyzers.EmeraldContractCompiler != nil {
+		analyzers, err = addAnalyzer(analyzers, err, syncTagEmerald, func() (A, error) {
+			return evmverifier.NewCompilerAnalyzer(common.RuntimeEmerald, *cfg.Analyzers.EmeraldContractCompiler, dbClient, logger)
+		})
+	}
+	if cfg.Analyzers.SapphireContractCompiler != nil {
+		analyzers, err = addAnalyzer(analyzers, err, syncTagSapphire, func() (A, error) {
+			return evmverifier.NewCompilerAnalyzer(common.RuntimeSapphire, *cfg.Analyzers.SapphireContractCompiler, dbClient, logger)
+		})
+	}
+	if cfg.Analyzers.PontusxTestContractCompiler != nil {
+		analyzers, err = addAnalyzer(analyzers, err, syncTagPontusxTest, func() (A, error) {
+			return evmverifier.NewCompilerAnalyzer(common.RuntimePontusxTest, *cfg.Analyzers.PontusxTestContractCompiler, dbClient, logger)
+		})
+	}
+	if cfg.Analyzers.PontusxDevContractCompiler != nil {
+		analyzers, err = addAnalyzer(analyzers, err, syncTagPontusxDev, func() (A, error) {
+			return evmverifier.NewCompilerAnalyzer(common.RuntimePontusxDev, *cfg.Analyzers.PontusxDevContractCompiler, dbClient, logger)
+		})
+	}
 	if cfg.Analyzers.EmeraldAbi != nil {
 		analyzers, err = addAnalyzer(analyzers, err, syncTagEmerald, func() (A, error) {
 			return evmabibackfill.NewAnalyzer(common.RuntimeEmerald, cfg.Analyzers.EmeraldAbi.ItemBasedAnalyzerConfig, dbClient, logger)
@@ -615,6 +677,11 @@ func NewService(cfg *config.AnalysisConfig) (*Service, error) { //nolint:gocyclo
 			return metadata_registry.NewAnalyzer(cfg.Analyzers.MetadataRegistry.ItemBasedAnalyzerConfig, dbClient, logger)
 		})
 	}
+	if cfg.Analyzers.AddressLabels != nil {
+		analyzers, err = addAnalyzer(analyzers, err, "" /*syncTag*/, func() (A, error) {
+			return addresslabels.NewAnalyzer(cfg.Source.ChainName, *cfg.Analyzers.AddressLabels, dbClient, logger)
+		})
+	}
 	if cfg.Analyzers.ValidatorStakingHistory != nil {
 		analyzers, err = addAnalyzer(analyzers, err, syncTagConsensus, func() (A, error) {
 			sourceClient, err1 := sources.Consensus(ctx)
@@ -628,6 +695,32 @@ func NewService(cfg *config.AnalysisConfig) (*Service, error) { //nolint:gocyclo
 			return validatorstakinghistory.NewAnalyzer(ctx, cfg.Analyzers.ValidatorStakingHistory.ItemBasedAnalyzerConfig, from, sourceClient, dbClient, logger)
 		})
 	}
+	if cfg.Analyzers.ValidatorUptime != nil {
+		analyzers, err = addAnalyzer(analyzers, err, syncTagConsensus, func() (A, error) {
+			sourceClient, err1 := sources.Consensus(ctx)
+			if err1 != nil {
+				return nil, err1
+			}
+			from := cfg.Analyzers.ValidatorUptime.From
+			if from == 0 && cfg.Analyzers.Consensus != nil {
+				from = cfg.Analyzers.Consensus.From
+			}
+			return validatoruptime.NewAnalyzer(ctx, cfg.Analyzers.ValidatorUptime.ItemBasedAnalyzerConfig, from, sourceClient, dbClient, logger)
+		})
+	}
+	if cfg.Analyzers.KeyManagerHistory != nil {
+		analyzers, err = addAnalyzer(analyzers, err, syncTagConsensus, func() (A, error) {
+			sourceClient, err1 := sources.Consensus(ctx)
+			if err1 != nil {
+				return nil, err1
+			}
+			from := cfg.Analyzers.KeyManagerHistory.From
+			if from == 0 && cfg.Analyzers.Consensus != nil {
+				from = cfg.Analyzers.Consensus.From
+			}
+			return keymanagerhistory.NewAnalyzer(ctx, cfg.Analyzers.KeyManagerHistory.ItemBasedAnalyzerConfig, from, sourceClient, dbClient, logger)
+		})
+	}
 	if cfg.Analyzers.NodeStats != nil {
 		analyzers, err = addAnalyzer(analyzers, err, "" /*syncTag*/, func() (A, error) {
 			sourceClient, err1 := sources.Consensus(ctx)
@@ -655,6 +748,16 @@ func NewService(cfg *config.AnalysisConfig) (*Service, error) { //nolint:gocyclo
 			return aggregate_stats.NewAggregateStatsAnalyzer(dbClient, logger)
 		})
 	}
+	if cfg.Analyzers.RuntimeEncryptionStats != nil {
+		analyzers, err = addAnalyzer(analyzers, err, "" /*syncTag*/, func() (A, error) {
+			return runtime_encryption_stats.NewAnalyzer(*cfg.Analyzers.RuntimeEncryptionStats, dbClient, logger)
+		})
+	}
+	if cfg.Analyzers.Webhooks != nil {
+		analyzers, err = addAnalyzer(analyzers, err, "" /*syncTag*/, func() (A, error) {
+			return webhooks.NewAnalyzer(ctx, *cfg.Analyzers.Webhooks, dbClient, logger)
+		})
+	}
 
 	if err != nil {
 		return nil, err
@@ -30,6 +30,11 @@ const (
 	moduleName = "api"
 	// The path portion with which all v1 API endpoints start.
 	v1BaseURL = "/v1"
+
+	// Maximum size of request bodies of the strict handler.
+	defaultMaxRequestBytes = 64 << 10
+	// Maximum size of submissions of contract sources, if not configured.
+	defaultMaxContractVerificationRequestBytes = 8 << 20
 )
 
 var (
@@ -105,9 +110,12 @@ func Init(cfg *config.ServerConfig) (*Service, error) {
 
 // Service is Oasis Nexus's API service.
 type Service struct {
-	address string
-	target  *storage.StorageClient
-	logger  *log.Logger
+	address    string
+	target     *storage.StorageClient
+	graphQLCfg config.GraphQLConfig
+	// Nil if contract verification is disabled.
+	contractVerificationCfg *config.EVMContractVerificationConfig
+	logger                  *log.Logger
 }
 
 // NewService creates a new API service.
@@ -140,10 +148,17 @@ func NewService(cfg *config.ServerConfig) (*Service, error) {
 		return nil, err
 	}
 
+	var graphQLCfg config.GraphQLConfig
+	if cfg.GraphQL != nil {
+		graphQLCfg = *cfg.GraphQL
+	}
+
 	return &Service{
-		address: cfg.Endpoint,
-		target:  client,
-		logger:  logger,
+		address:                 cfg.Endpoint,
+		target:                  client,
+		graphQLCfg:              graphQLCfg,
+		contractVerificationCfg: cfg.EVMContractVerification,
+		logger:                  logger,
 	}, nil
 }
 
@@ -160,13 +175,33 @@ func (s *Service) Start() {
 		r.Handle("/*", http.StripPrefix("/v1/spec", specFileServer{rootDir: "api/spec"}))
 	})
 
+	// Streaming (Server-Sent Events) endpoints for newly indexed data.
+	// chi routes the literal ".../stream" path segments here rather than to
+	// parametrized routes of the strict handler like /consensus/blocks/{height}.
+	streamCtx, cancelStream := context.WithCancel(context.Background())
+	defer cancelStream()
+	streamServer := v1.NewStreamServer(*s.target, *s.logger)
+	go streamServer.Run(streamCtx)
+	streamServer.Routes(baseRouter.With(api.RuntimeFromURLMiddleware(v1BaseURL)), v1BaseURL)
+
+	// GraphQL endpoint, for fetching related entities in a single request.
+	// It is served outside of the strict handler too, since it is not described
+	// by the OpenAPI spec.
+	graphQLServer, err := v1.NewGraphQLServer(*s.target, *s.logger, s.graphQLCfg)
+	if err != nil {
+		s.logger.Error("failed to create graphql server", "error", err)
+		return
+	}
+	baseRouter.Method(http.MethodGet, v1BaseURL+"/graphql", graphQLServer)
+	baseRouter.Method(http.MethodPost, v1BaseURL+"/graphql", graphQLServer)
+
 	// A "strict handler" that handles the great majority of requests.
 	// It is strict in the sense that it enforces input and output types
 	// as defined in the OpenAPI spec.
 	strictHandler := apiTypes.NewStrictHandlerWithOptions(
 		// The "meat" of the API. The rest of `strictHandler` is autogenned code
 		// that deals with validation and serialization.
-		v1.NewStrictServerImpl(*s.target, *s.logger),
+		v1.NewStrictServerImpl(*s.target, *s.logger, s.contractVerificationCfg != nil),
 		// Middleware to apply to all requests. These operate on parsed parameters.
 		[]apiTypes.StrictMiddlewareFunc{
 			api.FixDefaultsAndLimitsMiddleware,
@@ -178,14 +213,21 @@ func (s *Service) Start() {
 		},
 	)
 
+	// Middleware to apply to all requests. These operate on raw requests.
+	middlewares := []apiTypes.MiddlewareFunc{
+		api.RuntimeFromURLMiddleware(v1BaseURL),
+		api.MaxRequestBodyMiddleware(s.maxRequestBytes()),
+	}
+	if s.contractVerificationCfg != nil {
+		middlewares = append(middlewares, api.RequireAPIKeyMiddleware(v1BaseURL, s.contractVerificationCfg.APIKeys))
+	}
+
 	// The top-level chi handler.
 	handler := apiTypes.HandlerWithOptions(
 		strictHandler,
 		apiTypes.ChiServerOptions{
-			BaseURL: v1BaseURL,
-			Middlewares: []apiTypes.MiddlewareFunc{
-				api.RuntimeFromURLMiddleware(v1BaseURL),
-			},
+			BaseURL:          v1BaseURL,
+			Middlewares:      middlewares,
 			BaseRouter:       baseRouter,
 			ErrorHandlerFunc: api.HumanReadableJsonErrorHandler,
 		})
@@ -209,6 +251,18 @@ func (s *Service) Start() {
 	)
 }
 
+// maxRequestBytes returns the maximum size of request bodies of the strict handler.
+// Only submissions of contract sources for verification have a body.
+func (s *Service) maxRequestBytes() int64 {
+	if s.contractVerificationCfg == nil {
+		return defaultMaxRequestBytes
+	}
+	if s.contractVerificationCfg.MaxRequestBytes == 0 {
+		return defaultMaxContractVerificationRequestBytes
+	}
+	return s.contractVerificationCfg.MaxRequestBytes
+}
+
 // cleanup gracefully shuts down the service.
 func (s *Service) cleanup() {
 	s.target.Shutdown()
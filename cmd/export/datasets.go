@@ -0,0 +1,245 @@
+package export
+
+// dataset is a table (or join of tables) that can be exported.
+type dataset struct {
+	name string
+	// Whether the dataset is per-runtime. Rows of runtime datasets are
+	// ranged by round, and those of consensus datasets by height.
+	runtime bool
+	// Whether the dataset is a snapshot of the current state rather than a
+	// history. Snapshots are not ranged, and are exported to a single file.
+	snapshot bool
+	// query returns the columns of the dataset, as text, ordered by
+	// height/round. Its parameters are ($1) the runtime for runtime
+	// datasets, followed by the first and last height/round of the range
+	// unless the dataset is a snapshot.
+	query   string
+	columns []column
+	// amounts are decoded into additional columns, after columns.
+	amounts []amount
+}
+
+// amount describes how to decode an amount in base units into an amount of
+// whole tokens.
+type amount struct {
+	// name is the name of the column with the decoded amount.
+	name string
+	// value is the index of the column with the amount in base units.
+	value int
+	// symbol is the index of the column with the symbol of the oasis-sdk
+	// denomination of the amount, or -1 for amounts in the consensus
+	// denomination. An empty symbol means the native token, and is replaced
+	// with its symbol in the export. The amount is not decoded if the symbol
+	// is NULL.
+	symbol int
+	// decimals is the index of a column with the number of decimals of the
+	// token, or -1 if there is none. If present and not NULL, it takes
+	// precedence over symbol.
+	decimals int
+}
+
+const (
+	// SQL expressions that format a timestamp in ISO 8601.
+	isoBlockTime = `to_char(b.time AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')`
+	isoTimestamp = `to_char(timestamp AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')`
+)
+
+var datasets = []dataset{
+	{
+		name: "consensus_transactions",
+		query: `
+			SELECT
+				t.block::text, t.tx_index::text, ` + isoBlockTime + `, t.tx_hash, t.sender, t.nonce::text,
+				t.method, t.fee_amount::text, t.max_gas::text, t.code::text, t.module, t.message, t.body::text
+			FROM chain.transactions AS t
+			JOIN chain.blocks AS b ON b.height = t.block
+			WHERE t.block >= $1::bigint AND t.block <= $2::bigint
+			ORDER BY t.block, t.tx_index`,
+		columns: []column{
+			{"block", kindInt64},
+			{"tx_index", kindInt64},
+			{"timestamp", kindString},
+			{"tx_hash", kindString},
+			{"sender", kindString},
+			{"nonce", kindInt64},
+			{"method", kindString},
+			{"fee", kindString},
+			{"max_gas", kindString},
+			{"code", kindInt64},
+			{"module", kindString},
+			{"message", kindString},
+			{"body", kindString},
+		},
+		amounts: []amount{
+			{name: "fee_decimal", value: 7, symbol: -1, decimals: -1},
+		},
+	},
+	{
+		name: "consensus_events",
+		query: `
+			SELECT
+				e.tx_block::text, e.tx_index::text, e.event_index::text, ` + isoBlockTime + `, e.tx_hash,
+				e.type, e.body::text, array_to_json(e.related_accounts)::text,
+				e.roothash_runtime::text, e.roothash_runtime_round::text
+			FROM chain.events AS e
+			JOIN chain.blocks AS b ON b.height = e.tx_block
+			WHERE e.tx_block >= $1::bigint AND e.tx_block <= $2::bigint
+			ORDER BY e.tx_block, e.event_index`,
+		columns: []column{
+			{"block", kindInt64},
+			{"tx_index", kindInt64},
+			{"event_index", kindInt64},
+			{"timestamp", kindString},
+			{"tx_hash", kindString},
+			{"type", kindString},
+			{"body", kindString},
+			{"related_accounts", kindString},
+			{"roothash_runtime", kindString},
+			{"roothash_runtime_round", kindInt64},
+		},
+	},
+	{
+		name:    "runtime_transactions",
+		runtime: true,
+		query: `
+			SELECT
+				t.round::text, t.tx_index::text, ` + isoTimestamp + `, t.tx_hash, t.tx_eth_hash, s.signer_address,
+				t.method, t."to", t.amount::text,
+				-- Amounts of EVM txs have no symbol; they are in the native token.
+				CASE WHEN t.amount IS NOT NULL THEN COALESCE(t.amount_symbol, '') END,
+				t.fee::text, t.fee_symbol,
+				t.gas_limit::text, t.gas_used::text, t.size::text,
+				t.success::text, t.error_module, t.error_code::text, t.error_message,
+				t.evm_fn_name, t.body::text
+			FROM chain.runtime_transactions AS t
+			LEFT JOIN chain.runtime_transaction_signers AS s ON
+				s.runtime = t.runtime AND s.round = t.round AND s.tx_index = t.tx_index AND s.signer_index = 0
+			WHERE t.runtime = $1 AND t.round >= $2::bigint AND t.round <= $3::bigint
+			ORDER BY t.round, t.tx_index`,
+		columns: []column{
+			{"round", kindInt64},
+			{"tx_index", kindInt64},
+			{"timestamp", kindString},
+			{"tx_hash", kindString},
+			{"tx_eth_hash", kindString},
+			{"sender", kindString},
+			{"method", kindString},
+			{"to", kindString},
+			{"amount", kindString},
+			{"amount_symbol", kindString},
+			{"fee", kindString},
+			{"fee_symbol", kindString},
+			{"gas_limit", kindInt64},
+			{"gas_used", kindInt64},
+			{"size", kindInt64},
+			{"success", kindBool},
+			{"error_module", kindString},
+			{"error_code", kindInt64},
+			{"error_message", kindString},
+			{"evm_fn_name", kindString},
+			{"body", kindString},
+		},
+		amounts: []amount{
+			{name: "amount_decimal", value: 8, symbol: 9, decimals: -1},
+			{name: "fee_decimal", value: 10, symbol: 11, decimals: -1},
+		},
+	},
+	{
+		name:    "runtime_events",
+		runtime: true,
+		query: `
+			SELECT
+				round::text, tx_index::text, event_index::text, ` + isoTimestamp + `, tx_hash, tx_eth_hash,
+				type, body::text, array_to_json(related_accounts)::text,
+				evm_log_name, evm_log_params::text, encode(evm_log_signature, 'hex')
+			FROM chain.runtime_events
+			WHERE runtime = $1 AND round >= $2::bigint AND round <= $3::bigint
+			ORDER BY round, event_index`,
+		columns: []column{
+			{"round", kindInt64},
+			{"tx_index", kindInt64},
+			{"event_index", kindInt64},
+			{"timestamp", kindString},
+			{"tx_hash", kindString},
+			{"tx_eth_hash", kindString},
+			{"type", kindString},
+			{"body", kindString},
+			{"related_accounts", kindString},
+			{"evm_log_name", kindString},
+			{"evm_log_params", kindString},
+			{"evm_log_signature", kindString},
+		},
+	},
+	{
+		name:    "runtime_transfers",
+		runtime: true,
+		query: `
+			SELECT
+				t.round::text, ` + isoTimestamp + `, t.sender, t.receiver, t.symbol, t.amount::text
+			FROM chain.runtime_transfers AS t
+			JOIN chain.runtime_blocks AS b ON b.runtime = t.runtime AND b.round = t.round
+			WHERE t.runtime = $1 AND t.round >= $2::bigint AND t.round <= $3::bigint
+			ORDER BY t.round`,
+		columns: []column{
+			{"round", kindInt64},
+			{"timestamp", kindString},
+			{"sender", kindString},
+			{"receiver", kindString},
+			{"symbol", kindString},
+			{"amount", kindString},
+		},
+		amounts: []amount{
+			{name: "amount_decimal", value: 5, symbol: 4, decimals: -1},
+		},
+	},
+	{
+		// The current balances of oasis-sdk denominations and EVM tokens.
+		// `denomination` is set only for the former, and `token_decimals`
+		// only for the latter.
+		name:     "token_balances",
+		runtime:  true,
+		snapshot: true,
+		query: `
+			SELECT account_address, symbol, 'native', symbol, NULL, symbol, balance::text
+			FROM chain.runtime_sdk_balances
+			WHERE runtime = $1 AND balance != 0
+			UNION ALL
+			SELECT
+				b.account_address, b.token_address,
+				CASE WHEN t.token_type > 0 THEN 'ERC' || t.token_type END,
+				t.symbol, t.decimals::text, NULL, b.balance::text
+			FROM chain.evm_token_balances AS b
+			LEFT JOIN chain.evm_tokens AS t ON t.runtime = b.runtime AND t.token_address = b.token_address
+			WHERE b.runtime = $1 AND b.balance != 0
+			ORDER BY 1, 2`,
+		columns: []column{
+			{"account_address", kindString},
+			{"token", kindString},
+			{"token_type", kindString},
+			{"token_symbol", kindString},
+			{"token_decimals", kindInt64},
+			{"denomination", kindString},
+			{"balance", kindString},
+		},
+		amounts: []amount{
+			{name: "balance_decimal", value: 6, symbol: 5, decimals: 4},
+		},
+	},
+}
+
+func findDataset(name string) *dataset {
+	for i := range datasets {
+		if datasets[i].name == name {
+			return &datasets[i]
+		}
+	}
+	return nil
+}
+
+func datasetNames() []string {
+	names := make([]string, len(datasets))
+	for i, d := range datasets {
+		names[i] = d.name
+	}
+	return names
+}
@@ -0,0 +1,441 @@
+// Package export implements the export sub-command, which writes indexed
+// datasets to CSV or Parquet files.
+package export
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	sdkConfig "github.com/oasisprotocol/oasis-sdk/client-sdk/go/config"
+
+	cmdCommon "github.com/oasisprotocol/nexus/cmd/common"
+	"github.com/oasisprotocol/nexus/common"
+	"github.com/oasisprotocol/nexus/config"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage"
+	storageClient "github.com/oasisprotocol/nexus/storage/client"
+)
+
+const (
+	moduleName = "export"
+
+	consensusBoundsQuery = `SELECT MIN(height), MAX(height) FROM chain.blocks`
+	runtimeBoundsQuery   = `SELECT MIN(round), MAX(round) FROM chain.runtime_blocks WHERE runtime = $1`
+)
+
+var (
+	// Path to the configuration file.
+	configFile string
+
+	datasetName string
+	runtimeName string
+	formatName  string
+	fromFlag    uint64
+	toFlag      uint64
+	chunkSize   uint64
+	outDir      string
+
+	exportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Export indexed data to CSV or Parquet files",
+		Long: `Export an indexed dataset to CSV or Parquet files.
+
+Datasets other than snapshots (token_balances) are written to one file per
+range of --chunk-size heights (for consensus datasets) or rounds (for runtime
+datasets), named <runtime>_<dataset>_<first>-<last>.<format>. Existing files
+are skipped, so an interrupted export can be resumed by rerunning it. The last
+file is cut at the last indexed block; once more blocks are indexed, rerunning
+the export replaces it with a file of the extended range.
+
+The database and network are those of the server section of the config.
+Amounts are exported in base units, and additionally decoded into whole
+tokens using the denominations of the network.
+
+Datasets: ` + strings.Join(datasetNames(), ", "),
+		Run: runExport,
+	}
+)
+
+func runExport(cmd *cobra.Command, args []string) {
+	// Initialize config.
+	cfg, err := config.InitConfig(configFile)
+	if err != nil {
+		log.NewDefaultLogger("init").Error("init failed",
+			"error", err,
+		)
+		os.Exit(1)
+	}
+
+	// Initialize common environment.
+	if err = cmdCommon.Init(cfg); err != nil {
+		log.NewDefaultLogger("init").Error("init failed",
+			"error", err,
+		)
+		os.Exit(1)
+	}
+	logger := cmdCommon.RootLogger().WithModule(moduleName)
+
+	if cfg.Server == nil {
+		logger.Error("server config not provided")
+		os.Exit(1)
+	}
+
+	e, err := newExporter(cfg.Server, logger)
+	if err != nil {
+		logger.Error("failed to initialize export", "err", err)
+		os.Exit(1)
+	}
+	defer e.db.Close()
+
+	var from, to *uint64
+	if cmd.Flags().Changed("from") {
+		from = &fromFlag
+	}
+	if cmd.Flags().Changed("to") {
+		to = &toFlag
+	}
+	if err = e.run(context.Background(), from, to); err != nil {
+		logger.Error("export failed", "err", err)
+		os.Exit(1)
+	}
+}
+
+type exporter struct {
+	dataset   *dataset
+	runtime   common.Runtime
+	format    Format
+	chunkSize uint64
+	outDir    string
+
+	// Denominations, for decoding amounts.
+	nativeSymbol      string
+	tokenDecimals     func(denom string) int
+	consensusDecimals int
+
+	db     storage.TargetStorage
+	logger *log.Logger
+}
+
+func newExporter(cfg *config.ServerConfig, logger *log.Logger) (*exporter, error) {
+	d := findDataset(datasetName)
+	if d == nil {
+		return nil, fmt.Errorf("unknown dataset %q; expected one of %s", datasetName, strings.Join(datasetNames(), ", "))
+	}
+	format := Format(formatName)
+	if format != FormatCSV && format != FormatParquet {
+		return nil, fmt.Errorf("unknown format %q; expected %s or %s", formatName, FormatCSV, FormatParquet)
+	}
+	if chunkSize == 0 {
+		return nil, fmt.Errorf("chunk size must be positive")
+	}
+	network := cfg.Source.SDKNetwork()
+	if network == nil {
+		return nil, fmt.Errorf("unknown network")
+	}
+	runtime := common.Runtime(runtimeName)
+	switch {
+	case d.runtime && runtime == "":
+		return nil, fmt.Errorf("dataset %s requires a runtime", d.name)
+	case d.runtime && network.ParaTimes.All[string(runtime)] == nil:
+		return nil, fmt.Errorf("unknown runtime %q", runtime)
+	case !d.runtime && runtime != "":
+		return nil, fmt.Errorf("dataset %s is not per-runtime", d.name)
+	}
+
+	db, err := cmdCommon.NewClient(cfg.Storage, logger)
+	if err != nil {
+		return nil, err
+	}
+	// Used only for its denomination logic, so that amounts are decoded the
+	// same way as in the API.
+	client, err := storageClient.NewStorageClient(*cfg.Source, db, cfg.Source.ReferenceSwaps(), nil, network, logger)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &exporter{
+		dataset:           d,
+		runtime:           runtime,
+		format:            format,
+		chunkSize:         chunkSize,
+		outDir:            outDir,
+		nativeSymbol:      client.NativeTokenSymbol(runtime),
+		tokenDecimals:     func(denom string) int { return client.TokenDecimals(runtime, denom) },
+		consensusDecimals: int(network.Denomination.Decimals),
+		db:                db,
+		logger:            logger,
+	}, nil
+}
+
+// run exports the dataset. Unless the dataset is a snapshot, it is exported
+// from the first to the last height/round, inclusive, which default to the
+// first and last indexed block.
+func (e *exporter) run(ctx context.Context, from *uint64, to *uint64) error {
+	if err := os.MkdirAll(e.outDir, 0o755); err != nil {
+		return err
+	}
+	var args []interface{}
+	if e.dataset.runtime {
+		args = append(args, e.runtime)
+	}
+	if e.dataset.snapshot {
+		return e.exportFile(ctx, e.filePath(e.dataset.name), args...)
+	}
+
+	if from == nil || to == nil {
+		var first, last *uint64
+		var err error
+		if e.dataset.runtime {
+			err = e.db.QueryRow(ctx, runtimeBoundsQuery, e.runtime).Scan(&first, &last)
+		} else {
+			err = e.db.QueryRow(ctx, consensusBoundsQuery).Scan(&first, &last)
+		}
+		if err != nil {
+			return fmt.Errorf("querying indexed range: %w", err)
+		}
+		if first == nil || last == nil {
+			e.logger.Info("nothing to export; no blocks are indexed")
+			return nil
+		}
+		if from == nil {
+			from = first
+		}
+		if to == nil {
+			to = last
+		}
+	}
+	for start := *from; start <= *to; start += e.chunkSize {
+		end := min(start+e.chunkSize-1, *to)
+		path := e.chunkPath(start, end)
+		existing, err := e.existingChunks(start)
+		if err != nil {
+			return err
+		}
+		if coveringPath, ok := coveringChunk(existing, end); ok {
+			e.logger.Info("skipping existing file", "path", coveringPath)
+			continue
+		}
+		if err = e.exportFile(ctx, path, append(args, start, end)...); err != nil {
+			return err
+		}
+		// Files of the same start that were exported when fewer blocks were
+		// indexed are superseded by the new file.
+		for oldPath := range existing {
+			if err = os.Remove(oldPath); err != nil {
+				return err
+			}
+			e.logger.Info("removed superseded file", "path", oldPath)
+		}
+		if end == *to {
+			break // Avoid overflow of start.
+		}
+	}
+	return nil
+}
+
+// chunkPath returns the path of the file with the rows from start to end,
+// inclusive.
+func (e *exporter) chunkPath(start, end uint64) string {
+	return e.filePath(fmt.Sprintf("%s_%010d-%010d", e.dataset.name, start, end))
+}
+
+// existingChunks returns the paths of the existing files whose rows start at
+// start, mapped to the last height/round in them. Unless the chunk size has
+// changed, there is at most one; more appear if the last chunk is exported
+// before its range is fully indexed.
+func (e *exporter) existingChunks(start uint64) (map[string]uint64, error) {
+	// The path up to the end of the range, and the suffix after it.
+	suffix := "." + string(e.format)
+	prefix := strings.TrimSuffix(e.filePath(fmt.Sprintf("%s_%010d-", e.dataset.name, start)), suffix)
+	matches, err := filepath.Glob(prefix + "*" + suffix)
+	if err != nil {
+		return nil, err
+	}
+	chunks := map[string]uint64{}
+	for _, path := range matches {
+		end, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(path, prefix), suffix), 10, 64)
+		if err != nil {
+			continue // Not an exported chunk.
+		}
+		chunks[path] = end
+	}
+	return chunks, nil
+}
+
+// coveringChunk returns the path of one of the chunks that contains the rows
+// up to end, if any.
+func coveringChunk(chunks map[string]uint64, end uint64) (string, bool) {
+	for path, chunkEnd := range chunks {
+		if chunkEnd >= end {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+func (e *exporter) filePath(name string) string {
+	if e.dataset.runtime {
+		name = string(e.runtime) + "_" + name
+	}
+	return filepath.Join(e.outDir, name+"."+string(e.format))
+}
+
+// exportFile writes the rows of the dataset query with the given args to
+// the file at path. The file is written under a temporary name and renamed
+// once complete, so that files at path are always complete.
+func (e *exporter) exportFile(ctx context.Context, path string, args ...interface{}) error {
+	tmpPath := path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath) // No-op after the rename.
+	defer f.Close()
+
+	numRows, err := e.writeRows(ctx, f, args...)
+	if err != nil {
+		return fmt.Errorf("exporting %s: %w", path, err)
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	e.logger.Info("exported file", "path", path, "num_rows", numRows)
+	return nil
+}
+
+func (e *exporter) columns() []column {
+	columns := append([]column{}, e.dataset.columns...)
+	for _, a := range e.dataset.amounts {
+		columns = append(columns, column{a.name, kindString})
+	}
+	return columns
+}
+
+func (e *exporter) writeRows(ctx context.Context, f *os.File, args ...interface{}) (int, error) {
+	w, err := newRowWriter(e.format, f, e.columns())
+	if err != nil {
+		return 0, err
+	}
+	rows, err := e.db.Query(ctx, e.dataset.query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("querying rows: %w", err)
+	}
+	defer rows.Close()
+
+	numRows := 0
+	for rows.Next() {
+		row := make([]*string, len(e.dataset.columns), len(e.dataset.columns)+len(e.dataset.amounts))
+		dest := make([]interface{}, len(row))
+		for i := range row {
+			dest[i] = &row[i]
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return numRows, fmt.Errorf("scanning row: %w", err)
+		}
+		if row, err = e.decodeAmounts(row); err != nil {
+			return numRows, err
+		}
+		if err = w.Write(row); err != nil {
+			return numRows, err
+		}
+		numRows++
+	}
+	if err = rows.Err(); err != nil {
+		return numRows, err
+	}
+	return numRows, w.Close()
+}
+
+// decodeAmounts appends the decoded amounts of the dataset to the row.
+func (e *exporter) decodeAmounts(row []*string) ([]*string, error) {
+	for _, a := range e.dataset.amounts {
+		value := row[a.value]
+		var decimals int
+		switch {
+		case value == nil:
+			row = append(row, nil)
+			continue
+		case a.decimals >= 0 && row[a.decimals] != nil:
+			d, err := strconv.Atoi(*row[a.decimals])
+			if err != nil {
+				return nil, fmt.Errorf("%s: malformed decimals: %w", a.name, err)
+			}
+			decimals = d
+		case a.symbol < 0:
+			decimals = e.consensusDecimals
+		case row[a.symbol] == nil:
+			row = append(row, nil)
+			continue
+		default:
+			if *row[a.symbol] == "" {
+				row[a.symbol] = &e.nativeSymbol
+			}
+			decimals = e.denominationDecimals(*row[a.symbol])
+		}
+		decoded, err := formatAmount(*value, decimals)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", a.name, err)
+		}
+		row = append(row, &decoded)
+	}
+	return row, nil
+}
+
+// denominationDecimals returns the number of decimals of the runtime
+// denomination with the given symbol.
+func (e *exporter) denominationDecimals(symbol string) int {
+	if symbol == e.nativeSymbol {
+		return e.tokenDecimals(sdkConfig.NativeDenominationKey)
+	}
+	return e.tokenDecimals(symbol)
+}
+
+// formatAmount formats an amount in base units as a decimal number of whole
+// tokens, without trailing zeros.
+func formatAmount(baseUnits string, decimals int) (string, error) {
+	v, ok := new(big.Int).SetString(baseUnits, 10)
+	if !ok {
+		return "", fmt.Errorf("malformed amount %q", baseUnits)
+	}
+	if decimals <= 0 {
+		return v.String(), nil
+	}
+	sign := ""
+	if v.Sign() < 0 {
+		sign = "-"
+		v.Neg(v)
+	}
+	digits := v.String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if fraction == "" {
+		return sign + whole, nil
+	}
+	return sign + whole + "." + fraction, nil
+}
+
+// Register registers the export sub-command.
+func Register(parentCmd *cobra.Command) {
+	exportCmd.Flags().StringVar(&configFile, "config", "./config/local.yml", "path to the config.yml file")
+	exportCmd.Flags().StringVar(&datasetName, "dataset", "", "dataset to export: "+strings.Join(datasetNames(), ", "))
+	exportCmd.Flags().StringVar(&runtimeName, "runtime", "", "runtime of the dataset, for runtime datasets")
+	exportCmd.Flags().StringVar(&formatName, "format", string(FormatCSV), "file format: csv or parquet")
+	exportCmd.Flags().Uint64Var(&fromFlag, "from", 0, "first height/round to export (default: first indexed)")
+	exportCmd.Flags().Uint64Var(&toFlag, "to", 0, "last height/round to export (default: last indexed)")
+	exportCmd.Flags().Uint64Var(&chunkSize, "chunk-size", 100_000, "number of heights/rounds per file")
+	exportCmd.Flags().StringVar(&outDir, "out-dir", ".", "directory to write the files to")
+	_ = exportCmd.MarkFlagRequired("dataset")
+	parentCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,177 @@
+package export
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	sdkConfig "github.com/oasisprotocol/oasis-sdk/client-sdk/go/config"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+
+	"github.com/oasisprotocol/nexus/common"
+)
+
+func TestFormatAmount(t *testing.T) {
+	for _, tc := range []struct {
+		baseUnits string
+		decimals  int
+		expected  string
+	}{
+		{"1500000000000000000", 18, "1.5"},
+		{"1000000000000000000", 18, "1"},
+		{"1", 18, "0.000000000000000001"},
+		{"0", 18, "0"},
+		{"-2500", 3, "-2.5"},
+		{"12345", 0, "12345"},
+	} {
+		actual, err := formatAmount(tc.baseUnits, tc.decimals)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, actual, "formatAmount(%s, %d)", tc.baseUnits, tc.decimals)
+	}
+	_, err := formatAmount("1.5", 18)
+	require.Error(t, err)
+}
+
+func TestDecodeAmounts(t *testing.T) {
+	e := &exporter{
+		dataset:      findDataset("runtime_transactions"),
+		nativeSymbol: "ROSE",
+		tokenDecimals: func(denom string) int {
+			if denom == sdkConfig.NativeDenominationKey {
+				return 18
+			}
+			return 6
+		},
+	}
+	row := make([]*string, len(e.dataset.columns))
+	row[8] = common.Ptr("1500000000000000000") // amount
+	row[9] = common.Ptr("")                    // amount_symbol; native
+	row[10] = common.Ptr("100")                // fee
+	row[11] = common.Ptr("FOO")                // fee_symbol
+
+	row, err := e.decodeAmounts(row)
+	require.NoError(t, err)
+	require.Len(t, row, len(e.columns()))
+	require.Equal(t, "ROSE", *row[9])
+	require.Equal(t, "1.5", *row[len(row)-2])
+	require.Equal(t, "0.0001", *row[len(row)-1])
+
+	// Amounts without a value or symbol are not decoded.
+	row = make([]*string, len(e.dataset.columns))
+	row[10] = common.Ptr("100")
+	row, err = e.decodeAmounts(row)
+	require.NoError(t, err)
+	require.Nil(t, row[len(row)-2])
+	require.Nil(t, row[len(row)-1])
+}
+
+var testColumns = []column{
+	{"round", kindInt64},
+	{"sender", kindString},
+	{"success", kindBool},
+}
+
+var testRows = [][]*string{
+	{common.Ptr("1"), common.Ptr("oasis1a"), common.Ptr("true")},
+	{common.Ptr("2"), nil, common.Ptr("false")},
+	{common.Ptr("3"), common.Ptr("oasis1, \"c\""), nil},
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := newRowWriter(FormatCSV, &buf, testColumns)
+	require.NoError(t, err)
+	for _, row := range testRows {
+		require.NoError(t, w.Write(row))
+	}
+	require.NoError(t, w.Close())
+	require.Equal(t, "round,sender,success\n1,oasis1a,true\n2,,false\n3,\"oasis1, \"\"c\"\"\",\n", buf.String())
+}
+
+// TestParquetWriter tests that the output can be read back by a Parquet reader,
+// with NULLs and the typed values of the columns.
+func TestParquetWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := newRowWriter(FormatParquet, &buf, testColumns)
+	require.NoError(t, err)
+	for _, row := range testRows {
+		require.NoError(t, w.Write(row))
+	}
+	require.NoError(t, w.Close())
+
+	pf, err := buffer.NewBufferFile(buf.Bytes())
+	require.NoError(t, err)
+	pr, err := reader.NewParquetColumnReader(pf, 1)
+	require.NoError(t, err)
+	defer pr.ReadStop()
+	numRows := len(testRows)
+	require.Equal(t, int64(numRows), pr.GetNumRows())
+	require.Len(t, pr.SchemaHandler.ValueColumns, len(testColumns))
+
+	for i, c := range testColumns {
+		values, _, definitionLevels, err := pr.ReadColumnByIndex(int64(i), int64(numRows))
+		require.NoError(t, err, c.name)
+		require.Len(t, definitionLevels, numRows, c.name)
+		var nonNull int
+		for r, row := range testRows {
+			expected := row[i]
+			if expected == nil {
+				require.Equal(t, int32(0), definitionLevels[r], "%s, row %d", c.name, r)
+				continue
+			}
+			require.Equal(t, int32(1), definitionLevels[r], "%s, row %d", c.name, r)
+			var actual string
+			switch v := values[r].(type) {
+			case int64:
+				actual = strconv.FormatInt(v, 10)
+			case bool:
+				actual = strconv.FormatBool(v)
+			case string:
+				actual = v
+			default:
+				require.Failf(t, "unexpected value type", "%s, row %d: %T", c.name, r, v)
+			}
+			require.Equal(t, *expected, actual, "%s, row %d", c.name, r)
+			nonNull++
+		}
+		require.Positive(t, nonNull, c.name)
+	}
+}
+
+func TestExistingChunks(t *testing.T) {
+	e := &exporter{
+		dataset: findDataset("runtime_events"),
+		runtime: common.RuntimeSapphire,
+		format:  FormatCSV,
+		outDir:  t.TempDir(),
+	}
+	for _, name := range []string{
+		"sapphire_runtime_events_0000001000-0000001234.csv",
+		"sapphire_runtime_events_0000001000-0000001999.csv.tmp", // Incomplete.
+		"sapphire_runtime_events_0000001000-0000001999.parquet", // Other format.
+		"sapphire_runtime_events_0000002000-0000002999.csv",     // Other start.
+		"emerald_runtime_events_0000001000-0000001999.csv",      // Other runtime.
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(e.outDir, name), nil, 0o644))
+	}
+
+	chunks, err := e.existingChunks(1000)
+	require.NoError(t, err)
+	partial := e.chunkPath(1000, 1234)
+	require.Equal(t, map[string]uint64{partial: 1234}, chunks)
+
+	// The partial chunk covers a range up to its end, but not beyond.
+	path, ok := coveringChunk(chunks, 1234)
+	require.True(t, ok)
+	require.Equal(t, partial, path)
+	_, ok = coveringChunk(chunks, 1999)
+	require.False(t, ok)
+
+	chunks, err = e.existingChunks(3000)
+	require.NoError(t, err)
+	require.Empty(t, chunks)
+}
@@ -0,0 +1,52 @@
+package export
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// Number of goroutines that encode the column chunks of a row group.
+const parquetParallelism = 4
+
+// parquetWriter writes a flat schema of OPTIONAL columns, with Snappy
+// compression. Rows are buffered in memory until a row group is flushed.
+type parquetWriter struct {
+	w *writer.CSVWriter
+}
+
+func newParquetWriter(w io.Writer, columns []column) (*parquetWriter, error) {
+	schema := make([]string, len(columns))
+	for i, c := range columns {
+		schema[i] = fmt.Sprintf("name=%s, %s, repetitiontype=OPTIONAL", c.name, c.kind.parquetType())
+	}
+	pw, err := writer.NewCSVWriterFromWriter(schema, w, parquetParallelism)
+	if err != nil {
+		return nil, err
+	}
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+	return &parquetWriter{w: pw}, nil
+}
+
+func (p *parquetWriter) Write(row []*string) error {
+	return p.w.WriteString(row)
+}
+
+func (p *parquetWriter) Close() error {
+	return p.w.WriteStop()
+}
+
+// parquetType returns the schema metadata of the type of columns of kind k,
+// as understood by parquet-go.
+func (k columnKind) parquetType() string {
+	switch k {
+	case kindInt64:
+		return "type=INT64"
+	case kindBool:
+		return "type=BOOLEAN"
+	default:
+		return "type=BYTE_ARRAY, convertedtype=UTF8"
+	}
+}
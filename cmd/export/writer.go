@@ -0,0 +1,84 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// Format is the file format of an export.
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatParquet Format = "parquet"
+)
+
+type columnKind int
+
+const (
+	kindString columnKind = iota
+	kindInt64
+	kindBool
+)
+
+// column is a column of an exported dataset. Values are passed around as
+// text, as returned by the dataset queries; kind determines how they are
+// stored in typed formats (Parquet).
+type column struct {
+	name string
+	kind columnKind
+}
+
+// rowWriter writes the rows of a dataset to a file. A nil value is NULL.
+// Rows must not be modified after they are written.
+type rowWriter interface {
+	Write(row []*string) error
+	// Close flushes the buffered rows, but does not close the underlying
+	// writer.
+	Close() error
+}
+
+func newRowWriter(format Format, w io.Writer, columns []column) (rowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatParquet:
+		return newParquetWriter(w, columns)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// csvWriter writes a header row with the column names, then the rows.
+// NULLs are written as empty fields.
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer, columns []column) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), record: make([]string, len(columns))}
+	for i, c := range columns {
+		cw.record[i] = c.name
+	}
+	if err := cw.w.Write(cw.record); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (cw *csvWriter) Write(row []*string) error {
+	for i, v := range row {
+		cw.record[i] = ""
+		if v != nil {
+			cw.record[i] = *v
+		}
+	}
+	return cw.w.Write(cw.record)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
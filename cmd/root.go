@@ -15,6 +15,7 @@ import (
 	"github.com/oasisprotocol/nexus/cmd/analyzer"
 	"github.com/oasisprotocol/nexus/cmd/api"
 	"github.com/oasisprotocol/nexus/cmd/common"
+	"github.com/oasisprotocol/nexus/cmd/export"
 	"github.com/oasisprotocol/nexus/config"
 	"github.com/oasisprotocol/nexus/log"
 )
@@ -103,6 +104,7 @@ func init() {
 	for _, f := range []func(*cobra.Command){
 		analyzer.Register,
 		api.Register,
+		export.Register,
 	} {
 		f(rootCmd)
 	}
@@ -13,4 +13,5 @@ const (
 	TokenTypeUnsupported TokenType = 0  // A smart contract for which we're confident it's not a supported token kind.
 	TokenTypeERC20       TokenType = 20
 	TokenTypeERC721      TokenType = 721
+	TokenTypeERC1155     TokenType = 1155
 )
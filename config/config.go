@@ -2,7 +2,10 @@
 package config
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
 	"fmt"
+	"net/url"
 	"strings"
 	"time"
 
@@ -106,6 +109,11 @@ func (cfg *AnalysisConfig) Validate() error {
 			return err
 		}
 	}
+	if cfg.Analyzers.AddressLabels != nil {
+		if err := cfg.Analyzers.AddressLabels.Validate(); err != nil {
+			return err
+		}
+	}
 	if cfg.Analyzers.EmeraldContractVerifier != nil {
 		if err := cfg.Analyzers.EmeraldContractVerifier.Validate(); err != nil {
 			return err
@@ -116,6 +124,18 @@ func (cfg *AnalysisConfig) Validate() error {
 			return err
 		}
 	}
+	for _, c := range []*EVMContractCompilerConfig{
+		cfg.Analyzers.EmeraldContractCompiler,
+		cfg.Analyzers.SapphireContractCompiler,
+		cfg.Analyzers.PontusxTestContractCompiler,
+		cfg.Analyzers.PontusxDevContractCompiler,
+	} {
+		if c != nil {
+			if err := c.Validate(); err != nil {
+				return err
+			}
+		}
+	}
 	if cfg.Analyzers.NodeStats != nil {
 		if err := cfg.Analyzers.NodeStats.Validate(); err != nil {
 			return err
@@ -126,6 +146,11 @@ func (cfg *AnalysisConfig) Validate() error {
 			return err
 		}
 	}
+	if cfg.Analyzers.Webhooks != nil {
+		if err := cfg.Analyzers.Webhooks.Validate(); err != nil {
+			return err
+		}
+	}
 
 	return cfg.Storage.Validate(true /* requireMigrations */)
 }
@@ -138,7 +163,8 @@ type AnalyzersList struct {
 	PontusxDev  *BlockBasedAnalyzerConfig `koanf:"pontusx_dev"`
 	Cipher      *BlockBasedAnalyzerConfig `koanf:"cipher"`
 
-	ConsensusAccountsList *ItemBasedAnalyzerConfig `koanf:"consensus_accounts_list"`
+	ConsensusAccountsList  *ItemBasedAnalyzerConfig `koanf:"consensus_accounts_list"`
+	RuntimeEncryptionStats *ItemBasedAnalyzerConfig `koanf:"runtime_encryption_stats"`
 
 	EmeraldEvmTokens            *EvmTokensAnalyzerConfig       `koanf:"evm_tokens_emerald"`
 	SapphireEvmTokens           *EvmTokensAnalyzerConfig       `koanf:"evm_tokens_sapphire"`
@@ -156,19 +182,31 @@ type AnalyzersList struct {
 	SapphireContractCode        *EvmContractCodeAnalyzerConfig `koanf:"evm_contract_code_sapphire"`
 	PontusxTestContractCode     *EvmContractCodeAnalyzerConfig `koanf:"evm_contract_code_pontusx_test"`
 	PontusxDevContractCode      *EvmContractCodeAnalyzerConfig `koanf:"evm_contract_code_pontusx_dev"`
+	EmeraldCallTraces           *EvmCallTracesAnalyzerConfig   `koanf:"evm_call_traces_emerald"`
+	SapphireCallTraces          *EvmCallTracesAnalyzerConfig   `koanf:"evm_call_traces_sapphire"`
+	PontusxTestCallTraces       *EvmCallTracesAnalyzerConfig   `koanf:"evm_call_traces_pontusx_test"`
+	PontusxDevCallTraces        *EvmCallTracesAnalyzerConfig   `koanf:"evm_call_traces_pontusx_dev"`
 	EmeraldContractVerifier     *EVMContractVerifierConfig     `koanf:"evm_contract_verifier_emerald"`
 	SapphireContractVerifier    *EVMContractVerifierConfig     `koanf:"evm_contract_verifier_sapphire"`
 	PontusxTestContractVerifier *EVMContractVerifierConfig     `koanf:"evm_contract_verifier_pontusx_test"`
 	PontusxDevContractVerifier  *EVMContractVerifierConfig     `koanf:"evm_contract_verifier_pontusx_dev"`
+	EmeraldContractCompiler     *EVMContractCompilerConfig     `koanf:"evm_contract_compiler_emerald"`
+	SapphireContractCompiler    *EVMContractCompilerConfig     `koanf:"evm_contract_compiler_sapphire"`
+	PontusxTestContractCompiler *EVMContractCompilerConfig     `koanf:"evm_contract_compiler_pontusx_test"`
+	PontusxDevContractCompiler  *EVMContractCompilerConfig     `koanf:"evm_contract_compiler_pontusx_dev"`
 	EmeraldAbi                  *EvmAbiAnalyzerConfig          `koanf:"evm_abi_emerald"`
 	SapphireAbi                 *EvmAbiAnalyzerConfig          `koanf:"evm_abi_sapphire"`
 	PontusxTestAbi              *EvmAbiAnalyzerConfig          `koanf:"evm_abi_pontusx_test"`
 	PontusxDevAbi               *EvmAbiAnalyzerConfig          `koanf:"evm_abi_pontusx_dev"`
 
 	MetadataRegistry        *MetadataRegistryConfig        `koanf:"metadata_registry"`
+	AddressLabels           *AddressLabelsConfig           `koanf:"address_labels"`
 	ValidatorStakingHistory *ValidatorStakingHistoryConfig `koanf:"validator_staking_history"`
+	ValidatorUptime         *ValidatorUptimeConfig         `koanf:"validator_uptime"`
+	KeyManagerHistory       *KeyManagerHistoryConfig       `koanf:"keymanager_history"`
 	NodeStats               *NodeStatsConfig               `koanf:"node_stats"`
 	AggregateStats          *AggregateStatsConfig          `koanf:"aggregate_stats"`
+	Webhooks                *WebhooksConfig                `koanf:"webhooks"`
 }
 
 type HelperList struct {
@@ -222,6 +260,11 @@ func (sc *SourceConfig) Validate() error {
 		if sc.CustomChain.SDKNetwork == nil {
 			return fmt.Errorf("source.custom_chain.sdk_network not specified")
 		}
+		for runtime, rs := range sc.CustomChain.ReferenceSwaps {
+			if err := rs.Validate(); err != nil {
+				return fmt.Errorf("source.custom_chain.reference_swaps[%s]: %w", runtime, err)
+			}
+		}
 	}
 	for archiveName, archiveConfig := range sc.Nodes {
 		if archiveConfig.DefaultNode == nil && archiveConfig.ConsensusNode == nil && len(archiveConfig.RuntimeNodes) == 0 {
@@ -343,6 +386,11 @@ func (ac *ArchiveConfig) ResolvedRuntimeNode(runtime common.Runtime) *NodeConfig
 type NodeConfig struct {
 	// RPC is the node endpoint.
 	RPC string `koanf:"rpc"`
+	// EVMTraceRPC is an optional Ethereum JSON-RPC endpoint (e.g. an
+	// oasis-web3-gateway) of an EVM runtime that supports
+	// `debug_traceBlockByNumber`. It is used to collect call traces, which
+	// the node cannot produce.
+	EVMTraceRPC string `koanf:"evm_trace_rpc"`
 }
 
 // IPFSConfig is information about accessing IPFS.
@@ -497,6 +545,12 @@ type EvmContractCodeAnalyzerConfig struct {
 	ItemBasedAnalyzerConfig `koanf:",squash"`
 }
 
+// EvmCallTracesAnalyzerConfig is the configuration for the EVM call traces
+// analyzer. It requires `evm_trace_rpc` to be set for the runtime's node.
+type EvmCallTracesAnalyzerConfig struct {
+	ItemBasedAnalyzerConfig `koanf:",squash"`
+}
+
 // EVMContractVerifierConfig is the configuration for the EVM contracts verifier analyzer.
 type EVMContractVerifierConfig struct {
 	ItemBasedAnalyzerConfig `koanf:",squash"`
@@ -511,6 +565,31 @@ func (cfg *EVMContractVerifierConfig) Validate() error {
 	return nil
 }
 
+// EVMContractCompilerConfig is the configuration for the analyzer that verifies
+// EVM contracts submitted through the API by compiling them with local solc binaries.
+type EVMContractCompilerConfig struct {
+	ItemBasedAnalyzerConfig `koanf:",squash"`
+
+	// SolcBinaries maps compiler versions (e.g. "0.8.24") to paths of solc binaries.
+	// Submissions for other compiler versions are rejected.
+	SolcBinaries map[string]string `koanf:"solc_binaries"`
+
+	// CompileTimeout is the maximum duration of a single compilation.
+	// Defaults to 2 minutes if unset.
+	CompileTimeout time.Duration `koanf:"compile_timeout"`
+}
+
+// Validate validates the EVM contract compiler config.
+func (cfg *EVMContractCompilerConfig) Validate() error {
+	if len(cfg.SolcBinaries) == 0 {
+		return fmt.Errorf("evm contract compiler requires at least one solc binary")
+	}
+	if cfg.CompileTimeout < 0 {
+		return fmt.Errorf("evm contract compiler compile_timeout must not be negative")
+	}
+	return nil
+}
+
 type EvmAbiAnalyzerConfig struct {
 	ItemBasedAnalyzerConfig `koanf:",squash"`
 }
@@ -528,6 +607,36 @@ func (cfg *MetadataRegistryConfig) Validate() error {
 	return nil
 }
 
+// AddressLabelsConfig is the configuration for the address labels analyzer.
+type AddressLabelsConfig struct {
+	ItemBasedAnalyzerConfig `koanf:",squash"`
+
+	// RegistryURL is the URL of an optional remote registry of address labels,
+	// indexed in addition to the labels bundled with Nexus. The registry must serve
+	// a JSON object with base64-encoded `labels` and `signature` fields, where
+	// `labels` is a JSON object mapping layers to lists of labels in the format
+	// of named-addresses/*.json, and `signature` is its Ed25519 signature.
+	RegistryURL string `koanf:"registry_url"`
+
+	// RegistryPublicKey is the base64-encoded Ed25519 public key of the
+	// remote registry. Required if RegistryURL is set.
+	RegistryPublicKey string `koanf:"registry_public_key"`
+}
+
+// Validate validates the configuration.
+func (cfg *AddressLabelsConfig) Validate() error {
+	if cfg.Interval != 0 && cfg.Interval < time.Minute {
+		return fmt.Errorf("address labels interval must be at least 1 minute")
+	}
+	if cfg.RegistryURL != "" {
+		publicKey, err := base64.StdEncoding.DecodeString(cfg.RegistryPublicKey)
+		if err != nil || len(publicKey) != ed25519.PublicKeySize {
+			return fmt.Errorf("address labels registry_public_key must be a base64-encoded Ed25519 public key")
+		}
+	}
+	return nil
+}
+
 // ValidatorStakingHistoryConfig is the configuration for the validator balances analyzer.
 type ValidatorStakingHistoryConfig struct {
 	ItemBasedAnalyzerConfig `koanf:",squash"`
@@ -545,6 +654,26 @@ func (cfg *ValidatorStakingHistoryConfig) Validate() error {
 	return nil
 }
 
+// ValidatorUptimeConfig is the configuration for the validator uptime analyzer.
+// Besides validator uptimes, the API needs the active validator sets that it
+// records to tally governance proposals.
+type ValidatorUptimeConfig struct {
+	ItemBasedAnalyzerConfig `koanf:",squash"`
+
+	// From is the height at which the analyzer should start computing validator
+	// uptime from. Defaults to the consensus analyzer start height.
+	From uint64 `koanf:"from"`
+}
+
+// KeyManagerHistoryConfig is the configuration for the key manager history analyzer.
+type KeyManagerHistoryConfig struct {
+	ItemBasedAnalyzerConfig `koanf:",squash"`
+
+	// From is the height at which the analyzer should start tracking key manager
+	// statuses from. Defaults to the consensus analyzer start height.
+	From uint64 `koanf:"from"`
+}
+
 // NodeStatsConfig is the configuration for the node stats analyzer.
 type NodeStatsConfig struct {
 	ItemBasedAnalyzerConfig `koanf:",squash"`
@@ -576,6 +705,96 @@ func (cfg *AggregateStatsConfig) Validate() error {
 	return nil
 }
 
+// WebhooksConfig is the configuration for the webhooks analyzer, which
+// notifies external services about activity of watched accounts.
+type WebhooksConfig struct {
+	ItemBasedAnalyzerConfig `koanf:",squash"`
+
+	// MaxAttempts is the number of times that the delivery of an event is
+	// attempted before it is moved to the dead-letter table.
+	// Uses default value of 8 if unset/set to 0.
+	MaxAttempts uint64 `koanf:"max_attempts"`
+
+	// RetryDelay is the delay before the first retry of a failed delivery.
+	// It doubles with each further attempt, up to MaxRetryDelay.
+	// Uses default values of 10s and 1h if unset/set to 0.
+	RetryDelay    time.Duration `koanf:"retry_delay"`
+	MaxRetryDelay time.Duration `koanf:"max_retry_delay"`
+
+	// Timeout is the timeout of a single delivery request.
+	// Uses default value of 10s if unset/set to 0.
+	Timeout time.Duration `koanf:"timeout"`
+
+	// Subscriptions are the webhooks to notify. They are synced to the DB
+	// when the analyzer starts; subscriptions that are removed from the
+	// config are deleted along with their pending deliveries.
+	Subscriptions []WebhookSubscriptionConfig `koanf:"subscriptions"`
+}
+
+// WebhookSubscriptionConfig is a webhook that is notified about the events
+// of one layer that match its filters.
+type WebhookSubscriptionConfig struct {
+	// ID is a unique, stable name of the subscription. Its progress is
+	// tracked in the DB under this name.
+	ID string `koanf:"id"`
+
+	// URL is the endpoint that events are POSTed to.
+	URL string `koanf:"url"`
+
+	// Secret is the key of the HMAC-SHA256 signature of each request.
+	// It is not stored in the DB.
+	Secret string `koanf:"secret"`
+
+	// Layer is the consensus or the runtime whose events are watched.
+	Layer common.Layer `koanf:"layer"`
+
+	// Addresses are the watched accounts (oasis1 addresses). An event
+	// matches if any of them is among its related accounts.
+	// If empty, events of all accounts match.
+	Addresses []string `koanf:"addresses"`
+
+	// EventTypes are the watched event types, e.g. `staking.transfer`,
+	// `staking.escrow.take` or `governance.proposal_finalized`.
+	// If empty, events of all types match.
+	EventTypes []string `koanf:"event_types"`
+}
+
+func (cfg *WebhooksConfig) Validate() error {
+	seen := make(map[string]struct{})
+	for _, sub := range cfg.Subscriptions {
+		if sub.ID == "" {
+			return fmt.Errorf("webhook subscription id must be set")
+		}
+		if _, ok := seen[sub.ID]; ok {
+			return fmt.Errorf("duplicate webhook subscription id %s", sub.ID)
+		}
+		seen[sub.ID] = struct{}{}
+		if err := sub.Validate(); err != nil {
+			return fmt.Errorf("webhook subscription %s: %w", sub.ID, err)
+		}
+	}
+	return nil
+}
+
+func (cfg *WebhookSubscriptionConfig) Validate() error {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("url must be http or https")
+	}
+	if cfg.Secret == "" {
+		return fmt.Errorf("secret must be set")
+	}
+	switch cfg.Layer {
+	case common.LayerConsensus, common.LayerEmerald, common.LayerCipher, common.LayerSapphire, common.LayerPontusxTest, common.LayerPontusxDev:
+	default:
+		return fmt.Errorf("invalid layer %q", cfg.Layer)
+	}
+	return nil
+}
+
 // ServerConfig contains the API server configuration.
 type ServerConfig struct {
 	// Endpoint is the service endpoint from which to serve the API.
@@ -586,6 +805,66 @@ type ServerConfig struct {
 	// Source is the configuration for accessing oasis-node(s) and chain
 	// information.
 	Source *SourceConfig `koanf:"source"`
+
+	// GraphQL is the configuration for the GraphQL endpoint. If not provided,
+	// the endpoint is served with the default limits.
+	GraphQL *GraphQLConfig `koanf:"graphql"`
+
+	// EVMContractVerification enables the submission of EVM contract sources for
+	// verification. If not provided, submissions are rejected. The submissions are
+	// processed by the evm_contract_compiler_<runtime> analyzers. The DB user of the
+	// server must be granted the evm_contract_verification_submitter role.
+	EVMContractVerification *EVMContractVerificationConfig `koanf:"evm_contract_verification"`
+}
+
+// EVMContractVerificationConfig is the configuration for the submission of EVM
+// contract sources for verification.
+type EVMContractVerificationConfig struct {
+	// APIKeys are the keys that authorize submissions, passed in the
+	// `Authorization: Bearer <key>` header. At least one is required.
+	APIKeys []string `koanf:"api_keys"`
+
+	// MaxRequestBytes is the maximum size of a submission, including all sources.
+	// 0 means the default.
+	MaxRequestBytes int64 `koanf:"max_request_bytes"`
+}
+
+// Validate validates the EVM contract verification config.
+func (cfg *EVMContractVerificationConfig) Validate() error {
+	if len(cfg.APIKeys) == 0 {
+		return fmt.Errorf("evm contract verification requires at least one api key")
+	}
+	for _, key := range cfg.APIKeys {
+		if key == "" {
+			return fmt.Errorf("evm contract verification api keys must not be empty")
+		}
+	}
+	if cfg.MaxRequestBytes < 0 {
+		return fmt.Errorf("evm contract verification max_request_bytes must not be negative")
+	}
+	return nil
+}
+
+// GraphQLConfig is the configuration for the GraphQL endpoint of the API server.
+type GraphQLConfig struct {
+	// MaxComplexity is the maximum estimated cost of a query. Each requested
+	// field costs 1; the cost of the fields nested in a paginated field is
+	// multiplied by its page size. 0 means the default.
+	MaxComplexity int `koanf:"max_complexity"`
+
+	// MaxDepth is the maximum nesting depth of fields in a query.
+	// 0 means the default.
+	MaxDepth int `koanf:"max_depth"`
+}
+
+func (cfg *GraphQLConfig) Validate() error {
+	if cfg.MaxComplexity < 0 {
+		return fmt.Errorf("graphql max_complexity must not be negative")
+	}
+	if cfg.MaxDepth < 0 {
+		return fmt.Errorf("graphql max_depth must not be negative")
+	}
+	return nil
 }
 
 // Validate validates the server configuration.
@@ -605,6 +884,16 @@ func (cfg *ServerConfig) Validate() error {
 	if cfg.Source.Cache != nil {
 		return fmt.Errorf("server config should not have a cache configured")
 	}
+	if cfg.GraphQL != nil {
+		if err := cfg.GraphQL.Validate(); err != nil {
+			return err
+		}
+	}
+	if cfg.EVMContractVerification != nil {
+		if err := cfg.EVMContractVerification.Validate(); err != nil {
+			return err
+		}
+	}
 
 	return cfg.Storage.Validate(false /* requireMigrations */)
 }
@@ -1,10 +1,23 @@
 package config
 
 import (
+	"fmt"
+
 	apiTypes "github.com/oasisprotocol/nexus/api/v1/types"
 	"github.com/oasisprotocol/nexus/common"
 )
 
+// SwapPoolType is the kind of swap pool contracts that a factory creates.
+type SwapPoolType string
+
+const (
+	// SwapPoolTypeUniswapV2 pairs price tokens by the ratio of their reserves.
+	SwapPoolTypeUniswapV2 SwapPoolType = "uniswap_v2"
+	// SwapPoolTypeUniswapV3 pools with concentrated liquidity price tokens by
+	// their sqrtPriceX96. There can be a pool per fee tier for the same tokens.
+	SwapPoolTypeUniswapV3 SwapPoolType = "uniswap_v3"
+)
+
 // ReferenceSwap identifies a factory contract and reference token in a
 // runtime. These settings identify a set of swap contracts that allow Nexus
 // to compare the relative value of tokens.
@@ -17,6 +30,35 @@ import (
 type ReferenceSwap struct {
 	FactoryAddr        apiTypes.Address `koanf:"factory_addr"`
 	ReferenceTokenAddr apiTypes.Address `koanf:"reference_token_addr"`
+	// PoolType is the kind of pools that the factory creates. Defaults to
+	// Uniswap V2 pairs.
+	PoolType SwapPoolType `koanf:"pool_type"`
+	// Fee is the fee tier, in hundredths of a bip, of the Uniswap V3 pools to
+	// use. If zero, the pool with the most liquidity of the reference token
+	// is used.
+	Fee uint32 `koanf:"fee"`
+	// USDTokenAddr is an optional USD stablecoin. If set, token prices can
+	// also be expressed in USD, through the pool of the reference token and
+	// this token that was created by the same factory.
+	USDTokenAddr apiTypes.Address `koanf:"usd_token_addr"`
+}
+
+// IsUniswapV3 returns whether the factory creates Uniswap V3 pools.
+func (rs ReferenceSwap) IsUniswapV3() bool {
+	return rs.PoolType == SwapPoolTypeUniswapV3
+}
+
+func (rs ReferenceSwap) Validate() error {
+	switch rs.PoolType {
+	case "", SwapPoolTypeUniswapV2:
+		if rs.Fee != 0 {
+			return fmt.Errorf("fee is only supported for %s pools", SwapPoolTypeUniswapV3)
+		}
+	case SwapPoolTypeUniswapV3:
+	default:
+		return fmt.Errorf("unknown pool_type %q", rs.PoolType)
+	}
+	return nil
 }
 
 var DefaultReferenceSwaps = map[common.ChainName]map[common.Runtime]ReferenceSwap{
@@ -28,6 +70,7 @@ var DefaultReferenceSwaps = map[common.ChainName]map[common.Runtime]ReferenceSwa
 			// Wrapped ROSE
 			// https://docs.oasis.io/dapp/sapphire/addresses/
 			ReferenceTokenAddr: "oasis1qpdgv5nv2dhxp4q897cgag6kgnm9qs0dccwnckuu",
+			PoolType:           SwapPoolTypeUniswapV2,
 		},
 	},
 }
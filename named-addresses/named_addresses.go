@@ -0,0 +1,11 @@
+// Package namedaddresses embeds the lists of known addresses in this directory,
+// so that Nexus can index them as address labels.
+package namedaddresses
+
+import "embed"
+
+// Files holds the lists of known addresses, one `<chain>_<layer>.json` file
+// per chain and layer.
+//
+//go:embed *.json
+var Files embed.FS
@@ -3,38 +3,96 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"sync"
 
-	"github.com/jackc/pgx/v5"
-
 	"github.com/oasisprotocol/nexus/common"
 )
 
+// ErrNoRows is returned by QueryResult.Scan when the query returned no rows.
+// Backends translate their native "no rows" errors to it.
+var ErrNoRows = errors.New("no rows in result set")
+
+// NewBlockNotifyChannel is the name of the notification channel on which
+// block analyzers announce newly committed blocks. The payload of each
+// notification is a JSON-encoded NewBlockNotification.
+const NewBlockNotifyChannel = "nexus_new_block"
+
+// NewBlockNotification is the payload of a notification on NewBlockNotifyChannel.
+type NewBlockNotification struct {
+	// Layer is "consensus" or the name of a runtime.
+	Layer  string `json:"layer"`
+	Height int64  `json:"height"`
+}
+
 type BatchItem struct {
 	Cmd  string
 	Args []interface{}
 }
 
 // QueryBatch represents a batch of queries to be executed atomically.
-// We use a custom type that mirrors `pgx.Batch`, but is thread-safe to use and
-// allows introspection for debugging.
+// We use a custom type that mirrors `pgx.Batch`, but is thread-safe to use,
+// allows introspection for debugging, and is independent of the backend.
 type QueryBatch struct {
 	items []*BatchItem
 	mu    sync.Mutex
 }
 
 // QueryResults represents the results from a read query.
-type QueryResults = pgx.Rows
+type QueryResults interface {
+	// Next prepares the next row for reading. It returns false if there are
+	// no more rows, or if an error occurred; see Err.
+	Next() bool
+
+	// Scan reads the values of the current row into dest.
+	Scan(dest ...interface{}) error
+
+	// Err returns the error, if any, that was encountered during iteration.
+	Err() error
+
+	// Close closes the results. It is safe to call it multiple times.
+	Close()
+}
 
 // QueryResult represents the result from a read query.
-type QueryResult = pgx.Row
+type QueryResult interface {
+	// Scan reads the values of the row into dest. If the query returned
+	// no rows, it returns ErrNoRows.
+	Scan(dest ...interface{}) error
+}
+
+// TxIsoLevel is the transaction isolation level.
+type TxIsoLevel string
+
+// Transaction isolation levels. An empty TxIsoLevel is the backend's default.
+const (
+	Serializable    TxIsoLevel = "serializable"
+	RepeatableRead  TxIsoLevel = "repeatable read"
+	ReadCommitted   TxIsoLevel = "read committed"
+	ReadUncommitted TxIsoLevel = "read uncommitted"
+)
 
 // TxOptions encodes the way DB transactions are executed.
-type TxOptions = pgx.TxOptions
+type TxOptions struct {
+	IsoLevel TxIsoLevel
+}
 
 // Tx represents a database transaction.
-type Tx = pgx.Tx
+type Tx interface {
+	// Query submits a query to fetch data within the transaction.
+	Query(ctx context.Context, sql string, args ...interface{}) (QueryResults, error)
+
+	// QueryRow submits a query to fetch a single row of data within the transaction.
+	QueryRow(ctx context.Context, sql string, args ...interface{}) QueryResult
+
+	// Commit commits the transaction.
+	Commit(ctx context.Context) error
+
+	// Rollback rolls back the transaction. It is safe to call it (e.g. deferred)
+	// after the transaction has been committed; it then has no effect.
+	Rollback(ctx context.Context) error
+}
 
 // Queue adds query to a batch.
 func (b *QueryBatch) Queue(cmd string, args ...interface{}) {
@@ -65,17 +123,6 @@ func (b *QueryBatch) Len() int {
 	return len(b.items)
 }
 
-// AsPgxBatch converts a QueryBatch to a pgx.Batch.
-func (b *QueryBatch) AsPgxBatch() pgx.Batch {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-	pgxBatch := pgx.Batch{}
-	for _, item := range b.items {
-		pgxBatch.Queue(item.Cmd, item.Args...)
-	}
-	return pgxBatch
-}
-
 // Queries returns the queries in the batch. Each item of the returned slice
 // is composed of the SQL command and its arguments.
 func (b *QueryBatch) Queries() []*BatchItem {
@@ -100,9 +147,6 @@ type TargetStorage interface {
 	QueryRow(ctx context.Context, sql string, args ...interface{}) QueryResult
 
 	// Begin starts a new transaction.
-	// XXX: Not the nicest that this exposes the underlying pgx.Tx interface. Could instead
-	// return a `TargetStorage`-like interface wrapper, that only exposes Query/QueryRow/SendBatch/SendBatchWithOptions
-	// and Commit/Rollback.
 	Begin(ctx context.Context) (Tx, error)
 
 	// Close shuts down the target storage client.
@@ -124,6 +168,11 @@ type TargetStorage interface {
 	// WARNING: This might enable triggers not explicitly disabled by DisableTriggersAndFKConstraints.
 	// WARNING: This does not enforce/check contraints on rows that were inserted while triggers were disabled.
 	EnableTriggersAndFKConstraints(ctx context.Context) error
+
+	// Listen subscribes to notifications on the given channel and calls handler
+	// with the payload of each notification. It blocks until the context is
+	// canceled or the underlying connection fails.
+	Listen(ctx context.Context, channel string, handler func(payload string)) error
 }
 
 // Postgres requires valid UTF-8 with no 0x00.
@@ -0,0 +1,163 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	coreStaking "github.com/oasisprotocol/oasis-core/go/staking/api"
+
+	analyzerQueries "github.com/oasisprotocol/nexus/analyzer/queries"
+	apiTypes "github.com/oasisprotocol/nexus/api/v1/types"
+	"github.com/oasisprotocol/nexus/common"
+	"github.com/oasisprotocol/nexus/config"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage"
+	"github.com/oasisprotocol/nexus/storage/client"
+	"github.com/oasisprotocol/nexus/storage/client/queries"
+	"github.com/oasisprotocol/nexus/storage/postgres"
+	"github.com/oasisprotocol/nexus/tests"
+)
+
+type balances struct {
+	available, escrow, debonding int64
+}
+
+// recordBalances sets the balances of an account at a height the same way the
+// consensus analyzer does: the history row is started before the balances are
+// updated and finished after.
+func recordBalances(ctx context.Context, t *testing.T, db *postgres.Client, address string, height int64, b balances) {
+	batch := &storage.QueryBatch{}
+	batch.Queue(analyzerQueries.ConsensusAccountBalanceHistoryStart, height, []string{address})
+	batch.Queue(`
+    INSERT INTO chain.accounts (address, general_balance, escrow_balance_active, escrow_balance_debonding)
+      VALUES ($1, $2, $3, $4)
+    ON CONFLICT (address) DO UPDATE SET
+      general_balance = excluded.general_balance,
+      escrow_balance_active = excluded.escrow_balance_active,
+      escrow_balance_debonding = excluded.escrow_balance_debonding`,
+		address, b.available, b.escrow, b.debonding)
+	batch.Queue(analyzerQueries.ConsensusAccountBalanceHistoryFinish, height, []string{address})
+	require.NoError(t, db.SendBatch(ctx, batch))
+}
+
+func TestAccountBalanceAtHeight(t *testing.T) {
+	tests.SkipIfShort(t)
+	ctx := context.Background()
+	db := setupDB(t)
+
+	address := coreStaking.NewModuleAddress("test", "balances").String()
+	// The account was funded before the history was recorded, then changed at heights 10 and 20.
+	batch := &storage.QueryBatch{}
+	batch.Queue(`INSERT INTO chain.accounts (address, general_balance, escrow_balance_active, escrow_balance_debonding) VALUES ($1, 100, 50, 0)`, address)
+	require.NoError(t, db.SendBatch(ctx, batch))
+	recordBalances(ctx, t, db, address, 10, balances{available: 70, escrow: 80, debonding: 0})
+	recordBalances(ctx, t, db, address, 20, balances{available: 70, escrow: 60, debonding: 20})
+
+	for _, tc := range []struct {
+		name     string
+		height   int64
+		expected balances
+	}{
+		{"before the first change", 5, balances{available: 100, escrow: 50, debonding: 0}},
+		{"just before the first change", 9, balances{available: 100, escrow: 50, debonding: 0}},
+		{"at the first change", 10, balances{available: 70, escrow: 80, debonding: 0}},
+		{"between changes", 15, balances{available: 70, escrow: 80, debonding: 0}},
+		{"at the last change", 20, balances{available: 70, escrow: 60, debonding: 20}},
+		{"after the last change", 1000, balances{available: 70, escrow: 60, debonding: 20}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var available, escrow, debonding common.BigInt
+			require.NoError(t, db.QueryRow(ctx, queries.AccountBalanceAtHeight, address, tc.height).Scan(&available, &escrow, &debonding))
+			require.Equal(t, common.NewBigInt(tc.expected.available).String(), available.String())
+			require.Equal(t, common.NewBigInt(tc.expected.escrow).String(), escrow.String())
+			require.Equal(t, common.NewBigInt(tc.expected.debonding).String(), debonding.String())
+		})
+	}
+
+	// An account without recorded changes has no historical balances.
+	var available, escrow, debonding common.BigInt
+	err := db.QueryRow(ctx, queries.AccountBalanceAtHeight, coreStaking.NewModuleAddress("test", "unchanged").String(), int64(15)).Scan(&available, &escrow, &debonding)
+	require.ErrorIs(t, err, storage.ErrNoRows)
+}
+
+// TestRuntimeAccountBalanceHistory tests the daily balances of a runtime
+// account whose stored balance is not the sum of its recorded changes: the
+// first rounds were processed in fast-sync mode, and the balance was corrected
+// twice without a recorded change.
+func TestRuntimeAccountBalanceHistory(t *testing.T) {
+	tests.SkipIfShort(t)
+	ctx := context.Background()
+	db := setupDB(t)
+
+	runtime := common.RuntimeSapphire
+	address := testAddress("holder")
+	symbol := "TEST"
+	day := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	timestamps := map[uint64]time.Time{
+		1: day,
+		2: day.AddDate(0, 0, 1),
+		3: day.AddDate(0, 0, 2),
+		4: day.AddDate(0, 0, 3),
+		5: day.AddDate(0, 0, 3).Add(time.Hour),
+		6: day.AddDate(0, 0, 4),
+	}
+	batch := &storage.QueryBatch{}
+	for round, timestamp := range timestamps {
+		batch.Queue(`
+    INSERT INTO chain.runtime_blocks (runtime, round, version, timestamp, block_hash, prev_block_hash, io_root, state_root, messages_hash, in_messages_hash, num_transactions, gas_used, size)
+      VALUES ($1, $2, 0, $3, $4, $4, $4, $4, $4, $4, 0, 0, 0)`,
+			runtime, round, timestamp, fmt.Sprintf("%064x", round))
+	}
+	// change records a change of the balance in a round, the same way the
+	// runtime analyzer does; in slow-sync mode, after updating the balance.
+	change := func(round uint64, delta int64, slowSync bool) {
+		if slowSync {
+			batch.Queue(analyzerQueries.RuntimeNativeBalanceUpsert, runtime, address, symbol, delta)
+		}
+		batch.Queue(analyzerQueries.RuntimeBalanceChangeInsert, runtime, round, address, symbol, delta, timestamps[round], slowSync)
+	}
+	correct := func(balance int64) {
+		batch.Queue(analyzerQueries.RuntimeNativeBalanceAbsoluteUpsert, runtime, address, symbol, balance)
+	}
+	change(1, 100, false)
+	change(2, -30, false)
+	correct(500) // The account was funded before the first recorded change.
+	change(3, 50, true)
+	correct(1000)
+	change(4, -100, true)
+	change(5, 10, true)
+	change(6, 5, false) // E.g. after a reindex in fast-sync mode.
+	correct(2000)
+	require.NoError(t, db.SendBatch(ctx, batch))
+
+	c, err := client.NewStorageClient(config.SourceConfig{}, db, nil, nil, nil, log.NewDefaultLogger("testing"))
+	require.NoError(t, err)
+	limit, offset := uint64(10), uint64(0)
+	h, err := c.RuntimeAccountBalanceHistory(
+		context.WithValue(ctx, common.RuntimeContextKey, runtime),
+		parseAddress(t, address),
+		apiTypes.GetRuntimeAccountsAddressBalanceHistoryParams{Token: &symbol, Limit: &limit, Offset: &offset},
+	)
+	require.NoError(t, err)
+
+	expected := []struct {
+		day             time.Time
+		balance, change int64
+	}{
+		{day.AddDate(0, 0, 4), 2000, 5},  // Anchored at the current balance.
+		{day.AddDate(0, 0, 3), 910, -90}, // Anchored at the balance recorded in round 5.
+		{day.AddDate(0, 0, 2), 550, 50},  // Anchored at the balance recorded in round 3.
+		{day.AddDate(0, 0, 1), 500, -30},
+		{day, 530, 100},
+	}
+	require.Len(t, h.History, len(expected))
+	for i, e := range expected {
+		require.Equal(t, e.day.Truncate(24*time.Hour), h.History[i].Date.UTC())
+		require.Equal(t, common.NewBigInt(e.balance).String(), h.History[i].Balance.String())
+		require.Equal(t, common.NewBigInt(e.change).String(), h.History[i].Change.String())
+	}
+}
@@ -2,10 +2,12 @@ package client
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"math"
 	"math/big"
 	"strings"
 	"time"
@@ -13,7 +15,6 @@ import (
 	"github.com/dgraph-io/ristretto"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	ethCommon "github.com/ethereum/go-ethereum/common"
-	"github.com/jackc/pgx/v5"
 
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
 	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
@@ -42,8 +43,15 @@ const (
 	txCost    = 1
 
 	maxTotalCount = 1000
+
+	// Maximum number of pending requests to verify the same contract.
+	maxPendingContractVerifications = 5
 )
 
+// validatorUptimeWindowLengths are the numbers of most recent blocks over which
+// the uptime of validators is reported.
+var validatorUptimeWindowLengths = []uint64{100, 1000}
+
 // StorageClient is a wrapper around a storage.TargetStorage
 // with knowledge of network semantics.
 type StorageClient struct {
@@ -64,6 +72,8 @@ func translateTokenType(tokenType common.TokenType) apiTypes.EvmTokenType {
 		return apiTypes.EvmTokenTypeERC20
 	case common.TokenTypeERC721:
 		return apiTypes.EvmTokenTypeERC721
+	case common.TokenTypeERC1155:
+		return apiTypes.EvmTokenTypeERC1155
 	default:
 		return "unexpected_other_type"
 	}
@@ -91,7 +101,7 @@ func translateLayer(layer apiTypes.Layer) common.Layer {
 }
 
 type rowsWithCount struct {
-	rows                pgx.Rows
+	rows                storage.QueryResults
 	totalCount          uint64
 	isTotalCountClipped bool
 }
@@ -127,6 +137,20 @@ func (c *StorageClient) Shutdown() {
 	c.db.Close()
 }
 
+// ListenNewBlocks calls handler for every block that the analyzers announce
+// as newly processed. It blocks until the context is canceled or the
+// connection to the database fails.
+func (c *StorageClient) ListenNewBlocks(ctx context.Context, handler func(storage.NewBlockNotification)) error {
+	return c.db.Listen(ctx, storage.NewBlockNotifyChannel, func(payload string) {
+		var n storage.NewBlockNotification
+		if err := json.Unmarshal([]byte(payload), &n); err != nil {
+			c.logger.Warn("malformed new block notification", "payload", payload, "err", err)
+			return
+		}
+		handler(n)
+	})
+}
+
 // Returns the native token symbol of the specified runtime in the network
 // specified by the networkConfig.
 func (c *StorageClient) nativeTokenSymbol(runtime common.Runtime) string {
@@ -151,9 +175,22 @@ func (c *StorageClient) tokenDecimals(runtime common.Runtime, denom string) int
 	return int(c.networkConfig.ParaTimes.All[string(runtime)].Denominations[denom].Decimals)
 }
 
+// NativeTokenSymbol returns the symbol of the native token of the runtime, as
+// specified by the network config, or "" if it is unknown.
+func (c *StorageClient) NativeTokenSymbol(runtime common.Runtime) string {
+	return c.nativeTokenSymbol(runtime)
+}
+
+// TokenDecimals returns the number of decimals of the runtime denomination
+// (oasisConfig.NativeDenominationKey for the native token), as specified by the
+// network config, or 0 if it is unknown.
+func (c *StorageClient) TokenDecimals(runtime common.Runtime, denom string) int {
+	return c.tokenDecimals(runtime, denom)
+}
+
 // Wraps an error into one of the error types defined by the `common` package, if applicable.
 func wrapError(err error) error {
-	if err == pgx.ErrNoRows {
+	if err == storage.ErrNoRows {
 		return apiCommon.ErrNotFound
 	}
 	return apiCommon.ErrStorageError{Err: err}
@@ -219,7 +256,7 @@ func (c *StorageClient) Status(ctx context.Context) (*Status, error) {
 	).Scan(&s.LatestBlock, &latestBlockUpdate)
 	switch err {
 	case nil:
-	case pgx.ErrNoRows:
+	case storage.ErrNoRows:
 		s.LatestBlock = -1
 	default:
 		return nil, wrapError(err)
@@ -236,7 +273,7 @@ func (c *StorageClient) Status(ctx context.Context) (*Status, error) {
 	).Scan(nil, nil, &s.LatestBlockTime, nil, nil, nil, nil, nil)
 	switch err {
 	case nil:
-	case pgx.ErrNoRows:
+	case storage.ErrNoRows:
 		s.LatestBlockTime = time.Time{}
 	default:
 		return nil, wrapError(err)
@@ -255,7 +292,7 @@ func (c *StorageClient) Status(ctx context.Context) (*Status, error) {
 		if s.LatestNodeBlock < s.LatestBlock {
 			s.LatestNodeBlock = s.LatestBlock
 		}
-	case pgx.ErrNoRows:
+	case storage.ErrNoRows:
 		s.LatestNodeBlock = -1
 	default:
 		return nil, wrapError(err)
@@ -288,6 +325,10 @@ func (c *StorageClient) Blocks(ctx context.Context, r apiTypes.GetConsensusBlock
 	if err != nil {
 		return nil, wrapError(err)
 	}
+	var cursorHeight *int64
+	if err = decodeCursor(r.Cursor, &cursorHeight); err != nil {
+		return nil, err
+	}
 	res, err := c.withTotalCount(
 		ctx,
 		queries.Blocks,
@@ -297,6 +338,7 @@ func (c *StorageClient) Blocks(ctx context.Context, r apiTypes.GetConsensusBlock
 		r.Before,
 		hash,
 		r.ProposedBy,
+		cursorHeight,
 		r.Limit,
 		r.Offset,
 	)
@@ -340,6 +382,10 @@ func (c *StorageClient) Blocks(ctx context.Context, r apiTypes.GetConsensusBlock
 
 		bs.Blocks = append(bs.Blocks, b)
 	}
+	if isFullPage(len(bs.Blocks), r.Limit) {
+		last := bs.Blocks[len(bs.Blocks)-1]
+		bs.NextCursor = encodeCursor(last.Height)
+	}
 
 	return &bs, nil
 }
@@ -386,6 +432,11 @@ func (c *StorageClient) cacheBlock(blk *Block) {
 
 // Transactions returns a list of consensus transactions.
 func (c *StorageClient) Transactions(ctx context.Context, p apiTypes.GetConsensusTransactionsParams, txHash *string) (*TransactionList, error) {
+	var cursorBlock *int64
+	var cursorTxIndex *int32
+	if err := decodeCursor(p.Cursor, &cursorBlock, &cursorTxIndex); err != nil {
+		return nil, err
+	}
 	res, err := c.withTotalCount(
 		ctx,
 		queries.Transactions,
@@ -396,6 +447,8 @@ func (c *StorageClient) Transactions(ctx context.Context, p apiTypes.GetConsensu
 		p.Rel,
 		p.After,
 		p.Before,
+		cursorBlock,
+		cursorTxIndex,
 		p.Limit,
 		p.Offset,
 	)
@@ -428,6 +481,7 @@ func (c *StorageClient) Transactions(ctx context.Context, p apiTypes.GetConsensu
 			&module,
 			&message,
 			&t.Timestamp,
+			&t.SenderLabel,
 		); err != nil {
 			return nil, wrapError(err)
 		}
@@ -443,12 +497,21 @@ func (c *StorageClient) Transactions(ctx context.Context, p apiTypes.GetConsensu
 
 		ts.Transactions = append(ts.Transactions, t)
 	}
+	if isFullPage(len(ts.Transactions), p.Limit) {
+		last := ts.Transactions[len(ts.Transactions)-1]
+		ts.NextCursor = encodeCursor(last.Block, last.Index)
+	}
 
 	return &ts, nil
 }
 
 // Events returns a list of events.
 func (c *StorageClient) Events(ctx context.Context, p apiTypes.GetConsensusEventsParams) (*EventList, error) {
+	var cursorBlock *int64
+	var cursorEventIndex *int32
+	if err := decodeCursor(p.Cursor, &cursorBlock, &cursorEventIndex); err != nil {
+		return nil, err
+	}
 	res, err := c.withTotalCount(
 		ctx,
 		queries.Events,
@@ -457,6 +520,8 @@ func (c *StorageClient) Events(ctx context.Context, p apiTypes.GetConsensusEvent
 		p.TxHash,
 		p.Type,
 		p.Rel,
+		cursorBlock,
+		cursorEventIndex,
 		p.Limit,
 		p.Offset,
 	)
@@ -471,11 +536,13 @@ func (c *StorageClient) Events(ctx context.Context, p apiTypes.GetConsensusEvent
 		IsTotalCountClipped: res.isTotalCountClipped,
 	}
 
+	var lastEventIndex int32
 	for res.rows.Next() {
 		var e Event
 		if err := res.rows.Scan(
 			&e.Block,
 			&e.TxIndex,
+			&lastEventIndex,
 			&e.TxHash,
 			&e.RoothashRuntimeId,
 			&e.RoothashRuntime,
@@ -483,16 +550,26 @@ func (c *StorageClient) Events(ctx context.Context, p apiTypes.GetConsensusEvent
 			&e.Type,
 			&e.Body,
 			&e.Timestamp,
+			&e.RelatedLabels,
 		); err != nil {
 			return nil, wrapError(err)
 		}
 		es.Events = append(es.Events, e)
 	}
+	if isFullPage(len(es.Events), p.Limit) {
+		es.NextCursor = encodeCursor(es.Events[len(es.Events)-1].Block, lastEventIndex)
+	}
 
 	return &es, nil
 }
 
 func (c *StorageClient) RoothashMessages(ctx context.Context, p apiTypes.GetConsensusRoothashMessagesParams) (*apiTypes.RoothashMessageList, error) {
+	var cursorRound *int64
+	var cursorIndex *int32
+	var cursorRuntime *string
+	if err := decodeCursor(p.Cursor, &cursorRound, &cursorIndex, &cursorRuntime); err != nil {
+		return nil, err
+	}
 	res, err := c.withTotalCount(
 		ctx,
 		queries.RoothashMessages,
@@ -500,6 +577,9 @@ func (c *StorageClient) RoothashMessages(ctx context.Context, p apiTypes.GetCons
 		p.Round,
 		p.Type,
 		p.Rel,
+		cursorRound,
+		cursorIndex,
+		cursorRuntime,
 		p.Limit,
 		p.Offset,
 	)
@@ -538,15 +618,24 @@ func (c *StorageClient) RoothashMessages(ctx context.Context, p apiTypes.GetCons
 		}
 		ms.RoothashMessages = append(ms.RoothashMessages, m)
 	}
+	if isFullPage(len(ms.RoothashMessages), p.Limit) {
+		last := ms.RoothashMessages[len(ms.RoothashMessages)-1]
+		ms.NextCursor = encodeCursor(last.Round, last.Index, last.Runtime)
+	}
 
 	return &ms, nil
 }
 
 // Entities returns a list of registered entities.
 func (c *StorageClient) Entities(ctx context.Context, p apiTypes.GetConsensusEntitiesParams) (*EntityList, error) {
+	var cursorID *string
+	if err := decodeCursor(p.Cursor, &cursorID); err != nil {
+		return nil, err
+	}
 	res, err := c.withTotalCount(
 		ctx,
 		queries.Entities,
+		cursorID,
 		p.Limit,
 		p.Offset,
 	)
@@ -568,6 +657,9 @@ func (c *StorageClient) Entities(ctx context.Context, p apiTypes.GetConsensusEnt
 
 		es.Entities = append(es.Entities, e)
 	}
+	if isFullPage(len(es.Entities), p.Limit) {
+		es.NextCursor = encodeCursor(es.Entities[len(es.Entities)-1].ID)
+	}
 
 	return &es, nil
 }
@@ -607,10 +699,15 @@ func (c *StorageClient) Entity(ctx context.Context, address staking.Address) (*E
 
 // EntityNodes returns a list of nodes controlled by the provided entity.
 func (c *StorageClient) EntityNodes(ctx context.Context, address staking.Address, r apiTypes.GetConsensusEntitiesAddressNodesParams) (*NodeList, error) {
+	var cursorID *string
+	if err := decodeCursor(r.Cursor, &cursorID); err != nil {
+		return nil, err
+	}
 	res, err := c.withTotalCount(
 		ctx,
 		queries.EntityNodes,
 		address.String(),
+		cursorID,
 		r.Limit,
 		r.Offset,
 	)
@@ -641,6 +738,9 @@ func (c *StorageClient) EntityNodes(ctx context.Context, address staking.Address
 
 		ns.Nodes = append(ns.Nodes, n)
 	}
+	if isFullPage(len(ns.Nodes), r.Limit) {
+		ns.NextCursor = encodeCursor(ns.Nodes[len(ns.Nodes)-1].ID)
+	}
 
 	if err := c.db.QueryRow(
 		ctx,
@@ -679,9 +779,15 @@ func (c *StorageClient) EntityNode(ctx context.Context, entityAddress staking.Ad
 
 // Accounts returns a list of consensus accounts.
 func (c *StorageClient) Accounts(ctx context.Context, r apiTypes.GetConsensusAccountsParams) (*AccountList, error) {
+	var cursorTotalBalance, cursorAddress *string
+	if err := decodeCursor(r.Cursor, &cursorTotalBalance, &cursorAddress); err != nil {
+		return nil, err
+	}
 	res, err := c.withTotalCount(
 		ctx,
 		queries.Accounts,
+		cursorTotalBalance,
+		cursorAddress,
 		r.Limit,
 		r.Offset,
 	)
@@ -695,6 +801,7 @@ func (c *StorageClient) Accounts(ctx context.Context, r apiTypes.GetConsensusAcc
 		TotalCount:          res.totalCount,
 		IsTotalCountClipped: res.isTotalCountClipped,
 	}
+	var lastTotalBalance common.BigInt
 	for res.rows.Next() {
 		a := Account{}
 		if err = res.rows.Scan(
@@ -706,24 +813,37 @@ func (c *StorageClient) Accounts(ctx context.Context, r apiTypes.GetConsensusAcc
 			&a.DelegationsBalance,
 			&a.DebondingDelegationsBalance,
 			&a.FirstActivity,
+			&a.Label,
+			&lastTotalBalance,
 		); err != nil {
 			return nil, wrapError(err)
 		}
 		as.Accounts = append(as.Accounts, a)
 	}
+	if isFullPage(len(as.Accounts), r.Limit) {
+		as.NextCursor = encodeCursor(lastTotalBalance.String(), as.Accounts[len(as.Accounts)-1].Address)
+	}
 
 	return &as, nil
 }
 
 // Account returns a consensus account.
-func (c *StorageClient) Account(ctx context.Context, address staking.Address) (*Account, error) {
+func (c *StorageClient) Account(ctx context.Context, address staking.Address, p apiTypes.GetConsensusAccountsAddressParams) (*Account, error) {
+	height, err := c.accountBalanceHeight(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get basic account info.
 	a := Account{
 		// Initialize optional fields to empty values to avoid null pointer dereferences
 		// when filling them from the database.
 		Allowances: []Allowance{},
 	}
-	err := c.db.QueryRow(
+	if a.Label, err = c.addressLabel(ctx, common.LayerConsensus, address); err != nil {
+		return nil, err
+	}
+	err = c.db.QueryRow(
 		ctx,
 		queries.Account,
 		address.String(),
@@ -740,7 +860,7 @@ func (c *StorageClient) Account(ctx context.Context, address staking.Address) (*
 	switch {
 	case err == nil:
 		// Continues below.
-	case err == pgx.ErrNoRows:
+	case err == storage.ErrNoRows:
 		// An address can have no entry in the `accounts` table, which means no analyzer
 		// has seen any activity for this address before. However, the address itself is
 		// still valid, with 0 balance. We rely on type-checking of the input `address` to
@@ -787,9 +907,138 @@ func (c *StorageClient) Account(ctx context.Context, address staking.Address) (*
 		return nil, wrapError(err)
 	}
 
+	// Replace the current balances with historical ones, if requested.
+	if height != nil {
+		err = c.db.QueryRow(
+			ctx,
+			queries.AccountBalanceAtHeight,
+			address.String(),
+			*height,
+		).Scan(
+			&a.Available,
+			&a.Escrow,
+			&a.Debonding,
+		)
+		switch err {
+		case nil:
+		case storage.ErrNoRows:
+			// The balances have not changed since the height; the current ones apply.
+		default:
+			return nil, wrapError(err)
+		}
+	}
+
 	return &a, nil
 }
 
+// addressLabel returns the label of an address on a layer, or nil if the address is not labeled.
+func (c *StorageClient) addressLabel(ctx context.Context, layer common.Layer, address staking.Address) (*string, error) {
+	var label string
+	err := c.db.QueryRow(
+		ctx,
+		queries.AddressLabel,
+		layer,
+		address.String(),
+	).Scan(&label)
+	switch err {
+	case nil:
+		return &label, nil
+	case storage.ErrNoRows:
+		return nil, nil
+	default:
+		return nil, wrapError(err)
+	}
+}
+
+// accountBalanceHeight returns the height at which the balances of an account
+// were requested, or nil for the current balances.
+func (c *StorageClient) accountBalanceHeight(ctx context.Context, p apiTypes.GetConsensusAccountsAddressParams) (*int64, error) {
+	if p.Height == nil && p.Time == nil {
+		return nil, nil
+	}
+	if p.Height != nil && p.Time != nil {
+		return nil, fmt.Errorf("%w: at most one of height and time can be set", apiCommon.ErrBadRequest)
+	}
+
+	var first, latest *int64
+	if err := c.db.QueryRow(ctx, queries.AccountBalanceHistoryRange).Scan(&first, &latest); err != nil {
+		return nil, wrapError(err)
+	}
+	if first == nil || latest == nil {
+		return nil, fmt.Errorf("%w: balance history is not available yet", apiCommon.ErrBadRequest)
+	}
+
+	height := p.Height
+	if p.Time != nil {
+		var h int64
+		switch err := c.db.QueryRow(ctx, queries.BlockHeightAtTime, *p.Time).Scan(&h); err {
+		case nil:
+			height = &h
+		case storage.ErrNoRows:
+			return nil, fmt.Errorf("%w: no indexed block at or before %s", apiCommon.ErrBadRequest, p.Time.Format(time.RFC3339))
+		default:
+			return nil, wrapError(err)
+		}
+	}
+	// Balance changes are only recorded from the first block that was
+	// processed in slow-sync mode.
+	if *height < *first || *height > *latest {
+		return nil, fmt.Errorf("%w: balance history is only available for heights %d to %d", apiCommon.ErrBadRequest, *first, *latest)
+	}
+	return height, nil
+}
+
+// AccountBalanceHistory returns the changes of an account's balances.
+func (c *StorageClient) AccountBalanceHistory(ctx context.Context, address staking.Address, p apiTypes.GetConsensusAccountsAddressBalanceHistoryParams) (*AccountBalanceHistory, error) {
+	var cursorHeight *int64
+	if err := decodeCursor(p.Cursor, &cursorHeight); err != nil {
+		return nil, err
+	}
+	res, err := c.withTotalCount(
+		ctx,
+		queries.AccountBalanceHistory,
+		address.String(),
+		p.From,
+		p.To,
+		p.After,
+		p.Before,
+		cursorHeight,
+		p.Limit,
+		p.Offset,
+	)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer res.rows.Close()
+
+	h := AccountBalanceHistory{
+		History:             []AccountBalanceHistoryPoint{},
+		TotalCount:          res.totalCount,
+		IsTotalCountClipped: res.isTotalCountClipped,
+	}
+	for res.rows.Next() {
+		b := AccountBalanceHistoryPoint{}
+		if err = res.rows.Scan(
+			&b.Height,
+			&b.Timestamp,
+			&b.Available,
+			&b.Escrow,
+			&b.Debonding,
+			&b.AvailableChange,
+			&b.EscrowChange,
+			&b.DebondingChange,
+		); err != nil {
+			return nil, wrapError(err)
+		}
+		h.History = append(h.History, b)
+	}
+	if isFullPage(len(h.History), p.Limit) {
+		h.NextCursor = encodeCursor(h.History[len(h.History)-1].Height)
+	}
+
+	return &h, nil
+}
+
 // Computes shares worth given total shares and total balance.
 func amountFromShares(shares common.BigInt, totalShares common.BigInt, totalBalance common.BigInt) (common.BigInt, error) {
 	if shares.IsZero() {
@@ -806,12 +1055,66 @@ func amountFromShares(shares common.BigInt, totalShares common.BigInt, totalBala
 	return common.BigInt{Int: *amount}, nil
 }
 
+// DelegatorRewards returns the staking rewards earned by a delegator.
+func (c *StorageClient) DelegatorRewards(ctx context.Context, address staking.Address, p apiTypes.GetConsensusAccountsAddressRewardsParams) (*DelegatorRewardList, error) {
+	var cursorEpoch *int64
+	var cursorValidator *string
+	if err := decodeCursor(p.Cursor, &cursorEpoch, &cursorValidator); err != nil {
+		return nil, err
+	}
+	res, err := c.withTotalCount(
+		ctx,
+		queries.DelegatorRewards,
+		address.String(),
+		p.From,
+		p.To,
+		cursorEpoch,
+		cursorValidator,
+		p.Limit,
+		p.Offset,
+	)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer res.rows.Close()
+
+	rs := DelegatorRewardList{
+		Rewards:             []DelegatorReward{},
+		TotalCount:          res.totalCount,
+		IsTotalCountClipped: res.isTotalCountClipped,
+	}
+	for res.rows.Next() {
+		r := DelegatorReward{}
+		if err = res.rows.Scan(
+			&r.Epoch,
+			&r.Validator,
+			&r.Shares,
+			&r.Amount,
+		); err != nil {
+			return nil, wrapError(err)
+		}
+		rs.Rewards = append(rs.Rewards, r)
+	}
+	if isFullPage(len(rs.Rewards), p.Limit) {
+		last := rs.Rewards[len(rs.Rewards)-1]
+		rs.NextCursor = encodeCursor(last.Epoch, last.Validator)
+	}
+
+	return &rs, nil
+}
+
 // Delegations returns a list of delegations.
 func (c *StorageClient) Delegations(ctx context.Context, address staking.Address, p apiTypes.GetConsensusAccountsAddressDelegationsParams) (*DelegationList, error) {
+	var cursorShares, cursorAddress *string
+	if err := decodeCursor(p.Cursor, &cursorShares, &cursorAddress); err != nil {
+		return nil, err
+	}
 	res, err := c.withTotalCount(
 		ctx,
 		queries.Delegations,
 		address.String(),
+		cursorShares,
+		cursorAddress,
 		p.Limit,
 		p.Offset,
 	)
@@ -853,16 +1156,26 @@ func (c *StorageClient) Delegations(ctx context.Context, address staking.Address
 
 		ds.Delegations = append(ds.Delegations, d)
 	}
+	if isFullPage(len(ds.Delegations), p.Limit) {
+		last := ds.Delegations[len(ds.Delegations)-1]
+		ds.NextCursor = encodeCursor(last.Shares.String(), last.Validator)
+	}
 
 	return &ds, nil
 }
 
 // DelegationsTo returns a list of delegations to an address.
 func (c *StorageClient) DelegationsTo(ctx context.Context, address staking.Address, p apiTypes.GetConsensusAccountsAddressDelegationsToParams) (*DelegationList, error) {
+	var cursorShares, cursorAddress *string
+	if err := decodeCursor(p.Cursor, &cursorShares, &cursorAddress); err != nil {
+		return nil, err
+	}
 	res, err := c.withTotalCount(
 		ctx,
 		queries.DelegationsTo,
 		address.String(),
+		cursorShares,
+		cursorAddress,
 		p.Limit,
 		p.Offset,
 	)
@@ -904,16 +1217,28 @@ func (c *StorageClient) DelegationsTo(ctx context.Context, address staking.Addre
 
 		ds.Delegations = append(ds.Delegations, d)
 	}
+	if isFullPage(len(ds.Delegations), p.Limit) {
+		last := ds.Delegations[len(ds.Delegations)-1]
+		ds.NextCursor = encodeCursor(last.Shares.String(), last.Delegator)
+	}
 
 	return &ds, nil
 }
 
 // DebondingDelegations returns a list of debonding delegations.
 func (c *StorageClient) DebondingDelegations(ctx context.Context, address staking.Address, p apiTypes.GetConsensusAccountsAddressDebondingDelegationsParams) (*DebondingDelegationList, error) {
+	var cursorDebondEnd *int64
+	var cursorShares, cursorAddress *string
+	if err := decodeCursor(p.Cursor, &cursorDebondEnd, &cursorShares, &cursorAddress); err != nil {
+		return nil, err
+	}
 	res, err := c.withTotalCount(
 		ctx,
 		queries.DebondingDelegations,
 		address.String(),
+		cursorDebondEnd,
+		cursorShares,
+		cursorAddress,
 		p.Limit,
 		p.Offset,
 	)
@@ -956,16 +1281,28 @@ func (c *StorageClient) DebondingDelegations(ctx context.Context, address stakin
 
 		ds.DebondingDelegations = append(ds.DebondingDelegations, d)
 	}
+	if isFullPage(len(ds.DebondingDelegations), p.Limit) {
+		last := ds.DebondingDelegations[len(ds.DebondingDelegations)-1]
+		ds.NextCursor = encodeCursor(last.DebondEnd, last.Shares.String(), last.Validator)
+	}
 
 	return &ds, nil
 }
 
 // DebondingDelegationsTo returns a list of debonding delegations to an address.
 func (c *StorageClient) DebondingDelegationsTo(ctx context.Context, address staking.Address, p apiTypes.GetConsensusAccountsAddressDebondingDelegationsToParams) (*DebondingDelegationList, error) {
+	var cursorDebondEnd *int64
+	var cursorShares, cursorAddress *string
+	if err := decodeCursor(p.Cursor, &cursorDebondEnd, &cursorShares, &cursorAddress); err != nil {
+		return nil, err
+	}
 	res, err := c.withTotalCount(
 		ctx,
 		queries.DebondingDelegationsTo,
 		address.String(),
+		cursorDebondEnd,
+		cursorShares,
+		cursorAddress,
 		p.Limit,
 		p.Offset,
 	)
@@ -1008,16 +1345,25 @@ func (c *StorageClient) DebondingDelegationsTo(ctx context.Context, address stak
 
 		ds.DebondingDelegations = append(ds.DebondingDelegations, d)
 	}
+	if isFullPage(len(ds.DebondingDelegations), p.Limit) {
+		last := ds.DebondingDelegations[len(ds.DebondingDelegations)-1]
+		ds.NextCursor = encodeCursor(last.DebondEnd, last.Shares.String(), last.Delegator)
+	}
 
 	return &ds, nil
 }
 
 // Epochs returns a list of consensus epochs.
 func (c *StorageClient) Epochs(ctx context.Context, p apiTypes.GetConsensusEpochsParams) (*EpochList, error) {
+	var cursorID *int64
+	if err := decodeCursor(p.Cursor, &cursorID); err != nil {
+		return nil, err
+	}
 	res, err := c.withTotalCount(
 		ctx,
 		queries.Epochs,
 		nil,
+		cursorID,
 		p.Limit,
 		p.Offset,
 	)
@@ -1038,6 +1384,9 @@ func (c *StorageClient) Epochs(ctx context.Context, p apiTypes.GetConsensusEpoch
 
 		es.Epochs = append(es.Epochs, e)
 	}
+	if isFullPage(len(es.Epochs), p.Limit) {
+		es.NextCursor = encodeCursor(es.Epochs[len(es.Epochs)-1].ID)
+	}
 
 	return &es, nil
 }
@@ -1049,6 +1398,7 @@ func (c *StorageClient) Epoch(ctx context.Context, epoch int64) (*Epoch, error)
 		ctx,
 		queries.Epochs,
 		epoch,
+		nil,
 		1,
 		0,
 	).Scan(&e.ID, &e.StartHeight, &e.EndHeight); err != nil {
@@ -1060,11 +1410,16 @@ func (c *StorageClient) Epoch(ctx context.Context, epoch int64) (*Epoch, error)
 
 // Proposals returns a list of governance proposals.
 func (c *StorageClient) Proposals(ctx context.Context, p apiTypes.GetConsensusProposalsParams) (*ProposalList, error) {
+	var cursorID *int64
+	if err := decodeCursor(p.Cursor, &cursorID); err != nil {
+		return nil, err
+	}
 	res, err := c.withTotalCount(
 		ctx,
 		queries.Proposals,
 		p.Submitter,
 		p.State,
+		cursorID,
 		p.Limit,
 		p.Offset,
 	)
@@ -1112,6 +1467,9 @@ func (c *StorageClient) Proposals(ctx context.Context, p apiTypes.GetConsensusPr
 
 		ps.Proposals = append(ps.Proposals, p)
 	}
+	if isFullPage(len(ps.Proposals), p.Limit) {
+		ps.NextCursor = encodeCursor(ps.Proposals[len(ps.Proposals)-1].ID)
+	}
 
 	return &ps, nil
 }
@@ -1153,15 +1511,112 @@ func (c *StorageClient) Proposal(ctx context.Context, proposalID uint64) (*Propo
 		p.ParametersChange = &res
 	}
 
+	tally, err := c.proposalTally(ctx, proposalID)
+	if err != nil {
+		return nil, err
+	}
+	p.Tally = tally
+
 	return &p, nil
 }
 
-// ProposalVotes returns votes for a governance proposal.
-func (c *StorageClient) ProposalVotes(ctx context.Context, proposalID uint64, p apiTypes.GetConsensusProposalsProposalIdVotesParams) (*ProposalVotes, error) {
+// proposalTallyParameters returns the epoch at which voting for a proposal closes, and
+// the governance parameters that determine whether it passes.
+func (c *StorageClient) proposalTallyParameters(ctx context.Context, proposalID uint64) (int64, *tallyParameters, error) {
+	var closesAt int64
+	var params tallyParameters
+	if err := c.db.QueryRow(
+		ctx,
+		queries.ProposalTallyParameters,
+		proposalID,
+	).Scan(
+		&closesAt,
+		&params.stakeThreshold,
+		&params.quorum,
+		&params.threshold,
+	); err != nil {
+		return 0, nil, wrapError(err)
+	}
+	return closesAt, &params, nil
+}
+
+// proposalTally returns the tally of a proposal at the epoch at which voting closes, or
+// at the latest epoch if voting is still open. Returns nil if the validator set or the
+// validator escrow balances are not known for any such epoch, e.g. because the
+// validator_uptime analyzer is not enabled.
+func (c *StorageClient) proposalTally(ctx context.Context, proposalID uint64) (*ProposalTally, error) {
+	closesAt, params, err := c.proposalTallyParameters(ctx, proposalID)
+	if err != nil {
+		return nil, err
+	}
+
+	t := ProposalTally{}
+	switch err = c.db.QueryRow(ctx, queries.ProposalTallyEpoch, closesAt).Scan(&t.Epoch, &t.Height, &t.Timestamp); err {
+	case nil:
+		if t.Timestamp != nil {
+			*t.Timestamp = t.Timestamp.UTC()
+		}
+	case storage.ErrNoRows:
+		return nil, nil
+	default:
+		return nil, wrapError(err)
+	}
+	if err = c.db.QueryRow(
+		ctx,
+		queries.ProposalTally,
+		proposalID,
+		t.Epoch,
+		t.Height, // Count all votes while voting is open.
+	).Scan(
+		&t.Yes,
+		&t.No,
+		&t.Abstain,
+		&t.TotalVotingPower,
+		&t.NumVotingValidators,
+		&t.NumNonVotingValidators,
+	); err != nil {
+		return nil, wrapError(err)
+	}
+	fillTallyOutcome(&t, params)
+
+	rows, err := c.db.Query(ctx, queries.ProposalNonVotingValidators, proposalID, t.Epoch, t.Height)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	nonVoting := []ProposalNonVotingValidator{}
+	for rows.Next() {
+		var v ProposalNonVotingValidator
+		if err = rows.Scan(
+			&v.Address,
+			&v.VotingPower,
+		); err != nil {
+			return nil, wrapError(err)
+		}
+		nonVoting = append(nonVoting, v)
+	}
+	t.NonVotingValidators = &nonVoting
+
+	return &t, nil
+}
+
+// ProposalTallyHistory returns the tallies of a governance proposal after each block with votes.
+func (c *StorageClient) ProposalTallyHistory(ctx context.Context, proposalID uint64, p apiTypes.GetConsensusProposalsProposalIdTallyHistoryParams) (*ProposalTallyHistory, error) {
+	_, params, err := c.proposalTallyParameters(ctx, proposalID)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursorHeight *int64
+	if err = decodeCursor(p.Cursor, &cursorHeight); err != nil {
+		return nil, err
+	}
 	res, err := c.withTotalCount(
 		ctx,
-		queries.ProposalVotes,
+		queries.ProposalTallyHistory,
 		proposalID,
+		cursorHeight,
 		p.Limit,
 		p.Offset,
 	)
@@ -1170,56 +1625,51 @@ func (c *StorageClient) ProposalVotes(ctx context.Context, proposalID uint64, p
 	}
 	defer res.rows.Close()
 
-	vs := ProposalVotes{
-		Votes:               []ProposalVote{},
+	h := ProposalTallyHistory{
+		ProposalID:          proposalID,
+		History:             []ProposalTally{},
 		TotalCount:          res.totalCount,
 		IsTotalCountClipped: res.isTotalCountClipped,
 	}
 	for res.rows.Next() {
-		var v ProposalVote
-		if err := res.rows.Scan(
-			&v.Address,
-			&v.Vote,
-			&v.Height,
-			&v.Timestamp,
+		var t ProposalTally
+		if err = res.rows.Scan(
+			&t.Height,
+			&t.Timestamp,
+			&t.Epoch,
+			&t.Yes,
+			&t.No,
+			&t.Abstain,
+			&t.TotalVotingPower,
+			&t.NumVotingValidators,
+			&t.NumNonVotingValidators,
 		); err != nil {
 			return nil, wrapError(err)
 		}
-
-		vs.Votes = append(vs.Votes, v)
+		fillTallyOutcome(&t, params)
+		h.History = append(h.History, t)
+	}
+	if isFullPage(len(h.History), p.Limit) {
+		h.NextCursor = encodeCursor(*h.History[len(h.History)-1].Height)
 	}
-	vs.ProposalID = proposalID
 
-	return &vs, nil
+	return &h, nil
 }
 
-// Validators returns a list of validators, or optionally the single validator matching `address`.
-func (c *StorageClient) Validators(ctx context.Context, p apiTypes.GetConsensusValidatorsParams, address *staking.Address) (*ValidatorList, error) {
-	var epoch Epoch
-	if err := c.db.QueryRow(
-		ctx,
-		queries.LatestEpochStart,
-	).Scan(&epoch.ID, &epoch.StartHeight); err != nil {
-		return nil, wrapError(err)
-	}
-
-	var stats ValidatorAggStats
-	if err := c.db.QueryRow(
-		ctx,
-		queries.ValidatorsAggStats,
-	).Scan(
-		&stats.TotalVotingPower,
-		&stats.TotalDelegators,
-		&stats.TotalStakedBalance,
-	); err != nil {
-		return nil, wrapError(err)
+// Vaults returns a list of vaults.
+func (c *StorageClient) Vaults(ctx context.Context, p apiTypes.GetConsensusVaultsParams) (*VaultList, error) {
+	var cursorCreatedAt *int64
+	var cursorAddress *string
+	if err := decodeCursor(p.Cursor, &cursorCreatedAt, &cursorAddress); err != nil {
+		return nil, err
 	}
-
 	res, err := c.withTotalCount(
 		ctx,
-		queries.ValidatorsData,
-		address,
-		p.Name,
+		queries.Vaults,
+		p.Creator,
+		p.Authority,
+		cursorCreatedAt,
+		cursorAddress,
 		p.Limit,
 		p.Offset,
 	)
@@ -1228,18 +1678,328 @@ func (c *StorageClient) Validators(ctx context.Context, p apiTypes.GetConsensusV
 	}
 	defer res.rows.Close()
 
-	vs := ValidatorList{
-		Validators:          []Validator{},
-		Stats:               stats,
+	vs := VaultList{
+		Vaults:              []Vault{},
 		TotalCount:          res.totalCount,
 		IsTotalCountClipped: res.isTotalCountClipped,
 	}
 	for res.rows.Next() {
-		v := Validator{
-			Escrow: apiTypes.Escrow{},
+		var v Vault
+		if err := res.rows.Scan(
+			&v.Address,
+			&v.Creator,
+			&v.ID,
+			&v.State,
+			&v.Nonce,
+			&v.AdminAuthority.Addresses,
+			&v.AdminAuthority.Threshold,
+			&v.SuspendAuthority.Addresses,
+			&v.SuspendAuthority.Threshold,
+			&v.CreatedAt,
+		); err != nil {
+			return nil, wrapError(err)
 		}
-		var schedule staking.CommissionSchedule
-		var logoUrl *string
+		vs.Vaults = append(vs.Vaults, v)
+	}
+	if isFullPage(len(vs.Vaults), p.Limit) {
+		last := vs.Vaults[len(vs.Vaults)-1]
+		vs.NextCursor = encodeCursor(last.CreatedAt, last.Address)
+	}
+
+	return &vs, nil
+}
+
+// Vault returns a vault, including its pending actions.
+func (c *StorageClient) Vault(ctx context.Context, address staking.Address) (*Vault, error) {
+	var v Vault
+	if err := c.db.QueryRow(
+		ctx,
+		queries.Vault,
+		address.String(),
+	).Scan(
+		&v.Address,
+		&v.Creator,
+		&v.ID,
+		&v.State,
+		&v.Nonce,
+		&v.AdminAuthority.Addresses,
+		&v.AdminAuthority.Threshold,
+		&v.SuspendAuthority.Addresses,
+		&v.SuspendAuthority.Threshold,
+		&v.CreatedAt,
+	); err != nil {
+		return nil, wrapError(err)
+	}
+
+	rows, err := c.db.Query(ctx, queries.VaultPendingActions, address.String())
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	pendingActions := []VaultPendingAction{}
+	for rows.Next() {
+		var a VaultPendingAction
+		if err := rows.Scan(
+			&a.Nonce,
+			&a.Action,
+			&a.AuthorizedBy,
+		); err != nil {
+			return nil, wrapError(err)
+		}
+		pendingActions = append(pendingActions, a)
+	}
+	v.PendingActions = &pendingActions
+
+	return &v, nil
+}
+
+// KeyManagers returns a list of key managers with their latest status.
+func (c *StorageClient) KeyManagers(ctx context.Context, p apiTypes.GetConsensusKeymanagersParams) (*KeyManagerList, error) {
+	var cursorRuntimeID *string
+	if err := decodeCursor(p.Cursor, &cursorRuntimeID); err != nil {
+		return nil, err
+	}
+	res, err := c.withTotalCount(
+		ctx,
+		queries.KeyManagers,
+		cursorRuntimeID,
+		p.Limit,
+		p.Offset,
+	)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer res.rows.Close()
+
+	ks := KeyManagerList{
+		Keymanagers:         []KeyManager{},
+		TotalCount:          res.totalCount,
+		IsTotalCountClipped: res.isTotalCountClipped,
+	}
+	runtimeIDs := []string{}
+	for res.rows.Next() {
+		k := KeyManager{Churp: []ChurpStatus{}}
+		if err := res.rows.Scan(
+			&k.RuntimeId,
+			&k.Epoch,
+			&k.Height,
+			&k.IsInitialized,
+			&k.IsSecure,
+			&k.Generation,
+			&k.RotationEpoch,
+			&k.Checksum,
+			&k.Nodes,
+			&k.Rsk,
+			&k.Policy,
+		); err != nil {
+			return nil, wrapError(err)
+		}
+		ks.Keymanagers = append(ks.Keymanagers, k)
+		runtimeIDs = append(runtimeIDs, k.RuntimeId)
+	}
+	if isFullPage(len(ks.Keymanagers), p.Limit) {
+		ks.NextCursor = encodeCursor(ks.Keymanagers[len(ks.Keymanagers)-1].RuntimeId)
+	}
+
+	// Attach the CHURP instances of the key managers.
+	rows, err := c.db.Query(ctx, queries.KeyManagerChurpStatuses, runtimeIDs)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	churps := map[string][]ChurpStatus{}
+	for rows.Next() {
+		var runtimeID string
+		var s ChurpStatus
+		if err := rows.Scan(
+			&runtimeID,
+			&s.ID,
+			&s.Epoch,
+			&s.Height,
+			&s.SuiteId,
+			&s.Threshold,
+			&s.ExtraShares,
+			&s.HandoffInterval,
+			&s.Handoff,
+			&s.NextHandoff,
+			&s.Checksum,
+			&s.NextChecksum,
+			&s.Committee,
+			&s.Policy,
+		); err != nil {
+			return nil, wrapError(err)
+		}
+		churps[runtimeID] = append(churps[runtimeID], s)
+	}
+	for i, k := range ks.Keymanagers {
+		if cs, ok := churps[k.RuntimeId]; ok {
+			ks.Keymanagers[i].Churp = cs
+		}
+	}
+
+	return &ks, nil
+}
+
+// KeyManagerHistory returns the status changes of a key manager.
+func (c *StorageClient) KeyManagerHistory(ctx context.Context, runtimeID string, p apiTypes.GetConsensusKeymanagersRuntimeIdHistoryParams) (*KeyManagerStatusHistory, error) {
+	// Runtime IDs are stored in lowercase hex.
+	runtimeID = strings.ToLower(runtimeID)
+
+	var cursorEpoch *int64
+	if err := decodeCursor(p.Cursor, &cursorEpoch); err != nil {
+		return nil, err
+	}
+	res, err := c.withTotalCount(
+		ctx,
+		queries.KeyManagerHistory,
+		runtimeID,
+		p.From,
+		p.To,
+		cursorEpoch,
+		p.Limit,
+		p.Offset,
+	)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer res.rows.Close()
+
+	h := KeyManagerStatusHistory{
+		RuntimeId:           runtimeID,
+		History:             []KeyManagerStatus{},
+		TotalCount:          res.totalCount,
+		IsTotalCountClipped: res.isTotalCountClipped,
+	}
+	for res.rows.Next() {
+		var s KeyManagerStatus
+		if err := res.rows.Scan(
+			&s.Epoch,
+			&s.Height,
+			&s.IsInitialized,
+			&s.IsSecure,
+			&s.Generation,
+			&s.RotationEpoch,
+			&s.Checksum,
+			&s.Nodes,
+			&s.Rsk,
+			&s.Policy,
+		); err != nil {
+			return nil, wrapError(err)
+		}
+		h.History = append(h.History, s)
+	}
+	if isFullPage(len(h.History), p.Limit) {
+		h.NextCursor = encodeCursor(h.History[len(h.History)-1].Epoch)
+	}
+
+	return &h, nil
+}
+
+// ProposalVotes returns votes for a governance proposal.
+func (c *StorageClient) ProposalVotes(ctx context.Context, proposalID uint64, p apiTypes.GetConsensusProposalsProposalIdVotesParams) (*ProposalVotes, error) {
+	var cursorHeight *int64
+	var cursorVoter *string
+	if err := decodeCursor(p.Cursor, &cursorHeight, &cursorVoter); err != nil {
+		return nil, err
+	}
+	res, err := c.withTotalCount(
+		ctx,
+		queries.ProposalVotes,
+		proposalID,
+		cursorHeight,
+		cursorVoter,
+		p.Limit,
+		p.Offset,
+	)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer res.rows.Close()
+
+	vs := ProposalVotes{
+		Votes:               []ProposalVote{},
+		TotalCount:          res.totalCount,
+		IsTotalCountClipped: res.isTotalCountClipped,
+	}
+	for res.rows.Next() {
+		var v ProposalVote
+		if err := res.rows.Scan(
+			&v.Address,
+			&v.Vote,
+			&v.Height,
+			&v.Timestamp,
+		); err != nil {
+			return nil, wrapError(err)
+		}
+
+		vs.Votes = append(vs.Votes, v)
+	}
+	if isFullPage(len(vs.Votes), p.Limit) {
+		if last := vs.Votes[len(vs.Votes)-1]; last.Height != nil {
+			vs.NextCursor = encodeCursor(*last.Height, last.Address)
+		}
+	}
+	vs.ProposalID = proposalID
+
+	return &vs, nil
+}
+
+// Validators returns a list of validators, or optionally the single validator matching `address`.
+func (c *StorageClient) Validators(ctx context.Context, p apiTypes.GetConsensusValidatorsParams, address *staking.Address) (*ValidatorList, error) {
+	var epoch Epoch
+	if err := c.db.QueryRow(
+		ctx,
+		queries.LatestEpochStart,
+	).Scan(&epoch.ID, &epoch.StartHeight); err != nil {
+		return nil, wrapError(err)
+	}
+
+	var stats ValidatorAggStats
+	if err := c.db.QueryRow(
+		ctx,
+		queries.ValidatorsAggStats,
+	).Scan(
+		&stats.TotalVotingPower,
+		&stats.TotalDelegators,
+		&stats.TotalStakedBalance,
+	); err != nil {
+		return nil, wrapError(err)
+	}
+
+	var cursorRank *int64
+	var cursorNodeID *string
+	if err := decodeCursor(p.Cursor, &cursorRank, &cursorNodeID); err != nil {
+		return nil, err
+	}
+	res, err := c.withTotalCount(
+		ctx,
+		queries.ValidatorsData,
+		address,
+		p.Name,
+		cursorRank,
+		cursorNodeID,
+		p.Limit,
+		p.Offset,
+	)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer res.rows.Close()
+
+	vs := ValidatorList{
+		Validators:          []Validator{},
+		Stats:               stats,
+		TotalCount:          res.totalCount,
+		IsTotalCountClipped: res.isTotalCountClipped,
+	}
+	for res.rows.Next() {
+		v := Validator{
+			Escrow: apiTypes.Escrow{},
+		}
+		var schedule staking.CommissionSchedule
+		var logoUrl *string
 		if err = res.rows.Scan(
 			&v.EntityID,
 			&v.EntityAddress,
@@ -1290,6 +2050,14 @@ func (c *StorageClient) Validators(ctx context.Context, p apiTypes.GetConsensusV
 
 		vs.Validators = append(vs.Validators, v)
 	}
+	if isFullPage(len(vs.Validators), p.Limit) {
+		last := vs.Validators[len(vs.Validators)-1]
+		nodeID := ""
+		if last.NodeID != nil {
+			nodeID = *last.NodeID
+		}
+		vs.NextCursor = encodeCursor(last.Rank, nodeID)
+	}
 
 	// When querying for a single validator, include the detailed block sign data for last 100 blocks.
 	if address != nil && len(vs.Validators) == 1 {
@@ -1314,16 +2082,63 @@ func (c *StorageClient) Validators(ctx context.Context, p apiTypes.GetConsensusV
 		vs.Validators[0].SignedBlocks = &signedBlocks
 	}
 
+	entityIDs := make([]string, 0, len(vs.Validators))
+	for _, v := range vs.Validators {
+		entityIDs = append(entityIDs, v.EntityID)
+	}
+	uptimes, err := c.validatorUptimeWindows(ctx, entityIDs, validatorUptimeWindowLengths[len(validatorUptimeWindowLengths)-1])
+	if err != nil {
+		return nil, err
+	}
+	for i := range vs.Validators {
+		if uptime, ok := uptimes[vs.Validators[i].EntityID]; ok {
+			vs.Validators[i].Uptime = &uptime
+		}
+	}
+
 	return &vs, nil
 }
 
+// validatorUptimeWindows returns the block signing statistics over the latest windowLength
+// blocks of the validators with the given entity IDs, keyed by entity ID. Validators that
+// were not in the active validator set during the window are omitted.
+func (c *StorageClient) validatorUptimeWindows(ctx context.Context, entityIDs []string, windowLength uint64) (map[string]ValidatorUptimeWindow, error) {
+	rows, err := c.db.Query(ctx, queries.ValidatorUptimeWindows, entityIDs, windowLength)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	uptimes := make(map[string]ValidatorUptimeWindow, len(entityIDs))
+	for rows.Next() {
+		var entityID string
+		uptime := ValidatorUptimeWindow{WindowLength: windowLength}
+		if err = rows.Scan(
+			&entityID,
+			&uptime.SignedBlocks,
+			&uptime.MissedBlocks,
+			&uptime.ProposedBlocks,
+		); err != nil {
+			return nil, wrapError(err)
+		}
+		uptimes[entityID] = uptime
+	}
+
+	return uptimes, nil
+}
+
 func (c *StorageClient) ValidatorHistory(ctx context.Context, address staking.Address, p apiTypes.GetConsensusValidatorsAddressHistoryParams) (*ValidatorHistory, error) {
+	var cursorEpoch *int64
+	if err := decodeCursor(p.Cursor, &cursorEpoch); err != nil {
+		return nil, err
+	}
 	res, err := c.withTotalCount(
 		ctx,
 		queries.ValidatorHistory,
 		address.String(),
 		p.From,
 		p.To,
+		cursorEpoch,
 		p.Limit,
 		p.Offset,
 	)
@@ -1346,21 +2161,98 @@ func (c *StorageClient) ValidatorHistory(ctx context.Context, address staking.Ad
 			&b.DebondingBalance,
 			&b.DebondingShares,
 			&b.NumDelegators,
+			&b.Apr,
 		); err != nil {
 			return nil, wrapError(err)
 		}
 		h.History = append(h.History, b)
 	}
+	if isFullPage(len(h.History), p.Limit) {
+		h.NextCursor = encodeCursor(h.History[len(h.History)-1].Epoch)
+	}
 
 	return &h, nil
 }
 
+// ValidatorUptime returns the block signing statistics of a single validator.
+func (c *StorageClient) ValidatorUptime(ctx context.Context, address staking.Address, p apiTypes.GetConsensusValidatorsAddressUptimeParams) (*ValidatorUptime, error) {
+	var entityID string
+	if err := c.db.QueryRow(
+		ctx,
+		queries.Entity,
+		address.String(),
+	).Scan(&entityID, nil); err != nil {
+		return nil, wrapError(err)
+	}
+
+	u := ValidatorUptime{
+		Address: common.Ptr(address.String()),
+		Windows: []ValidatorUptimeWindow{},
+		Epochs:  []ValidatorEpochUptime{},
+	}
+	for _, windowLength := range validatorUptimeWindowLengths {
+		uptimes, err := c.validatorUptimeWindows(ctx, []string{entityID}, windowLength)
+		if err != nil {
+			return nil, err
+		}
+		uptime, ok := uptimes[entityID]
+		if !ok {
+			// The validator was not in the active validator set during the window.
+			uptime = ValidatorUptimeWindow{WindowLength: windowLength}
+		}
+		u.Windows = append(u.Windows, uptime)
+	}
+
+	var cursorEpoch *int64
+	if err := decodeCursor(p.Cursor, &cursorEpoch); err != nil {
+		return nil, err
+	}
+	res, err := c.withTotalCount(
+		ctx,
+		queries.ValidatorEpochUptimes,
+		address.String(),
+		p.From,
+		p.To,
+		cursorEpoch,
+		p.Limit,
+		p.Offset,
+	)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer res.rows.Close()
+
+	u.TotalCount = res.totalCount
+	u.IsTotalCountClipped = res.isTotalCountClipped
+	for res.rows.Next() {
+		e := ValidatorEpochUptime{}
+		if err = res.rows.Scan(
+			&e.Epoch,
+			&e.SignedBlocks,
+			&e.MissedBlocks,
+			&e.ProposedBlocks,
+		); err != nil {
+			return nil, wrapError(err)
+		}
+		u.Epochs = append(u.Epochs, e)
+	}
+	if isFullPage(len(u.Epochs), p.Limit) {
+		u.NextCursor = encodeCursor(u.Epochs[len(u.Epochs)-1].Epoch)
+	}
+
+	return &u, nil
+}
+
 // RuntimeBlocks returns a list of runtime blocks.
 func (c *StorageClient) RuntimeBlocks(ctx context.Context, p apiTypes.GetRuntimeBlocksParams) (*RuntimeBlockList, error) {
 	hash, err := canonicalizedHash(p.Hash)
 	if err != nil {
 		return nil, wrapError(err)
 	}
+	var cursorRound *int64
+	if err = decodeCursor(p.Cursor, &cursorRound); err != nil {
+		return nil, err
+	}
 	res, err := c.withTotalCount(
 		ctx,
 		queries.RuntimeBlocks,
@@ -1370,6 +2262,7 @@ func (c *StorageClient) RuntimeBlocks(ctx context.Context, p apiTypes.GetRuntime
 		p.After,
 		p.Before,
 		hash,
+		cursorRound,
 		p.Limit,
 		p.Offset,
 	)
@@ -1392,6 +2285,9 @@ func (c *StorageClient) RuntimeBlocks(ctx context.Context, p apiTypes.GetRuntime
 
 		bs.Blocks = append(bs.Blocks, b)
 	}
+	if isFullPage(len(bs.Blocks), p.Limit) {
+		bs.NextCursor = encodeCursor(bs.Blocks[len(bs.Blocks)-1].Round)
+	}
 
 	return &bs, nil
 }
@@ -1443,6 +2339,11 @@ func (c *StorageClient) RuntimeTransactions(ctx context.Context, p apiTypes.GetR
 	if err != nil {
 		return nil, err
 	}
+	var cursorRound *int64
+	var cursorTxIndex *int32
+	if err = decodeCursor(p.Cursor, &cursorRound, &cursorTxIndex); err != nil {
+		return nil, err
+	}
 	res, err := c.withTotalCount(
 		ctx,
 		queries.RuntimeTransactions,
@@ -1452,8 +2353,11 @@ func (c *StorageClient) RuntimeTransactions(ctx context.Context, p apiTypes.GetR
 		ocAddrRel,
 		p.After,
 		p.Before,
+		cursorRound,
+		cursorTxIndex,
 		p.Limit,
 		p.Offset,
+		p.Encrypted,
 	)
 	if err != nil {
 		return nil, wrapError(err)
@@ -1480,6 +2384,7 @@ func (c *StorageClient) RuntimeTransactions(ctx context.Context, p apiTypes.GetR
 		var toPreimageContextVersion *int
 		var toPreimageData []byte
 		var errorCode *uint32
+		var evmFnLowConfidence bool
 		if err := res.rows.Scan(
 			&t.Round,
 			&t.Index,
@@ -1501,6 +2406,7 @@ func (c *StorageClient) RuntimeTransactions(ctx context.Context, p apiTypes.GetR
 			&t.Size,
 			&oasisEncryptionEnvelopeFormat,
 			&oasisEncryptionEnvelope.PublicKey,
+			&oasisEncryptionEnvelope.Epoch,
 			&oasisEncryptionEnvelope.DataNonce,
 			&oasisEncryptionEnvelope.Data,
 			&oasisEncryptionEnvelope.ResultNonce,
@@ -1515,6 +2421,7 @@ func (c *StorageClient) RuntimeTransactions(ctx context.Context, p apiTypes.GetR
 			&t.AmountSymbol,
 			&evmEncryptionEnvelopeFormat,
 			&evmEncryptionEnvelope.PublicKey,
+			&evmEncryptionEnvelope.Epoch,
 			&evmEncryptionEnvelope.DataNonce,
 			&evmEncryptionEnvelope.Data,
 			&evmEncryptionEnvelope.ResultNonce,
@@ -1522,10 +2429,13 @@ func (c *StorageClient) RuntimeTransactions(ctx context.Context, p apiTypes.GetR
 			&t.Success,
 			&t.EvmFnName,
 			&t.EvmFnParams,
+			&evmFnLowConfidence,
 			&t.Error.Module,
 			&errorCode,
 			&t.Error.Message,
 			&t.Error.RevertParams,
+			&t.Sender0Label,
+			&t.ToLabel,
 		); err != nil {
 			return nil, wrapError(err)
 		}
@@ -1538,6 +2448,9 @@ func (c *StorageClient) RuntimeTransactions(ctx context.Context, p apiTypes.GetR
 		} else if errorCode != nil {
 			t.Error.Code = *errorCode
 		}
+		if evmFnLowConfidence {
+			t.EvmFnLowConfidence = common.Ptr(true)
+		}
 		if oasisEncryptionEnvelopeFormat != nil { // a rudimentary check to determine if the tx was encrypted
 			oasisEncryptionEnvelope.Format = *oasisEncryptionEnvelopeFormat
 			t.OasisEncryptionEnvelope = &oasisEncryptionEnvelope
@@ -1572,10 +2485,70 @@ func (c *StorageClient) RuntimeTransactions(ctx context.Context, p apiTypes.GetR
 
 		ts.Transactions = append(ts.Transactions, t)
 	}
+	if isFullPage(len(ts.Transactions), p.Limit) {
+		last := ts.Transactions[len(ts.Transactions)-1]
+		ts.NextCursor = encodeCursor(last.Round, last.Index)
+	}
 
 	return &ts, nil
 }
 
+// RuntimeInternalCalls returns the internal calls of the runtime
+// transactions with the given (Oasis or Ethereum) hash.
+func (c *StorageClient) RuntimeInternalCalls(ctx context.Context, p apiTypes.GetRuntimeTransactionsTxHashInternalCallsParams, txHash string) (*RuntimeInternalCallList, error) {
+	res, err := c.withTotalCount(
+		ctx,
+		queries.RuntimeInternalCalls,
+		runtimeFromCtx(ctx),
+		txHash,
+		p.Limit,
+		p.Offset,
+	)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer res.rows.Close()
+
+	cs := RuntimeInternalCallList{
+		InternalCalls:       []RuntimeInternalCall{},
+		TotalCount:          res.totalCount,
+		IsTotalCountClipped: res.isTotalCountClipped,
+	}
+	for res.rows.Next() {
+		var ic RuntimeInternalCall
+		var fromEth []byte
+		var toEth []byte
+		if err := res.rows.Scan(
+			&ic.Round,
+			&ic.TxIndex,
+			&ic.TxHash,
+			&ic.EthTxHash,
+			&ic.Index,
+			&ic.Depth,
+			&ic.Type,
+			&ic.From,
+			&fromEth,
+			&ic.To,
+			&toEth,
+			&ic.Amount,
+			&ic.Gas,
+			&ic.GasUsed,
+			&ic.Input,
+			&ic.Output,
+			&ic.Error,
+			&ic.Success,
+		); err != nil {
+			return nil, wrapError(err)
+		}
+		ic.FromEth = EthChecksumAddrPtrFromBarePreimage(fromEth)
+		ic.ToEth = EthChecksumAddrPtrFromBarePreimage(toEth)
+
+		cs.InternalCalls = append(cs.InternalCalls, ic)
+	}
+
+	return &cs, nil
+}
+
 // RuntimeEvents returns a list of runtime events.
 func (c *StorageClient) RuntimeEvents(ctx context.Context, p apiTypes.GetRuntimeEventsParams) (*RuntimeEventList, error) {
 	var evmLogSignature *ethCommon.Hash
@@ -1614,6 +2587,11 @@ func (c *StorageClient) RuntimeEvents(ctx context.Context, p apiTypes.GetRuntime
 		}
 		NFTIdB64 = common.Ptr(base64.StdEncoding.EncodeToString(erc721TransferTokenIdBuf))
 	}
+	var cursorRound *int64
+	var cursorEventIndex *int32
+	if err = decodeCursor(p.Cursor, &cursorRound, &cursorEventIndex); err != nil {
+		return nil, err
+	}
 
 	res, err := c.withTotalCount(
 		ctx,
@@ -1627,6 +2605,8 @@ func (c *StorageClient) RuntimeEvents(ctx context.Context, p apiTypes.GetRuntime
 		ocAddrRel,
 		ocAddrContract,
 		NFTIdB64,
+		cursorRound,
+		cursorEventIndex,
 		p.Limit,
 		p.Offset,
 	)
@@ -1640,6 +2620,7 @@ func (c *StorageClient) RuntimeEvents(ctx context.Context, p apiTypes.GetRuntime
 		TotalCount:          res.totalCount,
 		IsTotalCountClipped: res.isTotalCountClipped,
 	}
+	var lastEventIndex int32
 	for res.rows.Next() {
 		var e RuntimeEvent
 		var et apiTypes.EvmEventToken
@@ -1653,9 +2634,11 @@ func (c *StorageClient) RuntimeEvents(ctx context.Context, p apiTypes.GetRuntime
 		var ownerPreimageContextIdentifier *string
 		var ownerPreimageContextVersion *int
 		var ownerPreimageData []byte
+		var evmLogLowConfidence bool
 		if err := res.rows.Scan(
 			&e.Round,
 			&e.TxIndex,
+			&lastEventIndex,
 			&e.TxHash,
 			&e.EthTxHash,
 			&e.Timestamp,
@@ -1663,6 +2646,7 @@ func (c *StorageClient) RuntimeEvents(ctx context.Context, p apiTypes.GetRuntime
 			&e.Body,
 			&e.EvmLogName,
 			&e.EvmLogParams,
+			&evmLogLowConfidence,
 			&et.Symbol,
 			&tokenType,
 			&et.Decimals,
@@ -1675,9 +2659,13 @@ func (c *StorageClient) RuntimeEvents(ctx context.Context, p apiTypes.GetRuntime
 			&ownerPreimageContextIdentifier,
 			&ownerPreimageContextVersion,
 			&ownerPreimageData,
+			&e.RelatedLabels,
 		); err != nil {
 			return nil, wrapError(err)
 		}
+		if evmLogLowConfidence {
+			e.EvmLogLowConfidence = common.Ptr(true)
+		}
 		if tokenType.Valid {
 			et.Type = common.Ptr(translateTokenType(common.TokenType(tokenType.Int32)))
 		}
@@ -1704,6 +2692,9 @@ func (c *StorageClient) RuntimeEvents(ctx context.Context, p apiTypes.GetRuntime
 		}
 		es.Events = append(es.Events, e)
 	}
+	if isFullPage(len(es.Events), p.Limit) {
+		es.NextCursor = encodeCursor(es.Events[len(es.Events)-1].Round, lastEventIndex)
+	}
 
 	return &es, nil
 }
@@ -1722,7 +2713,7 @@ func (c *StorageClient) fetchAccountBalancesFromNode(ctx context.Context, ch cha
 	).Scan(&latestIndexedHeight, nil)
 	switch err {
 	case nil:
-	case pgx.ErrNoRows:
+	case storage.ErrNoRows:
 		// No runtime blocks indexed yet; return a 0 native balance.
 		ch <- &RuntimeSdkBalance{
 			Balance:       common.NewBigInt(0),
@@ -1793,8 +2784,14 @@ func (c *StorageClient) RuntimeAccount(ctx context.Context, address staking.Addr
 	ch := make(chan *RuntimeSdkBalance)
 	go c.fetchAccountBalancesFromNode(nodeFetchCtx, ch, runtimeFromCtx(ctx), address)
 
+	label, err := c.addressLabel(ctx, common.Layer(runtimeFromCtx(ctx)), address)
+	if err != nil {
+		return nil, err
+	}
+	a.Label = label
+
 	var preimageContext string
-	err := c.db.QueryRow(
+	err = c.db.QueryRow(
 		ctx,
 		queries.AddressPreimage,
 		address,
@@ -1805,7 +2802,7 @@ func (c *StorageClient) RuntimeAccount(ctx context.Context, address staking.Addr
 	)
 	if err == nil { //nolint:gocritic
 		a.AddressPreimage.Context = AddressDerivationContext(preimageContext)
-	} else if err == pgx.ErrNoRows {
+	} else if err == storage.ErrNoRows {
 		// An address can have no entry in the address preimage table, which means no analyzer
 		// has seen any activity for this address before. However, the address itself is
 		// still valid, with 0 balance. We rely on type-checking of the input `address` to
@@ -1878,6 +2875,7 @@ func (c *StorageClient) RuntimeAccount(ctx context.Context, address staking.Addr
 	evmContract := RuntimeEvmContract{
 		Verification: &RuntimeEvmContractVerification{},
 	}
+	var implementationEthAddr []byte
 	err = c.db.QueryRow(
 		ctx,
 		queries.RuntimeEvmContract,
@@ -1892,11 +2890,14 @@ func (c *StorageClient) RuntimeAccount(ctx context.Context, address staking.Addr
 		&evmContract.Verification.CompilationMetadata,
 		&evmContract.Verification.SourceFiles,
 		&evmContract.Verification.VerificationLevel,
+		&evmContract.ImplementationAddress,
+		&implementationEthAddr,
 	)
 	switch err {
 	case nil:
+		evmContract.ImplementationAddressEth = EthChecksumAddrPtrFromBarePreimage(implementationEthAddr)
 		a.EvmContract = &evmContract
-	case pgx.ErrNoRows:
+	case storage.ErrNoRows:
 		// If an account address does not represent a smart contract; skip.
 		a.EvmContract = nil
 	default:
@@ -1907,32 +2908,207 @@ func (c *StorageClient) RuntimeAccount(ctx context.Context, address staking.Addr
 		evmContract.Verification = nil
 	}
 
-	err = c.db.QueryRow(
+	err = c.db.QueryRow(
+		ctx,
+		queries.RuntimeAccountStats,
+		runtimeFromCtx(ctx),
+		address.String(),
+	).Scan(
+		&a.Stats.TotalSent,
+		&a.Stats.TotalReceived,
+		&a.Stats.NumTxns,
+	)
+
+	switch err {
+	case nil:
+	case storage.ErrNoRows:
+		// If an account address has no activity, default to 0.
+		a.Stats.TotalSent = common.Ptr(common.NewBigInt(0))
+		a.Stats.TotalReceived = common.Ptr(common.NewBigInt(0))
+		a.Stats.NumTxns = 0
+	default:
+		return nil, wrapError(err)
+	}
+
+	// Reconcile sdk balance(s) from node with the balance(s) fetched from the db.
+	c.upsertBalances(ch, &a)
+
+	return &a, nil
+}
+
+// SubmitRuntimeEvmContractVerification stores a request to verify the contract at address
+// from its sources. The request is processed asynchronously by the analyzer.
+func (c *StorageClient) SubmitRuntimeEvmContractVerification(ctx context.Context, submission apiTypes.EvmContractVerificationSubmission, address *staking.Address) (*EvmContractVerificationRequest, error) {
+	switch {
+	case strings.TrimSpace(submission.CompilerVersion) == "":
+		return nil, fmt.Errorf("%w: compiler_version is required", apiCommon.ErrBadRequest)
+	case strings.TrimSpace(submission.ContractName) == "":
+		return nil, fmt.Errorf("%w: contract_name is required", apiCommon.ErrBadRequest)
+	case len(submission.Sources) == 0:
+		return nil, fmt.Errorf("%w: sources are required", apiCommon.ErrBadRequest)
+	}
+	sources, err := json.Marshal(submission.Sources)
+	if err != nil {
+		return nil, fmt.Errorf("%w: sources: %v", apiCommon.ErrBadRequest, err)
+	}
+	settings := []byte("{}")
+	if submission.Settings != nil {
+		if settings, err = json.Marshal(submission.Settings); err != nil {
+			return nil, fmt.Errorf("%w: settings: %v", apiCommon.ErrBadRequest, err)
+		}
+	}
+
+	compilerVersion := strings.TrimSpace(submission.CompilerVersion)
+	contractName := strings.TrimSpace(submission.ContractName)
+	inputHash, err := contractVerificationInputHash(compilerVersion, contractName, sources, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	var id int64
+	err = c.db.QueryRow(
+		ctx,
+		queries.RuntimeEvmContractVerificationInsert,
+		runtimeFromCtx(ctx),
+		address.String(),
+		compilerVersion,
+		contractName,
+		sources,
+		settings,
+		inputHash,
+		maxPendingContractVerifications,
+	).Scan(&id)
+	switch err {
+	case nil:
+		return c.RuntimeEvmContractVerification(ctx, id)
+	case storage.ErrNoRows:
+	default:
+		return nil, wrapError(err)
+	}
+
+	// Nothing was inserted; tell whether the request is a duplicate.
+	err = c.db.QueryRow(
+		ctx,
+		queries.RuntimeEvmContractVerificationPending,
+		runtimeFromCtx(ctx),
+		address.String(),
+		inputHash,
+	).Scan(&id)
+	switch err {
+	case nil:
+		return nil, fmt.Errorf("%w: an identical verification request is already pending: %d", apiCommon.ErrBadRequest, id)
+	case storage.ErrNoRows:
+		return nil, fmt.Errorf("%w: the contract already has %d pending verification requests", apiCommon.ErrBadRequest, maxPendingContractVerifications)
+	default:
+		return nil, wrapError(err)
+	}
+}
+
+// contractVerificationInputHash returns the hash by which identical requests to
+// verify a contract are recognized. sources and settings are JSON encodings,
+// which encoding/json produces with sorted keys.
+func contractVerificationInputHash(compilerVersion string, contractName string, sources []byte, settings []byte) ([]byte, error) {
+	input, err := json.Marshal([]interface{}{compilerVersion, contractName, json.RawMessage(sources), json.RawMessage(settings)})
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.Sum256(input)
+	return h[:], nil
+}
+
+// RuntimeEvmContractVerification returns a request to verify a contract from its sources,
+// or nil if there is no such request.
+func (c *StorageClient) RuntimeEvmContractVerification(ctx context.Context, id int64) (*EvmContractVerificationRequest, error) {
+	var v EvmContractVerificationRequest
+	var ethAddress []byte
+	err := c.db.QueryRow(
+		ctx,
+		queries.RuntimeEvmContractVerification,
+		runtimeFromCtx(ctx),
+		id,
+	).Scan(
+		&v.Id,
+		&v.ContractAddress,
+		&ethAddress,
+		&v.Status,
+		&v.VerificationLevel,
+		&v.Error,
+		&v.SubmittedAt,
+		&v.ProcessedAt,
+	)
+	switch err {
+	case nil:
+	case storage.ErrNoRows:
+		return nil, nil
+	default:
+		return nil, wrapError(err)
+	}
+	if ethAddress != nil {
+		v.EthContractAddress = EthChecksumAddrPtrFromBarePreimage(ethAddress)
+	}
+
+	return &v, nil
+}
+
+// RuntimeAccountBalanceHistory returns the daily balances of a runtime account
+// for the token given in p, which defaults to the native token of the runtime.
+func (c *StorageClient) RuntimeAccountBalanceHistory(ctx context.Context, address staking.Address, p apiTypes.GetRuntimeAccountsAddressBalanceHistoryParams) (*RuntimeAccountBalanceHistory, error) {
+	runtime := runtimeFromCtx(ctx)
+	token := c.nativeTokenSymbol(runtime)
+	if p.Token != nil {
+		token = *p.Token
+		// ERC-20 tokens are keyed by the Oasis address of their contract.
+		if ethCommon.IsHexAddress(token) || strings.HasPrefix(token, "oasis1") {
+			tokenAddr, err := apiTypes.UnmarshalToOcAddress(p.Token)
+			if err != nil {
+				return nil, &apiTypes.InvalidParamFormatError{ParamName: "token", Err: err}
+			}
+			token = tokenAddr.String()
+		}
+	}
+	var cursorDay *int64
+	if err := decodeCursor(p.Cursor, &cursorDay); err != nil {
+		return nil, err
+	}
+	res, err := c.withTotalCount(
 		ctx,
-		queries.RuntimeAccountStats,
-		runtimeFromCtx(ctx),
+		queries.RuntimeAccountBalanceHistory,
+		runtime,
 		address.String(),
-	).Scan(
-		&a.Stats.TotalSent,
-		&a.Stats.TotalReceived,
-		&a.Stats.NumTxns,
+		token,
+		p.After,
+		p.Before,
+		cursorDay,
+		p.Limit,
+		p.Offset,
 	)
-
-	switch err {
-	case nil:
-	case pgx.ErrNoRows:
-		// If an account address has no activity, default to 0.
-		a.Stats.TotalSent = common.Ptr(common.NewBigInt(0))
-		a.Stats.TotalReceived = common.Ptr(common.NewBigInt(0))
-		a.Stats.NumTxns = 0
-	default:
+	if err != nil {
 		return nil, wrapError(err)
 	}
+	defer res.rows.Close()
 
-	// Reconcile sdk balance(s) from node with the balance(s) fetched from the db.
-	c.upsertBalances(ch, &a)
+	h := RuntimeAccountBalanceHistory{
+		Token:               token,
+		History:             []RuntimeAccountBalanceHistoryPoint{},
+		TotalCount:          res.totalCount,
+		IsTotalCountClipped: res.isTotalCountClipped,
+	}
+	for res.rows.Next() {
+		b := RuntimeAccountBalanceHistoryPoint{}
+		if err = res.rows.Scan(
+			&b.Date,
+			&b.Balance,
+			&b.Change,
+		); err != nil {
+			return nil, wrapError(err)
+		}
+		h.History = append(h.History, b)
+	}
+	if isFullPage(len(h.History), p.Limit) {
+		h.NextCursor = encodeCursor(h.History[len(h.History)-1].Date.Unix())
+	}
 
-	return &a, nil
+	return &h, nil
 }
 
 // Reads node sdk balances from ch and upserts them into acct.Balances, logging a
@@ -1971,14 +3147,37 @@ func fillInPriceFromReserves(t *EvmToken) {
 	}
 }
 
-func fillInPrice(t *EvmToken, refSwapTokenAddr *apiTypes.Address) {
-	if t.ContractAddr == *refSwapTokenAddr {
+// q96 is 2^96, the scaling factor of Uniswap V3's Q64.96 fixed point numbers.
+var q96 = new(big.Int).Lsh(big.NewInt(1), 96)
+
+// fillInPriceFromSqrtPrice prices a token in a Uniswap V3 pool. The pool's
+// sqrtPriceX96 is the square root of the price of token0 in units of token1,
+// as a Q64.96 fixed point number.
+func fillInPriceFromSqrtPrice(t *EvmToken) {
+	sqrtPrice, _ := new(big.Float).Quo(new(big.Float).SetInt(&t.RefSwap.SqrtPriceX96.Int), new(big.Float).SetInt(q96)).Float64()
+	price0 := sqrtPrice * sqrtPrice
+	if price0 > 0 && !math.IsInf(price0, 0) {
+		if t.ContractAddr == *t.RefSwap.Token0Address {
+			t.RelativePrice = common.Ptr(price0)
+		} else {
+			t.RelativePrice = common.Ptr(1 / price0)
+		}
+	}
+}
+
+func fillInPrice(t *EvmToken, rs *config.ReferenceSwap) {
+	if t.ContractAddr == rs.ReferenceTokenAddr {
 		t.RelativePrice = common.Ptr(1.0)
-	} else if t.RefSwap.Token0Address != nil && t.RefSwap.Token1Address != nil && t.RefSwap.Reserve0 != nil && t.RefSwap.Reserve1 != nil {
-		fillInPriceFromReserves(t)
+	} else if t.RefSwap.Token0Address != nil && t.RefSwap.Token1Address != nil {
+		switch {
+		case rs.IsUniswapV3() && t.RefSwap.SqrtPriceX96 != nil:
+			fillInPriceFromSqrtPrice(t)
+		case !rs.IsUniswapV3() && t.RefSwap.Reserve0 != nil && t.RefSwap.Reserve1 != nil:
+			fillInPriceFromReserves(t)
+		}
 	}
 	if t.RelativePrice != nil {
-		t.RelativeTokenAddress = refSwapTokenAddr
+		t.RelativeTokenAddress = &rs.ReferenceTokenAddr
 		if t.TotalSupply != nil {
 			totalSuppplyF, _ := t.TotalSupply.Float64()
 			t.RelativeTotalValue = common.Ptr(*t.RelativePrice * totalSuppplyF)
@@ -1990,11 +3189,21 @@ func fillInPrice(t *EvmToken, refSwapTokenAddr *apiTypes.Address) {
 // with the correcponding contract address.
 func (c *StorageClient) RuntimeTokens(ctx context.Context, p apiTypes.GetRuntimeEvmTokensParams, address *staking.Address) (*EvmTokenList, error) {
 	runtime := runtimeFromCtx(ctx)
+	var refSwapCfg *config.ReferenceSwap
 	var refSwapFactoryAddr *apiTypes.Address
 	var refSwapTokenAddr *apiTypes.Address
+	var refSwapFee uint32
 	if rs, ok := c.referenceSwaps[runtime]; ok {
+		refSwapCfg = &rs
 		refSwapFactoryAddr = &rs.FactoryAddr
 		refSwapTokenAddr = &rs.ReferenceTokenAddr
+		refSwapFee = rs.Fee
+	}
+	var cursorMarketCap *float64
+	var cursorNumHolders *int64
+	var cursorAddress *string
+	if err := decodeCursor(p.Cursor, &cursorMarketCap, &cursorNumHolders, &cursorAddress); err != nil {
+		return nil, err
 	}
 	res, err := c.withTotalCount(
 		ctx,
@@ -2004,6 +3213,10 @@ func (c *StorageClient) RuntimeTokens(ctx context.Context, p apiTypes.GetRuntime
 		p.Name,
 		refSwapFactoryAddr,
 		refSwapTokenAddr,
+		refSwapFee,
+		cursorMarketCap,
+		cursorNumHolders,
+		cursorAddress,
 		p.Limit,
 		p.Offset,
 	)
@@ -2017,6 +3230,7 @@ func (c *StorageClient) RuntimeTokens(ctx context.Context, p apiTypes.GetRuntime
 		TotalCount:          res.totalCount,
 		IsTotalCountClipped: res.isTotalCountClipped,
 	}
+	var lastMarketCap float64
 	for res.rows.Next() {
 		var t EvmToken
 		var addrPreimage []byte
@@ -2029,6 +3243,7 @@ func (c *StorageClient) RuntimeTokens(ctx context.Context, p apiTypes.GetRuntime
 		var refSwapToken1EthAddr []byte
 		var refToken apiTypes.EvmRefToken
 		var refTokenType *common.TokenType
+		var implementationEthAddr []byte
 		if err2 := res.rows.Scan(
 			&t.ContractAddr,
 			&addrPreimage,
@@ -2047,15 +3262,20 @@ func (c *StorageClient) RuntimeTokens(ctx context.Context, p apiTypes.GetRuntime
 			&refSwapToken0EthAddr,
 			&refSwap.Token1Address,
 			&refSwapToken1EthAddr,
+			&refSwap.Fee,
 			&refSwap.CreateRound,
 			&refSwap.Reserve0,
 			&refSwap.Reserve1,
+			&refSwap.SqrtPriceX96,
 			&refSwap.LastSyncRound,
 			&refTokenType,
 			&refToken.Name,
 			&refToken.Symbol,
 			&refToken.Decimals,
 			&t.VerificationLevel,
+			&t.ImplementationAddress,
+			&implementationEthAddr,
+			&lastMarketCap,
 		); err2 != nil {
 			return nil, wrapError(err2)
 		}
@@ -2063,6 +3283,7 @@ func (c *StorageClient) RuntimeTokens(ctx context.Context, p apiTypes.GetRuntime
 		t.IsVerified = (t.VerificationLevel != nil)
 		t.EthContractAddr = EthChecksumAddrFromBarePreimage(addrPreimage)
 		t.Type = translateTokenType(tokenType)
+		t.ImplementationAddressEth = EthChecksumAddrPtrFromBarePreimage(implementationEthAddr)
 		if refSwapPairAddr != nil {
 			refSwap.PairAddress = *refSwapPairAddr
 			t.RefSwap = &refSwap
@@ -2070,8 +3291,8 @@ func (c *StorageClient) RuntimeTokens(ctx context.Context, p apiTypes.GetRuntime
 			t.RefSwap.FactoryAddressEth = EthChecksumAddrPtrFromBarePreimage(refSwapFactoryEthAddr)
 			t.RefSwap.Token0AddressEth = EthChecksumAddrPtrFromBarePreimage(refSwapToken0EthAddr)
 			t.RefSwap.Token1AddressEth = EthChecksumAddrPtrFromBarePreimage(refSwapToken1EthAddr)
-			if refSwapTokenAddr != nil {
-				fillInPrice(&t, refSwapTokenAddr)
+			if refSwapCfg != nil {
+				fillInPrice(&t, refSwapCfg)
 			}
 		}
 		if refTokenType != nil {
@@ -2080,16 +3301,26 @@ func (c *StorageClient) RuntimeTokens(ctx context.Context, p apiTypes.GetRuntime
 		}
 		ts.EvmTokens = append(ts.EvmTokens, t)
 	}
+	if isFullPage(len(ts.EvmTokens), p.Limit) {
+		last := ts.EvmTokens[len(ts.EvmTokens)-1]
+		ts.NextCursor = encodeCursor(lastMarketCap, last.NumHolders, last.ContractAddr)
+	}
 
 	return &ts, nil
 }
 
 func (c *StorageClient) RuntimeTokenHolders(ctx context.Context, p apiTypes.GetRuntimeEvmTokensAddressHoldersParams, address staking.Address) (*TokenHolderList, error) {
+	var cursorBalance, cursorHolder *string
+	if err := decodeCursor(p.Cursor, &cursorBalance, &cursorHolder); err != nil {
+		return nil, err
+	}
 	res, err := c.withTotalCount(
 		ctx,
 		queries.EvmTokenHolders,
 		runtimeFromCtx(ctx),
 		address,
+		cursorBalance,
+		cursorHolder,
 		p.Limit,
 		p.Offset,
 	)
@@ -2116,11 +3347,250 @@ func (c *StorageClient) RuntimeTokenHolders(ctx context.Context, p apiTypes.GetR
 		h.EthHolderAddress = EthChecksumAddrPtrFromBarePreimage(addrPreimage)
 		hs.Holders = append(hs.Holders, h)
 	}
+	if isFullPage(len(hs.Holders), p.Limit) {
+		last := hs.Holders[len(hs.Holders)-1]
+		hs.NextCursor = encodeCursor(last.Balance.String(), last.HolderAddress)
+	}
 
 	return &hs, nil
 }
 
-func (c *StorageClient) RuntimeEVMNFTs(ctx context.Context, limit *uint64, offset *uint64, tokenAddress *staking.Address, id *common.BigInt, ownerAddress *staking.Address) (*EvmNftList, error) {
+// RuntimeTokenTransfers returns the decoded transfers of EVM tokens. If
+// `token` is non-nil, only transfers of that token are returned. If `account`
+// is non-nil, only transfers from or to that account are returned.
+func (c *StorageClient) RuntimeTokenTransfers(ctx context.Context, limit *uint64, offset *uint64, cursor *string, token *staking.Address, account *staking.Address, counterparty *staking.Address, after *time.Time, before *time.Time) (*EvmTokenTransferList, error) {
+	var cursorRound *int64
+	var cursorEventIndex *int32
+	var cursorTransferIndex *int32
+	if err := decodeCursor(cursor, &cursorRound, &cursorEventIndex, &cursorTransferIndex); err != nil {
+		return nil, err
+	}
+	res, err := c.withTotalCount(
+		ctx,
+		queries.EvmTokenTransfers,
+		runtimeFromCtx(ctx),
+		token,
+		account,
+		counterparty,
+		after,
+		before,
+		cursorRound,
+		cursorEventIndex,
+		cursorTransferIndex,
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer res.rows.Close()
+
+	ts := EvmTokenTransferList{
+		Transfers:           []EvmTokenTransfer{},
+		TotalCount:          res.totalCount,
+		IsTotalCountClipped: res.isTotalCountClipped,
+	}
+	var lastTransferIndex int32
+	for res.rows.Next() {
+		var t EvmTokenTransfer
+		var tokenType common.TokenType
+		var tokenEthAddr []byte
+		var fromEthAddr []byte
+		var toEthAddr []byte
+		if err = res.rows.Scan(
+			&t.Round,
+			&t.EventIndex,
+			&lastTransferIndex,
+			&t.TxIndex,
+			&t.TxHash,
+			&t.EthTxHash,
+			&t.Timestamp,
+			&t.TokenContractAddr,
+			&tokenEthAddr,
+			&tokenType,
+			&t.TokenName,
+			&t.TokenSymbol,
+			&t.TokenDecimals,
+			&t.From,
+			&fromEthAddr,
+			&t.To,
+			&toEthAddr,
+			&t.TokenId,
+			&t.Amount,
+		); err != nil {
+			return nil, wrapError(err)
+		}
+		t.TokenType = translateTokenType(tokenType)
+		t.TokenContractAddrEth = EthChecksumAddrPtrFromBarePreimage(tokenEthAddr)
+		t.FromEth = EthChecksumAddrPtrFromBarePreimage(fromEthAddr)
+		t.ToEth = EthChecksumAddrPtrFromBarePreimage(toEthAddr)
+		ts.Transfers = append(ts.Transfers, t)
+	}
+	if isFullPage(len(ts.Transfers), limit) {
+		last := ts.Transfers[len(ts.Transfers)-1]
+		ts.NextCursor = encodeCursor(last.Round, last.EventIndex, lastTransferIndex)
+	}
+
+	return &ts, nil
+}
+
+// priceSwapPair is a swap pair that prices a token in units of a quote token.
+type priceSwapPair struct {
+	addr apiTypes.Address
+	// Whether the priced token is token0 of the pair.
+	isToken0 bool
+	// The factor that adjusts the ratio of the pair's reserves for the
+	// decimals of the tokens.
+	scale float64
+}
+
+// decimalsScale returns the factor that converts a price in base units of the
+// quote token per base unit of the token to one in whole units.
+func decimalsScale(tokenDecimals int, quoteDecimals int) float64 {
+	return math.Pow10(tokenDecimals - quoteDecimals)
+}
+
+// referenceSwapPair returns the pair of the token and the quote token that was
+// created by the reference swap factory, or nil if there is no such pair or
+// the decimals of the tokens are not known.
+func (c *StorageClient) referenceSwapPair(ctx context.Context, runtime common.Runtime, rs config.ReferenceSwap, token apiTypes.Address, quote apiTypes.Address) (*priceSwapPair, error) {
+	var pair priceSwapPair
+	var tokenDecimals, quoteDecimals *int
+	switch err := c.db.QueryRow(
+		ctx,
+		queries.EvmTokenReferenceSwapPair,
+		runtime,
+		token,
+		rs.FactoryAddr,
+		quote,
+		rs.Fee,
+	).Scan(&pair.addr, &pair.isToken0, &tokenDecimals, &quoteDecimals); err {
+	case nil:
+	case storage.ErrNoRows:
+		return nil, nil
+	default:
+		return nil, wrapError(err)
+	}
+	if tokenDecimals == nil || quoteDecimals == nil {
+		return nil, nil
+	}
+	pair.scale = decimalsScale(*tokenDecimals, *quoteDecimals)
+	return &pair, nil
+}
+
+// RuntimeTokenPriceHistory returns the price candles of a token, in units of
+// the reference token of the runtime or of its USD stablecoin.
+func (c *StorageClient) RuntimeTokenPriceHistory(ctx context.Context, p apiTypes.GetRuntimeEvmTokensAddressPriceHistoryParams, address staking.Address) (*EvmTokenPriceHistory, error) {
+	runtime := runtimeFromCtx(ctx)
+	interval := "1d"
+	if p.Interval != nil {
+		interval = string(*p.Interval)
+	}
+	quoteUSD := p.Quote != nil && *p.Quote == apiTypes.GetRuntimeEvmTokensAddressPriceHistoryParamsQuoteUsd
+	h := EvmTokenPriceHistory{
+		Interval: interval,
+		Candles:  []EvmTokenPriceCandle{},
+	}
+	rs, ok := c.referenceSwaps[runtime]
+	if quoteUSD && (!ok || rs.USDTokenAddr == "") {
+		return nil, fmt.Errorf("%w: runtime %s has no USD stablecoin", apiCommon.ErrBadRequest, runtime)
+	}
+	if !ok {
+		return &h, nil
+	}
+	token := apiTypes.Address(address.String())
+
+	// The prices come from the pair of the token and the reference token and,
+	// for prices in USD, are converted with the pair of the reference token and
+	// the stablecoin. The reference token is priced in USD by the latter alone.
+	var pair, conversion *priceSwapPair
+	var err error
+	switch {
+	case !quoteUSD:
+		h.RelativeTokenContractAddr = &rs.ReferenceTokenAddr
+		if token == rs.ReferenceTokenAddr {
+			// The reference token has no price relative to itself.
+			return &h, nil
+		}
+		if pair, err = c.referenceSwapPair(ctx, runtime, rs, token, rs.ReferenceTokenAddr); err != nil {
+			return nil, err
+		}
+	case token == rs.ReferenceTokenAddr:
+		h.RelativeTokenContractAddr = &rs.USDTokenAddr
+		if pair, err = c.referenceSwapPair(ctx, runtime, rs, token, rs.USDTokenAddr); err != nil {
+			return nil, err
+		}
+	default:
+		h.RelativeTokenContractAddr = &rs.USDTokenAddr
+		if pair, err = c.referenceSwapPair(ctx, runtime, rs, token, rs.ReferenceTokenAddr); err != nil {
+			return nil, err
+		}
+		if conversion, err = c.referenceSwapPair(ctx, runtime, rs, rs.ReferenceTokenAddr, rs.USDTokenAddr); err != nil {
+			return nil, err
+		}
+		if conversion == nil {
+			return &h, nil
+		}
+	}
+	if pair == nil {
+		return &h, nil
+	}
+	h.SwapPairAddr = &pair.addr
+
+	var conversionAddr *apiTypes.Address
+	var conversionIsToken0 bool
+	conversionScale := 1.0
+	if conversion != nil {
+		conversionAddr = &conversion.addr
+		conversionIsToken0 = conversion.isToken0
+		conversionScale = conversion.scale
+	}
+	res, err := c.withTotalCount(
+		ctx,
+		queries.EvmTokenPriceHistory,
+		runtime,
+		pair.addr,
+		pair.isToken0,
+		interval,
+		p.After,
+		p.Before,
+		pair.scale,
+		conversionAddr,
+		conversionIsToken0,
+		conversionScale,
+		p.Limit,
+		p.Offset,
+	)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer res.rows.Close()
+
+	h.TotalCount = res.totalCount
+	h.IsTotalCountClipped = res.isTotalCountClipped
+	for res.rows.Next() {
+		var candle EvmTokenPriceCandle
+		if err = res.rows.Scan(
+			&candle.WindowStart,
+			&candle.Open,
+			&candle.High,
+			&candle.Low,
+			&candle.Close,
+			&candle.Volume,
+		); err != nil {
+			return nil, wrapError(err)
+		}
+		h.Candles = append(h.Candles, candle)
+	}
+
+	return &h, nil
+}
+
+func (c *StorageClient) RuntimeEVMNFTs(ctx context.Context, limit *uint64, offset *uint64, cursor *string, tokenAddress *staking.Address, id *common.BigInt, ownerAddress *staking.Address) (*EvmNftList, error) {
+	var cursorTokenAddress, cursorID *string
+	if err := decodeCursor(cursor, &cursorTokenAddress, &cursorID); err != nil {
+		return nil, err
+	}
 	res, err := c.withTotalCount(
 		ctx,
 		queries.EvmNfts,
@@ -2128,6 +3598,8 @@ func (c *StorageClient) RuntimeEVMNFTs(ctx context.Context, limit *uint64, offse
 		tokenAddress,
 		id,
 		ownerAddress,
+		cursorTokenAddress,
+		cursorID,
 		limit,
 		offset,
 	)
@@ -2167,6 +3639,7 @@ func (c *StorageClient) RuntimeEVMNFTs(ctx context.Context, limit *uint64, offse
 			&nft.Token.VerificationLevel,
 			&nft.Id,
 			&nft.Owner,
+			&nft.Balance,
 			&ownerAddrContextIdentifier,
 			&ownerAddrContextVersion,
 			&ownerAddrData,
@@ -2203,6 +3676,10 @@ func (c *StorageClient) RuntimeEVMNFTs(ctx context.Context, limit *uint64, offse
 		}
 		nfts.EvmNfts = append(nfts.EvmNfts, nft)
 	}
+	if isFullPage(len(nfts.EvmNfts), limit) {
+		last := nfts.EvmNfts[len(nfts.EvmNfts)-1]
+		nfts.NextCursor = encodeCursor(last.Token.ContractAddr, last.Id.String())
+	}
 
 	return &nfts, nil
 }
@@ -2227,7 +3704,7 @@ func (c *StorageClient) RuntimeStatus(ctx context.Context) (*RuntimeStatus, erro
 	).Scan(&s.LatestBlock, &latest_block_update)
 	switch err {
 	case nil:
-	case pgx.ErrNoRows:
+	case storage.ErrNoRows:
 		// No runtime blocks indexed yet.
 		s.LatestBlock = -1
 	default:
@@ -2309,6 +3786,199 @@ func (c *StorageClient) TxVolumes(ctx context.Context, layer apiTypes.Layer, p a
 	return &ts, nil
 }
 
+// AddressLabels returns a list of labels of known addresses.
+func (c *StorageClient) AddressLabels(ctx context.Context, layer apiTypes.Layer, p apiTypes.GetLayerLabelsParams) (*AddressLabelList, error) {
+	var pattern *string
+	if p.Q != nil {
+		pattern = common.Ptr("%" + escapeLikePattern(*p.Q) + "%")
+	}
+	res, err := c.withTotalCount(
+		ctx,
+		queries.AddressLabels,
+		translateLayer(layer),
+		pattern,
+		p.Limit,
+		p.Offset,
+	)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer res.rows.Close()
+
+	ls := AddressLabelList{
+		Labels:              []AddressLabel{},
+		TotalCount:          res.totalCount,
+		IsTotalCountClipped: res.isTotalCountClipped,
+	}
+	for res.rows.Next() {
+		var l AddressLabel
+		var description string
+		if err := res.rows.Scan(
+			&l.Address,
+			&l.Name,
+			&description,
+		); err != nil {
+			return nil, wrapError(err)
+		}
+		if description != "" {
+			l.Description = &description
+		}
+		ls.Labels = append(ls.Labels, l)
+	}
+
+	return &ls, nil
+}
+
+// EncryptionStats returns statistics on encrypted EVM calls in a runtime.
+// There are no such calls in the consensus layer.
+func (c *StorageClient) EncryptionStats(ctx context.Context, layer apiTypes.Layer) (*EncryptionStats, error) {
+	stats := EncryptionStats{
+		CallErrors:         []EncryptedCallError{},
+		DecryptionFailures: []EncryptedCallError{},
+		KeyEpochs:          []EncryptionKeyEpoch{},
+	}
+	if layer == apiTypes.LayerConsensus {
+		return &stats, nil
+	}
+
+	if err := c.db.QueryRow(
+		ctx,
+		queries.EncryptionCallCounts,
+		translateLayer(layer),
+	).Scan(
+		&stats.NumEncryptedCalls,
+		&stats.NumPlainCalls,
+	); err != nil {
+		return nil, wrapError(err)
+	}
+
+	errorRows, err := c.db.Query(ctx, queries.EncryptedCallErrors, translateLayer(layer))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer errorRows.Close()
+	for errorRows.Next() {
+		var e EncryptedCallError
+		var decryptionFailure bool
+		if err := errorRows.Scan(
+			&e.Module,
+			&e.Code,
+			&decryptionFailure,
+			&e.NumCalls,
+			&e.LastRound,
+		); err != nil {
+			return nil, wrapError(err)
+		}
+		stats.CallErrors = append(stats.CallErrors, e)
+		if decryptionFailure {
+			stats.DecryptionFailures = append(stats.DecryptionFailures, e)
+		}
+	}
+
+	epochRows, err := c.db.Query(ctx, queries.EncryptionKeyEpochs, translateLayer(layer))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer epochRows.Close()
+	for epochRows.Next() {
+		var e EncryptionKeyEpoch
+		if err := epochRows.Scan(
+			&e.Epoch,
+			&e.NumCalls,
+			&e.FirstRound,
+			&e.LastRound,
+		); err != nil {
+			return nil, wrapError(err)
+		}
+		stats.KeyEpochs = append(stats.KeyEpochs, e)
+	}
+
+	return &stats, nil
+}
+
+// EncryptionRounds returns the number of encrypted and plain EVM calls per round.
+func (c *StorageClient) EncryptionRounds(ctx context.Context, layer apiTypes.Layer, p apiTypes.GetLayerStatsEncryptionRoundsParams) (*EncryptionRoundStatsList, error) {
+	rs := EncryptionRoundStatsList{
+		Rounds: []EncryptionRoundStats{},
+	}
+	if layer == apiTypes.LayerConsensus {
+		return &rs, nil
+	}
+
+	res, err := c.withTotalCount(
+		ctx,
+		queries.EncryptionRounds,
+		translateLayer(layer),
+		p.Limit,
+		p.Offset,
+	)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer res.rows.Close()
+
+	rs.TotalCount = res.totalCount
+	rs.IsTotalCountClipped = res.isTotalCountClipped
+	for res.rows.Next() {
+		var r EncryptionRoundStats
+		if err := res.rows.Scan(
+			&r.Round,
+			&r.Timestamp,
+			&r.NumEncryptedCalls,
+			&r.NumPlainCalls,
+		); err != nil {
+			return nil, wrapError(err)
+		}
+		r.Timestamp = r.Timestamp.UTC()
+		rs.Rounds = append(rs.Rounds, r)
+	}
+
+	return &rs, nil
+}
+
+// EncryptionContracts returns the number of encrypted and plain EVM calls per contract.
+func (c *StorageClient) EncryptionContracts(ctx context.Context, layer apiTypes.Layer, p apiTypes.GetLayerStatsEncryptionContractsParams) (*EncryptionContractStatsList, error) {
+	cs := EncryptionContractStatsList{
+		Contracts: []EncryptionContractStats{},
+	}
+	if layer == apiTypes.LayerConsensus {
+		return &cs, nil
+	}
+
+	res, err := c.withTotalCount(
+		ctx,
+		queries.EncryptionContracts,
+		translateLayer(layer),
+		p.Limit,
+		p.Offset,
+	)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer res.rows.Close()
+
+	cs.TotalCount = res.totalCount
+	cs.IsTotalCountClipped = res.isTotalCountClipped
+	for res.rows.Next() {
+		var s EncryptionContractStats
+		var contractEthAddr []byte
+		if err := res.rows.Scan(
+			&s.ContractAddress,
+			&contractEthAddr,
+			&s.NumEncryptedCalls,
+			&s.NumPlainCalls,
+			&s.NumFailedEncryptedCalls,
+			&s.LastRound,
+		); err != nil {
+			return nil, wrapError(err)
+		}
+		s.ContractAddressEth = EthChecksumAddrPtrFromBarePreimage(contractEthAddr)
+		cs.Contracts = append(cs.Contracts, s)
+	}
+
+	return &cs, nil
+}
+
 // DailyActiveAccounts returns a list of daily active accounts.
 func (c *StorageClient) DailyActiveAccounts(ctx context.Context, layer apiTypes.Layer, p apiTypes.GetLayerStatsActiveAccountsParams) (*DailyActiveAccountsList, error) {
 	var query string
@@ -0,0 +1,27 @@
+package client_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	analyzerCmd "github.com/oasisprotocol/nexus/cmd/analyzer"
+	"github.com/oasisprotocol/nexus/storage/postgres"
+	pgTestUtil "github.com/oasisprotocol/nexus/storage/postgres/testutil"
+)
+
+// Relative path to the migrations directory when running tests in this package.
+const migrationsPath = "file://../../storage/migrations"
+
+// setupDB returns a client of an empty, fully migrated test database.
+func setupDB(t *testing.T) *postgres.Client {
+	ctx := context.Background()
+
+	testDB := pgTestUtil.NewTestClient(t)
+	require.NoError(t, testDB.Wipe(ctx), "testDb.Wipe")
+	require.NoError(t, analyzerCmd.RunMigrations(migrationsPath, os.Getenv("CI_TEST_CONN_STRING")), "failed to run migrations")
+
+	return testDB
+}
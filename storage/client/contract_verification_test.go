@@ -0,0 +1,62 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apiCommon "github.com/oasisprotocol/nexus/api"
+	apiTypes "github.com/oasisprotocol/nexus/api/v1/types"
+	"github.com/oasisprotocol/nexus/common"
+	"github.com/oasisprotocol/nexus/config"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage/client"
+	"github.com/oasisprotocol/nexus/tests"
+)
+
+// TestSubmitRuntimeEvmContractVerification tests that identical pending
+// submissions are rejected, and that pending submissions are capped per contract.
+func TestSubmitRuntimeEvmContractVerification(t *testing.T) {
+	tests.SkipIfShort(t)
+	ctx := context.WithValue(context.Background(), common.RuntimeContextKey, common.RuntimeSapphire)
+	db := setupDB(t)
+
+	c, err := client.NewStorageClient(config.SourceConfig{}, db, nil, nil, nil, log.NewDefaultLogger("testing"))
+	require.NoError(t, err)
+
+	contract := parseAddress(t, testAddress("contract"))
+	other := parseAddress(t, testAddress("other"))
+	submission := func(version int) apiTypes.EvmContractVerificationSubmission {
+		return apiTypes.EvmContractVerificationSubmission{
+			CompilerVersion: "0.8.24",
+			ContractName:    "Counter",
+			Sources: map[string]string{
+				"Counter.sol": fmt.Sprintf("contract Counter { uint public v = %d; }", version),
+			},
+		}
+	}
+
+	first, err := c.SubmitRuntimeEvmContractVerification(ctx, submission(0), &contract)
+	require.NoError(t, err)
+	require.Equal(t, apiTypes.EvmContractVerificationStatus("pending"), first.Status)
+
+	// An identical submission is rejected while the first one is pending.
+	_, err = c.SubmitRuntimeEvmContractVerification(ctx, submission(0), &contract)
+	require.ErrorIs(t, err, apiCommon.ErrBadRequest)
+	require.Contains(t, err.Error(), "already pending")
+
+	// ... but not for another contract.
+	_, err = c.SubmitRuntimeEvmContractVerification(ctx, submission(0), &other)
+	require.NoError(t, err)
+
+	// Different submissions are accepted up to the cap.
+	for i := 1; i < 5; i++ {
+		_, err = c.SubmitRuntimeEvmContractVerification(ctx, submission(i), &contract)
+		require.NoError(t, err, i)
+	}
+	_, err = c.SubmitRuntimeEvmContractVerification(ctx, submission(5), &contract)
+	require.ErrorIs(t, err, apiCommon.ErrBadRequest)
+	require.Contains(t, err.Error(), "pending verification requests")
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	apiTypes "github.com/oasisprotocol/nexus/api/v1/types"
+)
+
+// Cursors implement keyset pagination for list endpoints. A cursor encodes
+// the sort key of the last item of a page; the next page consists of the
+// items that sort after that key. Unlike offsets, cursors keep pointing at
+// the same position in the list when new items are indexed, and they can be
+// looked up efficiently via the indexes that the lists are sorted by.
+//
+// Cursors are base64url-encoded JSON arrays of the sort key, but clients
+// should treat them as opaque.
+
+// encodeCursor returns a cursor for the given sort key.
+func encodeCursor(key ...interface{}) *string {
+	// The key consists of numbers and strings, which always marshal successfully.
+	raw, _ := json.Marshal(key)
+	cursor := base64.RawURLEncoding.EncodeToString(raw)
+	return &cursor
+}
+
+// decodeCursor parses the cursor into key, which must consist of pointers to
+// nil pointers, e.g. **int64. If cursor is nil, key is left untouched (i.e. nil),
+// which the list queries interpret as "start from the beginning".
+func decodeCursor(cursor *string, key ...interface{}) error {
+	if cursor == nil {
+		return nil
+	}
+	invalid := func(err error) error {
+		return &apiTypes.InvalidParamFormatError{ParamName: "cursor", Err: err}
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(*cursor)
+	if err != nil {
+		return invalid(err)
+	}
+	var parts []json.RawMessage
+	if err = json.Unmarshal(raw, &parts); err != nil {
+		return invalid(err)
+	}
+	if len(parts) != len(key) {
+		return invalid(fmt.Errorf("expected %d key components, got %d", len(key), len(parts)))
+	}
+	for i, part := range parts {
+		if string(part) == "null" {
+			return invalid(fmt.Errorf("key component %d is null", i))
+		}
+		if err = json.Unmarshal(part, key[i]); err != nil {
+			return invalid(err)
+		}
+	}
+	return nil
+}
+
+// isFullPage returns whether a page with numItems items is as long as
+// the requested limit, i.e. whether there might be more items after it.
+func isFullPage(numItems int, limit *uint64) bool {
+	return limit != nil && numItems > 0 && uint64(numItems) >= *limit
+}
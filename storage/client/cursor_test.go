@@ -0,0 +1,48 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/nexus/common"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := encodeCursor(int64(8048956), int32(3), "emerald")
+
+	var height *int64
+	var index *int32
+	var runtime *string
+	require.NoError(t, decodeCursor(cursor, &height, &index, &runtime))
+	require.Equal(t, int64(8048956), *height)
+	require.Equal(t, int32(3), *index)
+	require.Equal(t, "emerald", *runtime)
+}
+
+func TestCursorNil(t *testing.T) {
+	var height *int64
+	require.NoError(t, decodeCursor(nil, &height))
+	require.Nil(t, height)
+}
+
+func TestCursorInvalid(t *testing.T) {
+	var height *int64
+	var index *int32
+	for _, cursor := range []*string{
+		common.Ptr("not base64!"),
+		common.Ptr("bm90IGpzb24"),      // "not json"
+		encodeCursor(int64(1)),         // too few components
+		encodeCursor(int64(1), nil),    // null component
+		encodeCursor(int64(1), "text"), // wrong type
+	} {
+		require.Error(t, decodeCursor(cursor, &height, &index), "cursor %q", *cursor)
+	}
+}
+
+func TestIsFullPage(t *testing.T) {
+	require.True(t, isFullPage(10, common.Ptr(uint64(10))))
+	require.False(t, isFullPage(9, common.Ptr(uint64(10))))
+	require.False(t, isFullPage(0, common.Ptr(uint64(0))))
+	require.False(t, isFullPage(10, nil))
+}
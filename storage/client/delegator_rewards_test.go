@@ -0,0 +1,117 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	coreStaking "github.com/oasisprotocol/oasis-core/go/staking/api"
+
+	"github.com/oasisprotocol/nexus/analyzer/queries"
+	apiTypes "github.com/oasisprotocol/nexus/api/v1/types"
+	"github.com/oasisprotocol/nexus/config"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage"
+	"github.com/oasisprotocol/nexus/storage/client"
+	"github.com/oasisprotocol/nexus/tests"
+)
+
+// TestDelegatorRewards tests the attribution of a validator's staking rewards to
+// its delegators, and the APR of the validator, from the evolution of its share price.
+func TestDelegatorRewards(t *testing.T) {
+	tests.SkipIfShort(t)
+	ctx := context.Background()
+	db := setupDB(t)
+
+	var validatorID signature.PublicKey
+	validatorID[0] = 1
+	validator := coreStaking.NewAddress(validatorID)
+	delegator1 := parseAddress(t, testAddress("delegator1"))
+	delegator2 := parseAddress(t, testAddress("delegator2"))
+	epochStart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	batch := &storage.QueryBatch{}
+	batch.Queue(`INSERT INTO chain.entities (id, address) VALUES ($1, $2)`, validatorID.String(), validator.String())
+	// Epochs of an hour each. The share price of the validator is 1 at the start of epoch 1,
+	// 1.1 after the rewards of epoch 1, and 0.99 after a slash in epoch 2.
+	for epoch, activeBalance := range map[int64]int64{1: 1000, 2: 1100, 3: 990, 4: 990} {
+		batch.Queue(`INSERT INTO chain.epochs (id, start_height, end_height) VALUES ($1, $1, $1)`, epoch)
+		batch.Queue(`
+    INSERT INTO chain.blocks (height, block_hash, time, num_txs, epoch, namespace, version, state_root)
+      VALUES ($1, $2, $3, 0, $1, '', 0, $2)`,
+			epoch, fmt.Sprintf("%064x", epoch), epochStart.Add(time.Duration(epoch)*time.Hour))
+		batch.Queue(`
+    INSERT INTO history.validators (id, epoch, escrow_balance_active, escrow_balance_debonding, escrow_total_shares_active, escrow_total_shares_debonding)
+      VALUES ($1, $2, $3, 0, 1000, 0)`,
+			validatorID.String(), epoch, activeBalance)
+	}
+	for _, delegations := range []struct {
+		epoch      uint64
+		delegators []string
+		shares     []string
+	}{
+		// Rewards of 300 * (1.1 - 1) = 30 in epoch 1; 7 shares are truncated to 7 before
+		// and after the rewards, so they are not stored.
+		{2, []string{delegator1.String(), delegator2.String()}, []string{"300", "7"}},
+		{2, []string{delegator1.String()}, []string{"400"}}, // Reprocessed with more shares: 400 * (1.1 - 1) = 40.
+		{3, []string{delegator1.String()}, []string{"300"}}, // Slashed by 300 * (0.99 - 1.1) = -33 in epoch 2.
+		{4, []string{delegator1.String()}, []string{"300"}}, // No rewards in epoch 3; not stored.
+		{5, []string{delegator1.String()}, []string{"300"}}, // Epoch 5 has no validator history yet; not stored.
+	} {
+		batch.Queue(queries.DelegatorRewardsInsert,
+			validator.String(),
+			validatorID.String(),
+			delegations.epoch,
+			delegations.delegators,
+			delegations.shares,
+		)
+	}
+	require.NoError(t, db.SendBatch(ctx, batch))
+
+	c, err := client.NewStorageClient(config.SourceConfig{}, db, nil, nil, nil, log.NewDefaultLogger("testing"))
+	require.NoError(t, err)
+	limit, offset := uint64(10), uint64(0)
+
+	rewards, err := c.DelegatorRewards(ctx, delegator1, apiTypes.GetConsensusAccountsAddressRewardsParams{Limit: &limit, Offset: &offset})
+	require.NoError(t, err)
+	require.Len(t, rewards.Rewards, 2)
+	for i, expected := range []struct {
+		epoch  int64
+		shares string
+		amount string
+	}{
+		{2, "300", "-33"},
+		{1, "400", "40"},
+	} {
+		require.Equal(t, expected.epoch, rewards.Rewards[i].Epoch)
+		require.Equal(t, validator.String(), rewards.Rewards[i].Validator)
+		require.Equal(t, expected.shares, rewards.Rewards[i].Shares.String())
+		require.Equal(t, expected.amount, rewards.Rewards[i].Amount.String())
+	}
+
+	rewards, err = c.DelegatorRewards(ctx, delegator2, apiTypes.GetConsensusAccountsAddressRewardsParams{Limit: &limit, Offset: &offset})
+	require.NoError(t, err)
+	require.Empty(t, rewards.Rewards)
+
+	// The APR annualises the change of the share price over the duration of the epoch:
+	// 10% in an hour is 0.1 * 8766 hours per Julian year.
+	history, err := c.ValidatorHistory(ctx, validator, apiTypes.GetConsensusValidatorsAddressHistoryParams{Limit: &limit, Offset: &offset})
+	require.NoError(t, err)
+	require.Len(t, history.History, 4)
+	aprs := map[int64]*float64{}
+	for _, h := range history.History {
+		aprs[h.Epoch] = h.Apr
+	}
+	require.NotNil(t, aprs[1])
+	require.InDelta(t, 876.6, *aprs[1], 1e-9)
+	require.NotNil(t, aprs[2])
+	require.InDelta(t, -876.6, *aprs[2], 1e-9)
+	require.NotNil(t, aprs[3])
+	require.InDelta(t, 0, *aprs[3], 1e-9)
+	// The latest epoch is not over yet.
+	require.Nil(t, aprs[4])
+}
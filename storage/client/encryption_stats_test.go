@@ -0,0 +1,122 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	apiTypes "github.com/oasisprotocol/nexus/api/v1/types"
+	"github.com/oasisprotocol/nexus/common"
+	"github.com/oasisprotocol/nexus/config"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage"
+	"github.com/oasisprotocol/nexus/storage/client"
+	"github.com/oasisprotocol/nexus/tests"
+)
+
+// TestEncryptionStats tests the statistics on encrypted calls, in either
+// envelope, as refreshed by the runtime_encryption_stats analyzer.
+func TestEncryptionStats(t *testing.T) {
+	tests.SkipIfShort(t)
+	ctx := context.Background()
+	db := setupDB(t)
+
+	contract := testAddress("contract")
+	timestamp := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	const envelope = "encrypted/x25519-deoxysii"
+
+	batch := &storage.QueryBatch{}
+	for round := uint64(1); round <= 2; round++ {
+		batch.Queue(`
+    INSERT INTO chain.runtime_blocks (runtime, round, version, timestamp, block_hash, prev_block_hash, io_root, state_root, messages_hash, in_messages_hash, num_transactions, gas_used, size)
+      VALUES ($1, $2, 0, $3, $4, $4, $4, $4, $4, $4, 0, 0, 0)`,
+			common.RuntimeSapphire, round, timestamp.Add(time.Duration(round)*time.Minute), fmt.Sprintf("%064x", round))
+	}
+	for i, tx := range []struct {
+		round       uint64
+		method      *string
+		evmFormat   *string
+		evmEpoch    *uint64
+		oasisFormat *string
+		oasisEpoch  *uint64
+		success     bool
+		errModule   *string
+		errCode     *uint64
+	}{
+		// Round 1: a plain call, a call encrypted with the long-term key, and one that reverted.
+		{round: 1, method: common.Ptr("evm.Call"), success: true},
+		{round: 1, method: common.Ptr("evm.Call"), evmFormat: common.Ptr(envelope), evmEpoch: common.Ptr(uint64(0)), success: true},
+		{round: 1, method: common.Ptr("evm.Call"), evmFormat: common.Ptr(envelope), evmEpoch: common.Ptr(uint64(7)), errModule: common.Ptr("evm"), errCode: common.Ptr(uint64(8))},
+		// Round 2: a transaction in an Oasis envelope, which hides the method and contract,
+		// and another one that the runtime failed to decrypt.
+		{round: 2, oasisFormat: common.Ptr(envelope), oasisEpoch: common.Ptr(uint64(7)), success: true},
+		{round: 2, oasisFormat: common.Ptr(envelope), oasisEpoch: common.Ptr(uint64(7)), errModule: common.Ptr("core"), errCode: common.Ptr(uint64(17))},
+	} {
+		var to *apiTypes.Address
+		if tx.method != nil {
+			to = &contract
+		}
+		batch.Queue(`
+    INSERT INTO chain.runtime_transactions (runtime, round, tx_index, timestamp, tx_hash, fee, gas_limit, gas_used, size, method, "to", evm_encrypted_format, evm_encrypted_epoch, oasis_encrypted_format, oasis_encrypted_epoch, success, error_module, error_code)
+      VALUES ($1, $2, $3, $4, $5, 0, 0, 0, 0, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+			common.RuntimeSapphire, tx.round, i, timestamp, fmt.Sprintf("%064x", 100+i),
+			tx.method, to, tx.evmFormat, tx.evmEpoch, tx.oasisFormat, tx.oasisEpoch, tx.success, tx.errModule, tx.errCode)
+	}
+	require.NoError(t, db.SendBatch(ctx, batch))
+
+	batch = &storage.QueryBatch{}
+	for _, view := range []string{
+		"views.runtime_encryption_contracts",
+		"views.runtime_encrypted_calls",
+		"views.runtime_encrypted_call_errors",
+		"views.runtime_encryption_key_epochs",
+	} {
+		batch.Queue("REFRESH MATERIALIZED VIEW CONCURRENTLY " + view)
+	}
+	require.NoError(t, db.SendBatch(ctx, batch))
+
+	c, err := client.NewStorageClient(config.SourceConfig{}, db, nil, nil, nil, log.NewDefaultLogger("testing"))
+	require.NoError(t, err)
+	layer := apiTypes.Layer(common.RuntimeSapphire)
+
+	stats, err := c.EncryptionStats(ctx, layer)
+	require.NoError(t, err)
+	require.EqualValues(t, 4, stats.NumEncryptedCalls)
+	require.EqualValues(t, 1, stats.NumPlainCalls)
+	// All errors of failed encrypted calls, not only failures to decrypt them.
+	require.Equal(t, []client.EncryptedCallError{
+		{Module: "core", Code: 17, NumCalls: 1, LastRound: 2},
+		{Module: "evm", Code: 8, NumCalls: 1, LastRound: 1},
+	}, stats.CallErrors)
+	// Only the call that the runtime failed to decrypt.
+	require.Equal(t, []client.EncryptedCallError{
+		{Module: "core", Code: 17, NumCalls: 1, LastRound: 2},
+	}, stats.DecryptionFailures)
+	require.Equal(t, []client.EncryptionKeyEpoch{
+		{Epoch: 7, NumCalls: 3, FirstRound: 1, LastRound: 2},
+		{Epoch: 0, NumCalls: 1, FirstRound: 1, LastRound: 1},
+	}, stats.KeyEpochs)
+
+	limit, offset := uint64(10), uint64(0)
+	rounds, err := c.EncryptionRounds(ctx, layer, apiTypes.GetLayerStatsEncryptionRoundsParams{Limit: &limit, Offset: &offset})
+	require.NoError(t, err)
+	require.Len(t, rounds.Rounds, 2)
+	require.EqualValues(t, 2, rounds.Rounds[0].Round)
+	require.EqualValues(t, 2, rounds.Rounds[0].NumEncryptedCalls)
+	require.EqualValues(t, 0, rounds.Rounds[0].NumPlainCalls)
+	require.EqualValues(t, 1, rounds.Rounds[1].Round)
+	require.EqualValues(t, 2, rounds.Rounds[1].NumEncryptedCalls)
+	require.EqualValues(t, 1, rounds.Rounds[1].NumPlainCalls)
+
+	// Only calls in an EVM envelope are attributed to the contract.
+	contracts, err := c.EncryptionContracts(ctx, layer, apiTypes.GetLayerStatsEncryptionContractsParams{Limit: &limit, Offset: &offset})
+	require.NoError(t, err)
+	require.Len(t, contracts.Contracts, 1)
+	require.Equal(t, contract, contracts.Contracts[0].ContractAddress)
+	require.EqualValues(t, 2, contracts.Contracts[0].NumEncryptedCalls)
+	require.EqualValues(t, 1, contracts.Contracts[0].NumPlainCalls)
+	require.EqualValues(t, 1, contracts.Contracts[0].NumFailedEncryptedCalls)
+}
@@ -0,0 +1,110 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	coreCommon "github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+
+	"github.com/oasisprotocol/nexus/analyzer/queries"
+	apiTypes "github.com/oasisprotocol/nexus/api/v1/types"
+	"github.com/oasisprotocol/nexus/common"
+	"github.com/oasisprotocol/nexus/config"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage"
+	"github.com/oasisprotocol/nexus/storage/client"
+	"github.com/oasisprotocol/nexus/tests"
+)
+
+// TestKeyManagerHistory tests that the statuses of key managers and their CHURP instances
+// are only stored when they change, including the fields that are NULL before Eden.
+func TestKeyManagerHistory(t *testing.T) {
+	tests.SkipIfShort(t)
+	ctx := context.Background()
+	db := setupDB(t)
+
+	var runtimeID coreCommon.Namespace
+	runtimeID[31] = 1
+	var node1, node2, rsk signature.PublicKey
+	node1[0], node2[0], rsk[0] = 1, 2, 3
+	policy := []byte(`{"serial":1}`)
+
+	queueStatus := func(batch *storage.QueryBatch, epoch uint64, generation, rotationEpoch *uint64, nodes []string, rsk *string) {
+		batch.Queue(queries.KeyManagerStatusInsert,
+			runtimeID.Hex(), epoch, int64(epoch*10), true, true, generation, rotationEpoch, "ab", nodes, rsk, policy)
+	}
+	queueChurpStatus := func(batch *storage.QueryBatch, epoch uint64, handoff, nextHandoff *uint64) {
+		batch.Queue(queries.KeyManagerChurpStatusInsert,
+			runtimeID.Hex(), uint8(1), epoch, int64(epoch*10), uint8(0), uint8(1), uint8(0), uint64(5), handoff, nextHandoff, nil, nil, []string{node1.String()}, []byte(nil))
+	}
+
+	// Each query is sent in its own batch, like the analyzer processes one epoch at a time.
+	for _, queue := range []func(*storage.QueryBatch){
+		// Before Eden, the generation, rotation epoch and RSK are NULL.
+		func(b *storage.QueryBatch) { queueStatus(b, 1, nil, nil, []string{node1.String()}, nil) },
+		func(b *storage.QueryBatch) { queueStatus(b, 2, nil, nil, []string{node1.String()}, nil) },
+		// The nodes changed.
+		func(b *storage.QueryBatch) {
+			queueStatus(b, 3, nil, nil, []string{node1.String(), node2.String()}, nil)
+		},
+		// The upgrade to Eden reveals the generation, rotation epoch and RSK.
+		func(b *storage.QueryBatch) {
+			queueStatus(b, 4, common.Ptr(uint64(0)), common.Ptr(uint64(4)), []string{node1.String(), node2.String()}, common.Ptr(rsk.String()))
+		},
+		func(b *storage.QueryBatch) {
+			queueStatus(b, 5, common.Ptr(uint64(0)), common.Ptr(uint64(4)), []string{node1.String(), node2.String()}, common.Ptr(rsk.String()))
+		},
+		// Reprocessing an epoch does not change its status.
+		func(b *storage.QueryBatch) { queueStatus(b, 4, nil, nil, []string{node2.String()}, nil) },
+
+		// No handoff is scheduled yet.
+		func(b *storage.QueryBatch) { queueChurpStatus(b, 4, common.Ptr(uint64(0)), nil) },
+		func(b *storage.QueryBatch) { queueChurpStatus(b, 5, common.Ptr(uint64(0)), nil) },
+		func(b *storage.QueryBatch) { queueChurpStatus(b, 6, common.Ptr(uint64(0)), common.Ptr(uint64(10))) },
+		func(b *storage.QueryBatch) { queueChurpStatus(b, 7, common.Ptr(uint64(0)), common.Ptr(uint64(10))) },
+	} {
+		batch := &storage.QueryBatch{}
+		queue(batch)
+		require.NoError(t, db.SendBatch(ctx, batch))
+	}
+
+	c, err := client.NewStorageClient(config.SourceConfig{}, db, nil, nil, nil, log.NewDefaultLogger("testing"))
+	require.NoError(t, err)
+	limit, offset := uint64(10), uint64(0)
+
+	history, err := c.KeyManagerHistory(ctx, runtimeID.Hex(), apiTypes.GetConsensusKeymanagersRuntimeIdHistoryParams{Limit: &limit, Offset: &offset})
+	require.NoError(t, err)
+	require.Len(t, history.History, 3)
+	eden, nodesChanged, first := history.History[0], history.History[1], history.History[2]
+	require.Equal(t, int64(1), first.Epoch)
+	require.Nil(t, first.Generation)
+	require.Nil(t, first.RotationEpoch)
+	require.Nil(t, first.Rsk)
+	require.Equal(t, []string{node1.String()}, first.Nodes)
+	require.Equal(t, &map[string]interface{}{"serial": float64(1)}, first.Policy)
+	require.Equal(t, int64(3), nodesChanged.Epoch)
+	require.Equal(t, []string{node1.String(), node2.String()}, nodesChanged.Nodes)
+	require.Equal(t, int64(4), eden.Epoch)
+	require.Equal(t, int64(40), eden.Height)
+	require.Equal(t, common.Ptr(uint64(0)), eden.Generation)
+	require.Equal(t, common.Ptr(int64(4)), eden.RotationEpoch)
+	require.Equal(t, common.Ptr(rsk.String()), eden.Rsk)
+
+	keyManagers, err := c.KeyManagers(ctx, apiTypes.GetConsensusKeymanagersParams{Limit: &limit, Offset: &offset})
+	require.NoError(t, err)
+	require.Len(t, keyManagers.Keymanagers, 1)
+	km := keyManagers.Keymanagers[0]
+	require.Equal(t, runtimeID.Hex(), km.RuntimeId)
+	require.Equal(t, int64(4), km.Epoch)
+	require.Len(t, km.Churp, 1)
+	churp := km.Churp[0]
+	require.Equal(t, int32(1), churp.ID)
+	require.Equal(t, int64(6), churp.Epoch)
+	require.Equal(t, common.Ptr(int64(0)), churp.Handoff)
+	require.Equal(t, common.Ptr(int64(10)), churp.NextHandoff)
+	require.Nil(t, churp.Checksum)
+	require.Nil(t, churp.Policy)
+}
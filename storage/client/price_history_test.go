@@ -0,0 +1,145 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	coreStaking "github.com/oasisprotocol/oasis-core/go/staking/api"
+
+	"github.com/oasisprotocol/nexus/analyzer/aggregate_stats"
+	analyzerQueries "github.com/oasisprotocol/nexus/analyzer/queries"
+	apiCommon "github.com/oasisprotocol/nexus/api"
+	apiTypes "github.com/oasisprotocol/nexus/api/v1/types"
+	"github.com/oasisprotocol/nexus/common"
+	"github.com/oasisprotocol/nexus/config"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage"
+	"github.com/oasisprotocol/nexus/storage/client"
+	"github.com/oasisprotocol/nexus/tests"
+)
+
+func testAddress(name string) apiTypes.Address {
+	return apiTypes.Address(coreStaking.NewModuleAddress("test", name).String())
+}
+
+func parseAddress(t *testing.T, address apiTypes.Address) coreStaking.Address {
+	var a coreStaking.Address
+	require.NoError(t, a.UnmarshalText([]byte(address)))
+	return a
+}
+
+// TestRuntimeTokenPriceHistory tests the price candles of a token that is
+// swapped in a Uniswap V2 pair with the reference token, from the recorded
+// reserves to the prices in the reference token and in USD.
+func TestRuntimeTokenPriceHistory(t *testing.T) {
+	tests.SkipIfShort(t)
+	ctx := context.WithValue(context.Background(), common.RuntimeContextKey, common.RuntimeSapphire)
+	db := setupDB(t)
+
+	factory := testAddress("factory")
+	wrose := testAddress("wrose") // The reference token, with 18 decimals.
+	usdc := testAddress("usdc")   // The USD stablecoin, with 6 decimals.
+	foo := testAddress("foo")     // The priced token, with 8 decimals.
+	fooPair := testAddress("wrose-foo")
+	usdPair := testAddress("wrose-usdc")
+	windowStart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	batch := &storage.QueryBatch{}
+	for token, decimals := range map[apiTypes.Address]int{wrose: 18, usdc: 6, foo: 8} {
+		batch.Queue(`INSERT INTO chain.evm_tokens (runtime, token_address, decimals) VALUES ($1, $2, $3)`, common.RuntimeSapphire, token, decimals)
+	}
+	for pair, tokens := range map[apiTypes.Address][2]apiTypes.Address{fooPair: {wrose, foo}, usdPair: {wrose, usdc}} {
+		batch.Queue(`INSERT INTO chain.evm_swap_pair_creations (runtime, factory_address, token0_address, token1_address, pair_address, create_round) VALUES ($1, $2, $3, $4, $5, 1)`,
+			common.RuntimeSapphire, factory, tokens[0], tokens[1], pair)
+	}
+	for round := uint64(1); round <= 2; round++ {
+		batch.Queue(`
+    INSERT INTO chain.runtime_blocks (runtime, round, version, timestamp, block_hash, prev_block_hash, io_root, state_root, messages_hash, in_messages_hash, num_transactions, gas_used, size)
+      VALUES ($1, $2, 0, $3, $4, $4, $4, $4, $4, $4, 0, 0, 0)`,
+			common.RuntimeSapphire, round, windowStart.Add(time.Duration(round)*time.Minute), fmt.Sprintf("%064x", round))
+	}
+	bigInt := func(s string) *big.Int {
+		n, ok := new(big.Int).SetString(s, 10)
+		require.True(t, ok)
+		return n
+	}
+	// Round 1: liquidity of 1,000,000 WROSE and 20,000 FOO (50 WROSE per FOO),
+	// and of 1,000,000 WROSE and 50,000 USDC (0.05 USDC per WROSE).
+	// Round 2: 10 FOO are swapped for WROSE, with the 0.3% fee of Uniswap V2.
+	for _, sync := range []struct {
+		round              uint64
+		pair               apiTypes.Address
+		reserve0, reserve1 *big.Int
+		volume0, volume1   *big.Int
+	}{
+		{1, fooPair, bigInt("1000000000000000000000000"), big.NewInt(2_000_000_000_000), big.NewInt(0), big.NewInt(0)},
+		{1, usdPair, bigInt("1000000000000000000000000"), big.NewInt(50_000_000_000), big.NewInt(0), big.NewInt(0)},
+		{2, fooPair, bigInt("999501748378433350974540"), big.NewInt(2_001_000_000_000), bigInt("498251621566649025460"), big.NewInt(1_000_000_000)},
+	} {
+		batch.Queue(analyzerQueries.RuntimeEVMSwapPairSyncInsert,
+			common.RuntimeSapphire, sync.round, sync.pair, sync.reserve0, sync.reserve1, nil, sync.volume0, sync.volume1, windowStart.Add(time.Duration(sync.round)*time.Minute))
+	}
+	batch.Queue(aggregate_stats.QueryInsertMin5SwapCandles, common.RuntimeSapphire, windowStart, windowStart.Add(5*time.Minute))
+	require.NoError(t, db.SendBatch(ctx, batch))
+
+	referenceSwaps := map[common.Runtime]config.ReferenceSwap{
+		common.RuntimeSapphire: {FactoryAddr: factory, ReferenceTokenAddr: wrose, USDTokenAddr: usdc},
+	}
+	c, err := client.NewStorageClient(config.SourceConfig{}, db, referenceSwaps, nil, nil, log.NewDefaultLogger("testing"))
+	require.NoError(t, err)
+	priceHistory := func(token apiTypes.Address, quote apiTypes.GetRuntimeEvmTokensAddressPriceHistoryParamsQuote) *client.EvmTokenPriceHistory {
+		interval := apiTypes.GetRuntimeEvmTokensAddressPriceHistoryParamsIntervalN5m
+		limit := uint64(10)
+		offset := uint64(0)
+		h, err := c.RuntimeTokenPriceHistory(ctx, apiTypes.GetRuntimeEvmTokensAddressPriceHistoryParams{Interval: &interval, Quote: &quote, Limit: &limit, Offset: &offset}, parseAddress(t, token))
+		require.NoError(t, err)
+		return h
+	}
+
+	// FOO in WROSE, adjusted for the decimals and inverted since FOO is token1.
+	h := priceHistory(foo, apiTypes.GetRuntimeEvmTokensAddressPriceHistoryParamsQuoteReference)
+	require.Equal(t, wrose, *h.RelativeTokenContractAddr)
+	require.Equal(t, fooPair, *h.SwapPairAddr)
+	require.Len(t, h.Candles, 1)
+	candle := h.Candles[0]
+	require.True(t, windowStart.Equal(candle.WindowStart))
+	require.InDelta(t, 50, candle.Open, 1e-9)
+	require.InDelta(t, 50, candle.High, 1e-9)
+	require.InDelta(t, 49.9501123627403, candle.Low, 1e-9)
+	require.InDelta(t, 49.9501123627403, candle.Close, 1e-9)
+	require.Equal(t, "1000000000", candle.Volume.String())
+
+	// FOO in USDC, through the price of WROSE in USDC.
+	h = priceHistory(foo, apiTypes.GetRuntimeEvmTokensAddressPriceHistoryParamsQuoteUsd)
+	require.Equal(t, usdc, *h.RelativeTokenContractAddr)
+	require.Equal(t, fooPair, *h.SwapPairAddr)
+	require.Len(t, h.Candles, 1)
+	require.InDelta(t, 2.5, h.Candles[0].Open, 1e-9)
+	require.InDelta(t, 49.9501123627403*0.05, h.Candles[0].Close, 1e-9)
+	require.Equal(t, "1000000000", h.Candles[0].Volume.String())
+
+	// WROSE in USDC.
+	h = priceHistory(wrose, apiTypes.GetRuntimeEvmTokensAddressPriceHistoryParamsQuoteUsd)
+	require.Equal(t, usdPair, *h.SwapPairAddr)
+	require.Len(t, h.Candles, 1)
+	require.InDelta(t, 0.05, h.Candles[0].Close, 1e-9)
+
+	// The reference token has no price relative to itself.
+	h = priceHistory(wrose, apiTypes.GetRuntimeEvmTokensAddressPriceHistoryParamsQuoteReference)
+	require.Nil(t, h.SwapPairAddr)
+	require.Empty(t, h.Candles)
+
+	// Prices in USD need a stablecoin.
+	c, err = client.NewStorageClient(config.SourceConfig{}, db, map[common.Runtime]config.ReferenceSwap{
+		common.RuntimeSapphire: {FactoryAddr: factory, ReferenceTokenAddr: wrose},
+	}, nil, nil, log.NewDefaultLogger("testing"))
+	require.NoError(t, err)
+	quote := apiTypes.GetRuntimeEvmTokensAddressPriceHistoryParamsQuoteUsd
+	_, err = c.RuntimeTokenPriceHistory(ctx, apiTypes.GetRuntimeEvmTokensAddressPriceHistoryParams{Quote: &quote}, parseAddress(t, foo))
+	require.ErrorIs(t, err, apiCommon.ErrBadRequest)
+}
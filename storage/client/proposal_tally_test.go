@@ -0,0 +1,87 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	coreStaking "github.com/oasisprotocol/oasis-core/go/staking/api"
+
+	"github.com/oasisprotocol/nexus/config"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage"
+	"github.com/oasisprotocol/nexus/storage/client"
+	"github.com/oasisprotocol/nexus/tests"
+)
+
+// TestProposalTally tests that the tally of a closed proposal only counts the
+// votes up to the block in which voting closed, in its totals and in the list
+// of validators that did not vote.
+func TestProposalTally(t *testing.T) {
+	tests.SkipIfShort(t)
+	ctx := context.Background()
+	db := setupDB(t)
+
+	var a, b, c signature.PublicKey
+	a[0], b[0], c[0] = 1, 2, 3
+	validators := []signature.PublicKey{a, b, c}
+	escrows := map[signature.PublicKey]int64{a: 50, b: 30, c: 20}
+	blockTime := func(height int64) time.Time {
+		return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(height) * time.Minute)
+	}
+
+	batch := &storage.QueryBatch{}
+	// Epoch 1 has blocks 1-2, and voting closes at epoch 2, in block 3.
+	batch.Queue(`INSERT INTO chain.epochs (id, start_height, end_height, active_validators) VALUES (1, 1, 2, $1), (2, 3, 4, $1)`,
+		[]string{a.String(), b.String(), c.String()})
+	for height := int64(1); height <= 4; height++ {
+		batch.Queue(`
+    INSERT INTO chain.blocks (height, block_hash, time, num_txs, epoch, namespace, version, state_root)
+      VALUES ($1, $2, $3, 0, $4, '', 0, $2)`,
+			height, fmt.Sprintf("%064x", height), blockTime(height), (height+1)/2)
+	}
+	for _, v := range validators {
+		batch.Queue(`INSERT INTO chain.entities (id, address) VALUES ($1, $2)`, v.String(), coreStaking.NewAddress(v).String())
+		for epoch := 1; epoch <= 2; epoch++ {
+			batch.Queue(`
+    INSERT INTO history.validators (id, epoch, escrow_balance_active, escrow_balance_debonding, escrow_total_shares_active, escrow_total_shares_debonding)
+      VALUES ($1, $2, $3, 0, $3, 0)`,
+				v.String(), epoch, escrows[v])
+		}
+	}
+	batch.Queue(`
+    INSERT INTO chain.proposals (id, submitter, deposit, created_at, closes_at, stake_threshold)
+      VALUES (1, $1, 0, 1, 2, 68)`,
+		coreStaking.NewAddress(a).String())
+	batch.Queue(`
+    INSERT INTO chain.votes (proposal, voter, vote, height)
+      VALUES (1, $1, 'yes', 2), (1, $2, 'no', 3), (1, $3, 'yes', 4)`,
+		coreStaking.NewAddress(a).String(), coreStaking.NewAddress(b).String(), coreStaking.NewAddress(c).String())
+	require.NoError(t, db.SendBatch(ctx, batch))
+
+	cl, err := client.NewStorageClient(config.SourceConfig{}, db, nil, nil, nil, log.NewDefaultLogger("testing"))
+	require.NoError(t, err)
+	proposal, err := cl.Proposal(ctx, 1)
+	require.NoError(t, err)
+	tally := proposal.Tally
+	require.NotNil(t, tally)
+
+	require.EqualValues(t, 2, tally.Epoch)
+	require.Equal(t, int64(3), *tally.Height)
+	require.Equal(t, blockTime(3), *tally.Timestamp)
+	// The vote of c came after voting closed.
+	require.Equal(t, "50", tally.Yes.String())
+	require.Equal(t, "30", tally.No.String())
+	require.Equal(t, "100", tally.TotalVotingPower.String())
+	require.EqualValues(t, 2, tally.NumVotingValidators)
+	require.EqualValues(t, 1, tally.NumNonVotingValidators)
+	require.NotNil(t, tally.NonVotingValidators)
+	require.Len(t, *tally.NonVotingValidators, 1)
+	require.Equal(t, coreStaking.NewAddress(c).String(), (*tally.NonVotingValidators)[0].Address)
+	require.Equal(t, "20", (*tally.NonVotingValidators)[0].VotingPower.String())
+	require.False(t, *tally.Passing)
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"fmt"
+	"math/big"
 	"reflect"
 
 	"github.com/oasisprotocol/oasis-core/go/common/cbor"
@@ -111,3 +112,46 @@ func extractProposalParametersChange(raw cbor.RawMessage, module string) (interf
 		return nil, fmt.Errorf("unhandled module: %s", module)
 	}
 }
+
+// tallyParameters are the governance parameters that determine whether a proposal
+// passes, in percent. See chain.proposals.
+type tallyParameters struct {
+	stakeThreshold *int32
+	quorum         *int32
+	threshold      *int32
+}
+
+// fillTallyOutcome sets the turnout of the tally, and whether the proposal passes
+// with it, mirroring the tallying rules of oasis-core.
+func fillTallyOutcome(t *ProposalTally, params *tallyParameters) {
+	t.StakeThreshold = params.stakeThreshold
+	t.Quorum = params.quorum
+	t.Threshold = params.threshold
+
+	total := &t.TotalVotingPower.Int
+	if total.Sign() <= 0 {
+		return
+	}
+	cast := new(big.Int).Add(&t.Yes.Int, &t.No.Int)
+	cast.Add(cast, &t.Abstain.Int)
+	turnout, _ := new(big.Rat).SetFrac(cast, total).Float64()
+	t.Turnout = &turnout
+
+	// atLeast returns whether x is at least percent % of y.
+	atLeast := func(x *big.Int, y *big.Int, percent int32) bool {
+		lhs := new(big.Int).Mul(x, big.NewInt(100))
+		rhs := new(big.Int).Mul(y, big.NewInt(int64(percent)))
+		return lhs.Cmp(rhs) >= 0
+	}
+	switch {
+	case params.stakeThreshold != nil:
+		// Since Damask, a proposal passes if enough of the total voting power votes yes.
+		passing := atLeast(&t.Yes.Int, total, *params.stakeThreshold)
+		t.Passing = &passing
+	case params.quorum != nil && params.threshold != nil:
+		// Before Damask, a proposal passed if enough of the total voting power voted,
+		// and enough of the cast votes were yes.
+		passing := atLeast(cast, total, *params.quorum) && cast.Sign() > 0 && atLeast(&t.Yes.Int, cast, *params.threshold)
+		t.Passing = &passing
+	}
+}
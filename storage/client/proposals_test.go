@@ -0,0 +1,58 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/nexus/common"
+)
+
+func TestFillTallyOutcome(t *testing.T) {
+	damask := &tallyParameters{stakeThreshold: common.Ptr(int32(68))}
+	preDamask := &tallyParameters{quorum: common.Ptr(int32(75)), threshold: common.Ptr(int32(90))}
+
+	for _, tc := range []struct {
+		name            string
+		params          *tallyParameters
+		yes, no, abs    int64
+		total           int64
+		expectedTurnout *float64
+		expectedPassing *bool
+	}{
+		// Since Damask, only yes votes count towards the stake threshold.
+		{"damask passing", damask, 68, 0, 0, 100, common.Ptr(0.68), common.Ptr(true)},
+		{"damask not enough yes", damask, 67, 30, 3, 100, common.Ptr(1.0), common.Ptr(false)},
+		{"damask abstain does not count", damask, 60, 0, 40, 100, common.Ptr(1.0), common.Ptr(false)},
+		// Before Damask, the quorum counts all votes, and the threshold the yes votes among them.
+		{"pre-damask passing", preDamask, 72, 3, 5, 100, common.Ptr(0.8), common.Ptr(true)},
+		{"pre-damask no quorum", preDamask, 74, 0, 0, 100, common.Ptr(0.74), common.Ptr(false)},
+		{"pre-damask below threshold", preDamask, 71, 9, 0, 100, common.Ptr(0.8), common.Ptr(false)},
+		{"pre-damask no votes with zero quorum", &tallyParameters{quorum: common.Ptr(int32(0)), threshold: common.Ptr(int32(0))}, 0, 0, 0, 100, common.Ptr(0.0), common.Ptr(false)},
+		// Without the parameters, e.g. for proposals from genesis, the outcome is unknown.
+		{"unknown parameters", &tallyParameters{}, 100, 0, 0, 100, common.Ptr(1.0), nil},
+		// Without voting power, e.g. if the validator escrows are unknown, nothing is known.
+		{"no voting power", damask, 0, 0, 0, 0, nil, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tally := ProposalTally{
+				Yes:              common.NewBigInt(tc.yes),
+				No:               common.NewBigInt(tc.no),
+				Abstain:          common.NewBigInt(tc.abs),
+				TotalVotingPower: common.NewBigInt(tc.total),
+			}
+			fillTallyOutcome(&tally, tc.params)
+
+			require.Equal(t, tc.params.stakeThreshold, tally.StakeThreshold)
+			require.Equal(t, tc.params.quorum, tally.Quorum)
+			require.Equal(t, tc.params.threshold, tally.Threshold)
+			if tc.expectedTurnout == nil {
+				require.Nil(t, tally.Turnout)
+			} else {
+				require.NotNil(t, tally.Turnout)
+				require.InDelta(t, *tc.expectedTurnout, *tally.Turnout, 1e-9)
+			}
+			require.Equal(t, tc.expectedPassing, tally.Passing)
+		})
+	}
+}
@@ -62,10 +62,11 @@ const (
 				FROM chain.entities
 				WHERE address = $6::text
 				LIMIT 1
-			), 'none'))
+			), 'none')) AND
+			($7::bigint IS NULL OR height < $7::bigint)
 		ORDER BY height DESC
-		LIMIT $7::bigint
-		OFFSET $8::bigint`
+		LIMIT $8::bigint
+		OFFSET $9::bigint`
 
 	Block = `
 		SELECT height, block_hash, time, num_txs, gas_limit, size_limit, epoch, state_root
@@ -86,9 +87,12 @@ const (
 				chain.transactions.code as code,
 				chain.transactions.module as module,
 				chain.transactions.message as message,
-				chain.blocks.time as time
+				chain.blocks.time as time,
+				sender_label.name as sender_label
 			FROM chain.transactions
 			JOIN chain.blocks ON chain.transactions.block = chain.blocks.height
+			LEFT JOIN chain.address_labels AS sender_label ON sender_label.layer = 'consensus'
+				AND chain.transactions.sender = sender_label.address
 			LEFT JOIN chain.accounts_related_transactions ON chain.transactions.block = chain.accounts_related_transactions.tx_block
 				AND chain.transactions.tx_index = chain.accounts_related_transactions.tx_index
 				-- When related_address ($5) is NULL and hence we do no filtering on it, avoid the join altogether.
@@ -100,23 +104,32 @@ const (
 					($4::text IS NULL OR chain.transactions.sender = $4::text) AND
 					($5::text IS NULL OR chain.accounts_related_transactions.account_address = $5::text) AND
 					($6::timestamptz IS NULL OR chain.blocks.time >= $6::timestamptz) AND
-					($7::timestamptz IS NULL OR chain.blocks.time < $7::timestamptz)
+					($7::timestamptz IS NULL OR chain.blocks.time < $7::timestamptz) AND
+					($8::bigint IS NULL OR chain.transactions.block < $8::bigint OR
+						(chain.transactions.block = $8::bigint AND chain.transactions.tx_index > $9::integer))
 			ORDER BY chain.transactions.block DESC, chain.transactions.tx_index
-			LIMIT $8::bigint
-			OFFSET $9::bigint`
+			LIMIT $10::bigint
+			OFFSET $11::bigint`
 
 	Events = `
-		SELECT tx_block, tx_index, tx_hash, roothash_runtime_id, roothash_runtime, roothash_runtime_round, type, body, b.time
+		SELECT tx_block, tx_index, event_index, tx_hash, roothash_runtime_id, roothash_runtime, roothash_runtime_round, type, body, b.time,
+				(
+					SELECT jsonb_agg(jsonb_build_object('address', l.address, 'name', l.name, 'description', NULLIF(l.description, '')) ORDER BY l.name)
+					FROM chain.address_labels AS l
+					WHERE l.layer = 'consensus' AND l.address = ANY(related_accounts)
+				) AS related_labels
 			FROM chain.events
 			LEFT JOIN chain.blocks b ON tx_block = b.height
 			WHERE ($1::bigint IS NULL OR tx_block = $1::bigint) AND
 					($2::integer IS NULL OR tx_index = $2::integer) AND
 					($3::text IS NULL OR tx_hash = $3::text) AND
 					($4::text IS NULL OR type = $4::text) AND
-					($5::text IS NULL OR ARRAY[$5::text] <@ related_accounts)
-			ORDER BY tx_block DESC, tx_index
-			LIMIT $6::bigint
-			OFFSET $7::bigint`
+					($5::text IS NULL OR ARRAY[$5::text] <@ related_accounts) AND
+					($6::bigint IS NULL OR tx_block < $6::bigint OR
+						(tx_block = $6::bigint AND event_index > $7::integer))
+			ORDER BY tx_block DESC, event_index
+			LIMIT $8::bigint
+			OFFSET $9::bigint`
 
 	RoothashMessages = `
 		SELECT
@@ -133,17 +146,19 @@ const (
 			($1::runtime IS NULL OR runtime = $1) AND
 			($2::bigint IS NULL OR round = $2) AND
 			($3::text IS NULL OR type = $3) AND
-			($4::oasis_addr IS NULL OR related_accounts @> ARRAY[$4])
-		ORDER BY round DESC, message_index DESC
-		LIMIT $5
-		OFFSET $6`
+			($4::oasis_addr IS NULL OR related_accounts @> ARRAY[$4]) AND
+			($5::bigint IS NULL OR (round, message_index, runtime) < ($5, $6::integer, $7::runtime))
+		ORDER BY round DESC, message_index DESC, runtime DESC
+		LIMIT $8
+		OFFSET $9`
 
 	Entities = `
 		SELECT id, address
 			FROM chain.entities
+			WHERE ($1::text IS NULL OR id > $1::text)
 		ORDER BY id
-		LIMIT $1::bigint
-		OFFSET $2::bigint`
+		LIMIT $2::bigint
+		OFFSET $3::bigint`
 
 	Entity = `
 		SELECT id, address
@@ -163,10 +178,11 @@ const (
 		FROM chain.nodes as nodes
 		JOIN chain.entities as entities
 			ON entities.id = nodes.entity_id
-		WHERE entities.address = $1::text
+		WHERE entities.address = $1::text AND
+			($2::text IS NULL OR nodes.id > $2::text)
 		ORDER BY id
-		LIMIT $2::bigint
-		OFFSET $3::bigint`
+		LIMIT $3::bigint
+		OFFSET $4::bigint`
 
 	EntityNode = `
 		SELECT nodes.id, entity_id, expiration, tls_pubkey, tls_next_pubkey, p2p_pubkey, consensus_pubkey, roles
@@ -198,22 +214,104 @@ const (
 		FROM chain.accounts
 		WHERE address = $1::text`
 
+	AddressLabel = `
+		SELECT name
+		FROM chain.address_labels
+		WHERE layer = $1::text AND address = $2::text`
+
 	// Uses periodically computed view.
 	Accounts = `
 		SELECT
-			address,
-			nonce,
-			general_balance,
-			escrow_balance_active,
-			escrow_balance_debonding,
-			delegations_balance,
-			debonding_delegations_balance,
-			first_activity
+			accts.address,
+			accts.nonce,
+			accts.general_balance,
+			accts.escrow_balance_active,
+			accts.escrow_balance_debonding,
+			accts.delegations_balance,
+			accts.debonding_delegations_balance,
+			accts.first_activity,
+			labels.name,
+			accts.total_balance
 		FROM
-			views.accounts_list
-		ORDER BY total_balance DESC, address
-		LIMIT $1::bigint
-		OFFSET $2::bigint`
+			views.accounts_list AS accts
+		LEFT JOIN chain.address_labels AS labels ON
+			labels.layer = 'consensus' AND
+			labels.address = accts.address
+		WHERE ($1::numeric IS NULL OR
+				accts.total_balance < $1::numeric OR
+				(accts.total_balance = $1::numeric AND accts.address > $2::text))
+		ORDER BY accts.total_balance DESC, accts.address
+		LIMIT $3::bigint
+		OFFSET $4::bigint`
+
+	// The range of heights for which historical consensus balances are available:
+	// from the first block processed in slow-sync mode (balances are not tracked
+	// in fast-sync mode) to the latest indexed block.
+	AccountBalanceHistoryRange = `
+		SELECT
+			(SELECT min(height) FROM analysis.processed_blocks
+				WHERE analyzer = 'consensus' AND NOT is_fast_sync AND processed_time IS NOT NULL),
+			(SELECT max(height) FROM chain.blocks)`
+
+	// The last block at or before the given time.
+	BlockHeightAtTime = `
+		SELECT height
+			FROM chain.blocks
+			WHERE time <= $1::timestamptz
+			ORDER BY time DESC, height DESC
+			LIMIT 1`
+
+	// The balances of an account at the end of a block: those after its last
+	// change at or before the block, or if it only changed after the block,
+	// those before its first later change. Returns no rows if the balances have
+	// not changed since the start of the history, i.e. they are the current ones.
+	AccountBalanceAtHeight = `
+		SELECT general_balance, escrow_balance_active, escrow_balance_debonding
+		FROM (
+			(
+				SELECT 0 AS priority, general_balance, escrow_balance_active, escrow_balance_debonding
+				FROM history.account_balances
+				WHERE address = $1::text AND height <= $2::bigint
+				ORDER BY height DESC
+				LIMIT 1
+			)
+			UNION ALL
+			(
+				SELECT
+					1 AS priority,
+					general_balance - general_balance_delta,
+					escrow_balance_active - escrow_balance_active_delta,
+					escrow_balance_debonding - escrow_balance_debonding_delta
+				FROM history.account_balances
+				WHERE address = $1::text AND height > $2::bigint
+				ORDER BY height ASC
+				LIMIT 1
+			)
+		) AS candidates
+		ORDER BY priority
+		LIMIT 1`
+
+	AccountBalanceHistory = `
+		SELECT
+			h.height,
+			b.time,
+			h.general_balance,
+			h.escrow_balance_active,
+			h.escrow_balance_debonding,
+			h.general_balance_delta,
+			h.escrow_balance_active_delta,
+			h.escrow_balance_debonding_delta
+		FROM history.account_balances AS h
+		JOIN chain.blocks AS b ON b.height = h.height
+		WHERE (h.address = $1::text) AND
+				($2::bigint IS NULL OR h.height >= $2::bigint) AND
+				($3::bigint IS NULL OR h.height <= $3::bigint) AND
+				($4::timestamptz IS NULL OR b.time >= $4::timestamptz) AND
+				($5::timestamptz IS NULL OR b.time < $5::timestamptz) AND
+				($6::bigint IS NULL OR h.height < $6::bigint)
+		ORDER BY h.height DESC
+		LIMIT $7::bigint
+		OFFSET $8::bigint`
 
 	AccountStats = `
 		SELECT
@@ -230,10 +328,11 @@ const (
 		SELECT delegatee, shares, escrow_balance_active, escrow_total_shares_active
 			FROM chain.delegations
 			JOIN chain.accounts ON chain.delegations.delegatee = chain.accounts.address
-			WHERE delegator = $1::text
+			WHERE delegator = $1::text AND
+				($2::numeric IS NULL OR shares < $2::numeric OR (shares = $2::numeric AND delegatee > $3::text))
 		ORDER BY shares DESC, delegatee
-		LIMIT $2::bigint
-		OFFSET $3::bigint`
+		LIMIT $4::bigint
+		OFFSET $5::bigint`
 
 	DelegationsTo = `
 		-- delegatee_info is used to calculate the escrow amount of the delegators in base units
@@ -244,19 +343,23 @@ const (
 		)
 		SELECT delegator, shares, delegatee_info.escrow_balance_active, delegatee_info.escrow_total_shares_active
 			FROM chain.delegations, delegatee_info
-			WHERE delegatee = $1::text
+			WHERE delegatee = $1::text AND
+				($2::numeric IS NULL OR shares < $2::numeric OR (shares = $2::numeric AND delegator > $3::text))
 		ORDER BY shares DESC, delegator
-		LIMIT $2::bigint
-		OFFSET $3::bigint`
+		LIMIT $4::bigint
+		OFFSET $5::bigint`
 
 	DebondingDelegations = `
 		SELECT delegatee, shares, debond_end, escrow_balance_debonding, escrow_total_shares_debonding
 			FROM chain.debonding_delegations
 			JOIN chain.accounts ON chain.debonding_delegations.delegatee = chain.accounts.address
-			WHERE delegator = $1::text
-		ORDER BY debond_end, shares DESC, delegator
-		LIMIT $2::bigint
-		OFFSET $3::bigint`
+			WHERE delegator = $1::text AND
+				($2::bigint IS NULL OR debond_end > $2::bigint OR
+					(debond_end = $2::bigint AND
+						(shares < $3::numeric OR (shares = $3::numeric AND delegatee > $4::text))))
+		ORDER BY debond_end, shares DESC, delegatee
+		LIMIT $5::bigint
+		OFFSET $6::bigint`
 
 	DebondingDelegationsTo = `
 		-- delegatee_info is used to calculate the debonding escrow amount of the delegators in base units
@@ -267,29 +370,34 @@ const (
 		)
 		SELECT delegator, shares, debond_end, delegatee_info.escrow_balance_debonding, delegatee_info.escrow_total_shares_debonding
 			FROM chain.debonding_delegations, delegatee_info
-			WHERE delegatee = $1::text
+			WHERE delegatee = $1::text AND
+				($2::bigint IS NULL OR debond_end > $2::bigint OR
+					(debond_end = $2::bigint AND
+						(shares < $3::numeric OR (shares = $3::numeric AND delegator > $4::text))))
 		ORDER BY debond_end, shares DESC, delegator
-		LIMIT $2::bigint
-		OFFSET $3::bigint`
+		LIMIT $5::bigint
+		OFFSET $6::bigint`
 
 	Epochs = `
 		SELECT id, start_height,
 			(CASE id WHEN (SELECT max(id) FROM chain.epochs) THEN NULL ELSE end_height END) AS end_height
 			FROM chain.epochs
-		WHERE ($1::bigint IS NULL OR id = $1::bigint)
+		WHERE ($1::bigint IS NULL OR id = $1::bigint) AND
+			($2::bigint IS NULL OR id < $2::bigint)
 		ORDER BY id DESC
-		LIMIT $2::bigint
-		OFFSET $3::bigint`
+		LIMIT $3::bigint
+		OFFSET $4::bigint`
 
 	Proposals = `
 		SELECT id, submitter, state, deposit, title, description, handler, cp_target_version, rhp_target_version, rcp_target_version,
 				upgrade_epoch, cancels, parameters_change_module, parameters_change, created_at, closes_at, invalid_votes
 			FROM chain.proposals
 			WHERE ($1::text IS NULL OR submitter = $1::text) AND
-						($2::text IS NULL OR state = $2::text)
+						($2::text IS NULL OR state = $2::text) AND
+						($3::bigint IS NULL OR id < $3::bigint)
 		ORDER BY id DESC
-		LIMIT $3::bigint
-		OFFSET $4::bigint`
+		LIMIT $4::bigint
+		OFFSET $5::bigint`
 
 	Proposal = `
 		SELECT id, submitter, state, deposit, title, description, handler, cp_target_version, rhp_target_version, rcp_target_version,
@@ -297,14 +405,179 @@ const (
 			FROM chain.proposals
 			WHERE id = $1::bigint`
 
+	Vaults = `
+		SELECT address, creator, id, state, nonce, admin_authority_addresses::text[], admin_authority_threshold,
+				suspend_authority_addresses::text[], suspend_authority_threshold, created_at
+			FROM chain.vaults
+			WHERE ($1::text IS NULL OR creator = $1::text) AND
+					($2::text IS NULL OR $2::text = ANY(admin_authority_addresses) OR $2::text = ANY(suspend_authority_addresses)) AND
+					($3::bigint IS NULL OR (created_at, address) < ($3::bigint, $4::text))
+		ORDER BY created_at DESC, address DESC
+		LIMIT $5::bigint
+		OFFSET $6::bigint`
+
+	Vault = `
+		SELECT address, creator, id, state, nonce, admin_authority_addresses::text[], admin_authority_threshold,
+				suspend_authority_addresses::text[], suspend_authority_threshold, created_at
+			FROM chain.vaults
+			WHERE address = $1::text`
+
+	VaultPendingActions = `
+		SELECT nonce, action, authorized_by::text[]
+			FROM chain.vault_pending_actions
+			WHERE vault = $1::text
+		ORDER BY nonce`
+
+	// The latest status of each key manager.
+	KeyManagers = `
+		SELECT runtime_id, epoch, height, is_initialized, is_secure, generation, rotation_epoch,
+				checksum, nodes::text[], rsk, policy
+			FROM (
+				SELECT DISTINCT ON (runtime_id) *
+					FROM history.keymanager_statuses
+				ORDER BY runtime_id, epoch DESC
+			) AS statuses
+			WHERE ($1::text IS NULL OR runtime_id > $1::text)
+		ORDER BY runtime_id
+		LIMIT $2::bigint
+		OFFSET $3::bigint`
+
+	// The latest status of each CHURP instance of the key managers in $1.
+	KeyManagerChurpStatuses = `
+		SELECT DISTINCT ON (runtime_id, churp_id)
+				runtime_id, churp_id, epoch, height, suite_id, threshold, extra_shares, handoff_interval,
+				handoff, next_handoff, checksum, next_checksum, committee::text[], policy
+			FROM history.keymanager_churp_statuses
+			WHERE runtime_id = ANY($1::text[])
+		ORDER BY runtime_id, churp_id, epoch DESC`
+
+	KeyManagerHistory = `
+		SELECT epoch, height, is_initialized, is_secure, generation, rotation_epoch,
+				checksum, nodes::text[], rsk, policy
+			FROM history.keymanager_statuses
+			WHERE runtime_id = $1::text AND
+				($2::bigint IS NULL OR epoch >= $2::bigint) AND
+				($3::bigint IS NULL OR epoch <= $3::bigint) AND
+				($4::bigint IS NULL OR epoch < $4::bigint)
+		ORDER BY epoch DESC
+		LIMIT $5::bigint
+		OFFSET $6::bigint`
+
+	ProposalTallyParameters = `
+		SELECT closes_at, stake_threshold, quorum, threshold
+			FROM chain.proposals
+			WHERE id = $1::bigint`
+
+	// The latest epoch up to $1 for which the validator set and validator escrow
+	// balances are known, and the height and time of the first block of epoch $1,
+	// in which voting closes. The height is NULL while voting is open.
+	//
+	// The validator set of an epoch is only known if the validator_uptime analyzer
+	// processed the epoch, and the escrow balances if the validator_staking_history
+	// analyzer did.
+	ProposalTallyEpoch = `
+		SELECT epochs.id, closing.start_height, closing_blocks.time
+			FROM chain.epochs AS epochs
+			LEFT JOIN chain.epochs AS closing ON closing.id = $1::bigint
+			LEFT JOIN chain.blocks AS closing_blocks ON closing_blocks.height = closing.start_height
+			WHERE epochs.id <= $1::bigint AND
+				epochs.active_validators IS NOT NULL AND
+				EXISTS (SELECT 1 FROM history.validators AS validators WHERE validators.epoch = epochs.id)
+			ORDER BY epochs.id DESC
+			LIMIT 1`
+
+	// The voting power behind each option of proposal $1, counting votes up to height $3
+	// (or all votes if NULL), weighted by the escrow of the validators in the validator set
+	// of epoch $2.
+	ProposalTally = `
+		SELECT
+			COALESCE(SUM(validators.escrow_balance_active) FILTER (WHERE votes.vote = 'yes'), 0),
+			COALESCE(SUM(validators.escrow_balance_active) FILTER (WHERE votes.vote = 'no'), 0),
+			COALESCE(SUM(validators.escrow_balance_active) FILTER (WHERE votes.vote = 'abstain'), 0),
+			COALESCE(SUM(validators.escrow_balance_active), 0),
+			COUNT(votes.voter),
+			COUNT(*) FILTER (WHERE votes.voter IS NULL)
+		FROM chain.epochs AS epochs
+		CROSS JOIN LATERAL unnest(epochs.active_validators) AS active(id)
+		JOIN history.validators AS validators ON validators.id = active.id AND validators.epoch = epochs.id
+		JOIN chain.entities AS entities ON entities.id = active.id
+		LEFT JOIN chain.votes AS votes
+			ON votes.proposal = $1::bigint AND
+				votes.voter = entities.address AND
+				($3::bigint IS NULL OR votes.height IS NULL OR votes.height <= $3::bigint)
+		WHERE epochs.id = $2::bigint`
+
+	// The validators in the validator set of epoch $2 that did not vote on proposal $1
+	// up to height $3 (or at all if NULL), with their voting power.
+	ProposalNonVotingValidators = `
+		SELECT entities.address, validators.escrow_balance_active
+		FROM chain.epochs AS epochs
+		CROSS JOIN LATERAL unnest(epochs.active_validators) AS active(id)
+		JOIN history.validators AS validators ON validators.id = active.id AND validators.epoch = epochs.id
+		JOIN chain.entities AS entities ON entities.id = active.id
+		WHERE epochs.id = $2::bigint AND
+			NOT EXISTS (
+				SELECT 1 FROM chain.votes AS votes
+				WHERE votes.proposal = $1::bigint AND
+					votes.voter = entities.address AND
+					($3::bigint IS NULL OR votes.height IS NULL OR votes.height <= $3::bigint)
+			)
+		ORDER BY validators.escrow_balance_active DESC, entities.address`
+
+	// The tally of proposal $1 after each block with votes, weighted by the escrow
+	// of the validators in the validator set of the block's epoch.
+	ProposalTallyHistory = `
+		WITH
+		points AS (
+			SELECT DISTINCT votes.height, blocks.epoch, blocks.time
+				FROM chain.votes AS votes
+				JOIN chain.blocks AS blocks ON blocks.height = votes.height
+				WHERE votes.proposal = $1::bigint AND
+					($2::bigint IS NULL OR votes.height < $2::bigint)
+		)
+		SELECT
+			points.height,
+			points.time,
+			points.epoch,
+			tally.yes,
+			tally.no,
+			tally.abstain,
+			tally.total,
+			tally.num_voting,
+			tally.num_non_voting
+		FROM points
+		CROSS JOIN LATERAL (
+			SELECT
+				COALESCE(SUM(validators.escrow_balance_active) FILTER (WHERE votes.vote = 'yes'), 0) AS yes,
+				COALESCE(SUM(validators.escrow_balance_active) FILTER (WHERE votes.vote = 'no'), 0) AS no,
+				COALESCE(SUM(validators.escrow_balance_active) FILTER (WHERE votes.vote = 'abstain'), 0) AS abstain,
+				COALESCE(SUM(validators.escrow_balance_active), 0) AS total,
+				COUNT(votes.voter) AS num_voting,
+				COUNT(*) FILTER (WHERE votes.voter IS NULL) AS num_non_voting
+			FROM chain.epochs AS epochs
+			CROSS JOIN LATERAL unnest(epochs.active_validators) AS active(id)
+			JOIN history.validators AS validators ON validators.id = active.id AND validators.epoch = epochs.id
+			JOIN chain.entities AS entities ON entities.id = active.id
+			LEFT JOIN chain.votes AS votes
+				ON votes.proposal = $1::bigint AND
+					votes.voter = entities.address AND
+					(votes.height IS NULL OR votes.height <= points.height)
+			WHERE epochs.id = points.epoch
+		) AS tally
+		ORDER BY points.height DESC
+		LIMIT $3::bigint
+		OFFSET $4::bigint`
+
 	ProposalVotes = `
 		SELECT votes.voter, votes.vote, votes.height, blocks.time
 			FROM chain.votes as votes
 			LEFT JOIN chain.blocks as blocks ON votes.height = blocks.height
-			WHERE proposal = $1::bigint
+			WHERE proposal = $1::bigint AND
+				($2::bigint IS NULL OR votes.height < $2::bigint OR
+					(votes.height = $2::bigint AND votes.voter > $3::text))
 		ORDER BY height DESC, voter ASC
-		LIMIT $2::bigint
-		OFFSET $3::bigint`
+		LIMIT $4::bigint
+		OFFSET $5::bigint`
 
 	LatestEpochStart = `
 		SELECT id, start_height
@@ -370,75 +643,144 @@ const (
 				) AS rank
 			FROM chain.entities
 			JOIN chain.accounts ON chain.entities.address = chain.accounts.address
+		),
+		validators AS (
+			SELECT
+				chain.entities.id AS entity_id,
+				chain.entities.address AS entity_address,
+				chain.nodes.id AS node_id,
+				chain.accounts.escrow_balance_active AS active_balance,
+				chain.accounts.escrow_total_shares_active AS active_shares,
+				chain.accounts.escrow_balance_debonding AS debonding_balance,
+				chain.accounts.escrow_total_shares_debonding AS debonding_shares,
+				COALESCE (
+					ROUND(COALESCE(self_delegations.shares, 0) * chain.accounts.escrow_balance_active / NULLIF(chain.accounts.escrow_total_shares_active, 0))
+				, 0) AS self_delegation_balance,
+				COALESCE (
+					self_delegations.shares
+				, 0) AS self_delegation_shares,
+				history.validators.escrow_balance_active AS active_balance_24,
+				COALESCE (
+					delegators_count.count
+				, 0) AS num_delegators,
+				COALESCE (
+					validator_nodes.voting_power
+				, 0) AS voting_power,
+				SUM(validator_nodes.voting_power)
+					OVER (ORDER BY validator_rank.rank) AS voting_power_cumulative,
+				COALESCE(chain.commissions.schedule, '{}'::JSONB) AS commissions_schedule,
+				chain.blocks.time AS start_date,
+				validator_rank.rank AS rank,
+				EXISTS(SELECT NULL FROM chain.nodes WHERE chain.entities.id = chain.nodes.entity_id AND chain.nodes.roles LIKE '%validator%') AS active,
+				EXISTS(SELECT NULL FROM chain.nodes WHERE chain.entities.id = chain.nodes.entity_id AND voting_power > 0) AS in_validator_set,
+				chain.entities.meta AS meta,
+				chain.entities.logo_url as logo_url
+			FROM chain.entities
+			JOIN chain.accounts ON chain.entities.address = chain.accounts.address
+			JOIN chain.blocks ON chain.entities.start_block = chain.blocks.height
+			LEFT JOIN chain.commissions ON chain.entities.address = chain.commissions.address
+			LEFT JOIN self_delegations ON chain.entities.address = self_delegations.address
+			LEFT JOIN history.validators ON chain.entities.id = history.validators.id
+				-- Find the epoch id from 24 hours ago. Each epoch is ~1hr.
+				AND history.validators.epoch = (SELECT id - 24 from chain.epochs
+					ORDER BY id DESC
+					LIMIT 1)
+			LEFT JOIN delegators_count ON chain.entities.address = delegators_count.address
+			LEFT JOIN validator_nodes ON validator_nodes.address = entities.address
+			JOIN validator_rank ON chain.entities.address = validator_rank.address
+			LEFT JOIN chain.nodes ON chain.entities.id = chain.nodes.entity_id
+				AND chain.nodes.roles LIKE '%validator%'
+				AND chain.nodes.voting_power = validator_nodes.voting_power
+			WHERE ($1::text IS NULL OR chain.entities.address = $1::text) AND
+					($2::text IS NULL OR chain.entities.meta->>'name' LIKE '%' || $2::text || '%')
 		)
+		-- Apply the cursor only after voting_power_cumulative has been computed over all validators.
+		SELECT *
+			FROM validators
+			WHERE ($3::bigint IS NULL OR rank > $3::bigint OR (rank = $3::bigint AND COALESCE(node_id, '') > $4::text))
+		ORDER BY rank, COALESCE(node_id, '')
+		LIMIT $5::bigint
+		OFFSET $6::bigint`
+
+	// The APR of an epoch is the relative change of the validator's share price from
+	// the start of the epoch to the start of the next one, annualised by the duration
+	// of the epoch.
+	ValidatorHistory = `
 		SELECT
-			chain.entities.id AS entity_id,
-			chain.entities.address AS entity_address,
-			chain.nodes.id AS node_id,
-			chain.accounts.escrow_balance_active AS active_balance,
-			chain.accounts.escrow_total_shares_active AS active_shares,
-			chain.accounts.escrow_balance_debonding AS debonding_balance,
-			chain.accounts.escrow_total_shares_debonding AS debonding_shares,
-			COALESCE (
-				ROUND(COALESCE(self_delegations.shares, 0) * chain.accounts.escrow_balance_active / NULLIF(chain.accounts.escrow_total_shares_active, 0))
-			, 0) AS self_delegation_balance,
-			COALESCE (
-				self_delegations.shares
-			, 0) AS self_delegation_shares,
-			history.validators.escrow_balance_active AS active_balance_24,
-			COALESCE (
-				delegators_count.count
-			, 0) AS num_delegators,
-			COALESCE (
-				validator_nodes.voting_power
-			, 0) AS voting_power,
-			SUM(validator_nodes.voting_power)
-				OVER (ORDER BY validator_rank.rank) AS voting_power_cumulative,
-			COALESCE(chain.commissions.schedule, '{}'::JSONB) AS commissions_schedule,
-			chain.blocks.time AS start_date,
-			validator_rank.rank AS rank,
-			EXISTS(SELECT NULL FROM chain.nodes WHERE chain.entities.id = chain.nodes.entity_id AND chain.nodes.roles LIKE '%validator%') AS active,
-			EXISTS(SELECT NULL FROM chain.nodes WHERE chain.entities.id = chain.nodes.entity_id AND voting_power > 0) AS in_validator_set,
-			chain.entities.meta AS meta,
-			chain.entities.logo_url as logo_url
+			validators.epoch,
+			validators.escrow_balance_active,
+			validators.escrow_total_shares_active,
+			validators.escrow_balance_debonding,
+			validators.escrow_total_shares_debonding,
+			validators.num_delegators,
+			(
+				(next_validators.escrow_balance_active::float8 / NULLIF(next_validators.escrow_total_shares_active, 0)::float8) /
+					NULLIF(validators.escrow_balance_active::float8 / NULLIF(validators.escrow_total_shares_active, 0)::float8, 0)
+				- 1
+			) * 31557600 / NULLIF(EXTRACT(EPOCH FROM next_start_blocks.time - start_blocks.time), 0)::float8 AS apr
 		FROM chain.entities
-		JOIN chain.accounts ON chain.entities.address = chain.accounts.address
-		JOIN chain.blocks ON chain.entities.start_block = chain.blocks.height
-		LEFT JOIN chain.commissions ON chain.entities.address = chain.commissions.address
-		LEFT JOIN self_delegations ON chain.entities.address = self_delegations.address
-		LEFT JOIN history.validators ON chain.entities.id = history.validators.id
-			-- Find the epoch id from 24 hours ago. Each epoch is ~1hr.
-			AND history.validators.epoch = (SELECT id - 24 from chain.epochs
-				ORDER BY id DESC
-				LIMIT 1)
-		LEFT JOIN delegators_count ON chain.entities.address = delegators_count.address
-		LEFT JOIN validator_nodes ON validator_nodes.address = entities.address
-		JOIN validator_rank ON chain.entities.address = validator_rank.address
-		LEFT JOIN chain.nodes ON chain.entities.id = chain.nodes.entity_id
-			AND chain.nodes.roles LIKE '%validator%'
-			AND chain.nodes.voting_power = validator_nodes.voting_power
-		WHERE ($1::text IS NULL OR chain.entities.address = $1::text) AND
-				($2::text IS NULL OR chain.entities.meta->>'name' LIKE '%' || $2::text || '%')
-		ORDER BY rank
-		LIMIT $3::bigint
-		OFFSET $4::bigint`
+		JOIN history.validators AS validators ON chain.entities.id = validators.id
+		LEFT JOIN history.validators AS next_validators
+			ON next_validators.id = validators.id AND next_validators.epoch = validators.epoch + 1
+		LEFT JOIN chain.epochs AS epochs ON epochs.id = validators.epoch
+		LEFT JOIN chain.blocks AS start_blocks ON start_blocks.height = epochs.start_height
+		LEFT JOIN chain.epochs AS next_epochs ON next_epochs.id = validators.epoch + 1
+		LEFT JOIN chain.blocks AS next_start_blocks ON next_start_blocks.height = next_epochs.start_height
+		WHERE (chain.entities.address = $1::text) AND
+				($2::bigint IS NULL OR validators.epoch >= $2::bigint) AND
+				($3::bigint IS NULL OR validators.epoch <= $3::bigint) AND
+				($4::bigint IS NULL OR validators.epoch < $4::bigint)
+		ORDER BY validators.epoch DESC
+		LIMIT $5::bigint
+		OFFSET $6::bigint`
 
-	ValidatorHistory = `
+	DelegatorRewards = `
+		SELECT epoch, delegatee, shares, reward
+			FROM history.delegator_rewards
+			WHERE (delegator = $1::text) AND
+					($2::bigint IS NULL OR epoch >= $2::bigint) AND
+					($3::bigint IS NULL OR epoch <= $3::bigint) AND
+					($4::bigint IS NULL OR (epoch, delegatee) < ($4::bigint, $5::text))
+			ORDER BY epoch DESC, delegatee DESC
+			LIMIT $6::bigint
+			OFFSET $7::bigint`
+
+	// Block signing statistics of the validators with entity IDs $1 over the
+	// latest $2 blocks.
+	ValidatorUptimeWindows = `
+		WITH
+		recent_blocks AS (
+			SELECT epoch, signer_entity_ids, proposer_entity_id
+				FROM chain.blocks
+			ORDER BY height DESC
+			LIMIT $2::bigint
+		)
+		SELECT
+			validators.id,
+			COUNT(*) FILTER (WHERE validators.id = ANY(blocks.signer_entity_ids)),
+			COUNT(*) FILTER (WHERE blocks.signer_entity_ids IS NOT NULL AND NOT validators.id = ANY(blocks.signer_entity_ids)),
+			COUNT(*) FILTER (WHERE blocks.proposer_entity_id = validators.id)
+		FROM recent_blocks AS blocks
+		JOIN chain.epochs AS epochs ON epochs.id = blocks.epoch
+		CROSS JOIN LATERAL unnest(epochs.active_validators) AS validators(id)
+		WHERE validators.id = ANY($1::text[])
+		GROUP BY validators.id`
+
+	ValidatorEpochUptimes = `
 		SELECT
 			epoch,
-			escrow_balance_active,
-			escrow_total_shares_active,
-			escrow_balance_debonding,
-			escrow_total_shares_debonding,
-			num_delegators
+			signed_blocks,
+			missed_blocks,
+			proposed_blocks
 		FROM chain.entities
-		JOIN history.validators ON chain.entities.id = history.validators.id
+		JOIN history.validator_uptimes ON chain.entities.id = history.validator_uptimes.id
 		WHERE (chain.entities.address = $1::text) AND
-				($2::bigint IS NULL OR history.validators.epoch >= $2::bigint) AND
-				($3::bigint IS NULL OR history.validators.epoch <= $3::bigint)
+				($2::bigint IS NULL OR history.validator_uptimes.epoch >= $2::bigint) AND
+				($3::bigint IS NULL OR history.validator_uptimes.epoch <= $3::bigint) AND
+				($4::bigint IS NULL OR history.validator_uptimes.epoch < $4::bigint)
 		ORDER BY epoch DESC
-		LIMIT $4::bigint
-		OFFSET $5::bigint`
+		LIMIT $5::bigint
+		OFFSET $6::bigint`
 
 	RuntimeBlocks = `
 		SELECT round, block_hash, timestamp, num_transactions, size, gas_used
@@ -448,10 +790,11 @@ const (
 						($3::bigint IS NULL OR round <= $3::bigint) AND
 						($4::timestamptz IS NULL OR timestamp >= $4::timestamptz) AND
 						($5::timestamptz IS NULL OR timestamp < $5::timestamptz) AND
-						($6::text IS NULL OR block_hash = $6::text)
+						($6::text IS NULL OR block_hash = $6::text) AND
+						($7::bigint IS NULL OR round < $7::bigint)
 		ORDER BY round DESC
-		LIMIT $7::bigint
-		OFFSET $8::bigint`
+		LIMIT $8::bigint
+		OFFSET $9::bigint`
 
 	RuntimeBlock = `
 		SELECT round, block_hash, timestamp, num_transactions, size, gas_used
@@ -483,6 +826,7 @@ const (
 			txs.size,
 			txs.oasis_encrypted_format,
 			txs.oasis_encrypted_public_key,
+			txs.oasis_encrypted_epoch,
 			txs.oasis_encrypted_data_nonce,
 			txs.oasis_encrypted_data_data,
 			txs.oasis_encrypted_result_nonce,
@@ -497,6 +841,7 @@ const (
 			txs.amount_symbol,
 			txs.evm_encrypted_format,
 			txs.evm_encrypted_public_key,
+			txs.evm_encrypted_epoch,
 			txs.evm_encrypted_data_nonce,
 			txs.evm_encrypted_data_data,
 			txs.evm_encrypted_result_nonce,
@@ -504,10 +849,13 @@ const (
 			txs.success,
 			txs.evm_fn_name,
 			txs.evm_fn_params,
+			txs.evm_fn_low_confidence,
 			txs.error_module,
 			txs.error_code,
 			txs.error_message,
-			txs.error_params
+			txs.error_params,
+			sender0_label.name AS sender0_label,
+			to_label.name AS to_label
 		FROM chain.runtime_transactions AS txs
 		LEFT JOIN chain.runtime_transaction_signers AS signer0 ON
 			(signer0.runtime = txs.runtime) AND
@@ -526,6 +874,12 @@ const (
 			-- about Ethereum-compatible addresses, so only get those. Can
 			-- easily enable for other address types though.
 			(to_preimage.context_identifier = 'oasis-runtime-sdk/address: secp256k1eth') AND (to_preimage.context_version = 0)
+		LEFT JOIN chain.address_labels AS sender0_label ON
+			(sender0_label.layer = txs.runtime::text) AND
+			(sender0_label.address = signer0.signer_address)
+		LEFT JOIN chain.address_labels AS to_label ON
+			(to_label.layer = txs.runtime::text) AND
+			(to_label.address = txs.to)
 		LEFT JOIN chain.runtime_related_transactions AS rel ON
 			(txs.round = rel.tx_round) AND
 			(txs.tx_index = rel.tx_index) AND
@@ -540,16 +894,59 @@ const (
 			($4::text IS NULL OR rel.account_address = $4::text) AND
 			($5::timestamptz IS NULL OR txs.timestamp >= $5::timestamptz) AND
 			($6::timestamptz IS NULL OR txs.timestamp < $6::timestamptz) AND
+			($7::bigint IS NULL OR (txs.round, txs.tx_index) < ($7::bigint, $8::integer)) AND
+			($11::boolean IS NULL OR (txs.evm_encrypted_format IS NOT NULL OR txs.oasis_encrypted_format IS NOT NULL) = $11::boolean) AND
 			(signer0.signer_address IS NOT NULL) -- HACK: excludes malformed transactions that do not have the required fields
 		ORDER BY txs.round DESC, txs.tx_index DESC
-		LIMIT $7::bigint
-		OFFSET $8::bigint
+		LIMIT $9::bigint
+		OFFSET $10::bigint
 		`
 
+	RuntimeInternalCalls = `
+		SELECT
+			calls.round,
+			calls.tx_index,
+			txs.tx_hash,
+			txs.tx_eth_hash,
+			calls.call_index,
+			calls.depth,
+			calls.type,
+			calls.from_address,
+			from_preimage.address_data AS from_eth,
+			calls.to_address,
+			to_preimage.address_data AS to_eth,
+			calls.value,
+			calls.gas,
+			calls.gas_used,
+			calls.input,
+			calls.output,
+			calls.error,
+			calls.success
+		FROM chain.runtime_transactions AS txs
+		JOIN chain.runtime_internal_calls AS calls ON
+			calls.runtime = txs.runtime AND
+			calls.round = txs.round AND
+			calls.tx_index = txs.tx_index
+		LEFT JOIN chain.address_preimages AS from_preimage ON
+			calls.from_address = from_preimage.address AND
+			from_preimage.context_identifier = 'oasis-runtime-sdk/address: secp256k1eth' AND
+			from_preimage.context_version = 0
+		LEFT JOIN chain.address_preimages AS to_preimage ON
+			calls.to_address = to_preimage.address AND
+			to_preimage.context_identifier = 'oasis-runtime-sdk/address: secp256k1eth' AND
+			to_preimage.context_version = 0
+		WHERE
+			txs.runtime = $1 AND
+			(txs.tx_hash = $2::text OR txs.tx_eth_hash = $2::text)
+		ORDER BY calls.round, calls.tx_index, calls.call_index
+		LIMIT $3::bigint
+		OFFSET $4::bigint`
+
 	RuntimeEvents = `
 		SELECT
 			evs.round,
 			evs.tx_index,
+			evs.event_index,
 			evs.tx_hash,
 			evs.tx_eth_hash,
 			evs.timestamp,
@@ -557,6 +954,7 @@ const (
 			evs.body,
 			evs.evm_log_name,
 			evs.evm_log_params,
+			evs.evm_log_low_confidence,
 			tokens.symbol,
 			tokens.token_type,
 			tokens.decimals,
@@ -568,7 +966,12 @@ const (
 			pre_to.address_data AS to_preimage_address_data,
 			pre_owner.context_identifier AS owner_preimage_context_identifier,
 			pre_owner.context_version AS owner_preimage_context_version,
-			pre_owner.address_data AS owner_preimage_address_data
+			pre_owner.address_data AS owner_preimage_address_data,
+			(
+				SELECT jsonb_agg(jsonb_build_object('address', l.address, 'name', l.name, 'description', NULLIF(l.description, '')) ORDER BY l.name)
+				FROM chain.address_labels AS l
+				WHERE l.layer = evs.runtime::text AND l.address = ANY(evs.related_accounts)
+			) AS related_labels
 		FROM chain.runtime_events as evs
 		-- Look up the oasis-style address derived from evs.body.address.
 		-- The derivation is just a keccak hash and we could theoretically compute it instead of looking it up,
@@ -606,10 +1009,12 @@ const (
 				evs.evm_log_signature = '\xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef' AND
 				jsonb_array_length(evs.body -> 'topics') = 4 AND
 				evs.body -> 'topics' ->> 3 = $9::text
-			))
-		ORDER BY evs.round DESC, evs.tx_index, evs.type, evs.body::text
-		LIMIT $10::bigint
-		OFFSET $11::bigint`
+			)) AND
+			($10::bigint IS NULL OR evs.round < $10::bigint OR
+				(evs.round = $10::bigint AND evs.event_index > $11::integer))
+		ORDER BY evs.round DESC, evs.event_index
+		LIMIT $12::bigint
+		OFFSET $13::bigint`
 
 	RuntimeEvmContract = `
 		SELECT
@@ -627,8 +1032,17 @@ const (
 			), 0) AS gas_for_calling,
 			compilation_metadata,
 			source_files,
-			verification_level
+			verification_level,
+			impl.implementation_address,
+			eth_preimage(impl.implementation_address)
 		FROM chain.evm_contracts
+		LEFT JOIN LATERAL (
+			SELECT impls.implementation_address
+			FROM chain.evm_proxy_implementations AS impls
+			WHERE impls.runtime = $1 AND impls.proxy_address = $2::text
+			ORDER BY impls.round DESC
+			LIMIT 1
+		) AS impl ON TRUE
 		WHERE (runtime = $1) AND (contract_address = $2::text)`
 
 	AddressPreimage = `
@@ -636,6 +1050,37 @@ const (
 			FROM chain.address_preimages
 			WHERE address = $1::text`
 
+	// Inserts a request to verify contract $2, unless an identical request is
+	// pending or the contract already has $8 pending requests.
+	RuntimeEvmContractVerificationInsert = `
+		INSERT INTO analysis.evm_contract_verifications (runtime, contract_address, compiler_version, contract_name, sources, settings, input_hash)
+			SELECT $1, $2::text, $3, $4, $5, $6, $7
+			WHERE (
+				SELECT COUNT(*)
+				FROM analysis.evm_contract_verifications
+				WHERE runtime = $1 AND contract_address = $2::text AND status = 'pending'
+			) < $8::bigint
+			ON CONFLICT (runtime, contract_address, input_hash) WHERE status = 'pending' DO NOTHING
+			RETURNING id`
+
+	RuntimeEvmContractVerificationPending = `
+		SELECT id
+		FROM analysis.evm_contract_verifications
+		WHERE runtime = $1 AND contract_address = $2::text AND input_hash = $3 AND status = 'pending'`
+
+	RuntimeEvmContractVerification = `
+		SELECT
+			id,
+			contract_address,
+			eth_preimage(contract_address),
+			status,
+			verification_level,
+			error,
+			submitted_at,
+			processed_at
+		FROM analysis.evm_contract_verifications
+		WHERE (runtime = $1) AND (id = $2::bigint)`
+
 	RuntimeAccountStats = `
 		SELECT
 			total_sent, total_received, num_txs
@@ -644,6 +1089,58 @@ const (
 			(runtime = $1) AND
 			(address = $2::text)`
 
+	// The balance at the end of each day is the balance after its last change.
+	// Each change is anchored at the balance recorded with the next change that
+	// has one (e.g. processed in slow-sync mode), or at the current balance if
+	// there is none: the balance after a change is that of its anchor, minus the
+	// changes after it up to the anchor. $3 is either a native denomination
+	// symbol or an ERC-20 token address; the two never collide.
+	RuntimeAccountBalanceHistory = `
+		WITH current AS (
+			SELECT COALESCE(
+				(SELECT balance FROM chain.runtime_sdk_balances
+					WHERE (runtime = $1) AND (account_address = $2::text) AND (symbol = $3::text)),
+				(SELECT balance FROM chain.evm_token_balances
+					WHERE (runtime = $1) AND (account_address = $2::text) AND (token_address = $3::text)),
+				0
+			) AS balance
+		),
+		anchored AS (
+			SELECT
+				round, timestamp, delta, balance,
+				-- Changes with the same anchor: from a change with a balance, back to the
+				-- next one with a balance. Group 0 is anchored at the current balance.
+				COUNT(balance) OVER (ORDER BY round DESC ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS anchor
+			FROM chain.runtime_balance_changes
+			WHERE (runtime = $1) AND (account_address = $2::text) AND (token = $3::text)
+		),
+		balances AS (
+			SELECT
+				date_trunc('day', anchored.timestamp, 'UTC') AS day,
+				anchored.round,
+				anchored.delta,
+				COALESCE(FIRST_VALUE(anchored.balance) OVER w, current.balance) -
+					COALESCE(SUM(anchored.delta) OVER (w ROWS BETWEEN UNBOUNDED PRECEDING AND 1 PRECEDING), 0) AS balance
+			FROM anchored, current
+			WINDOW w AS (PARTITION BY anchored.anchor ORDER BY anchored.round DESC)
+		),
+		daily AS (
+			SELECT DISTINCT ON (day)
+				day,
+				balance,
+				SUM(delta) OVER (PARTITION BY day) AS change
+			FROM balances
+			ORDER BY day, round DESC
+		)
+		SELECT day, balance, change
+		FROM daily
+		WHERE ($4::timestamptz IS NULL OR day >= $4::timestamptz) AND
+				($5::timestamptz IS NULL OR day < $5::timestamptz) AND
+				($6::bigint IS NULL OR day < to_timestamp($6::bigint))
+		ORDER BY day DESC
+		LIMIT $7::bigint
+		OFFSET $8::bigint`
+
 	//nolint:gosec // Linter suspects a hardcoded access token.
 	EvmTokens = `
 		WITH holders AS (
@@ -662,78 +1159,239 @@ const (
 			tokens.num_transfers,
 			tokens.token_type AS type,
 			COALESCE(holders.cnt, 0) AS num_holders,
-			ref_swap_pair_creations.pair_address AS ref_swap_pair_address,
-			eth_preimage(ref_swap_pair_creations.pair_address) AS ref_swap_pair_address_eth,
-			ref_swap_pair_creations.factory_address AS ref_swap_factory_address,
-			eth_preimage(ref_swap_pair_creations.factory_address) AS ref_swap_factory_address_eth,
-			ref_swap_pair_creations.token0_address AS ref_swap_token0_address,
-			eth_preimage(ref_swap_pair_creations.token0_address) AS ref_swap_token0_address_eth,
-			ref_swap_pair_creations.token1_address AS ref_swap_token1_address,
-			eth_preimage(ref_swap_pair_creations.token1_address) AS ref_swap_token1_address_eth,
-			ref_swap_pair_creations.create_round AS ref_swap_create_round,
-			ref_swap_pairs.reserve0 AS ref_swap_reserve0,
-			ref_swap_pairs.reserve1 AS ref_swap_reserve1,
-			ref_swap_pairs.last_sync_round AS ref_swap_last_sync_round,
+			ref_swap.pair_address AS ref_swap_pair_address,
+			eth_preimage(ref_swap.pair_address) AS ref_swap_pair_address_eth,
+			ref_swap.factory_address AS ref_swap_factory_address,
+			eth_preimage(ref_swap.factory_address) AS ref_swap_factory_address_eth,
+			ref_swap.token0_address AS ref_swap_token0_address,
+			eth_preimage(ref_swap.token0_address) AS ref_swap_token0_address_eth,
+			ref_swap.token1_address AS ref_swap_token1_address,
+			eth_preimage(ref_swap.token1_address) AS ref_swap_token1_address_eth,
+			ref_swap.fee AS ref_swap_fee,
+			ref_swap.create_round AS ref_swap_create_round,
+			ref_swap.reserve0 AS ref_swap_reserve0,
+			ref_swap.reserve1 AS ref_swap_reserve1,
+			ref_swap.sqrt_price_x96 AS ref_swap_sqrt_price_x96,
+			ref_swap.last_sync_round AS ref_swap_last_sync_round,
 			ref_tokens.token_type AS ref_token_type,
 			ref_tokens.token_name AS ref_token_name,
 			ref_tokens.symbol AS ref_token_symbol,
 			ref_tokens.decimals AS ref_token_decimals,
-			contracts.verification_level
+			-- For proxies, the verification of the current implementation, which has the token's logic.
+			CASE WHEN impl.implementation_address IS NULL THEN contracts.verification_level ELSE impl_contracts.verification_level END AS verification_level,
+			impl.implementation_address,
+			eth_preimage(impl.implementation_address) AS implementation_address_eth,
+			ranking.market_cap
 		FROM chain.evm_tokens AS tokens
 		JOIN chain.address_preimages AS preimages ON (token_address = preimages.address AND preimages.context_identifier = 'oasis-runtime-sdk/address: secp256k1eth' AND preimages.context_version = 0)
 		LEFT JOIN holders USING (token_address)
-		LEFT JOIN chain.evm_swap_pair_creations AS ref_swap_pair_creations ON
-			ref_swap_pair_creations.runtime = tokens.runtime AND
-			ref_swap_pair_creations.factory_address = $4 AND
-			(
-				(ref_swap_pair_creations.token0_address = tokens.token_address AND ref_swap_pair_creations.token1_address = $5) OR
-				(ref_swap_pair_creations.token0_address = $5 AND ref_swap_pair_creations.token1_address = tokens.token_address)
-			)
-		LEFT JOIN chain.evm_swap_pairs AS ref_swap_pairs ON
-			ref_swap_pairs.runtime = tokens.runtime AND
-			ref_swap_pairs.pair_address = ref_swap_pair_creations.pair_address
+		-- A Uniswap V3 factory can have a pool per fee tier for the same tokens. Unless the fee tier
+		-- is given in $6, use the pool with the most liquidity of the reference token.
+		LEFT JOIN LATERAL (
+			SELECT
+				creations.pair_address,
+				creations.factory_address,
+				creations.token0_address,
+				creations.token1_address,
+				creations.fee,
+				creations.create_round,
+				pairs.reserve0,
+				pairs.reserve1,
+				pairs.sqrt_price_x96,
+				pairs.last_sync_round
+			FROM chain.evm_swap_pair_creations AS creations
+			LEFT JOIN chain.evm_swap_pairs AS pairs ON
+				pairs.runtime = creations.runtime AND
+				pairs.pair_address = creations.pair_address
+			WHERE
+				creations.runtime = tokens.runtime AND
+				creations.factory_address = $4 AND
+				(
+					(creations.token0_address = tokens.token_address AND creations.token1_address = $5) OR
+					(creations.token0_address = $5 AND creations.token1_address = tokens.token_address)
+				) AND
+				($6::integer = 0 OR creations.fee = $6::integer)
+			ORDER BY
+				CASE WHEN creations.token0_address = $5 THEN pairs.reserve0 ELSE pairs.reserve1 END DESC NULLS LAST,
+				creations.fee
+			LIMIT 1
+		) AS ref_swap ON TRUE
 		LEFT JOIN chain.evm_tokens AS ref_tokens ON
 			ref_tokens.runtime = tokens.runtime AND
 			ref_tokens.token_address = $5
 		LEFT JOIN chain.evm_contracts as contracts ON (tokens.runtime = contracts.runtime AND tokens.token_address = contracts.contract_address)
-		WHERE
-			(tokens.runtime = $1) AND
-			($2::oasis_addr IS NULL OR tokens.token_address = $2::oasis_addr) AND
-			($3::text IS NULL OR tokens.token_name ILIKE '%' || $3 || '%' OR tokens.symbol ILIKE '%' || $3 || '%') AND
-			tokens.token_type IS NOT NULL AND -- exclude token _candidates_ that we haven't inspected yet
-			tokens.token_type != 0 -- exclude unknown-type tokens; they're often just contracts that emitted Transfer events but don't expose the token ticker, name, balance etc.
-		ORDER BY
-			(
+		LEFT JOIN LATERAL (
+			SELECT impls.implementation_address
+			FROM chain.evm_proxy_implementations AS impls
+			WHERE impls.runtime = tokens.runtime AND impls.proxy_address = tokens.token_address
+			ORDER BY impls.round DESC
+			LIMIT 1
+		) AS impl ON TRUE
+		LEFT JOIN chain.evm_contracts AS impl_contracts ON (tokens.runtime = impl_contracts.runtime AND impl.implementation_address = impl_contracts.contract_address)
+		-- The market cap of the token in units of the reference token, by which the tokens are sorted.
+		LEFT JOIN LATERAL (
+			SELECT (
 				CASE
 					-- For the reference token itself, it is 1:1 in value with, you know, itself.
 					WHEN
 						tokens.token_address = $5
 					THEN 1.0
 					-- The pool keeps a proportion of reserves so that reserve0 of token0 is worth about as much as reserve1 of token1.
+					-- For Uniswap V3 pools, the virtual reserves have the same proportion.
 					-- When token0 is the reference token, more reserve0 means token1 is worth more than the reference token.
 					WHEN
-						ref_swap_pair_creations.token0_address = $5 AND
-						ref_swap_pairs.reserve0 IS NOT NULL AND
-						ref_swap_pairs.reserve0 > 0 AND
-						ref_swap_pairs.reserve1 IS NOT NULL AND
-						ref_swap_pairs.reserve1 > 0
-					THEN ref_swap_pairs.reserve0::REAL / ref_swap_pairs.reserve1::REAL
+						ref_swap.token0_address = $5 AND
+						ref_swap.reserve0 IS NOT NULL AND
+						ref_swap.reserve0 > 0 AND
+						ref_swap.reserve1 IS NOT NULL AND
+						ref_swap.reserve1 > 0
+					THEN ref_swap.reserve0::REAL / ref_swap.reserve1::REAL
 					-- When token1 is the reference token, more reserve1 means token0 is worth more than the reference token.
 					WHEN
-						ref_swap_pair_creations.token1_address = $5 AND
-						ref_swap_pairs.reserve0 IS NOT NULL AND
-						ref_swap_pairs.reserve0 > 0 AND
-						ref_swap_pairs.reserve1 IS NOT NULL AND
-						ref_swap_pairs.reserve1 > 0
-					THEN ref_swap_pairs.reserve1::REAL / ref_swap_pairs.reserve0::REAL
+						ref_swap.token1_address = $5 AND
+						ref_swap.reserve0 IS NOT NULL AND
+						ref_swap.reserve0 > 0 AND
+						ref_swap.reserve1 IS NOT NULL AND
+						ref_swap.reserve1 > 0
+					THEN ref_swap.reserve1::REAL / ref_swap.reserve0::REAL
 					ELSE 0.0
 				END *
 				COALESCE(tokens.total_supply, 0)
-			) DESC,
-		    num_holders DESC,
-		    contract_addr
-		LIMIT $6::bigint
-		OFFSET $7::bigint`
+			)::double precision AS market_cap
+		) AS ranking ON TRUE
+		WHERE
+			(tokens.runtime = $1) AND
+			($2::oasis_addr IS NULL OR tokens.token_address = $2::oasis_addr) AND
+			($3::text IS NULL OR tokens.token_name ILIKE '%' || $3 || '%' OR tokens.symbol ILIKE '%' || $3 || '%') AND
+			tokens.token_type IS NOT NULL AND -- exclude token _candidates_ that we haven't inspected yet
+			tokens.token_type != 0 AND -- exclude unknown-type tokens; they're often just contracts that emitted Transfer events but don't expose the token ticker, name, balance etc.
+			($7::double precision IS NULL OR ranking.market_cap < $7::double precision OR
+				(ranking.market_cap = $7::double precision AND (COALESCE(holders.cnt, 0) < $8::bigint OR
+					(COALESCE(holders.cnt, 0) = $8::bigint AND tokens.token_address > $9::text))))
+		ORDER BY
+			ranking.market_cap DESC,
+			num_holders DESC,
+			contract_addr
+		LIMIT $10::bigint
+		OFFSET $11::bigint`
+
+	// The swap pair of token $2 and the reference token $4, created by the
+	// reference swap factory $3, with fee tier $5 (0 for any), and the decimals
+	// of the two tokens.
+	EvmTokenReferenceSwapPair = `
+		SELECT
+			creations.pair_address,
+			creations.token0_address = $2::text AS is_token0,
+			token.decimals,
+			reference.decimals
+		FROM chain.evm_swap_pair_creations AS creations
+		LEFT JOIN chain.evm_swap_pairs AS pairs ON
+			pairs.runtime = creations.runtime AND
+			pairs.pair_address = creations.pair_address
+		LEFT JOIN chain.evm_tokens AS token ON
+			token.runtime = creations.runtime AND
+			token.token_address = $2::text
+		LEFT JOIN chain.evm_tokens AS reference ON
+			reference.runtime = creations.runtime AND
+			reference.token_address = $4::text
+		WHERE
+			creations.runtime = $1 AND
+			creations.factory_address = $3::text AND
+			((creations.token0_address = $2::text AND creations.token1_address = $4::text) OR (creations.token0_address = $4::text AND creations.token1_address = $2::text)) AND
+			($5::integer = 0 OR creations.fee = $5::integer)
+		-- Same choice of pool as in EvmTokens.
+		ORDER BY
+			CASE WHEN creations.token0_address = $4::text THEN pairs.reserve0 ELSE pairs.reserve1 END DESC NULLS LAST,
+			creations.fee
+		LIMIT 1`
+
+	// The candles of swap pair $2, with prices of token0 in units of token1.
+	// If $3 is false, the prices are inverted to be those of token1 in units of
+	// token0, and the volume is that of token1. The prices are multiplied by $7
+	// to adjust for the decimals of the tokens.
+	// If $8 is given, the prices are converted with the candles of swap pair $8,
+	// whose prices are adjusted the same way with $9 and $10: every price of a
+	// window is multiplied by the latest close price of pair $8 at or before the
+	// window. Windows before the first candle of pair $8 are left out.
+	EvmTokenPriceHistory = `
+		SELECT
+			candles.window_start,
+			CASE WHEN $3::boolean THEN candles.open ELSE 1 / candles.open END * $7::double precision * COALESCE(conversion.price, 1),
+			CASE WHEN $3::boolean THEN candles.high ELSE 1 / candles.low END * $7::double precision * COALESCE(conversion.price, 1),
+			CASE WHEN $3::boolean THEN candles.low ELSE 1 / candles.high END * $7::double precision * COALESCE(conversion.price, 1),
+			CASE WHEN $3::boolean THEN candles.close ELSE 1 / candles.close END * $7::double precision * COALESCE(conversion.price, 1),
+			CASE WHEN $3::boolean THEN candles.volume0 ELSE candles.volume1 END
+		FROM stats.evm_swap_pair_candles AS candles
+		LEFT JOIN LATERAL (
+			SELECT CASE WHEN $9::boolean THEN conversion_candles.close ELSE 1 / conversion_candles.close END * $10::double precision AS price
+			FROM stats.evm_swap_pair_candles AS conversion_candles
+			WHERE
+				conversion_candles.runtime = candles.runtime AND
+				conversion_candles.pair_address = $8::text AND
+				conversion_candles.interval = candles.interval AND
+				conversion_candles.window_start <= candles.window_start
+			ORDER BY conversion_candles.window_start DESC
+			LIMIT 1
+		) AS conversion ON $8::text IS NOT NULL
+		WHERE
+			candles.runtime = $1 AND
+			candles.pair_address = $2::text AND
+			candles.interval = $4::text AND
+			($5::timestamptz IS NULL OR candles.window_start >= $5::timestamptz) AND
+			($6::timestamptz IS NULL OR candles.window_start < $6::timestamptz) AND
+			($8::text IS NULL OR conversion.price IS NOT NULL)
+		ORDER BY candles.window_start DESC
+		LIMIT $11::bigint
+		OFFSET $12::bigint`
+
+	// Transfers of EVM tokens, optionally of token $2 and/or from or to account $3.
+	// If both $3 and $4 are given, only transfers between $3 and $4 are returned;
+	// if only $4 is given, only transfers from or to $4.
+	EvmTokenTransfers = `
+		SELECT
+			transfers.round,
+			transfers.event_index,
+			transfers.transfer_index,
+			transfers.tx_index,
+			transfers.tx_hash,
+			transfers.tx_eth_hash,
+			transfers.timestamp,
+			transfers.token_address,
+			eth_preimage(transfers.token_address),
+			transfers.token_type,
+			tokens.token_name,
+			tokens.symbol,
+			tokens.decimals,
+			transfers.from_address,
+			eth_preimage(transfers.from_address),
+			transfers.to_address,
+			eth_preimage(transfers.to_address),
+			transfers.token_id,
+			transfers.amount
+		FROM chain.evm_token_transfers AS transfers
+		LEFT JOIN chain.evm_tokens AS tokens ON
+			tokens.runtime = transfers.runtime AND
+			tokens.token_address = transfers.token_address AND
+			tokens.token_type IS NOT NULL -- exclude token _candidates_ that we haven't inspected yet
+		WHERE
+			(transfers.runtime = $1) AND
+			($2::text IS NULL OR transfers.token_address = $2::text) AND
+			($3::text IS NULL OR transfers.from_address = $3::text OR transfers.to_address = $3::text) AND
+			($4::text IS NULL OR (
+				CASE
+					WHEN $3::text IS NULL THEN transfers.from_address = $4::text OR transfers.to_address = $4::text
+					ELSE
+						(transfers.from_address = $3::text AND transfers.to_address = $4::text) OR
+						(transfers.from_address = $4::text AND transfers.to_address = $3::text)
+				END
+			)) AND
+			($5::timestamptz IS NULL OR transfers.timestamp >= $5::timestamptz) AND
+			($6::timestamptz IS NULL OR transfers.timestamp < $6::timestamptz) AND
+			($7::bigint IS NULL OR transfers.round < $7::bigint OR
+				(transfers.round = $7::bigint AND (transfers.event_index, transfers.transfer_index) > ($8::integer, $9::integer)))
+		ORDER BY transfers.round DESC, transfers.event_index, transfers.transfer_index
+		LIMIT $10::bigint
+		OFFSET $11::bigint`
 
 	//nolint:gosec // Linter suspects a hardcoded credentials token.
 	EvmTokenHolders = `
@@ -746,10 +1404,12 @@ const (
 		WHERE
 			(balances.runtime = $1::runtime) AND
 			(balances.token_address = $2::oasis_addr) AND
-			(balances.balance != 0)
+			(balances.balance != 0) AND
+			($3::numeric IS NULL OR balances.balance < $3::numeric OR
+				(balances.balance = $3::numeric AND balances.account_address > $4::text))
 		ORDER BY balance DESC, holder_addr
-		LIMIT $3::bigint
-		OFFSET $4::bigint`
+		LIMIT $5::bigint
+		OFFSET $6::bigint`
 
 	EvmNfts = `
 		WITH
@@ -773,7 +1433,8 @@ const (
 			COALESCE(token_holders.num_holders, 0) AS num_holders,
 			chain.evm_contracts.verification_level,
 			chain.evm_nfts.nft_id,
-			chain.evm_nfts.owner,
+			COALESCE(chain.evm_nfts.owner, owner_balance.account_address) AS owner,
+			owner_balance.balance,
 			owner_preimage.context_identifier,
 			owner_preimage.context_version,
 			owner_preimage.address_data,
@@ -792,16 +1453,25 @@ const (
 		LEFT JOIN chain.evm_contracts ON
 			chain.evm_contracts.runtime = chain.evm_tokens.runtime AND
 			chain.evm_contracts.contract_address = chain.evm_tokens.token_address
+		-- ERC-1155 token IDs have no single owner. When filtering by owner, join the
+		-- owner's balance of the ID instead.
+		LEFT JOIN chain.evm_nft_balances AS owner_balance ON
+			owner_balance.runtime = chain.evm_nfts.runtime AND
+			owner_balance.token_address = chain.evm_nfts.token_address AND
+			owner_balance.nft_id = chain.evm_nfts.nft_id AND
+			owner_balance.account_address = $4::oasis_addr AND
+			owner_balance.balance != 0
 		LEFT JOIN chain.address_preimages AS owner_preimage ON
-			owner_preimage.address = chain.evm_nfts.owner
+			owner_preimage.address = COALESCE(chain.evm_nfts.owner, owner_balance.account_address)
 		WHERE
 			chain.evm_nfts.runtime = $1::runtime AND
 			($2::oasis_addr IS NULL OR chain.evm_nfts.token_address = $2::oasis_addr) AND
 			($3::uint_numeric IS NULL OR chain.evm_nfts.nft_id = $3::uint_numeric) AND
-			($4::oasis_addr IS NULL OR chain.evm_nfts.owner = $4::oasis_addr)
+			($4::oasis_addr IS NULL OR chain.evm_nfts.owner = $4::oasis_addr OR owner_balance.account_address IS NOT NULL) AND
+			($5::text IS NULL OR (chain.evm_nfts.token_address, chain.evm_nfts.nft_id) > ($5::text, $6::numeric))
 		ORDER BY token_address, nft_id
-		LIMIT $5::bigint
-		OFFSET $6::bigint`
+		LIMIT $7::bigint
+		OFFSET $8::bigint`
 
 	AccountRuntimeSdkBalances = `
 		SELECT
@@ -875,6 +1545,189 @@ const (
 		OFFSET $3::bigint
 	`
 
+	// Search returns the entities matching the interpretations of a search query:
+	// $1 as a block height or round or a proposal ID, $2 as a block or transaction hash,
+	// $3 as an address, and $4 as a name. $5 is the ILIKE pattern of names that contain $4.
+	// Uninterpreted parameters are NULL and match nothing.
+	Search = `
+		WITH
+			runtimes AS (
+				SELECT unnest(enum_range(NULL::runtime)) AS runtime
+			),
+			results (type, layer, id, eth_hash, name, score) AS (
+				-- Blocks by height or round.
+				SELECT 'block'::text, 'consensus'::text, height::text, NULL::text, NULL::text, 1::real
+				FROM chain.blocks
+				WHERE height = $1::bigint
+				UNION ALL
+				SELECT 'block', blocks.runtime::text, blocks.round::text, NULL, NULL, 1
+				FROM runtimes
+				JOIN chain.runtime_blocks AS blocks ON blocks.runtime = runtimes.runtime AND blocks.round = $1::bigint
+				UNION ALL
+				-- Blocks by hash.
+				SELECT 'block', 'consensus', height::text, NULL, NULL, 1
+				FROM chain.blocks
+				WHERE block_hash = $2::text
+				UNION ALL
+				SELECT 'block', runtime::text, round::text, NULL, NULL, 1
+				FROM chain.runtime_blocks
+				WHERE block_hash = $2::text
+				UNION ALL
+				-- Transactions by hash.
+				SELECT 'transaction', 'consensus', tx_hash, NULL, NULL, 1
+				FROM chain.transactions
+				WHERE tx_hash = $2::text
+				UNION ALL
+				SELECT 'transaction', runtime::text, tx_hash, tx_eth_hash, NULL, 1
+				FROM chain.runtime_transactions
+				WHERE tx_hash = $2::text OR tx_eth_hash = $2::text
+				UNION ALL
+				-- Accounts by address or label.
+				SELECT 'account', 'consensus', address, NULL, NULL, 1
+				FROM chain.accounts
+				WHERE address = $3::text
+				UNION ALL
+				SELECT 'account', accounts.runtime::text, accounts.address, NULL, NULL, 1
+				FROM runtimes
+				JOIN chain.runtime_accounts AS accounts ON accounts.runtime = runtimes.runtime AND accounts.address = $3::text
+				UNION ALL
+				SELECT 'account', layer, address, NULL, name, similarity(name, $4::text)
+				FROM chain.address_labels
+				WHERE name % $4::text OR name ILIKE $5::text
+				UNION ALL
+				-- Entities and validators by address or name.
+				SELECT
+					CASE
+						WHEN EXISTS(SELECT NULL FROM chain.nodes WHERE chain.entities.id = chain.nodes.entity_id AND chain.nodes.roles LIKE '%validator%') THEN 'validator'
+						ELSE 'entity'
+					END,
+					'consensus',
+					address,
+					NULL,
+					meta->>'name',
+					CASE WHEN address = $3::text THEN 1 ELSE similarity(meta->>'name', $4::text) END
+				FROM chain.entities
+				WHERE address = $3::text OR meta->>'name' % $4::text OR meta->>'name' ILIKE $5::text
+				UNION ALL
+				-- Tokens by address, name or symbol.
+				SELECT 'token', tokens.runtime::text, tokens.token_address, NULL, COALESCE(tokens.token_name, tokens.symbol), 1
+				FROM runtimes
+				JOIN chain.evm_tokens AS tokens ON tokens.runtime = runtimes.runtime AND tokens.token_address = $3::text
+				WHERE tokens.token_type IS NOT NULL AND tokens.token_type != 0
+				UNION ALL
+				SELECT
+					'token',
+					runtime::text,
+					token_address,
+					NULL,
+					COALESCE(token_name, symbol),
+					GREATEST(similarity(token_name, $4::text), similarity(symbol, $4::text))
+				FROM chain.evm_tokens
+				WHERE
+					(token_type IS NOT NULL AND token_type != 0) AND
+					(token_name % $4::text OR token_name ILIKE $5::text OR
+						symbol % $4::text OR symbol ILIKE $5::text)
+				UNION ALL
+				-- Proposals by ID or title.
+				SELECT 'proposal', 'consensus', id::text, NULL, title, CASE WHEN id = $1::bigint THEN 1 ELSE similarity(title, $4::text) END
+				FROM chain.proposals
+				WHERE id = $1::bigint OR title % $4::text OR title ILIKE $5::text
+			)
+		SELECT
+			r.type,
+			r.layer,
+			r.id,
+			r.eth_hash,
+			CASE WHEN r.type IN ('account', 'token') THEN eth_preimage(r.id::oasis_addr) END AS eth_address,
+			COALESCE(r.name, labels.name) AS name,
+			r.score
+		FROM (
+			-- An entity can match several interpretations of the query; keep its best match.
+			SELECT DISTINCT ON (type, layer, id) *
+			FROM results
+			ORDER BY type, layer, id, score DESC
+		) AS r
+		LEFT JOIN chain.address_labels AS labels ON
+			r.type = 'account' AND
+			labels.layer = r.layer AND
+			labels.address = r.id
+		ORDER BY r.score DESC, r.type, r.layer, r.id
+		LIMIT $6::bigint`
+
+	// $2 is the ILIKE pattern of names and addresses to match, with \ as the escape character.
+	AddressLabels = `
+		SELECT address, name, description
+		FROM chain.address_labels
+		WHERE
+			(layer = $1::text) AND
+			($2::text IS NULL OR name ILIKE $2::text ESCAPE '\' OR address ILIKE $2::text ESCAPE '\')
+		ORDER BY name, address
+		LIMIT $3::bigint
+		OFFSET $4::bigint`
+
+	// Plain calls are only counted per contract, along with the encrypted calls in an EVM envelope.
+	EncryptionCallCounts = `
+		SELECT
+			COALESCE((
+				SELECT num_evm_encrypted_calls + num_oasis_encrypted_calls
+				FROM views.runtime_encrypted_calls
+				WHERE runtime = $1::runtime
+			), 0)::bigint,
+			COALESCE((
+				SELECT SUM(num_plain_calls)
+				FROM views.runtime_encryption_contracts
+				WHERE runtime = $1::runtime
+			), 0)::bigint`
+
+	EncryptedCallErrors = `
+		SELECT error_module, error_code, decryption_failure, num_calls, last_round
+		FROM views.runtime_encrypted_call_errors
+		WHERE runtime = $1::runtime
+		ORDER BY num_calls DESC, error_module, error_code`
+
+	EncryptionKeyEpochs = `
+		SELECT epoch, num_calls, first_round, last_round
+		FROM views.runtime_encryption_key_epochs
+		WHERE runtime = $1::runtime
+		ORDER BY epoch DESC`
+
+	// Counted per round rather than from a view, so that the latest rounds are included.
+	EncryptionRounds = `
+		SELECT
+			blocks.round,
+			blocks.timestamp,
+			calls.num_encrypted_calls,
+			calls.num_plain_calls
+		FROM chain.runtime_blocks AS blocks
+		CROSS JOIN LATERAL (
+			SELECT
+				COUNT(*) FILTER (WHERE txs.evm_encrypted_format IS NOT NULL OR txs.oasis_encrypted_format IS NOT NULL) AS num_encrypted_calls,
+				COUNT(*) FILTER (WHERE txs.evm_encrypted_format IS NULL AND txs.oasis_encrypted_format IS NULL) AS num_plain_calls
+			FROM chain.runtime_transactions AS txs
+			WHERE
+				txs.runtime = blocks.runtime AND
+				txs.round = blocks.round AND
+				(txs.method = 'evm.Call' OR txs.oasis_encrypted_format IS NOT NULL)
+		) AS calls
+		WHERE blocks.runtime = $1::runtime
+		ORDER BY blocks.round DESC
+		LIMIT $2::bigint
+		OFFSET $3::bigint`
+
+	EncryptionContracts = `
+		SELECT
+			stats.contract_address,
+			eth_preimage(stats.contract_address),
+			stats.num_encrypted_calls,
+			stats.num_plain_calls,
+			stats.num_failed_encrypted_calls,
+			stats.last_round
+		FROM views.runtime_encryption_contracts AS stats
+		WHERE stats.runtime = $1::runtime
+		ORDER BY stats.num_encrypted_calls DESC, stats.contract_address
+		LIMIT $2::bigint
+		OFFSET $3::bigint`
+
 	// FineDailyActiveAccounts returns the fine-grained query for daily active account windows.
 	FineDailyActiveAccounts = `
 		SELECT window_end, active_accounts
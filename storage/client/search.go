@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	apiTypes "github.com/oasisprotocol/nexus/api/v1/types"
+	"github.com/oasisprotocol/nexus/common"
+	"github.com/oasisprotocol/nexus/storage/client/queries"
+)
+
+// searchTerms are the interpretations of a search query. Nil terms do not apply.
+type searchTerms struct {
+	// A block height or round, or a proposal ID.
+	height *int64
+	// A block or transaction hash, in lowercase hex without a 0x prefix.
+	hash *string
+	// An Oasis address; Ethereum addresses are converted to their Oasis address.
+	address *string
+	// A name to match approximately.
+	name *string
+	// An ILIKE pattern of the names that contain name.
+	namePattern *string
+}
+
+func parseSearchQuery(q string) searchTerms {
+	q = strings.TrimSpace(q)
+	var terms searchTerms
+
+	if height, err := strconv.ParseInt(q, 10, 64); err == nil && height >= 0 {
+		terms.height = &height
+	}
+	if hash := strings.ToLower(strings.TrimPrefix(q, "0x")); len(hash) == 64 {
+		if _, err := hex.DecodeString(hash); err == nil {
+			terms.hash = &hash
+		}
+	}
+	if address, err := apiTypes.UnmarshalToOcAddress(&q); err == nil {
+		terms.address = common.Ptr(address.String())
+	}
+	if terms.height == nil && terms.hash == nil && terms.address == nil && q != "" {
+		terms.name = &q
+		terms.namePattern = common.Ptr("%" + escapeLikePattern(q) + "%")
+	}
+
+	return terms
+}
+
+// escapeLikePattern escapes the characters that have a special meaning in
+// (I)LIKE patterns, so that s only matches itself.
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// apiLayer is the inverse of translateLayer.
+func apiLayer(layer common.Layer) apiTypes.Layer {
+	switch layer {
+	case common.LayerPontusxTest:
+		return apiTypes.LayerPontusxtest
+	case common.LayerPontusxDev:
+		return apiTypes.LayerPontusxdev
+	default:
+		return apiTypes.Layer(layer)
+	}
+}
+
+// Search returns the entities that match a search query, best match first.
+func (c *StorageClient) Search(ctx context.Context, p apiTypes.GetSearchParams) (*SearchResultList, error) {
+	terms := parseSearchQuery(p.Q)
+	rows, err := c.db.Query(
+		ctx,
+		queries.Search,
+		terms.height,
+		terms.hash,
+		terms.address,
+		terms.name,
+		terms.namePattern,
+		p.Limit,
+	)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	rs := SearchResultList{
+		Results: []SearchResult{},
+	}
+	for rows.Next() {
+		var r SearchResult
+		var layer common.Layer
+		var ethAddress []byte
+		if err := rows.Scan(
+			&r.Type,
+			&layer,
+			&r.Id,
+			&r.EthId,
+			&ethAddress,
+			&r.Name,
+			&r.Score,
+		); err != nil {
+			return nil, wrapError(err)
+		}
+		r.Layer = apiLayer(layer)
+		if ethAddress != nil {
+			r.EthId = EthChecksumAddrPtrFromBarePreimage(ethAddress)
+		}
+		rs.Results = append(rs.Results, r)
+	}
+
+	return &rs, nil
+}
@@ -0,0 +1,95 @@
+package client_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apiTypes "github.com/oasisprotocol/nexus/api/v1/types"
+	"github.com/oasisprotocol/nexus/config"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage"
+	"github.com/oasisprotocol/nexus/storage/client"
+	"github.com/oasisprotocol/nexus/tests"
+)
+
+// TestSearchEscapesNamePatterns tests that the characters with a special
+// meaning in ILIKE patterns only match themselves in searched names.
+func TestSearchEscapesNamePatterns(t *testing.T) {
+	tests.SkipIfShort(t)
+	ctx := context.Background()
+	db := setupDB(t)
+
+	// Queries of only these characters have no trigrams, so they can only
+	// match by substring.
+	labels := map[string]string{
+		"percent":    "Yield 100%",
+		"underscore": "snake_case",
+		"backslash":  `back\slash`,
+		"plain":      "Common Pool",
+	}
+	batch := &storage.QueryBatch{}
+	for name, label := range labels {
+		batch.Queue(`INSERT INTO chain.address_labels (layer, address, name, source) VALUES ('consensus', $1, $2, 'registry')`, testAddress(name), label)
+	}
+	require.NoError(t, db.SendBatch(ctx, batch))
+
+	c, err := client.NewStorageClient(config.SourceConfig{}, db, nil, nil, nil, log.NewDefaultLogger("testing"))
+	require.NoError(t, err)
+	for _, tc := range []struct {
+		q        string
+		expected string
+	}{
+		{"%", "percent"},
+		{"_", "underscore"},
+		{`\`, "backslash"},
+	} {
+		limit := uint64(10)
+		res, err := c.Search(ctx, apiTypes.GetSearchParams{Q: tc.q, Limit: &limit})
+		require.NoError(t, err, tc.q)
+		require.Len(t, res.Results, 1, tc.q)
+		require.Equal(t, apiTypes.Layer("consensus"), res.Results[0].Layer, tc.q)
+		require.Equal(t, string(testAddress(tc.expected)), res.Results[0].Id, tc.q)
+		require.Equal(t, labels[tc.expected], *res.Results[0].Name, tc.q)
+	}
+}
+
+// TestAddressLabelsEscapesPatterns tests that the characters with a special
+// meaning in ILIKE patterns only match themselves in filtered labels.
+func TestAddressLabelsEscapesPatterns(t *testing.T) {
+	tests.SkipIfShort(t)
+	ctx := context.Background()
+	db := setupDB(t)
+
+	labels := map[string]string{
+		"percent":    "Yield 100%",
+		"underscore": "snake_case",
+		"backslash":  `back\slash`,
+		"plain":      "Common Pool",
+	}
+	batch := &storage.QueryBatch{}
+	for name, label := range labels {
+		batch.Queue(`INSERT INTO chain.address_labels (layer, address, name, source) VALUES ('consensus', $1, $2, 'registry')`, testAddress(name), label)
+	}
+	require.NoError(t, db.SendBatch(ctx, batch))
+
+	c, err := client.NewStorageClient(config.SourceConfig{}, db, nil, nil, nil, log.NewDefaultLogger("testing"))
+	require.NoError(t, err)
+	for _, tc := range []struct {
+		q        string
+		expected string
+	}{
+		{"%", "percent"},
+		{"_", "underscore"},
+		{`\`, "backslash"},
+		{"common", "plain"},
+	} {
+		limit, offset := uint64(10), uint64(0)
+		q := tc.q
+		res, err := c.AddressLabels(ctx, apiTypes.LayerConsensus, apiTypes.GetLayerLabelsParams{Q: &q, Limit: &limit, Offset: &offset})
+		require.NoError(t, err, tc.q)
+		require.Len(t, res.Labels, 1, tc.q)
+		require.Equal(t, labels[tc.expected], res.Labels[0].Name, tc.q)
+	}
+}
@@ -0,0 +1,40 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/nexus/common"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	const txHash = "0d0531d6b8a468c07440182b1cdda517f5a076d69fb2199126a83082ecfc0f41"
+	const oasisAddr = "oasis1qpg2xuz46g53737343r20yxeddhlvc2ldqsjh70p"
+
+	for _, tc := range []struct {
+		q        string
+		expected searchTerms
+	}{
+		{q: "8048956", expected: searchTerms{height: common.Ptr(int64(8048956))}},
+		{q: " 8048956\n", expected: searchTerms{height: common.Ptr(int64(8048956))}},
+		{q: txHash, expected: searchTerms{hash: common.Ptr(txHash)}},
+		{q: "0x0D0531D6B8A468C07440182B1CDDA517F5A076D69FB2199126A83082ECFC0F41", expected: searchTerms{hash: common.Ptr(txHash)}},
+		{q: oasisAddr, expected: searchTerms{address: common.Ptr(oasisAddr)}},
+		{q: "Common Pool", expected: searchTerms{name: common.Ptr("Common Pool"), namePattern: common.Ptr("%Common Pool%")}},
+		{q: "-1", expected: searchTerms{name: common.Ptr("-1"), namePattern: common.Ptr("%-1%")}},
+		{q: `100%_a\b`, expected: searchTerms{name: common.Ptr(`100%_a\b`), namePattern: common.Ptr(`%100\%\_a\\b%`)}},
+		{q: "", expected: searchTerms{}},
+	} {
+		require.Equal(t, tc.expected, parseSearchQuery(tc.q), tc.q)
+	}
+
+	// Ethereum addresses are converted to Oasis addresses.
+	terms := parseSearchQuery("0xd8A2Ae03f6Edd58999a0F1005db7a6532F2AA79e")
+	require.Nil(t, terms.height)
+	require.Nil(t, terms.hash)
+	require.Nil(t, terms.name)
+	require.Nil(t, terms.namePattern)
+	require.NotNil(t, terms.address)
+	require.Regexp(t, "^oasis1[a-z0-9]{40}$", *terms.address)
+}
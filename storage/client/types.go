@@ -51,6 +51,12 @@ type AccountList = api.AccountList
 // Account is the storage response for GetAccount.
 type Account = api.Account
 
+// AccountBalanceHistory is the storage response for GetAccountBalanceHistory.
+type AccountBalanceHistory = api.AccountBalanceHistory
+
+// AccountBalanceHistoryPoint is the balances of an account at a block in which they changed.
+type AccountBalanceHistoryPoint = api.AccountBalanceHistoryPoint
+
 // DebondingDelegationList is the storage response for ListDebondingDelegations.
 type DebondingDelegationList = api.DebondingDelegationList
 
@@ -79,11 +85,47 @@ type Proposal = api.Proposal
 
 type ProposalTarget = api.ProposalTarget
 
+// ProposalTally is the voting power behind each option of a proposal.
+type ProposalTally = api.ProposalTally
+
+// ProposalTallyHistory is the storage response for GetProposalTallyHistory.
+type ProposalTallyHistory = api.ProposalTallyHistory
+
+// ProposalNonVotingValidator is a validator that did not vote on a proposal.
+type ProposalNonVotingValidator = api.ProposalNonVotingValidator
+
 // ProposalVotes is the storage response for GetProposalVotes.
 type ProposalVotes = api.ProposalVotes
 
 type ProposalVote = api.ProposalVote
 
+// VaultList is the storage response for GetConsensusVaults.
+type VaultList = api.VaultList
+
+// Vault is the storage response for GetConsensusVaultsAddress.
+type Vault = api.Vault
+
+// VaultAuthority is a multisig authority of a vault.
+type VaultAuthority = api.VaultAuthority
+
+// VaultPendingAction is a vault action that is waiting for authorizations.
+type VaultPendingAction = api.VaultPendingAction
+
+// KeyManagerList is the storage response for GetConsensusKeymanagers.
+type KeyManagerList = api.KeyManagerList
+
+// KeyManager is a key manager runtime with its latest status.
+type KeyManager = api.KeyManager
+
+// KeyManagerStatusHistory is the storage response for GetConsensusKeymanagersRuntimeIdHistory.
+type KeyManagerStatusHistory = api.KeyManagerStatusHistory
+
+// KeyManagerStatus is the status of a key manager at some epoch.
+type KeyManagerStatus = api.KeyManagerStatus
+
+// ChurpStatus is the status of a CHURP instance of a key manager at some epoch.
+type ChurpStatus = api.ChurpStatus
+
 // ValidatorList is the list of consensus validators.
 type ValidatorList = api.ValidatorList
 
@@ -108,6 +150,21 @@ type ValidatorHistory = api.ValidatorHistory
 // ValidatorHistoryPoint is the escrow information for a validator at a given epoch.
 type ValidatorHistoryPoint = api.ValidatorHistoryPoint
 
+// DelegatorRewardList is the storage response for GetConsensusAccountsAddressRewards.
+type DelegatorRewardList = api.DelegatorRewardList
+
+// DelegatorReward is the staking reward of a delegation in a given epoch.
+type DelegatorReward = api.DelegatorReward
+
+// ValidatorUptime is the storage response for GetValidatorUptime.
+type ValidatorUptime = api.ValidatorUptime
+
+// ValidatorUptimeWindow is the block signing information for a validator over the most recent blocks.
+type ValidatorUptimeWindow = api.ValidatorUptimeWindow
+
+// ValidatorEpochUptime is the block signing information for a validator in a given epoch.
+type ValidatorEpochUptime = api.ValidatorEpochUptime
+
 // RuntimeBlockList is the storage response for RuntimeListBlocks.
 type RuntimeBlockList = api.RuntimeBlockList
 
@@ -122,6 +179,12 @@ type (
 	TxError                              = api.TxError
 )
 
+// RuntimeInternalCallList is the storage response for RuntimeInternalCalls.
+type (
+	RuntimeInternalCallList = api.RuntimeInternalCallList
+	RuntimeInternalCall     = api.RuntimeInternalCall
+)
+
 // RuntimeEventList is the storage response for RuntimeEvents.
 type RuntimeEventList = api.RuntimeEventList
 
@@ -144,6 +207,13 @@ type (
 
 type RuntimeAccount = api.RuntimeAccount
 
+type (
+	RuntimeAccountBalanceHistory      = api.RuntimeAccountBalanceHistory
+	RuntimeAccountBalanceHistoryPoint = api.RuntimeAccountBalanceHistoryPoint
+)
+
+type EvmContractVerificationRequest = api.EvmContractVerificationRequest
+
 type AccountStats = api.AccountStats
 
 type EvmTokenList = api.EvmTokenList
@@ -154,10 +224,40 @@ type BareTokenHolder = api.BareTokenHolder
 
 type TokenHolderList = api.TokenHolderList
 
+type (
+	EvmTokenTransferList = api.EvmTokenTransferList
+	EvmTokenTransfer     = api.EvmTokenTransfer
+)
+
+type (
+	EvmTokenPriceHistory = api.EvmTokenPriceHistory
+	EvmTokenPriceCandle  = api.EvmTokenPriceCandle
+)
+
 type EvmNft = api.EvmNft
 
 type EvmNftList = api.EvmNftList
 
+type (
+	SearchResultList = api.SearchResultList
+	SearchResult     = api.SearchResult
+)
+
+type (
+	AddressLabelList = api.AddressLabelList
+	AddressLabel     = api.AddressLabel
+)
+
+type (
+	EncryptionStats             = api.EncryptionStats
+	EncryptedCallError          = api.EncryptedCallError
+	EncryptionKeyEpoch          = api.EncryptionKeyEpoch
+	EncryptionRoundStatsList    = api.EncryptionRoundStatsList
+	EncryptionRoundStats        = api.EncryptionRoundStats
+	EncryptionContractStatsList = api.EncryptionContractStatsList
+	EncryptionContractStats     = api.EncryptionContractStats
+)
+
 // TxVolumeList is the storage response for GetVolumes.
 type TxVolumeList = api.TxVolumeList
 
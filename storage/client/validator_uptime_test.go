@@ -0,0 +1,98 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	coreStaking "github.com/oasisprotocol/oasis-core/go/staking/api"
+
+	"github.com/oasisprotocol/nexus/analyzer/queries"
+	apiTypes "github.com/oasisprotocol/nexus/api/v1/types"
+	"github.com/oasisprotocol/nexus/config"
+	"github.com/oasisprotocol/nexus/log"
+	"github.com/oasisprotocol/nexus/storage"
+	"github.com/oasisprotocol/nexus/storage/client"
+	"github.com/oasisprotocol/nexus/tests"
+)
+
+// TestValidatorUptime tests the signed, missed and proposed blocks of validators,
+// per epoch as computed by the validator_uptime analyzer, and over the latest blocks.
+func TestValidatorUptime(t *testing.T) {
+	tests.SkipIfShort(t)
+	ctx := context.Background()
+	db := setupDB(t)
+
+	var a, b signature.PublicKey
+	a[0], b[0] = 1, 2
+	batch := &storage.QueryBatch{}
+	for _, entity := range []signature.PublicKey{a, b} {
+		batch.Queue(`INSERT INTO chain.entities (id, address) VALUES ($1, $2)`, entity.String(), coreStaking.NewAddress(entity).String())
+	}
+	// Epoch 1 has blocks 1-4 and validators a and b; epoch 2 has blocks 5-6 and only validator a.
+	batch.Queue(`INSERT INTO chain.epochs (id, start_height, end_height) VALUES (1, 1, 4), (2, 5, 6)`)
+	for _, block := range []struct {
+		height   int64
+		epoch    uint64
+		proposer signature.PublicKey
+		signers  []string
+	}{
+		{1, 1, a, []string{a.String(), b.String()}},
+		{2, 1, b, []string{a.String()}},
+		{3, 1, a, []string{a.String(), b.String()}},
+		{4, 1, a, nil}, // The signers are not known yet.
+		{5, 2, a, []string{a.String(), b.String()}},
+		{6, 2, a, []string{a.String()}},
+	} {
+		batch.Queue(`
+    INSERT INTO chain.blocks (height, block_hash, time, num_txs, epoch, namespace, version, state_root, proposer_entity_id, signer_entity_ids)
+      VALUES ($1, $2, $3, 0, $4, '', 0, $2, $5, $6)`,
+			block.height, fmt.Sprintf("%064x", block.height), time.Unix(block.height, 0), block.epoch, block.proposer.String(), block.signers)
+	}
+	batch.Queue(queries.EpochActiveValidatorsUpdate, 1, []string{a.String(), b.String()})
+	batch.Queue(queries.EpochActiveValidatorsUpdate, 2, []string{a.String()})
+	batch.Queue(queries.ValidatorUptimesUpsert, 1)
+	batch.Queue(queries.ValidatorUptimesUpsert, 2)
+	require.NoError(t, db.SendBatch(ctx, batch))
+
+	c, err := client.NewStorageClient(config.SourceConfig{}, db, nil, nil, nil, log.NewDefaultLogger("testing"))
+	require.NoError(t, err)
+	limit, offset := uint64(10), uint64(0)
+	uptime := func(entity signature.PublicKey) *client.ValidatorUptime {
+		u, err := c.ValidatorUptime(ctx, coreStaking.NewAddress(entity), apiTypes.GetConsensusValidatorsAddressUptimeParams{Limit: &limit, Offset: &offset})
+		require.NoError(t, err)
+		return u
+	}
+
+	// Blocks with unknown signers are not missed, but their proposer is known.
+	ua := uptime(a)
+	require.Equal(t, []client.ValidatorEpochUptime{
+		{Epoch: 2, SignedBlocks: 2, MissedBlocks: 0, ProposedBlocks: 2},
+		{Epoch: 1, SignedBlocks: 3, MissedBlocks: 0, ProposedBlocks: 3},
+	}, ua.Epochs)
+	require.Equal(t, []client.ValidatorUptimeWindow{
+		{WindowLength: 100, SignedBlocks: 5, MissedBlocks: 0, ProposedBlocks: 5},
+		{WindowLength: 1000, SignedBlocks: 5, MissedBlocks: 0, ProposedBlocks: 5},
+	}, ua.Windows)
+
+	// Blocks of epochs in which the validator was not active do not count.
+	ub := uptime(b)
+	require.Equal(t, []client.ValidatorEpochUptime{
+		{Epoch: 1, SignedBlocks: 2, MissedBlocks: 1, ProposedBlocks: 1},
+	}, ub.Epochs)
+	require.Equal(t, client.ValidatorUptimeWindow{WindowLength: 100, SignedBlocks: 2, MissedBlocks: 1, ProposedBlocks: 1}, ub.Windows[0])
+
+	// Recomputing an epoch overwrites its uptimes.
+	batch = &storage.QueryBatch{}
+	batch.Queue(`UPDATE chain.blocks SET signer_entity_ids = $1 WHERE height = 2`, []string{a.String(), b.String()})
+	batch.Queue(queries.ValidatorUptimesUpsert, 1)
+	require.NoError(t, db.SendBatch(ctx, batch))
+	ub = uptime(b)
+	require.Equal(t, []client.ValidatorEpochUptime{
+		{Epoch: 1, SignedBlocks: 3, MissedBlocks: 0, ProposedBlocks: 1},
+	}, ub.Epochs)
+}
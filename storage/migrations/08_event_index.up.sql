@@ -0,0 +1,35 @@
+BEGIN;
+
+-- The position of an event among all events of its block (consensus) or round (runtime).
+-- Together with the block height/round, it uniquely identifies an event and is used
+-- as the sort key for cursor-based pagination.
+ALTER TABLE chain.events ADD COLUMN event_index UINT31;
+ALTER TABLE chain.runtime_events ADD COLUMN event_index UINT31;
+
+-- The original order of already-indexed events is not recorded. Number them in the
+-- order in which the API used to list them.
+UPDATE chain.events AS evs
+  SET event_index = numbered.event_index
+  FROM (
+    SELECT ctid, ROW_NUMBER() OVER (PARTITION BY tx_block ORDER BY tx_index, type, body::text) - 1 AS event_index
+    FROM chain.events
+  ) AS numbered
+  WHERE evs.ctid = numbered.ctid;
+UPDATE chain.runtime_events AS evs
+  SET event_index = numbered.event_index
+  FROM (
+    SELECT ctid, ROW_NUMBER() OVER (PARTITION BY runtime, round ORDER BY tx_index, type, body::text) - 1 AS event_index
+    FROM chain.runtime_events
+  ) AS numbered
+  WHERE evs.ctid = numbered.ctid;
+
+ALTER TABLE chain.events ALTER COLUMN event_index SET NOT NULL;
+ALTER TABLE chain.runtime_events ALTER COLUMN event_index SET NOT NULL;
+
+-- Supersede the indexes for listing events by recency, to also cover the tie-breaker.
+CREATE INDEX ix_events_tx_block_event_index ON chain.events (tx_block, event_index);
+DROP INDEX chain.ix_events_tx_block;
+CREATE INDEX ix_runtime_events_round_event_index ON chain.runtime_events (runtime, round, event_index);
+DROP INDEX chain.ix_runtime_events_round;
+
+COMMIT;
@@ -0,0 +1,23 @@
+BEGIN;
+
+-- Per-ID balances of ERC-1155 tokens. Unlike an ERC-721 NFT instance, which has a
+-- single owner (chain.evm_nfts.owner), an ERC-1155 token ID can be held by many
+-- accounts at once, in varying amounts. The account's balance across all IDs of a
+-- token is tracked in chain.evm_token_balances, like for other token types.
+CREATE TABLE chain.evm_nft_balances
+(
+  runtime runtime NOT NULL,
+  token_address oasis_addr NOT NULL,
+  nft_id uint_numeric NOT NULL,
+  account_address oasis_addr NOT NULL,
+  PRIMARY KEY (runtime, token_address, nft_id, account_address),
+  -- Allow signed values because contracts may overdraw accounts beyond our
+  -- understanding or may misbehave.
+  balance NUMERIC(1000,0) NOT NULL
+);
+CREATE INDEX ix_evm_nft_balances_account ON chain.evm_nft_balances (runtime, account_address, token_address, nft_id) WHERE balance != 0;
+CREATE INDEX ix_evm_nft_balances_token_account ON chain.evm_nft_balances (runtime, token_address, account_address);
+
+GRANT SELECT ON chain.evm_nft_balances TO PUBLIC;
+
+COMMIT;
@@ -0,0 +1,30 @@
+BEGIN;
+
+-- Undo log for the effects of recently indexed runtime rounds on tables that are not
+-- keyed by round, e.g. dead-reckoned balances. If a runtime round that we indexed turns
+-- out not to be final (i.e. a later round does not build on it), the runtime analyzer
+-- reverts the rounds since the fork using this log, then re-processes them.
+--
+-- Only written in slow-sync mode; rounds processed by fast-sync are assumed final.
+-- Entries older than the max supported unwind depth are pruned by the analyzer.
+CREATE TABLE analysis.runtime_undo_log
+(
+  id BIGSERIAL PRIMARY KEY,  -- Order in which the entries were written; snapshots are restored from the earliest one.
+  runtime runtime NOT NULL,
+  round UINT63 NOT NULL,
+  -- The name of the affected table in the chain schema, e.g. 'runtime_sdk_balances'.
+  table_name TEXT NOT NULL,
+  -- Key of the affected row. Which fields are set depends on the table.
+  token TEXT,  -- Token address; or symbol, for runtime_sdk_balances. For evm_swap_pairs, the pair address.
+  account_address oasis_addr,
+  nft_id uint_numeric,
+  -- For dead-reckoned columns, the delta that was added to them in this round, e.g. {"balance": 100}.
+  -- For overwritten columns, their value before this round, e.g. {"owner": "oasis1..."}.
+  -- 'null' if the row did not exist before this round.
+  undo JSONB NOT NULL
+);
+CREATE INDEX ix_runtime_undo_log_round ON analysis.runtime_undo_log (runtime, round);
+
+GRANT SELECT ON analysis.runtime_undo_log TO PUBLIC;
+
+COMMIT;
@@ -0,0 +1,44 @@
+BEGIN;
+
+-- Webhook subscriptions of the webhooks analyzer. They are synced from the analyzer
+-- config on startup; the signing secrets are only kept in the config.
+CREATE TABLE analysis.webhook_subscriptions
+(
+  id TEXT PRIMARY KEY,
+  url TEXT NOT NULL,
+  layer TEXT NOT NULL,  -- 'consensus' or a runtime name.
+  addresses TEXT[] NOT NULL DEFAULT '{}',  -- Watched accounts; empty means all.
+  event_types TEXT[] NOT NULL DEFAULT '{}',  -- Watched event types; empty means all.
+  -- The last block height (or runtime round) whose events were matched against this
+  -- subscription. New subscriptions start at the indexed tip of their layer.
+  last_height UINT63 NOT NULL
+);
+
+-- Events that matched a subscription and have not been delivered yet.
+CREATE TABLE analysis.webhook_deliveries
+(
+  id BIGSERIAL PRIMARY KEY,  -- Also sent to the receiver, for deduplication.
+  subscription_id TEXT NOT NULL REFERENCES analysis.webhook_subscriptions(id) ON DELETE CASCADE,
+  payload JSONB NOT NULL,
+  num_attempts UINT31 NOT NULL DEFAULT 0,
+  next_attempt_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  last_error TEXT
+);
+CREATE INDEX ix_webhook_deliveries_next_attempt_time ON analysis.webhook_deliveries (next_attempt_time);
+
+-- Deliveries that failed max_attempts times. They are kept for inspection (and manual
+-- replay) even if their subscription is removed.
+CREATE TABLE analysis.webhook_dead_letters
+(
+  id BIGINT PRIMARY KEY,  -- The id of the delivery.
+  subscription_id TEXT NOT NULL,
+  payload JSONB NOT NULL,
+  num_attempts UINT31 NOT NULL,
+  last_error TEXT,
+  failed_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- No grants to PUBLIC: subscription URLs may embed credentials, and the API does not
+-- need these tables.
+
+COMMIT;
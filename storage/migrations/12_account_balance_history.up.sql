@@ -0,0 +1,29 @@
+BEGIN;
+
+-- Per-block changes of the dead-reckoned balances of consensus accounts (chain.accounts),
+-- with the resulting balances. There is a row for each account whose balance was touched
+-- by a staking event (transfer, burn, escrow) in a block.
+--
+-- Only written in slow-sync mode, since balances are not dead-reckoned in fast-sync mode.
+-- The balance of an account at a height before its first row (but after the start of
+-- slow-sync) is that row's balance minus its delta.
+CREATE TABLE history.account_balances
+(
+  address oasis_addr NOT NULL,
+  height UINT63 NOT NULL,
+  PRIMARY KEY (address, height),
+
+  -- Balances at the end of the block.
+  general_balance NUMERIC(1000,0) NOT NULL,
+  escrow_balance_active NUMERIC(1000,0) NOT NULL,
+  escrow_balance_debonding NUMERIC(1000,0) NOT NULL,
+
+  -- Changes of the balances in the block.
+  general_balance_delta NUMERIC(1000,0) NOT NULL DEFAULT 0,
+  escrow_balance_active_delta NUMERIC(1000,0) NOT NULL DEFAULT 0,
+  escrow_balance_debonding_delta NUMERIC(1000,0) NOT NULL DEFAULT 0
+);
+
+GRANT SELECT ON history.account_balances TO PUBLIC;
+
+COMMIT;
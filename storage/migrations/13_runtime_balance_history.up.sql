@@ -0,0 +1,33 @@
+BEGIN;
+
+-- Per-round changes of the balances of runtime accounts, for native runtime denominations
+-- (from accounts.transfer/mint/burn events) and ERC-20 tokens (from Transfer events, and
+-- Deposit/Withdrawal events of wrapped native tokens). There is a row for each account and
+-- token whose balance was changed by such events in a round.
+--
+-- Rows of rounds processed in slow-sync mode also record the balance after the round, as
+-- dead-reckoned in chain.runtime_sdk_balances or chain.evm_token_balances at the time. The
+-- balance after any other round is that of the next row with a balance, minus the changes in
+-- between; or if there is none, the current balance minus the changes in later rounds. Anchoring
+-- at these snapshots keeps balances that were never dead-reckoned (fast-sync) or were corrected
+-- later (e.g. by the evm_token_balances analyzer) from skewing the rest of the history.
+CREATE TABLE chain.runtime_balance_changes
+(
+  runtime runtime NOT NULL,
+  round UINT63 NOT NULL,
+  FOREIGN KEY (runtime, round) REFERENCES chain.runtime_blocks DEFERRABLE INITIALLY DEFERRED,
+  account_address oasis_addr NOT NULL,
+  -- The symbol of the native runtime denomination, or the Oasis address of the ERC-20 token contract.
+  token TEXT NOT NULL,
+  PRIMARY KEY (runtime, account_address, token, round),
+
+  delta NUMERIC(1000,0) NOT NULL,
+  balance NUMERIC(1000,0), -- NULL if the round was processed in fast-sync mode, or the balance is not tracked.
+  timestamp TIMESTAMP WITH TIME ZONE NOT NULL
+);
+-- For unwinding reorgs.
+CREATE INDEX ix_runtime_balance_changes_round ON chain.runtime_balance_changes (runtime, round);
+
+GRANT SELECT ON chain.runtime_balance_changes TO PUBLIC;
+
+COMMIT;
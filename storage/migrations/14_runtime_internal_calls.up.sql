@@ -0,0 +1,48 @@
+BEGIN;
+
+-- Calls, contract creations and self-destructs made by EVM contracts during the execution of a
+-- transaction, i.e. all frames of the transaction's call trace except the top-level one.
+-- Filled by the evm_call_traces analyzer, which requires an EVM trace RPC (see `evm_trace_rpc`).
+CREATE TABLE chain.runtime_internal_calls
+(
+  runtime runtime NOT NULL,
+  round UINT63 NOT NULL,
+  tx_index UINT31 NOT NULL,
+  FOREIGN KEY (runtime, round, tx_index) REFERENCES chain.runtime_transactions(runtime, round, tx_index) DEFERRABLE INITIALLY DEFERRED,
+  -- Position of the call in a depth-first (execution order) traversal of the call trace, starting at 0.
+  call_index UINT31 NOT NULL,
+  PRIMARY KEY (runtime, round, tx_index, call_index),
+
+  -- Nesting depth of the call; calls made by the transaction's top-level call have depth 1.
+  depth UINT31 NOT NULL,
+  -- CALL, STATICCALL, DELEGATECALL, CALLCODE, CREATE, CREATE2 or SELFDESTRUCT.
+  type TEXT NOT NULL,
+  from_address oasis_addr NOT NULL,
+  -- The created contract for CREATE/CREATE2, the beneficiary for SELFDESTRUCT.
+  -- NULL for creations that failed.
+  to_address oasis_addr,
+  value NUMERIC(1000,0) NOT NULL,
+  gas UINT63 NOT NULL,
+  gas_used UINT63 NOT NULL,
+  input BYTEA,
+  output BYTEA,
+  -- NULL if the call succeeded.
+  error TEXT,
+  -- Whether the effects of the call (e.g. its value transfer) persisted, i.e. neither the call nor
+  -- any of its ancestors failed.
+  success BOOLEAN NOT NULL
+);
+-- For unwinding reorgs.
+CREATE INDEX ix_runtime_internal_calls_round ON chain.runtime_internal_calls (runtime, round);
+
+GRANT SELECT ON chain.runtime_internal_calls TO PUBLIC;
+
+-- Rounds whose EVM transactions were traced by the evm_call_traces analyzer.
+CREATE TABLE analysis.evm_traced_rounds
+(
+  runtime runtime NOT NULL,
+  round UINT63 NOT NULL,
+  PRIMARY KEY (runtime, round)
+);
+
+COMMIT;
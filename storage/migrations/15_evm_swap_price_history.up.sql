@@ -0,0 +1,58 @@
+BEGIN;
+
+-- The reserves of swap pairs after each round in which they changed (i.e. the last `Sync` event
+-- of the pair in the round), and the amounts of each token that were swapped in the round.
+-- chain.evm_swap_pairs only has the latest reserves.
+CREATE TABLE chain.evm_swap_pair_syncs
+(
+  runtime runtime NOT NULL,
+  round UINT63 NOT NULL,
+  FOREIGN KEY (runtime, round) REFERENCES chain.runtime_blocks DEFERRABLE INITIALLY DEFERRED,
+  pair_address oasis_addr NOT NULL,
+  PRIMARY KEY (runtime, pair_address, round),
+
+  reserve0 uint_numeric NOT NULL,
+  reserve1 uint_numeric NOT NULL,
+  -- Sum of the amounts swapped in and out in this round, in base units.
+  volume0 uint_numeric NOT NULL,
+  volume1 uint_numeric NOT NULL,
+  timestamp TIMESTAMP WITH TIME ZONE NOT NULL
+);
+-- For computing candles, and for unwinding reorgs.
+CREATE INDEX ix_evm_swap_pair_syncs_timestamp ON chain.evm_swap_pair_syncs (runtime, timestamp);
+CREATE INDEX ix_evm_swap_pair_syncs_round ON chain.evm_swap_pair_syncs (runtime, round);
+
+-- OHLC candles of the prices of swap pairs, in time windows of `interval` ('5m', '1h' or '1d')
+-- that start at `window_start`. The price is that of token0 in units of token1, i.e.
+-- reserve1 / reserve0, without adjusting for the tokens' decimals. There are candles only for
+-- windows in which the reserves of the pair changed.
+CREATE TABLE stats.evm_swap_pair_candles
+(
+  runtime runtime NOT NULL,
+  pair_address oasis_addr NOT NULL,
+  interval TEXT NOT NULL,
+  window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+  PRIMARY KEY (runtime, pair_address, interval, window_start),
+
+  open DOUBLE PRECISION NOT NULL,
+  high DOUBLE PRECISION NOT NULL,
+  low DOUBLE PRECISION NOT NULL,
+  close DOUBLE PRECISION NOT NULL,
+  volume0 uint_numeric NOT NULL,
+  volume1 uint_numeric NOT NULL
+);
+
+-- The end of the latest time window for which candles have been computed, per runtime and
+-- interval. Candles are sparse, so they cannot tell how far the computation got.
+CREATE TABLE stats.evm_swap_pair_candles_computed
+(
+  layer TEXT NOT NULL, -- The runtime and the interval, e.g. 'sapphire_5m'.
+  window_end TIMESTAMP WITH TIME ZONE NOT NULL,
+  PRIMARY KEY (layer)
+);
+
+GRANT SELECT ON chain.evm_swap_pair_syncs TO PUBLIC;
+GRANT SELECT ON stats.evm_swap_pair_candles TO PUBLIC;
+GRANT SELECT ON stats.evm_swap_pair_candles_computed TO PUBLIC;
+
+COMMIT;
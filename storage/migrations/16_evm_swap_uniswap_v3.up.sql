@@ -0,0 +1,16 @@
+BEGIN;
+
+-- Uniswap V3 factories can create a pool per fee tier for the same pair of tokens.
+-- The fee is in hundredths of a bip; it is 0 for Uniswap V2 pairs.
+ALTER TABLE chain.evm_swap_pair_creations ADD COLUMN fee UINT31 NOT NULL DEFAULT 0;
+ALTER TABLE chain.evm_swap_pair_creations DROP CONSTRAINT evm_swap_pair_creations_pkey;
+ALTER TABLE chain.evm_swap_pair_creations ADD PRIMARY KEY (runtime, factory_address, token0_address, token1_address, fee);
+
+-- The square root of the price of token0 in units of token1 of Uniswap V3 pools, as a Q64.96
+-- fixed point number. NULL for Uniswap V2 pairs.
+-- For V3 pools, reserve0 and reserve1 are the virtual reserves: the reserves a V2 pair with the
+-- pool's active liquidity would have at the pool's price.
+ALTER TABLE chain.evm_swap_pairs ADD COLUMN sqrt_price_x96 uint_numeric;
+ALTER TABLE chain.evm_swap_pair_syncs ADD COLUMN sqrt_price_x96 uint_numeric;
+
+COMMIT;
@@ -0,0 +1,82 @@
+BEGIN;
+
+-- Transfers of EVM tokens, decoded from the ERC-20/ERC-721 `Transfer` and ERC-1155
+-- `TransferSingle`/`TransferBatch` events in chain.runtime_events. Rounds indexed before this
+-- migration are backfilled from the decoded events below.
+CREATE TABLE chain.evm_token_transfers
+(
+  runtime runtime NOT NULL,
+  round UINT63 NOT NULL,
+  FOREIGN KEY (runtime, round) REFERENCES chain.runtime_blocks DEFERRABLE INITIALLY DEFERRED,
+  -- The event in chain.runtime_events that the transfer was decoded from.
+  event_index UINT31 NOT NULL,
+  -- Position of the transfer in the event; an ERC-1155 TransferBatch event has a transfer per token ID.
+  transfer_index UINT31 NOT NULL,
+  PRIMARY KEY (runtime, round, event_index, transfer_index),
+
+  tx_index UINT31,
+  tx_hash HEX64,
+  tx_eth_hash HEX64,
+  timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+
+  token_address oasis_addr NOT NULL,
+  -- The type of token, as implied by the event. See common.TokenType.
+  token_type INTEGER NOT NULL,
+  from_address oasis_addr, -- NULL for mints.
+  to_address oasis_addr, -- NULL for burns.
+  token_id uint_numeric, -- NULL for ERC-20 transfers.
+  amount uint_numeric NOT NULL -- 1 for ERC-721 transfers.
+);
+CREATE INDEX ix_evm_token_transfers_token ON chain.evm_token_transfers (runtime, token_address, round DESC, event_index, transfer_index);
+CREATE INDEX ix_evm_token_transfers_from ON chain.evm_token_transfers (runtime, from_address, round DESC, event_index, transfer_index);
+CREATE INDEX ix_evm_token_transfers_to ON chain.evm_token_transfers (runtime, to_address, round DESC, event_index, transfer_index);
+
+-- Backfill the transfers of already indexed rounds from their decoded events, the same way the
+-- runtime analyzer extracts them: the zero address stands for the mint/burn side, and the
+-- number of topics tells ERC-20 (3) and ERC-721 (4) Transfer events apart.
+INSERT INTO chain.evm_token_transfers (runtime, round, event_index, transfer_index, tx_index, tx_hash, tx_eth_hash, timestamp, token_address, token_type, from_address, to_address, token_id, amount)
+  SELECT
+    evs.runtime, evs.round, evs.event_index, t.transfer_index, evs.tx_index, evs.tx_hash, evs.tx_eth_hash, evs.timestamp,
+    token.address, t.token_type, sender.address, recipient.address, t.token_id, t.amount
+  FROM chain.runtime_events AS evs
+  CROSS JOIN LATERAL (
+    -- Transfer(from, to, value)
+    SELECT 0 AS transfer_index, 20 AS token_type, 0 AS from_param, NULL::uint_numeric AS token_id, (evs.evm_log_params -> 2 ->> 'value')::uint_numeric AS amount
+    WHERE evs.evm_log_signature = '\xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef' AND jsonb_array_length(evs.body -> 'topics') = 3
+    UNION ALL
+    -- Transfer(from, to, tokenID)
+    SELECT 0, 721, 0, (evs.evm_log_params -> 2 ->> 'value')::uint_numeric, 1
+    WHERE evs.evm_log_signature = '\xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef' AND jsonb_array_length(evs.body -> 'topics') = 4
+    UNION ALL
+    -- TransferSingle(operator, from, to, id, value)
+    SELECT 0, 1155, 1, (evs.evm_log_params -> 3 ->> 'value')::uint_numeric, (evs.evm_log_params -> 4 ->> 'value')::uint_numeric
+    WHERE evs.evm_log_signature = '\xc3d58168c5ae7397731d063d5bbf3d657854427343f4c083240f7aacaa2d0f62'
+    UNION ALL
+    -- TransferBatch(operator, from, to, ids, values)
+    SELECT (ids.ord - 1)::integer, 1155, 1, ids.id::uint_numeric, (evs.evm_log_params -> 4 -> 'value' ->> (ids.ord - 1)::integer)::uint_numeric
+    FROM jsonb_array_elements_text(evs.evm_log_params -> 3 -> 'value') WITH ORDINALITY AS ids(id, ord)
+    WHERE evs.evm_log_signature = '\x4a39dc06d4c0dbc64b70af90fd698a233a518aa5d07e595d983b8c0526c8f7fb'
+  ) AS t
+  JOIN chain.address_preimages AS token ON
+    token.address_data = decode(evs.body ->> 'address', 'base64') AND
+    token.context_identifier = 'oasis-runtime-sdk/address: secp256k1eth' AND token.context_version = 0
+  LEFT JOIN chain.address_preimages AS sender ON
+    sender.address_data = decode(substr(evs.evm_log_params -> t.from_param ->> 'value', 3), 'hex') AND
+    sender.address_data <> '\x0000000000000000000000000000000000000000' AND
+    sender.context_identifier = 'oasis-runtime-sdk/address: secp256k1eth' AND sender.context_version = 0
+  LEFT JOIN chain.address_preimages AS recipient ON
+    recipient.address_data = decode(substr(evs.evm_log_params -> (t.from_param + 1) ->> 'value', 3), 'hex') AND
+    recipient.address_data <> '\x0000000000000000000000000000000000000000' AND
+    recipient.context_identifier = 'oasis-runtime-sdk/address: secp256k1eth' AND recipient.context_version = 0
+  WHERE
+    evs.type = 'evm.log' AND
+    evs.evm_log_signature IN (
+      '\xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef',
+      '\xc3d58168c5ae7397731d063d5bbf3d657854427343f4c083240f7aacaa2d0f62',
+      '\x4a39dc06d4c0dbc64b70af90fd698a233a518aa5d07e595d983b8c0526c8f7fb'
+    ) AND
+    evs.evm_log_params IS NOT NULL;
+
+GRANT SELECT ON chain.evm_token_transfers TO PUBLIC;
+
+COMMIT;
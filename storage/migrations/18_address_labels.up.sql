@@ -0,0 +1,22 @@
+BEGIN;
+
+-- Human-readable labels of known addresses, e.g. "Common Pool" or the name of a bridge contract.
+-- Maintained by the address_labels analyzer from the lists bundled with Nexus
+-- (named-addresses/, and the deprecated account-names/) and an optional remote registry.
+CREATE TABLE chain.address_labels
+(
+  -- The layer of the address, e.g. consensus or sapphire. See common.Layer.
+  layer TEXT NOT NULL,
+  address oasis_addr NOT NULL,
+  PRIMARY KEY (layer, address),
+
+  name TEXT NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  -- Where the label comes from: account_names, named_addresses or registry.
+  source TEXT NOT NULL
+);
+CREATE INDEX ix_address_labels_name ON chain.address_labels (layer, name);
+
+GRANT SELECT ON chain.address_labels TO PUBLIC;
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+-- Trigram indexes for the fuzzy matching of names in /search.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX ix_evm_tokens_token_name_trgm ON chain.evm_tokens USING gin (token_name gin_trgm_ops);
+CREATE INDEX ix_evm_tokens_symbol_trgm ON chain.evm_tokens USING gin (symbol gin_trgm_ops);
+CREATE INDEX ix_entities_meta_name_trgm ON chain.entities USING gin ((meta->>'name') gin_trgm_ops);
+CREATE INDEX ix_proposals_title_trgm ON chain.proposals USING gin (title gin_trgm_ops);
+
+-- Also serves the substring filter of /{layer}/labels.
+DROP INDEX chain.ix_address_labels_name;
+CREATE INDEX ix_address_labels_name_trgm ON chain.address_labels USING gin (name gin_trgm_ops);
+
+COMMIT;
@@ -0,0 +1,53 @@
+BEGIN;
+
+-- Requests to verify an EVM contract from its Solidity sources, submitted through the API.
+-- Processed by the evm_contract_compiler_<runtime> analyzers, which compile the sources with
+-- local solc binaries and compare the result with chain.evm_contracts.runtime_bytecode.
+-- On a match, the analyzer also stores the verification in chain.evm_contracts.
+CREATE TABLE analysis.evm_contract_verifications
+(
+  id BIGSERIAL PRIMARY KEY,
+  runtime runtime NOT NULL,
+  contract_address oasis_addr NOT NULL,
+
+  compiler_version TEXT NOT NULL,
+  -- The contract to verify, as "<source path>:<contract name>".
+  contract_name TEXT NOT NULL,
+  -- Source contents, keyed by source path.
+  sources JSONB NOT NULL,
+  -- The `settings` of the solc standard JSON input, e.g. the optimizer settings.
+  settings JSONB NOT NULL DEFAULT '{}',
+  -- SHA-256 of the compiler version, contract name, sources and settings, to reject
+  -- resubmissions of pending requests.
+  input_hash BYTEA NOT NULL,
+
+  -- One of pending, verified or failed.
+  status TEXT NOT NULL DEFAULT 'pending',
+  -- Why the verification failed; NULL unless status is failed.
+  error TEXT,
+  -- The level of the match; NULL unless status is verified.
+  verification_level sourcify_level,
+
+  submitted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  processed_at TIMESTAMP WITH TIME ZONE
+);
+CREATE INDEX ix_evm_contract_verifications_pending ON analysis.evm_contract_verifications (runtime, id) WHERE status = 'pending';
+-- At most one pending request per contract and input. Also for counting the pending requests of a contract.
+CREATE UNIQUE INDEX ix_evm_contract_verifications_pending_input ON analysis.evm_contract_verifications (runtime, contract_address, input_hash) WHERE status = 'pending';
+
+-- The API inserts new requests. Only the DB user of the API needs to, so inserting is
+-- granted to a dedicated role instead of PUBLIC; deployments that enable contract
+-- verification grant it to the API's user with
+--   GRANT evm_contract_verification_submitter TO <api user>;
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT FROM pg_roles WHERE rolname = 'evm_contract_verification_submitter') THEN
+    CREATE ROLE evm_contract_verification_submitter NOLOGIN;
+  END IF;
+END
+$$;
+GRANT SELECT ON analysis.evm_contract_verifications TO PUBLIC;
+GRANT INSERT ON analysis.evm_contract_verifications TO evm_contract_verification_submitter;
+GRANT USAGE ON SEQUENCE analysis.evm_contract_verifications_id_seq TO evm_contract_verification_submitter;
+
+COMMIT;
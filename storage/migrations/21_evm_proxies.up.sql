@@ -0,0 +1,28 @@
+BEGIN;
+
+-- The implementations that EVM proxy contracts (EIP-1967, including UUPS and transparent
+-- proxies) delegate their calls to. A proxy delegates to the implementation of the latest
+-- entry at or before a round.
+CREATE TABLE chain.evm_proxy_implementations
+(
+  runtime runtime NOT NULL,
+  proxy_address oasis_addr NOT NULL,
+  -- The round from which the proxy delegates to implementation_address.
+  round UINT63 NOT NULL,
+  PRIMARY KEY (runtime, proxy_address, round),
+
+  implementation_address oasis_addr NOT NULL,
+  -- How the implementation was found:
+  --  - event: an `Upgraded(address)` event emitted by the proxy in the round.
+  --  - storage: the EIP-1967 (or older) implementation storage slot of the proxy, read at the round.
+  --    The implementation may have been set before the round.
+  source TEXT NOT NULL,
+  -- When Nexus found the implementation. The ABI analyzer re-parses the proxy's
+  -- transactions and events that were parsed before.
+  detected_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX ix_evm_proxy_implementations_implementation ON chain.evm_proxy_implementations (runtime, implementation_address);
+
+GRANT SELECT ON chain.evm_proxy_implementations TO PUBLIC;
+
+COMMIT;
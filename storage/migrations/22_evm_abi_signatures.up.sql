@@ -0,0 +1,19 @@
+BEGIN;
+
+-- Calls to and events of contracts without a known ABI are decoded on a best-effort basis with a
+-- database of known function and event signatures. Such decodings may be wrong: different signatures
+-- can share a selector, and text signatures do not tell which event parameters are indexed.
+-- These columns are TRUE if evm_fn_name/evm_fn_params (evm_log_name/evm_log_params) were decoded that way.
+ALTER TABLE chain.runtime_transactions ADD COLUMN evm_fn_low_confidence BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE chain.runtime_events ADD COLUMN evm_log_low_confidence BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- For finding calls and events that have not been decoded with an ABI or the signature database yet,
+-- or could not be named with the signatures known at the time and are decoded again as more are added.
+CREATE INDEX ix_runtime_transactions_abi_unparsed ON chain.runtime_transactions (runtime, round)
+  WHERE method = 'evm.Call' AND abi_parsed_at IS NULL;
+CREATE INDEX ix_runtime_transactions_abi_unnamed ON chain.runtime_transactions (runtime, abi_parsed_at)
+  WHERE method = 'evm.Call' AND evm_fn_name IS NULL;
+CREATE INDEX ix_runtime_events_abi_unnamed ON chain.runtime_events (runtime, abi_parsed_at)
+  WHERE type = 'evm.log' AND evm_log_name IS NULL;
+
+COMMIT;
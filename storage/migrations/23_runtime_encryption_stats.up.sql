@@ -0,0 +1,88 @@
+BEGIN;
+
+-- The epoch of the ephemeral runtime (calldata) key that an encrypted transaction was encrypted
+-- with, or 0 for the long-term key. NULL for plain transactions and for transactions indexed before
+-- the epoch was recorded.
+ALTER TABLE chain.runtime_transactions ADD COLUMN oasis_encrypted_epoch UINT63;
+ALTER TABLE chain.runtime_transactions ADD COLUMN evm_encrypted_epoch UINT63;
+
+-- For listing encrypted transactions, and counting them per round.
+CREATE INDEX ix_runtime_transactions_encrypted ON chain.runtime_transactions (runtime, round, tx_index)
+  WHERE evm_encrypted_format IS NOT NULL OR oasis_encrypted_format IS NOT NULL;
+
+-- Statistics on the adoption of encrypted (confidential) calls, refreshed periodically by the
+-- runtime_encryption_stats analyzer. A call is encrypted if it is an evm.Call whose data is in a
+-- Sapphire encryption envelope (evm_encrypted_format), or a transaction in an Oasis encryption
+-- envelope (oasis_encrypted_format), whose method and contract are encrypted along with the call.
+-- Each view is refreshed from a partial index, rather than by scanning all transactions.
+
+-- For refreshing runtime_encryption_contracts with an index-only scan of the EVM calls.
+CREATE INDEX ix_runtime_transactions_evm_calls ON chain.runtime_transactions (runtime, "to") INCLUDE (round, success, evm_encrypted_format)
+  WHERE method = 'evm.Call' AND "to" IS NOT NULL;
+-- For refreshing runtime_encrypted_call_errors.
+CREATE INDEX ix_runtime_transactions_encrypted_errors ON chain.runtime_transactions (runtime, error_module, error_code) INCLUDE (round)
+  WHERE (evm_encrypted_format IS NOT NULL OR oasis_encrypted_format IS NOT NULL) AND success = FALSE;
+-- For refreshing runtime_encrypted_calls and runtime_encryption_key_epochs.
+CREATE INDEX ix_runtime_transactions_encrypted_epochs ON chain.runtime_transactions (runtime, (COALESCE(evm_encrypted_epoch, oasis_encrypted_epoch))) INCLUDE (round, evm_encrypted_format)
+  WHERE evm_encrypted_format IS NOT NULL OR oasis_encrypted_format IS NOT NULL;
+
+-- Encrypted and plain EVM calls per contract. Calls in an Oasis encryption envelope are not
+-- included, since their contract is encrypted.
+CREATE MATERIALIZED VIEW views.runtime_encryption_contracts AS
+  SELECT
+    runtime,
+    "to" AS contract_address,
+    COUNT(*) FILTER (WHERE evm_encrypted_format IS NOT NULL) AS num_encrypted_calls,
+    COUNT(*) FILTER (WHERE evm_encrypted_format IS NULL) AS num_plain_calls,
+    COUNT(*) FILTER (WHERE evm_encrypted_format IS NOT NULL AND success = FALSE) AS num_failed_encrypted_calls,
+    MAX(round) AS last_round
+  FROM chain.runtime_transactions
+  WHERE method = 'evm.Call' AND "to" IS NOT NULL
+  GROUP BY runtime, "to";
+CREATE UNIQUE INDEX ix_views_runtime_encryption_contracts_address ON views.runtime_encryption_contracts (runtime, contract_address); -- A unique index is required for CONCURRENTLY refreshing the view.
+CREATE INDEX ix_views_runtime_encryption_contracts_num_encrypted_calls ON views.runtime_encryption_contracts (runtime, num_encrypted_calls DESC, contract_address);
+
+-- Encrypted calls per runtime, in either envelope.
+CREATE MATERIALIZED VIEW views.runtime_encrypted_calls AS
+  SELECT
+    runtime,
+    COUNT(*) FILTER (WHERE evm_encrypted_format IS NOT NULL) AS num_evm_encrypted_calls,
+    COUNT(*) FILTER (WHERE evm_encrypted_format IS NULL) AS num_oasis_encrypted_calls
+  FROM chain.runtime_transactions
+  WHERE evm_encrypted_format IS NOT NULL OR oasis_encrypted_format IS NOT NULL
+  GROUP BY runtime;
+CREATE UNIQUE INDEX ix_views_runtime_encrypted_calls_runtime ON views.runtime_encrypted_calls (runtime);
+
+-- Errors of failed encrypted calls, per error module and code. These are all errors of the
+-- calls, e.g. reverts, not only failures of the runtime to decrypt the calls. The runtime fails
+-- calls that it cannot decrypt (e.g. with the key of an unknown epoch, or with a malformed
+-- envelope) with the `core` module's invalid call format error, code 17.
+CREATE MATERIALIZED VIEW views.runtime_encrypted_call_errors AS
+  SELECT
+    runtime,
+    COALESCE(error_module, '') AS error_module,
+    COALESCE(error_code, 0) AS error_code,
+    COALESCE(error_module = 'core' AND error_code = 17, FALSE) AS decryption_failure,
+    COUNT(*) AS num_calls,
+    MAX(round) AS last_round
+  FROM chain.runtime_transactions
+  WHERE (evm_encrypted_format IS NOT NULL OR oasis_encrypted_format IS NOT NULL) AND success = FALSE
+  GROUP BY runtime, COALESCE(error_module, ''), COALESCE(error_code, 0);
+CREATE UNIQUE INDEX ix_views_runtime_encrypted_call_errors_error ON views.runtime_encrypted_call_errors (runtime, error_module, error_code);
+
+-- Encrypted calls per epoch of the calldata key that they were encrypted with, in either envelope.
+CREATE MATERIALIZED VIEW views.runtime_encryption_key_epochs AS
+  SELECT
+    runtime,
+    COALESCE(evm_encrypted_epoch, oasis_encrypted_epoch) AS epoch,
+    COUNT(*) AS num_calls,
+    MIN(round) AS first_round,
+    MAX(round) AS last_round
+  FROM chain.runtime_transactions
+  WHERE (evm_encrypted_format IS NOT NULL OR oasis_encrypted_format IS NOT NULL) AND COALESCE(evm_encrypted_epoch, oasis_encrypted_epoch) IS NOT NULL
+  GROUP BY runtime, COALESCE(evm_encrypted_epoch, oasis_encrypted_epoch);
+CREATE UNIQUE INDEX ix_views_runtime_encryption_key_epochs_epoch ON views.runtime_encryption_key_epochs (runtime, epoch);
+
+GRANT SELECT ON views.runtime_encryption_contracts, views.runtime_encrypted_calls, views.runtime_encrypted_call_errors, views.runtime_encryption_key_epochs TO PUBLIC;
+
+COMMIT;
@@ -0,0 +1,26 @@
+BEGIN;
+
+-- Entity IDs of the validators in the active consensus validator set at the start of the epoch,
+-- i.e. the validators that were expected to sign the blocks of the epoch. Unlike `validators`,
+-- this does not include past validators. NULL until processed by the validator_uptime analyzer.
+ALTER TABLE chain.epochs ADD COLUMN active_validators base64_ed25519_pubkey[];
+
+-- Per-epoch block signing statistics of the validators in the active validator set of the epoch,
+-- computed by the validator_uptime analyzer from chain.blocks once the epoch is over.
+CREATE TABLE history.validator_uptimes
+(
+  id base64_ed25519_pubkey NOT NULL, -- Entity ID.
+  epoch UINT63 NOT NULL,
+  PRIMARY KEY (id, epoch),
+
+  -- Blocks of the epoch that the validator signed.
+  signed_blocks UINT63 NOT NULL,
+  -- Blocks of the epoch that the validator did not sign. Blocks whose signers are not known are not counted.
+  missed_blocks UINT63 NOT NULL,
+  -- Blocks of the epoch that the validator proposed.
+  proposed_blocks UINT63 NOT NULL
+);
+
+GRANT SELECT ON history.validator_uptimes TO PUBLIC;
+
+COMMIT;
@@ -0,0 +1,33 @@
+BEGIN;
+
+-- Staking rewards earned by delegators, per epoch and validator, computed by the
+-- validator_staking_history analyzer from the evolution of the validator's share price
+-- (escrow_balance_active / escrow_total_shares_active in history.validators).
+--
+-- Staking rewards for an epoch are granted in the first block of the subsequent epoch, and
+-- raise the share price of the validator's active escrow pool. The reward of a delegator for
+-- epoch E is the value of its shares at the start of epoch E+1 (after the rewards were granted),
+-- minus the value of the same shares at the start of epoch E. The validator's commission
+-- is paid out in new shares, so the rewards are net of commission.
+--
+-- The reward is negative if the validator was slashed. Rows with zero rewards are not stored.
+--
+-- The table grows by one row per delegation per epoch, since every active delegation earns
+-- rewards while its validator is active; on Mainnet, with an epoch about every hour, that is
+-- 24 rows per delegation per day. Rows are never pruned; the rewards of older epochs remain
+-- needed for the reward history of delegators.
+CREATE TABLE history.delegator_rewards
+(
+  delegator oasis_addr NOT NULL,
+  delegatee oasis_addr NOT NULL,
+  epoch UINT63 NOT NULL,
+  PRIMARY KEY (delegator, epoch, delegatee),
+
+  -- Active escrow shares of the delegator at the start of epoch+1.
+  shares UINT_NUMERIC NOT NULL,
+  reward NUMERIC(1000,0) NOT NULL
+);
+
+GRANT SELECT ON history.delegator_rewards TO PUBLIC;
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+
+-- The governance parameters that determine whether a proposal passes, as of its submission,
+-- updated at its finalization. For proposals imported from genesis, these are backfilled as of
+-- their submission or finalization epoch, if it was indexed. NULL for parameters that do not
+-- apply to the proposal's era: stake_threshold was introduced in Damask and replaced quorum and
+-- threshold. In percent.
+ALTER TABLE chain.proposals ADD COLUMN stake_threshold UINT31;
+ALTER TABLE chain.proposals ADD COLUMN quorum UINT31;
+ALTER TABLE chain.proposals ADD COLUMN threshold UINT31;
+
+COMMIT;
@@ -0,0 +1,43 @@
+BEGIN;
+
+-- Vaults of the consensus vault module (available starting with Eden). A vault is an account
+-- controlled by multisig authorities. Rows are refreshed from the node whenever a vault is
+-- created or emits an event.
+CREATE TABLE chain.vaults
+(
+  address oasis_addr PRIMARY KEY,
+  creator oasis_addr NOT NULL,
+  id UINT63 NOT NULL, -- Unique per creator.
+  state TEXT NOT NULL, -- "active" or "suspended".
+  nonce UINT63 NOT NULL, -- Nonce of the next action.
+
+  -- The authority that can perform all actions on the vault.
+  admin_authority_addresses oasis_addr[] NOT NULL,
+  admin_authority_threshold UINT31 NOT NULL,
+  -- The authority that can suspend the vault.
+  suspend_authority_addresses oasis_addr[] NOT NULL,
+  suspend_authority_threshold UINT31 NOT NULL,
+
+  created_at UINT63 NOT NULL, -- Height of the block in which the vault was first seen.
+  -- Height of the block as of which the row reflects the state of the vault. Guards against
+  -- overwriting newer state with older state when blocks are processed out of order.
+  last_update_height UINT63 NOT NULL
+);
+CREATE INDEX ix_vaults_creator ON chain.vaults (creator);
+
+-- Actions of a vault that were submitted but have not been authorized by enough addresses yet.
+-- Rows are removed once the action is executed or canceled.
+CREATE TABLE chain.vault_pending_actions
+(
+  vault oasis_addr NOT NULL,
+  nonce UINT63 NOT NULL,
+  PRIMARY KEY (vault, nonce),
+
+  action JSONB NOT NULL,
+  -- Addresses that have authorized the action so far.
+  authorized_by oasis_addr[] NOT NULL
+);
+
+GRANT SELECT ON chain.vaults, chain.vault_pending_actions TO PUBLIC;
+
+COMMIT;
@@ -0,0 +1,52 @@
+BEGIN;
+
+-- Whether the key manager statuses at the start of the epoch were processed by the
+-- keymanager_history analyzer.
+ALTER TABLE chain.epochs ADD COLUMN keymanagers_processed BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Statuses of key manager runtimes, as published by the consensus key manager module.
+-- A row is only inserted when the status differs from the previous row of the key manager,
+-- so each row marks a change, e.g. a rotation of the master secret or a change of the nodes
+-- that serve the key manager.
+CREATE TABLE history.keymanager_statuses
+(
+  runtime_id HEX64 NOT NULL,
+  epoch UINT63 NOT NULL, -- The first epoch whose start the status was observed at.
+  PRIMARY KEY (runtime_id, epoch),
+  height UINT63 NOT NULL, -- The height at which the status was observed, i.e. the first height of the epoch.
+
+  is_initialized BOOLEAN NOT NULL,
+  is_secure BOOLEAN NOT NULL,
+  generation UINT63, -- Generation of the latest master secret. NULL before Eden.
+  rotation_epoch UINT63, -- Epoch of the last master secret rotation. NULL before Eden.
+  checksum TEXT NOT NULL, -- Hex-encoded master secret verification checksum.
+  nodes base64_ed25519_pubkey[] NOT NULL, -- IDs of the nodes that serve the key manager.
+  rsk base64_ed25519_pubkey, -- Runtime signing key. NULL before Eden.
+  policy JSONB
+);
+
+-- Statuses of the CHURP instances of key manager runtimes (available starting with Eden).
+-- Like history.keymanager_statuses, a row is only inserted when the status changes.
+CREATE TABLE history.keymanager_churp_statuses
+(
+  runtime_id HEX64 NOT NULL,
+  churp_id UINT31 NOT NULL, -- Unique per runtime.
+  epoch UINT63 NOT NULL,
+  PRIMARY KEY (runtime_id, churp_id, epoch),
+  height UINT63 NOT NULL,
+
+  suite_id UINT31 NOT NULL,
+  threshold UINT31 NOT NULL,
+  extra_shares UINT31 NOT NULL,
+  handoff_interval UINT63 NOT NULL, -- In epochs. Zero if handoffs are disabled.
+  handoff UINT63, -- Epoch of the last completed handoff. NULL if invalid.
+  next_handoff UINT63, -- Epoch of the next handoff. NULL if handoffs are disabled.
+  checksum HEX64, -- Checksum of the secret that the committee shares.
+  next_checksum HEX64, -- Checksum of the secret of the next handoff.
+  committee base64_ed25519_pubkey[] NOT NULL, -- IDs of the nodes that hold shares of the secret.
+  policy JSONB
+);
+
+GRANT SELECT ON history.keymanager_statuses, history.keymanager_churp_statuses TO PUBLIC;
+
+COMMIT;
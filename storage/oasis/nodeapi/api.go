@@ -75,6 +75,12 @@ type ConsensusApiLite interface {
 	GetNodes(ctx context.Context, height int64) ([]Node, error)
 	GetCommittees(ctx context.Context, height int64, runtimeID coreCommon.Namespace) ([]Committee, error)
 	GetProposal(ctx context.Context, height int64, proposalID uint64) (*Proposal, error)
+	GetGovernanceParameters(ctx context.Context, height int64) (*GovernanceParameters, error)
+	VaultEvents(ctx context.Context, height int64) ([]Event, error)
+	GetVaults(ctx context.Context, height int64) ([]Vault, error)
+	GetVaultPendingActions(ctx context.Context, height int64, address Address) ([]VaultPendingAction, error)
+	GetKeyManagerStatuses(ctx context.Context, height int64) ([]KeyManagerStatus, error)
+	GetChurpStatuses(ctx context.Context, height int64) ([]ChurpStatus, error)
 	GetAccount(ctx context.Context, height int64, address Address) (*Account, error)
 	DelegationsTo(ctx context.Context, height int64, address Address) (map[Address]*Delegation, error)
 	Close() error
@@ -154,6 +160,14 @@ type Event struct {
 	GovernanceProposalExecuted  *ProposalExecutedEvent
 	GovernanceProposalFinalized *ProposalFinalizedEvent
 	GovernanceVote              *VoteEvent
+
+	// Vault events are available starting with Eden.
+	VaultActionSubmitted  *VaultActionSubmittedEvent
+	VaultActionCanceled   *VaultActionCanceledEvent
+	VaultActionExecuted   *VaultActionExecutedEvent
+	VaultStateChanged     *VaultStateChangedEvent
+	VaultPolicyUpdated    *VaultPolicyUpdatedEvent
+	VaultAuthorityUpdated *VaultAuthorityUpdatedEvent
 }
 
 // .................... Staking  ....................
@@ -252,6 +266,93 @@ type (
 	}
 )
 
+// .................... Vault ....................
+
+type (
+	VaultActionSubmittedEvent struct {
+		Submitter staking.Address
+		Vault     staking.Address
+		Nonce     uint64
+	}
+	VaultActionCanceledEvent struct {
+		Vault staking.Address
+		Nonce uint64
+	}
+	VaultActionExecutedEvent struct {
+		Vault staking.Address
+		Nonce uint64
+	}
+	VaultStateChangedEvent struct {
+		Vault    staking.Address
+		OldState string // enum: "suspended", "active"
+		NewState string // enum: "suspended", "active"
+	}
+	VaultPolicyUpdatedEvent struct {
+		Vault   staking.Address
+		Address staking.Address // The address whose withdraw policy was updated.
+	}
+	VaultAuthorityUpdatedEvent struct {
+		Vault staking.Address
+	}
+)
+
+// A lightweight version of `vault.Vault`.
+type Vault struct {
+	Creator          staking.Address
+	ID               uint64 // Unique per creator.
+	State            string // enum: "suspended", "active"
+	Nonce            uint64 // Nonce of the next action.
+	AdminAuthority   VaultAuthority
+	SuspendAuthority VaultAuthority
+}
+
+// A lightweight version of `vault.Authority`.
+type VaultAuthority struct {
+	Addresses []staking.Address
+	Threshold uint8
+}
+
+// A lightweight version of `vault.PendingAction`.
+type VaultPendingAction struct {
+	Nonce        uint64
+	AuthorizedBy []staking.Address
+	// The action as it was received from oasis-core, converted to JSON.
+	Action json.RawMessage
+}
+
+// .................... Key manager ....................
+
+// A lightweight version of `keymanager/secrets.Status` (`keymanager.Status` before Eden).
+type KeyManagerStatus struct {
+	ID            coreCommon.Namespace
+	IsInitialized bool
+	IsSecure      bool
+	Generation    uint64           // Generation of the latest master secret. Available starting with Eden.
+	RotationEpoch beacon.EpochTime // Epoch of the last master secret rotation. Available starting with Eden.
+	Checksum      []byte
+	Nodes         []signature.PublicKey
+	RSK           *signature.PublicKey // Runtime signing key. Available starting with Eden.
+	// The SGX policy as it was received from oasis-core, converted to JSON.
+	Policy json.RawMessage
+}
+
+// A lightweight version of `keymanager/churp.Status`. Available starting with Eden.
+type ChurpStatus struct {
+	ID              uint8
+	RuntimeID       coreCommon.Namespace
+	SuiteID         uint8
+	Threshold       uint8
+	ExtraShares     uint8
+	HandoffInterval beacon.EpochTime
+	Handoff         beacon.EpochTime // Epoch of the last successfully completed handoff.
+	NextHandoff     beacon.EpochTime // Epoch of the next handoff, or beacon.EpochInvalid if none is scheduled.
+	Checksum        *hash.Hash
+	NextChecksum    *hash.Hash
+	Committee       []signature.PublicKey
+	// The SGX policy as it was received from oasis-core, converted to JSON.
+	Policy json.RawMessage
+}
+
 // .................... Scheduler ....................
 
 type (
@@ -268,6 +369,21 @@ type (
 
 type Proposal governance.Proposal
 
+// A lightweight subset of `governance.ConsensusParameters`, with the parameters
+// that determine whether a proposal passes.
+type GovernanceParameters struct {
+	// StakeThreshold is the minimum percentage of yes votes in terms of total
+	// voting power for a proposal to pass. Zero before Damask.
+	StakeThreshold uint8
+
+	// Quorum is the minimum percentage of total voting power that needs to
+	// vote on a proposal, and Threshold is the minimum percentage of yes votes
+	// among the cast votes, for a proposal to pass. Only used before Damask,
+	// zero afterwards.
+	Quorum    uint8
+	Threshold uint8
+}
+
 // ....................................................
 // ....................  Runtimes  ....................
 // ....................................................
@@ -277,9 +393,15 @@ type RuntimeApiLite interface {
 	GetEventsRaw(ctx context.Context, round uint64) ([]RuntimeEvent, error)
 	EVMSimulateCall(ctx context.Context, round uint64, gasPrice []byte, gasLimit uint64, caller []byte, address []byte, value []byte, data []byte) (*FallibleResponse, error)
 	EVMGetCode(ctx context.Context, round uint64, address []byte) ([]byte, error)
+	// EVMGetStorage returns the 32-byte word at `index` in the storage of the
+	// contract at `address`. Confidential runtimes do not expose storage.
+	EVMGetStorage(ctx context.Context, round uint64, address []byte, index []byte) ([]byte, error)
 	GetBlockHeader(ctx context.Context, round uint64) (*RuntimeBlockHeader, error)
 	GetTransactionsWithResults(ctx context.Context, round uint64) ([]RuntimeTransactionWithResults, error)
 	GetBalances(ctx context.Context, round uint64, addr Address) (map[sdkTypes.Denomination]common.BigInt, error)
+	// EVMTraceRound returns the call traces of the EVM transactions of a
+	// round, or ErrEVMTracingUnsupported if tracing is not available.
+	EVMTraceRound(ctx context.Context, round uint64) ([]EVMTransactionTrace, error)
 	Close() error
 }
 
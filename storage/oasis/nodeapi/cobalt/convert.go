@@ -28,6 +28,7 @@ import (
 	txResultsCobalt "github.com/oasisprotocol/nexus/coreapi/v21.1.1/consensus/api/transaction/results"
 	genesisCobalt "github.com/oasisprotocol/nexus/coreapi/v21.1.1/genesis/api"
 	governanceCobalt "github.com/oasisprotocol/nexus/coreapi/v21.1.1/governance/api"
+	keymanagerCobalt "github.com/oasisprotocol/nexus/coreapi/v21.1.1/keymanager/api"
 	registryCobalt "github.com/oasisprotocol/nexus/coreapi/v21.1.1/registry/api"
 	roothashCobalt "github.com/oasisprotocol/nexus/coreapi/v21.1.1/roothash/api"
 	commitmentCobalt "github.com/oasisprotocol/nexus/coreapi/v21.1.1/roothash/api/commitment"
@@ -488,3 +489,14 @@ func convertCommittee(c schedulerCobalt.Committee) nodeapi.Committee {
 		ValidFor:  c.ValidFor,
 	}
 }
+
+func convertKeyManagerStatus(s keymanagerCobalt.Status) nodeapi.KeyManagerStatus {
+	return nodeapi.KeyManagerStatus{
+		ID:            s.ID,
+		IsInitialized: s.IsInitialized,
+		IsSecure:      s.IsSecure,
+		Checksum:      s.Checksum,
+		Nodes:         s.Nodes,
+		Policy:        common.TryAsJSON(s.Policy),
+	}
+}
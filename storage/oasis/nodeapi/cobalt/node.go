@@ -26,6 +26,7 @@ import (
 	txResultsCobalt "github.com/oasisprotocol/nexus/coreapi/v21.1.1/consensus/api/transaction/results"
 	genesisCobalt "github.com/oasisprotocol/nexus/coreapi/v21.1.1/genesis/api"
 	governanceCobalt "github.com/oasisprotocol/nexus/coreapi/v21.1.1/governance/api"
+	keymanagerCobalt "github.com/oasisprotocol/nexus/coreapi/v21.1.1/keymanager/api"
 	registryCobalt "github.com/oasisprotocol/nexus/coreapi/v21.1.1/registry/api"
 	roothashCobalt "github.com/oasisprotocol/nexus/coreapi/v21.1.1/roothash/api"
 	schedulerCobalt "github.com/oasisprotocol/nexus/coreapi/v21.1.1/scheduler/api"
@@ -230,6 +231,49 @@ func (c *ConsensusApiLite) GetProposal(ctx context.Context, height int64, propos
 	return (*nodeapi.Proposal)(convertProposal(rsp)), nil
 }
 
+func (c *ConsensusApiLite) GetGovernanceParameters(ctx context.Context, height int64) (*nodeapi.GovernanceParameters, error) {
+	var rsp governanceCobalt.ConsensusParameters
+	if err := c.grpcConn.Invoke(ctx, "/oasis-core.Governance/ConsensusParameters", height, &rsp); err != nil {
+		return nil, fmt.Errorf("GetGovernanceParameters(%d): %w", height, err)
+	}
+	return &nodeapi.GovernanceParameters{
+		Quorum:    rsp.Quorum,
+		Threshold: rsp.Threshold,
+	}, nil
+}
+
+func (c *ConsensusApiLite) VaultEvents(ctx context.Context, height int64) ([]nodeapi.Event, error) {
+	// The vault module does not exist in Cobalt.
+	return []nodeapi.Event{}, nil
+}
+
+func (c *ConsensusApiLite) GetVaults(ctx context.Context, height int64) ([]nodeapi.Vault, error) {
+	// The vault module does not exist in Cobalt.
+	return []nodeapi.Vault{}, nil
+}
+
+func (c *ConsensusApiLite) GetVaultPendingActions(ctx context.Context, height int64, address nodeapi.Address) ([]nodeapi.VaultPendingAction, error) {
+	// The vault module does not exist in Cobalt.
+	return []nodeapi.VaultPendingAction{}, nil
+}
+
+func (c *ConsensusApiLite) GetKeyManagerStatuses(ctx context.Context, height int64) ([]nodeapi.KeyManagerStatus, error) {
+	var rsp []*keymanagerCobalt.Status
+	if err := c.grpcConn.Invoke(ctx, "/oasis-core.KeyManager/GetStatuses", height, &rsp); err != nil {
+		return nil, fmt.Errorf("GetKeyManagerStatuses(%d): %w", height, err)
+	}
+	statuses := make([]nodeapi.KeyManagerStatus, len(rsp))
+	for i, s := range rsp {
+		statuses[i] = convertKeyManagerStatus(*s)
+	}
+	return statuses, nil
+}
+
+func (c *ConsensusApiLite) GetChurpStatuses(ctx context.Context, height int64) ([]nodeapi.ChurpStatus, error) {
+	// CHURP does not exist in Cobalt.
+	return []nodeapi.ChurpStatus{}, nil
+}
+
 func (c *ConsensusApiLite) GetAccount(ctx context.Context, height int64, address nodeapi.Address) (*nodeapi.Account, error) {
 	var rsp *stakingCobalt.Account
 	if err := c.grpcConn.Invoke(ctx, "/oasis-core.Staking/Account", &stakingCobalt.OwnerQuery{
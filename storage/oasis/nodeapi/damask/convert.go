@@ -19,6 +19,7 @@ import (
 	txResultsDamask "github.com/oasisprotocol/nexus/coreapi/v22.2.11/consensus/api/transaction/results"
 	genesisDamask "github.com/oasisprotocol/nexus/coreapi/v22.2.11/genesis/api"
 	governanceDamask "github.com/oasisprotocol/nexus/coreapi/v22.2.11/governance/api"
+	keymanagerDamask "github.com/oasisprotocol/nexus/coreapi/v22.2.11/keymanager/api"
 	registryDamask "github.com/oasisprotocol/nexus/coreapi/v22.2.11/registry/api"
 	roothashDamask "github.com/oasisprotocol/nexus/coreapi/v22.2.11/roothash/api"
 	stakingDamask "github.com/oasisprotocol/nexus/coreapi/v22.2.11/staking/api"
@@ -351,3 +352,14 @@ func ConvertGenesis(d genesisDamask.Document) *nodeapi.GenesisDocument {
 		},
 	}
 }
+
+func convertKeyManagerStatus(s keymanagerDamask.Status) nodeapi.KeyManagerStatus {
+	return nodeapi.KeyManagerStatus{
+		ID:            s.ID,
+		IsInitialized: s.IsInitialized,
+		IsSecure:      s.IsSecure,
+		Checksum:      s.Checksum,
+		Nodes:         s.Nodes,
+		Policy:        common.TryAsJSON(s.Policy),
+	}
+}
@@ -15,6 +15,7 @@ import (
 	consensusTx "github.com/oasisprotocol/nexus/coreapi/v22.2.11/consensus/api/transaction"
 	genesis "github.com/oasisprotocol/nexus/coreapi/v22.2.11/genesis/api"
 	governance "github.com/oasisprotocol/nexus/coreapi/v22.2.11/governance/api"
+	keymanager "github.com/oasisprotocol/nexus/coreapi/v22.2.11/keymanager/api"
 	registry "github.com/oasisprotocol/nexus/coreapi/v22.2.11/registry/api"
 	roothash "github.com/oasisprotocol/nexus/coreapi/v22.2.11/roothash/api"
 	scheduler "github.com/oasisprotocol/nexus/coreapi/v22.2.11/scheduler/api"
@@ -233,6 +234,48 @@ func (c *ConsensusApiLite) GetProposal(ctx context.Context, height int64, propos
 	return (*nodeapi.Proposal)(convertProposal(rsp)), nil
 }
 
+func (c *ConsensusApiLite) GetGovernanceParameters(ctx context.Context, height int64) (*nodeapi.GovernanceParameters, error) {
+	var rsp governance.ConsensusParameters
+	if err := c.grpcConn.Invoke(ctx, "/oasis-core.Governance/ConsensusParameters", height, &rsp); err != nil {
+		return nil, fmt.Errorf("GetGovernanceParameters(%d): %w", height, err)
+	}
+	return &nodeapi.GovernanceParameters{
+		StakeThreshold: rsp.StakeThreshold,
+	}, nil
+}
+
+func (c *ConsensusApiLite) VaultEvents(ctx context.Context, height int64) ([]nodeapi.Event, error) {
+	// The vault module does not exist in Damask.
+	return []nodeapi.Event{}, nil
+}
+
+func (c *ConsensusApiLite) GetVaults(ctx context.Context, height int64) ([]nodeapi.Vault, error) {
+	// The vault module does not exist in Damask.
+	return []nodeapi.Vault{}, nil
+}
+
+func (c *ConsensusApiLite) GetVaultPendingActions(ctx context.Context, height int64, address nodeapi.Address) ([]nodeapi.VaultPendingAction, error) {
+	// The vault module does not exist in Damask.
+	return []nodeapi.VaultPendingAction{}, nil
+}
+
+func (c *ConsensusApiLite) GetKeyManagerStatuses(ctx context.Context, height int64) ([]nodeapi.KeyManagerStatus, error) {
+	var rsp []*keymanager.Status
+	if err := c.grpcConn.Invoke(ctx, "/oasis-core.KeyManager/GetStatuses", height, &rsp); err != nil {
+		return nil, fmt.Errorf("GetKeyManagerStatuses(%d): %w", height, err)
+	}
+	statuses := make([]nodeapi.KeyManagerStatus, len(rsp))
+	for i, s := range rsp {
+		statuses[i] = convertKeyManagerStatus(*s)
+	}
+	return statuses, nil
+}
+
+func (c *ConsensusApiLite) GetChurpStatuses(ctx context.Context, height int64) ([]nodeapi.ChurpStatus, error) {
+	// CHURP does not exist in Damask.
+	return []nodeapi.ChurpStatus{}, nil
+}
+
 func (c *ConsensusApiLite) GetAccount(ctx context.Context, height int64, address nodeapi.Address) (*nodeapi.Account, error) {
 	var rsp *nodeapi.Account
 	if err := c.grpcConn.Invoke(ctx, "/oasis-core.Staking/Account", &staking.OwnerQuery{
@@ -8,6 +8,7 @@ import (
 
 	coreCommon "github.com/oasisprotocol/oasis-core/go/common"
 
+	beacon "github.com/oasisprotocol/nexus/coreapi/v22.2.11/beacon/api"
 	"github.com/oasisprotocol/nexus/coreapi/v22.2.11/common/node"
 	governance "github.com/oasisprotocol/nexus/coreapi/v22.2.11/governance/api"
 	registry "github.com/oasisprotocol/nexus/coreapi/v22.2.11/registry/api"
@@ -24,10 +25,13 @@ import (
 	txResultsEden "github.com/oasisprotocol/nexus/coreapi/v24.0/consensus/api/transaction/results"
 	genesisEden "github.com/oasisprotocol/nexus/coreapi/v24.0/genesis/api"
 	governanceEden "github.com/oasisprotocol/nexus/coreapi/v24.0/governance/api"
+	keymanagerChurpEden "github.com/oasisprotocol/nexus/coreapi/v24.0/keymanager/churp"
+	keymanagerSecretsEden "github.com/oasisprotocol/nexus/coreapi/v24.0/keymanager/secrets"
 	registryEden "github.com/oasisprotocol/nexus/coreapi/v24.0/registry/api"
 	roothashEden "github.com/oasisprotocol/nexus/coreapi/v24.0/roothash/api"
 	schedulerEden "github.com/oasisprotocol/nexus/coreapi/v24.0/scheduler/api"
 	stakingEden "github.com/oasisprotocol/nexus/coreapi/v24.0/staking/api"
+	vaultEden "github.com/oasisprotocol/nexus/coreapi/v24.0/vault/api"
 )
 
 func convertAccount(a *stakingEden.Account) *staking.Account {
@@ -456,6 +460,138 @@ func convertGovernanceEvent(e governanceEden.Event) nodeapi.Event {
 	return ret
 }
 
+func convertVaultState(s vaultEden.State) string {
+	switch s {
+	case vaultEden.StateSuspended:
+		return "suspended"
+	case vaultEden.StateActive:
+		return "active"
+	default:
+		return "unknown"
+	}
+}
+
+func convertVaultEvent(e vaultEden.Event) nodeapi.Event {
+	ret := nodeapi.Event{}
+	switch {
+	case e.ActionSubmitted != nil:
+		ret = nodeapi.Event{
+			VaultActionSubmitted: &nodeapi.VaultActionSubmittedEvent{
+				Submitter: e.ActionSubmitted.Submitter,
+				Vault:     e.ActionSubmitted.Vault,
+				Nonce:     e.ActionSubmitted.Nonce,
+			},
+			RawBody: common.TryAsJSON(e.ActionSubmitted),
+			Type:    apiTypes.ConsensusEventTypeVaultActionSubmitted,
+		}
+	case e.ActionCanceled != nil:
+		ret = nodeapi.Event{
+			VaultActionCanceled: &nodeapi.VaultActionCanceledEvent{
+				Vault: e.ActionCanceled.Vault,
+				Nonce: e.ActionCanceled.Nonce,
+			},
+			RawBody: common.TryAsJSON(e.ActionCanceled),
+			Type:    apiTypes.ConsensusEventTypeVaultActionCanceled,
+		}
+	case e.ActionExecuted != nil:
+		ret = nodeapi.Event{
+			VaultActionExecuted: &nodeapi.VaultActionExecutedEvent{
+				Vault: e.ActionExecuted.Vault,
+				Nonce: e.ActionExecuted.Nonce,
+			},
+			RawBody: common.TryAsJSON(e.ActionExecuted),
+			Type:    apiTypes.ConsensusEventTypeVaultActionExecuted,
+		}
+	case e.StateChanged != nil:
+		ret = nodeapi.Event{
+			VaultStateChanged: &nodeapi.VaultStateChangedEvent{
+				Vault:    e.StateChanged.Vault,
+				OldState: convertVaultState(e.StateChanged.OldState),
+				NewState: convertVaultState(e.StateChanged.NewState),
+			},
+			RawBody: common.TryAsJSON(e.StateChanged),
+			Type:    apiTypes.ConsensusEventTypeVaultStateChanged,
+		}
+	case e.PolicyUpdated != nil:
+		ret = nodeapi.Event{
+			VaultPolicyUpdated: &nodeapi.VaultPolicyUpdatedEvent{
+				Vault:   e.PolicyUpdated.Vault,
+				Address: e.PolicyUpdated.Address,
+			},
+			RawBody: common.TryAsJSON(e.PolicyUpdated),
+			Type:    apiTypes.ConsensusEventTypeVaultPolicyUpdated,
+		}
+	case e.AuthorityUpdated != nil:
+		ret = nodeapi.Event{
+			VaultAuthorityUpdated: &nodeapi.VaultAuthorityUpdatedEvent{
+				Vault: e.AuthorityUpdated.Vault,
+			},
+			RawBody: common.TryAsJSON(e.AuthorityUpdated),
+			Type:    apiTypes.ConsensusEventTypeVaultAuthorityUpdated,
+		}
+	}
+	ret.Height = e.Height
+	ret.TxHash = e.TxHash
+	return ret
+}
+
+func convertVaultAuthority(a vaultEden.Authority) nodeapi.VaultAuthority {
+	return nodeapi.VaultAuthority{
+		Addresses: a.Addresses,
+		Threshold: a.Threshold,
+	}
+}
+
+func convertVault(v vaultEden.Vault) nodeapi.Vault {
+	return nodeapi.Vault{
+		Creator:          v.Creator,
+		ID:               v.ID,
+		State:            convertVaultState(v.State),
+		Nonce:            v.Nonce,
+		AdminAuthority:   convertVaultAuthority(v.AdminAuthority),
+		SuspendAuthority: convertVaultAuthority(v.SuspendAuthority),
+	}
+}
+
+func convertVaultPendingAction(a vaultEden.PendingAction) nodeapi.VaultPendingAction {
+	return nodeapi.VaultPendingAction{
+		Nonce:        a.Nonce,
+		AuthorizedBy: a.AuthorizedBy,
+		Action:       common.TryAsJSON(a.Action),
+	}
+}
+
+func convertKeyManagerStatus(s keymanagerSecretsEden.Status) nodeapi.KeyManagerStatus {
+	return nodeapi.KeyManagerStatus{
+		ID:            s.ID,
+		IsInitialized: s.IsInitialized,
+		IsSecure:      s.IsSecure,
+		Generation:    s.Generation,
+		RotationEpoch: beacon.EpochTime(s.RotationEpoch),
+		Checksum:      s.Checksum,
+		Nodes:         s.Nodes,
+		RSK:           s.RSK,
+		Policy:        common.TryAsJSON(s.Policy),
+	}
+}
+
+func convertChurpStatus(s keymanagerChurpEden.Status) nodeapi.ChurpStatus {
+	return nodeapi.ChurpStatus{
+		ID:              s.ID,
+		RuntimeID:       s.RuntimeID,
+		SuiteID:         s.SuiteID,
+		Threshold:       s.Threshold,
+		ExtraShares:     s.ExtraShares,
+		HandoffInterval: beacon.EpochTime(s.HandoffInterval),
+		Handoff:         beacon.EpochTime(s.Handoff),
+		NextHandoff:     beacon.EpochTime(s.NextHandoff),
+		Checksum:        s.Checksum,
+		NextChecksum:    s.NextChecksum,
+		Committee:       s.Committee,
+		Policy:          common.TryAsJSON(s.Policy),
+	}
+}
+
 func convertEvent(e txResultsEden.Event) nodeapi.Event {
 	switch {
 	case e.Staking != nil:
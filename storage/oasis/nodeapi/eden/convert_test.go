@@ -0,0 +1,134 @@
+package eden
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/hash"
+	coreStaking "github.com/oasisprotocol/oasis-core/go/staking/api"
+
+	apiTypes "github.com/oasisprotocol/nexus/api/v1/types"
+	"github.com/oasisprotocol/nexus/storage/oasis/nodeapi"
+
+	vaultEden "github.com/oasisprotocol/nexus/coreapi/v24.0/vault/api"
+)
+
+func TestConvertVaultEvent(t *testing.T) {
+	vault := coreStaking.NewModuleAddress("test", "vault")
+	other := coreStaking.NewModuleAddress("test", "other")
+	txHash := hash.NewFromBytes([]byte("tx"))
+
+	for _, tc := range []struct {
+		name         string
+		event        vaultEden.Event
+		expected     nodeapi.Event
+		expectedBody string
+	}{
+		{
+			"action submitted",
+			vaultEden.Event{ActionSubmitted: &vaultEden.ActionSubmittedEvent{Submitter: other, Vault: vault, Nonce: 3}},
+			nodeapi.Event{
+				Type:                 apiTypes.ConsensusEventTypeVaultActionSubmitted,
+				VaultActionSubmitted: &nodeapi.VaultActionSubmittedEvent{Submitter: other, Vault: vault, Nonce: 3},
+			},
+			`{"submitter":"` + other.String() + `","vault":"` + vault.String() + `","nonce":3}`,
+		},
+		{
+			"action canceled",
+			vaultEden.Event{ActionCanceled: &vaultEden.ActionCanceledEvent{Vault: vault, Nonce: 3}},
+			nodeapi.Event{
+				Type:                apiTypes.ConsensusEventTypeVaultActionCanceled,
+				VaultActionCanceled: &nodeapi.VaultActionCanceledEvent{Vault: vault, Nonce: 3},
+			},
+			`{"vault":"` + vault.String() + `","nonce":3}`,
+		},
+		{
+			"action executed",
+			vaultEden.Event{ActionExecuted: &vaultEden.ActionExecutedEvent{Vault: vault, Nonce: 3}},
+			nodeapi.Event{
+				Type:                apiTypes.ConsensusEventTypeVaultActionExecuted,
+				VaultActionExecuted: &nodeapi.VaultActionExecutedEvent{Vault: vault, Nonce: 3},
+			},
+			`{"vault":"` + vault.String() + `","nonce":3,"result":{}}`,
+		},
+		{
+			"state changed",
+			vaultEden.Event{StateChanged: &vaultEden.StateChangedEvent{Vault: vault, OldState: vaultEden.StateActive, NewState: vaultEden.StateSuspended}},
+			nodeapi.Event{
+				Type:              apiTypes.ConsensusEventTypeVaultStateChanged,
+				VaultStateChanged: &nodeapi.VaultStateChangedEvent{Vault: vault, OldState: "active", NewState: "suspended"},
+			},
+			`{"vault":"` + vault.String() + `","old_state":1,"new_state":0}`,
+		},
+		{
+			"policy updated",
+			vaultEden.Event{PolicyUpdated: &vaultEden.PolicyUpdatedEvent{Vault: vault, Address: other}},
+			nodeapi.Event{
+				Type:               apiTypes.ConsensusEventTypeVaultPolicyUpdated,
+				VaultPolicyUpdated: &nodeapi.VaultPolicyUpdatedEvent{Vault: vault, Address: other},
+			},
+			`{"vault":"` + vault.String() + `","address":"` + other.String() + `"}`,
+		},
+		{
+			"authority updated",
+			vaultEden.Event{AuthorityUpdated: &vaultEden.AuthorityUpdatedEvent{Vault: vault}},
+			nodeapi.Event{
+				Type:                  apiTypes.ConsensusEventTypeVaultAuthorityUpdated,
+				VaultAuthorityUpdated: &nodeapi.VaultAuthorityUpdatedEvent{Vault: vault},
+			},
+			`{"vault":"` + vault.String() + `"}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.event.Height = 42
+			tc.event.TxHash = txHash
+			event := convertVaultEvent(tc.event)
+
+			require.JSONEq(t, tc.expectedBody, string(event.RawBody))
+			event.RawBody = nil
+			tc.expected.Height = 42
+			tc.expected.TxHash = txHash
+			require.Equal(t, tc.expected, event)
+		})
+	}
+}
+
+func TestConvertVault(t *testing.T) {
+	creator := coreStaking.NewModuleAddress("test", "creator")
+	admin := coreStaking.NewModuleAddress("test", "admin")
+	suspender := coreStaking.NewModuleAddress("test", "suspender")
+
+	vault := convertVault(vaultEden.Vault{
+		Creator:          creator,
+		ID:               3,
+		State:            vaultEden.StateActive,
+		Nonce:            5,
+		AdminAuthority:   vaultEden.Authority{Addresses: []coreStaking.Address{admin, suspender}, Threshold: 2},
+		SuspendAuthority: vaultEden.Authority{Addresses: []coreStaking.Address{suspender}, Threshold: 1},
+	})
+	require.Equal(t, nodeapi.Vault{
+		Creator:          creator,
+		ID:               3,
+		State:            "active",
+		Nonce:            5,
+		AdminAuthority:   nodeapi.VaultAuthority{Addresses: []coreStaking.Address{admin, suspender}, Threshold: 2},
+		SuspendAuthority: nodeapi.VaultAuthority{Addresses: []coreStaking.Address{suspender}, Threshold: 1},
+	}, vault)
+
+	require.Equal(t, "suspended", convertVault(vaultEden.Vault{State: vaultEden.StateSuspended}).State)
+	require.Equal(t, "unknown", convertVault(vaultEden.Vault{State: 7}).State)
+}
+
+func TestConvertVaultPendingAction(t *testing.T) {
+	admin := coreStaking.NewModuleAddress("test", "admin")
+
+	action := convertVaultPendingAction(vaultEden.PendingAction{
+		Nonce:        3,
+		AuthorizedBy: []coreStaking.Address{admin},
+		Action:       vaultEden.Action{Suspend: &vaultEden.ActionSuspend{}},
+	})
+	require.Equal(t, uint64(3), action.Nonce)
+	require.Equal(t, []coreStaking.Address{admin}, action.AuthorizedBy)
+	require.JSONEq(t, `{"suspend":{}}`, string(action.Action))
+}
@@ -24,10 +24,13 @@ import (
 	txResultsEden "github.com/oasisprotocol/nexus/coreapi/v24.0/consensus/api/transaction/results"
 	genesisEden "github.com/oasisprotocol/nexus/coreapi/v24.0/genesis/api"
 	governanceEden "github.com/oasisprotocol/nexus/coreapi/v24.0/governance/api"
+	keymanagerChurpEden "github.com/oasisprotocol/nexus/coreapi/v24.0/keymanager/churp"
+	keymanagerSecretsEden "github.com/oasisprotocol/nexus/coreapi/v24.0/keymanager/secrets"
 	registryEden "github.com/oasisprotocol/nexus/coreapi/v24.0/registry/api"
 	roothashEden "github.com/oasisprotocol/nexus/coreapi/v24.0/roothash/api"
 	schedulerEden "github.com/oasisprotocol/nexus/coreapi/v24.0/scheduler/api"
 	stakingEden "github.com/oasisprotocol/nexus/coreapi/v24.0/staking/api"
+	vaultEden "github.com/oasisprotocol/nexus/coreapi/v24.0/vault/api"
 )
 
 var logger = cmdCommon.RootLogger().WithModule("eden-consensus-api-lite")
@@ -239,6 +242,79 @@ func (c *ConsensusApiLite) GetProposal(ctx context.Context, height int64, propos
 	return (*nodeapi.Proposal)(rsp), nil
 }
 
+func (c *ConsensusApiLite) GetGovernanceParameters(ctx context.Context, height int64) (*nodeapi.GovernanceParameters, error) {
+	var rsp governanceEden.ConsensusParameters
+	if err := c.grpcConn.Invoke(ctx, "/oasis-core.Governance/ConsensusParameters", height, &rsp); err != nil {
+		return nil, fmt.Errorf("GetGovernanceParameters(%d): %w", height, err)
+	}
+	return &nodeapi.GovernanceParameters{
+		StakeThreshold: rsp.StakeThreshold,
+	}, nil
+}
+
+func (c *ConsensusApiLite) VaultEvents(ctx context.Context, height int64) ([]nodeapi.Event, error) {
+	var rsp []*vaultEden.Event
+	if err := c.grpcConn.Invoke(ctx, "/oasis-core.Vault/GetEvents", height, &rsp); err != nil {
+		return nil, fmt.Errorf("VaultEvents(%d): %w", height, err)
+	}
+	events := make([]nodeapi.Event, len(rsp))
+	for i, e := range rsp {
+		events[i] = convertVaultEvent(*e)
+	}
+	return events, nil
+}
+
+func (c *ConsensusApiLite) GetVaults(ctx context.Context, height int64) ([]nodeapi.Vault, error) {
+	var rsp []*vaultEden.Vault
+	if err := c.grpcConn.Invoke(ctx, "/oasis-core.Vault/Vaults", height, &rsp); err != nil {
+		return nil, fmt.Errorf("GetVaults(%d): %w", height, err)
+	}
+	vaults := make([]nodeapi.Vault, len(rsp))
+	for i, v := range rsp {
+		vaults[i] = convertVault(*v)
+	}
+	return vaults, nil
+}
+
+func (c *ConsensusApiLite) GetVaultPendingActions(ctx context.Context, height int64, address nodeapi.Address) ([]nodeapi.VaultPendingAction, error) {
+	var rsp []*vaultEden.PendingAction
+	if err := c.grpcConn.Invoke(ctx, "/oasis-core.Vault/PendingActions", &vaultEden.VaultQuery{
+		Height:  height,
+		Address: address,
+	}, &rsp); err != nil {
+		return nil, fmt.Errorf("GetVaultPendingActions(%d, %s): %w", height, address, err)
+	}
+	actions := make([]nodeapi.VaultPendingAction, len(rsp))
+	for i, a := range rsp {
+		actions[i] = convertVaultPendingAction(*a)
+	}
+	return actions, nil
+}
+
+func (c *ConsensusApiLite) GetKeyManagerStatuses(ctx context.Context, height int64) ([]nodeapi.KeyManagerStatus, error) {
+	var rsp []*keymanagerSecretsEden.Status
+	if err := c.grpcConn.Invoke(ctx, "/oasis-core.KeyManager/GetStatuses", height, &rsp); err != nil {
+		return nil, fmt.Errorf("GetKeyManagerStatuses(%d): %w", height, err)
+	}
+	statuses := make([]nodeapi.KeyManagerStatus, len(rsp))
+	for i, s := range rsp {
+		statuses[i] = convertKeyManagerStatus(*s)
+	}
+	return statuses, nil
+}
+
+func (c *ConsensusApiLite) GetChurpStatuses(ctx context.Context, height int64) ([]nodeapi.ChurpStatus, error) {
+	var rsp []*keymanagerChurpEden.Status
+	if err := c.grpcConn.Invoke(ctx, "/oasis-core.KeyManager.Churp/AllStatuses", height, &rsp); err != nil {
+		return nil, fmt.Errorf("GetChurpStatuses(%d): %w", height, err)
+	}
+	statuses := make([]nodeapi.ChurpStatus, len(rsp))
+	for i, s := range rsp {
+		statuses[i] = convertChurpStatus(*s)
+	}
+	return statuses, nil
+}
+
 func (c *ConsensusApiLite) GetAccount(ctx context.Context, height int64, address nodeapi.Address) (*nodeapi.Account, error) {
 	var rsp *stakingEden.Account
 	if err := c.grpcConn.Invoke(ctx, "/oasis-core.Staking/Account", &stakingEden.OwnerQuery{
@@ -0,0 +1,181 @@
+package nodeapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/oasisprotocol/nexus/common"
+)
+
+// ErrEVMTracingUnsupported is returned by EVMTraceRound if the runtime API has
+// no way of tracing EVM transactions, i.e. no trace RPC is configured.
+//
+// Replaying a transaction with EVMSimulateCall is not a substitute: it only
+// returns the output of the top-level call, not the calls it makes.
+var ErrEVMTracingUnsupported = errors.New("evm tracing unsupported: no evm trace rpc configured")
+
+// EVMTransactionTrace is the call trace of one EVM transaction of a round.
+type EVMTransactionTrace struct {
+	// TxHash is the Ethereum-style hash of the transaction.
+	TxHash []byte
+	// Call is the top-level call of the transaction.
+	Call EVMCallFrame
+}
+
+// EVMCallFrame is a call (or contract creation or self-destruct) made during
+// the execution of an EVM transaction, along with the calls it made, as
+// reported by the geth-compatible `callTracer`.
+type EVMCallFrame struct {
+	// Type is one of CALL, STATICCALL, DELEGATECALL, CALLCODE, CREATE,
+	// CREATE2, SELFDESTRUCT.
+	Type    string
+	From    []byte
+	To      []byte
+	Value   common.BigInt
+	Gas     uint64
+	GasUsed uint64
+	Input   []byte
+	Output  []byte
+	// Error is empty if the call succeeded.
+	Error string
+	Calls []EVMCallFrame
+}
+
+// EVMTraceClient fetches call traces from the `debug` namespace of an Ethereum
+// JSON-RPC endpoint, e.g. an oasis-web3-gateway with tracing enabled. In
+// Oasis EVM runtimes, the Ethereum block number is the runtime round.
+type EVMTraceClient struct {
+	url    string
+	client *http.Client
+	nextID atomic.Uint64
+}
+
+func NewEVMTraceClient(url string) *EVMTraceClient {
+	return &EVMTraceClient{
+		url:    url,
+		client: &http.Client{},
+	}
+}
+
+type jsonRPCRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type jsonRPCResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// callTracerFrame is the JSON encoding of a call frame by `callTracer`.
+type callTracerFrame struct {
+	Type    string            `json:"type"`
+	From    hexutil.Bytes     `json:"from"`
+	To      hexutil.Bytes     `json:"to"`
+	Value   *hexutil.Big      `json:"value"`
+	Gas     hexutil.Uint64    `json:"gas"`
+	GasUsed hexutil.Uint64    `json:"gasUsed"`
+	Input   hexutil.Bytes     `json:"input"`
+	Output  hexutil.Bytes     `json:"output"`
+	Error   string            `json:"error"`
+	Calls   []callTracerFrame `json:"calls"`
+}
+
+func (f *callTracerFrame) toFrame() EVMCallFrame {
+	frame := EVMCallFrame{
+		Type:    f.Type,
+		From:    f.From,
+		To:      f.To,
+		Value:   common.NewBigInt(0),
+		Gas:     uint64(f.Gas),
+		GasUsed: uint64(f.GasUsed),
+		Input:   f.Input,
+		Output:  f.Output,
+		Error:   f.Error,
+	}
+	if f.Value != nil {
+		frame.Value = common.BigInt{Int: *new(big.Int).Set(f.Value.ToInt())}
+	}
+	for i := range f.Calls {
+		frame.Calls = append(frame.Calls, f.Calls[i].toFrame())
+	}
+	return frame
+}
+
+// TraceBlock returns the call traces of the EVM transactions of a round, in
+// the order of execution.
+func (c *EVMTraceClient) TraceBlock(ctx context.Context, round uint64) ([]EVMTransactionTrace, error) {
+	reqBody, err := json.Marshal(jsonRPCRequest{
+		JSONRPC: "2.0",
+		ID:      c.nextID.Add(1),
+		Method:  "debug_traceBlockByNumber",
+		Params: []interface{}{
+			hexutil.EncodeUint64(round),
+			map[string]string{"tracer": "callTracer"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("trace rpc returned http status %s", resp.Status)
+	}
+	var rpcResp jsonRPCResponse
+	if err = json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return nil, fmt.Errorf("decoding trace rpc response: %w", err)
+	}
+	if rpcResp.Error != nil {
+		return nil, fmt.Errorf("trace rpc error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+	}
+	return parseBlockTraces(rpcResp.Result)
+}
+
+func parseBlockTraces(result json.RawMessage) ([]EVMTransactionTrace, error) {
+	var txTraces []struct {
+		TxHash hexutil.Bytes   `json:"txHash"`
+		Result callTracerFrame `json:"result"`
+		Error  string          `json:"error"`
+	}
+	if err := json.Unmarshal(result, &txTraces); err != nil {
+		return nil, fmt.Errorf("decoding block traces: %w", err)
+	}
+	traces := make([]EVMTransactionTrace, len(txTraces))
+	for i, t := range txTraces {
+		if t.Error != "" {
+			return nil, fmt.Errorf("tracing tx %d: %s", i, t.Error)
+		}
+		if len(t.TxHash) == 0 {
+			// Older tracers do not report the hash, and we cannot match the
+			// trace to its transaction without it.
+			return nil, fmt.Errorf("trace of tx %d has no tx hash", i)
+		}
+		traces[i] = EVMTransactionTrace{
+			TxHash: t.TxHash,
+			Call:   t.Result.toFrame(),
+		}
+	}
+	return traces, nil
+}
@@ -0,0 +1,54 @@
+package nodeapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBlockTraces(t *testing.T) {
+	result := json.RawMessage(`[{
+		"txHash": "0x0102",
+		"result": {
+			"type": "CALL",
+			"from": "0x00000000000000000000000000000000000000aa",
+			"to": "0x00000000000000000000000000000000000000bb",
+			"value": "0xde0b6b3a7640000",
+			"gas": "0x5208",
+			"gasUsed": "0x5000",
+			"input": "0x",
+			"calls": [{
+				"type": "CREATE2",
+				"from": "0x00000000000000000000000000000000000000bb",
+				"gas": "0x100",
+				"gasUsed": "0x100",
+				"input": "0x6080",
+				"error": "out of gas"
+			}]
+		}
+	}]`)
+	traces, err := parseBlockTraces(result)
+	require.NoError(t, err)
+	require.Len(t, traces, 1)
+	require.Equal(t, []byte{1, 2}, traces[0].TxHash)
+
+	call := traces[0].Call
+	require.Equal(t, "CALL", call.Type)
+	require.Equal(t, byte(0xbb), call.To[19])
+	require.Equal(t, "1000000000000000000", call.Value.String())
+	require.Equal(t, uint64(21000), call.Gas)
+	require.Empty(t, call.Error)
+	require.Len(t, call.Calls, 1)
+
+	create := call.Calls[0]
+	require.Equal(t, "CREATE2", create.Type)
+	require.Empty(t, create.To)
+	require.Equal(t, "0", create.Value.String())
+	require.Equal(t, []byte{0x60, 0x80}, create.Input)
+	require.Equal(t, "out of gas", create.Error)
+
+	// Traces without tx hashes cannot be matched to transactions.
+	_, err = parseBlockTraces(json.RawMessage(`[{"result": {"type": "CALL"}}]`))
+	require.Error(t, err)
+}
@@ -194,6 +194,58 @@ func (c *FileConsensusApiLite) GetProposal(ctx context.Context, height int64, pr
 	)
 }
 
+func (c *FileConsensusApiLite) GetGovernanceParameters(ctx context.Context, height int64) (*nodeapi.GovernanceParameters, error) {
+	return kvstore.GetFromCacheOrCall(
+		c.db, height == consensus.HeightLatest,
+		kvstore.GenerateCacheKey("GetGovernanceParameters", height),
+		func() (*nodeapi.GovernanceParameters, error) {
+			return c.consensusApi.GetGovernanceParameters(ctx, height)
+		},
+	)
+}
+
+func (c *FileConsensusApiLite) VaultEvents(ctx context.Context, height int64) ([]nodeapi.Event, error) {
+	return kvstore.GetSliceFromCacheOrCall(
+		c.db, height == consensus.HeightLatest,
+		kvstore.GenerateCacheKey("VaultEvents", height),
+		func() ([]nodeapi.Event, error) { return c.consensusApi.VaultEvents(ctx, height) },
+	)
+}
+
+func (c *FileConsensusApiLite) GetVaults(ctx context.Context, height int64) ([]nodeapi.Vault, error) {
+	return kvstore.GetSliceFromCacheOrCall(
+		c.db, height == consensus.HeightLatest,
+		kvstore.GenerateCacheKey("GetVaults", height),
+		func() ([]nodeapi.Vault, error) { return c.consensusApi.GetVaults(ctx, height) },
+	)
+}
+
+func (c *FileConsensusApiLite) GetVaultPendingActions(ctx context.Context, height int64, address nodeapi.Address) ([]nodeapi.VaultPendingAction, error) {
+	return kvstore.GetSliceFromCacheOrCall(
+		c.db, height == consensus.HeightLatest,
+		kvstore.GenerateCacheKey("GetVaultPendingActions", height, address),
+		func() ([]nodeapi.VaultPendingAction, error) {
+			return c.consensusApi.GetVaultPendingActions(ctx, height, address)
+		},
+	)
+}
+
+func (c *FileConsensusApiLite) GetKeyManagerStatuses(ctx context.Context, height int64) ([]nodeapi.KeyManagerStatus, error) {
+	return kvstore.GetSliceFromCacheOrCall(
+		c.db, height == consensus.HeightLatest,
+		kvstore.GenerateCacheKey("GetKeyManagerStatuses", height),
+		func() ([]nodeapi.KeyManagerStatus, error) { return c.consensusApi.GetKeyManagerStatuses(ctx, height) },
+	)
+}
+
+func (c *FileConsensusApiLite) GetChurpStatuses(ctx context.Context, height int64) ([]nodeapi.ChurpStatus, error) {
+	return kvstore.GetSliceFromCacheOrCall(
+		c.db, height == consensus.HeightLatest,
+		kvstore.GenerateCacheKey("GetChurpStatuses", height),
+		func() ([]nodeapi.ChurpStatus, error) { return c.consensusApi.GetChurpStatuses(ctx, height) },
+	)
+}
+
 func (c *FileConsensusApiLite) GetAccount(ctx context.Context, height int64, address nodeapi.Address) (*nodeapi.Account, error) {
 	return kvstore.GetFromCacheOrCall(
 		c.db, height == consensus.HeightLatest,
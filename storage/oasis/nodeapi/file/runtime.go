@@ -107,3 +107,23 @@ func (r *FileRuntimeApiLite) EVMGetCode(ctx context.Context, round uint64, addre
 		},
 	)
 }
+
+func (r *FileRuntimeApiLite) EVMGetStorage(ctx context.Context, round uint64, address []byte, index []byte) ([]byte, error) {
+	return kvstore.GetSliceFromCacheOrCall(
+		r.db, round == roothash.RoundLatest,
+		kvstore.GenerateCacheKey("EVMGetStorage", r.runtime, round, address, index),
+		func() ([]byte, error) {
+			return r.runtimeApi.EVMGetStorage(ctx, round, address, index)
+		},
+	)
+}
+
+func (r *FileRuntimeApiLite) EVMTraceRound(ctx context.Context, round uint64) ([]nodeapi.EVMTransactionTrace, error) {
+	return kvstore.GetSliceFromCacheOrCall(
+		r.db, round == roothash.RoundLatest,
+		kvstore.GenerateCacheKey("EVMTraceRound", r.runtime, round),
+		func() ([]nodeapi.EVMTransactionTrace, error) {
+			return r.runtimeApi.EVMTraceRound(ctx, round)
+		},
+	)
+}
@@ -240,6 +240,54 @@ func (c *HistoryConsensusApiLite) GetProposal(ctx context.Context, height int64,
 	return api.GetProposal(ctx, height, proposalID)
 }
 
+func (c *HistoryConsensusApiLite) GetGovernanceParameters(ctx context.Context, height int64) (*nodeapi.GovernanceParameters, error) {
+	api, err := c.APIForHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("getting api for height %d: %w", height, err)
+	}
+	return api.GetGovernanceParameters(ctx, height)
+}
+
+func (c *HistoryConsensusApiLite) VaultEvents(ctx context.Context, height int64) ([]nodeapi.Event, error) {
+	api, err := c.APIForHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("getting api for height %d: %w", height, err)
+	}
+	return api.VaultEvents(ctx, height)
+}
+
+func (c *HistoryConsensusApiLite) GetVaults(ctx context.Context, height int64) ([]nodeapi.Vault, error) {
+	api, err := c.APIForHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("getting api for height %d: %w", height, err)
+	}
+	return api.GetVaults(ctx, height)
+}
+
+func (c *HistoryConsensusApiLite) GetVaultPendingActions(ctx context.Context, height int64, address nodeapi.Address) ([]nodeapi.VaultPendingAction, error) {
+	api, err := c.APIForHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("getting api for height %d: %w", height, err)
+	}
+	return api.GetVaultPendingActions(ctx, height, address)
+}
+
+func (c *HistoryConsensusApiLite) GetKeyManagerStatuses(ctx context.Context, height int64) ([]nodeapi.KeyManagerStatus, error) {
+	api, err := c.APIForHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("getting api for height %d: %w", height, err)
+	}
+	return api.GetKeyManagerStatuses(ctx, height)
+}
+
+func (c *HistoryConsensusApiLite) GetChurpStatuses(ctx context.Context, height int64) ([]nodeapi.ChurpStatus, error) {
+	api, err := c.APIForHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("getting api for height %d: %w", height, err)
+	}
+	return api.GetChurpStatuses(ctx, height)
+}
+
 func (c *HistoryConsensusApiLite) GetAccount(ctx context.Context, height int64, address nodeapi.Address) (*nodeapi.Account, error) {
 	api, err := c.APIForHeight(height)
 	if err != nil {
@@ -38,7 +38,11 @@ func NewHistoryRuntimeApiLite(ctx context.Context, history *config.History, sdkP
 			}
 			sdkClient := sdkConn.Runtime(sdkPT)
 			rawConn := connections.NewLazyGrpcConn(*archiveConfig.ResolvedRuntimeNode(runtime))
-			apis[record.ArchiveName] = nodeapi.NewUniversalRuntimeApiLite(sdkPT.Namespace(), rawConn, &sdkClient)
+			var evmTracer *nodeapi.EVMTraceClient
+			if traceRPC := archiveConfig.ResolvedRuntimeNode(runtime).EVMTraceRPC; traceRPC != "" {
+				evmTracer = nodeapi.NewEVMTraceClient(traceRPC)
+			}
+			apis[record.ArchiveName] = nodeapi.NewUniversalRuntimeApiLite(sdkPT.Namespace(), rawConn, &sdkClient, evmTracer)
 		}
 	}
 	return &HistoryRuntimeApiLite{
@@ -98,6 +102,14 @@ func (rc *HistoryRuntimeApiLite) EVMGetCode(ctx context.Context, round uint64, a
 	return api.EVMGetCode(ctx, round, address)
 }
 
+func (rc *HistoryRuntimeApiLite) EVMGetStorage(ctx context.Context, round uint64, address []byte, index []byte) ([]byte, error) {
+	api, err := rc.APIForRound(round)
+	if err != nil {
+		return nil, fmt.Errorf("getting api for runtime %s round %d: %w", rc.Runtime, round, err)
+	}
+	return api.EVMGetStorage(ctx, round, address, index)
+}
+
 func (rc *HistoryRuntimeApiLite) GetBlockHeader(ctx context.Context, round uint64) (*nodeapi.RuntimeBlockHeader, error) {
 	api, err := rc.APIForRound(round)
 	if err != nil {
@@ -121,3 +133,11 @@ func (rc *HistoryRuntimeApiLite) GetTransactionsWithResults(ctx context.Context,
 	}
 	return api.GetTransactionsWithResults(ctx, round)
 }
+
+func (rc *HistoryRuntimeApiLite) EVMTraceRound(ctx context.Context, round uint64) ([]nodeapi.EVMTransactionTrace, error) {
+	api, err := rc.APIForRound(round)
+	if err != nil {
+		return nil, fmt.Errorf("getting api for runtime %s round %d: %w", rc.Runtime, round, err)
+	}
+	return api.EVMTraceRound(ctx, round)
+}
@@ -42,15 +42,20 @@ type UniversalRuntimeApiLite struct {
 	// provides nontrivial wrappers/parsing around raw RPC responses, making
 	// this preferable to raw gRPC.
 	sdkClient *connection.RuntimeClient
+
+	// A client for EVM call traces, or nil if no trace RPC is configured.
+	// The node itself cannot trace transactions.
+	evmTracer *EVMTraceClient
 }
 
 var _ RuntimeApiLite = (*UniversalRuntimeApiLite)(nil)
 
-func NewUniversalRuntimeApiLite(runtimeID coreCommon.Namespace, grpcConn connections.GrpcConn, sdkClient *connection.RuntimeClient) *UniversalRuntimeApiLite {
+func NewUniversalRuntimeApiLite(runtimeID coreCommon.Namespace, grpcConn connections.GrpcConn, sdkClient *connection.RuntimeClient, evmTracer *EVMTraceClient) *UniversalRuntimeApiLite {
 	return &UniversalRuntimeApiLite{
 		runtimeID: runtimeID,
 		grpcConn:  grpcConn,
 		sdkClient: sdkClient,
+		evmTracer: evmTracer,
 	}
 }
 
@@ -163,6 +168,17 @@ func (rc *UniversalRuntimeApiLite) EVMGetCode(ctx context.Context, round uint64,
 	return rc.sdkClient.Evm.Code(ctx, round, address)
 }
 
+func (rc *UniversalRuntimeApiLite) EVMGetStorage(ctx context.Context, round uint64, address []byte, index []byte) ([]byte, error) {
+	return rc.sdkClient.Evm.Storage(ctx, round, address, index)
+}
+
+func (rc *UniversalRuntimeApiLite) EVMTraceRound(ctx context.Context, round uint64) ([]EVMTransactionTrace, error) {
+	if rc.evmTracer == nil {
+		return nil, ErrEVMTracingUnsupported
+	}
+	return rc.evmTracer.TraceBlock(ctx, round)
+}
+
 func (rc *UniversalRuntimeApiLite) GetBalances(ctx context.Context, round uint64, addr Address) (map[sdkTypes.Denomination]common.BigInt, error) {
 	nodeBalances, err := rc.sdkClient.Accounts.Balances(ctx, round, sdkTypes.Address(addr))
 	if err != nil {
@@ -93,7 +93,56 @@ func NewClient(connString string, l *log.Logger) (*Client, error) {
 // by any nexus. We only care about atomic success or failure of the batch of queries
 // corresponding to a new block.
 func (c *Client) SendBatch(ctx context.Context, batch *storage.QueryBatch) error {
-	return c.SendBatchWithOptions(ctx, batch, pgx.TxOptions{})
+	return c.SendBatchWithOptions(ctx, batch, storage.TxOptions{})
+}
+
+// pgxTxOptions converts backend-independent transaction options to pgx ones.
+func pgxTxOptions(opts storage.TxOptions) pgx.TxOptions {
+	return pgx.TxOptions{IsoLevel: pgx.TxIsoLevel(opts.IsoLevel)}
+}
+
+// asPgxBatch converts a QueryBatch to a pgx.Batch.
+func asPgxBatch(batch *storage.QueryBatch) pgx.Batch {
+	pgxBatch := pgx.Batch{}
+	for _, item := range batch.Queries() {
+		pgxBatch.Queue(item.Cmd, item.Args...)
+	}
+	return pgxBatch
+}
+
+// row adapts pgx.Row to storage.QueryResult.
+type row struct {
+	pgx.Row
+}
+
+func (r row) Scan(dest ...interface{}) error {
+	if err := r.Row.Scan(dest...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return storage.ErrNoRows
+		}
+		return err
+	}
+	return nil
+}
+
+// tx adapts pgx.Tx to storage.Tx.
+type tx struct {
+	pgx.Tx
+}
+
+func (t tx) Query(ctx context.Context, sql string, args ...interface{}) (storage.QueryResults, error) {
+	return t.Tx.Query(ctx, sql, args...)
+}
+
+func (t tx) QueryRow(ctx context.Context, sql string, args ...interface{}) storage.QueryResult {
+	return row{t.Tx.QueryRow(ctx, sql, args...)}
+}
+
+func (t tx) Rollback(ctx context.Context) error {
+	if err := t.Tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+		return err
+	}
+	return nil
 }
 
 // Starts a new DB transaction and runs fn() with it. Takes care of committing
@@ -125,13 +174,13 @@ func (c *Client) WithTx(
 	return nil
 }
 
-// Submits a new batch. Under the hood, uses `tx.SendBatch(batch.AsPgxBatch())`,
+// Submits a new batch. Under the hood, uses `tx.SendBatch(asPgxBatch(batch))`,
 // which is more efficient as it happens in a single roundtrip to the server.
 // However, it reports errors poorly: If _any_ query is syntactically
 // malformed, called with the wrong number of args, or has a type conversion problem,
 // pgx will report the _first_ query as failing.
 func (c *Client) sendBatchWithOptionsFast(ctx context.Context, batch *storage.QueryBatch, opts pgx.TxOptions) error {
-	pgxBatch := batch.AsPgxBatch()
+	pgxBatch := asPgxBatch(batch)
 	return c.WithTx(ctx, opts, func(tx pgx.Tx) error {
 		// Read the results of indiviual queries in the batch.
 		batchResults := tx.SendBatch(ctx, &pgxBatch)
@@ -164,7 +213,8 @@ func (c *Client) sendBatchWithOptionsSlow(ctx context.Context, batch *storage.Qu
 	})
 }
 
-func (c *Client) SendBatchWithOptions(ctx context.Context, batch *storage.QueryBatch, opts pgx.TxOptions) error {
+func (c *Client) SendBatchWithOptions(ctx context.Context, batch *storage.QueryBatch, storageOpts storage.TxOptions) error {
+	opts := pgxTxOptions(storageOpts)
 	var err error
 	if err = c.sendBatchWithOptionsFast(ctx, batch, opts); err == nil {
 		// The fast path succeeded. This should happen most of the time.
@@ -182,7 +232,7 @@ func (c *Client) SendBatchWithOptions(ctx context.Context, batch *storage.QueryB
 }
 
 // Query submits a new read query to PostgreSQL.
-func (c *Client) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+func (c *Client) Query(ctx context.Context, sql string, args ...interface{}) (storage.QueryResults, error) {
 	rows, err := c.pool.Query(ctx, sql, args...)
 	if err != nil {
 		c.logger.Error("failed to query db",
@@ -196,13 +246,17 @@ func (c *Client) Query(ctx context.Context, sql string, args ...interface{}) (pg
 }
 
 // QueryRow submits a new read query for a single row to PostgreSQL.
-func (c *Client) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
-	return c.pool.QueryRow(ctx, sql, args...)
+func (c *Client) QueryRow(ctx context.Context, sql string, args ...interface{}) storage.QueryResult {
+	return row{c.pool.QueryRow(ctx, sql, args...)}
 }
 
 // Begin implements the storage.TargetStorage interface for Client.
 func (c *Client) Begin(ctx context.Context) (storage.Tx, error) {
-	return c.pool.Begin(ctx)
+	pgxTx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tx{pgxTx}, nil
 }
 
 // Close implements the storage.TargetStorage interface for Client.
@@ -210,6 +264,32 @@ func (c *Client) Close() {
 	c.pool.Close()
 }
 
+// Listen implements the storage.TargetStorage interface for Client.
+// It holds a dedicated connection from the pool for the duration of the call.
+func (c *Client) Listen(ctx context.Context, channel string, handler func(payload string)) error {
+	conn, err := c.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Do not return a listening connection to the pool. If the connection
+		// was closed due to context cancellation, the pool discards it anyway.
+		_, _ = conn.Exec(context.Background(), "UNLISTEN *")
+		conn.Release()
+	}()
+
+	if _, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return fmt.Errorf("listen on %s: %w", channel, err)
+	}
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		handler(notification.Payload)
+	}
+}
+
 // Name implements the storage.TargetStorage interface for Client.
 func (c *Client) Name() string {
 	return moduleName
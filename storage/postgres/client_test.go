@@ -7,6 +7,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -87,6 +88,19 @@ func TestQueryRow(t *testing.T) {
 	require.Equal(t, 2, result)
 }
 
+func TestQueryRowNoRows(t *testing.T) {
+	tests.SkipIfShort(t)
+
+	client := testutil.NewTestClient(t)
+	defer client.Close()
+
+	var result int
+	err := client.QueryRow(context.Background(), `
+		SELECT 1 WHERE false;
+	`).Scan(&result)
+	require.Equal(t, storage.ErrNoRows, err)
+}
+
 func TestInvalidQueryRow(t *testing.T) {
 	tests.SkipIfShort(t)
 
@@ -208,3 +222,42 @@ func TestNumeric(t *testing.T) {
 	require.Nil(t, mynull)
 	require.Equal(t, int64(2), my2.Int64())
 }
+
+func TestListen(t *testing.T) {
+	tests.SkipIfShort(t)
+	client := testutil.NewTestClient(t)
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	payloads := make(chan string, 1)
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- client.Listen(ctx, "nexus_test", func(payload string) {
+			select {
+			case payloads <- payload:
+			default:
+			}
+		})
+	}()
+
+	// Notifications sent before LISTEN takes effect are lost, so keep
+	// notifying until one arrives.
+	var payload string
+	require.Eventually(t, func() bool {
+		batch := &storage.QueryBatch{}
+		batch.Queue(`SELECT pg_notify('nexus_test', 'hello')`)
+		require.NoError(t, client.SendBatch(ctx, batch))
+		select {
+		case payload = <-payloads:
+			return true
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, "hello", payload)
+
+	cancel()
+	require.Error(t, <-listenErr)
+}
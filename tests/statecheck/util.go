@@ -7,7 +7,6 @@ import (
 	"os"
 	"testing"
 
-	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/require"
 
 	oasisConfig "github.com/oasisprotocol/oasis-sdk/client-sdk/go/config"
@@ -94,7 +93,7 @@ func snapshotBackends(target *postgres.Client, analyzer string, tables []string)
 	// Create the snapshot using a high level of isolation; we don't want another
 	// tx to be able to modify the tables while this is running, creating a snapshot that
 	// represents analyzer state at two (or more) blockchain heights.
-	if err := target.SendBatchWithOptions(ctx, batch, pgx.TxOptions{IsoLevel: pgx.Serializable}); err != nil {
+	if err := target.SendBatchWithOptions(ctx, batch, storage.TxOptions{IsoLevel: storage.Serializable}); err != nil {
 		return 0, err
 	}
 